@@ -1,11 +1,14 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
+	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/elastos/Elastos.ELA.Arbiter/arbitration/arbitrator"
 	"github.com/elastos/Elastos.ELA.Arbiter/arbitration/cs"
@@ -14,8 +17,13 @@ import (
 	"github.com/elastos/Elastos.ELA.Arbiter/config"
 	"github.com/elastos/Elastos.ELA.Arbiter/log"
 	"github.com/elastos/Elastos.ELA.Arbiter/net/servers/httpjsonrpc"
+	"github.com/elastos/Elastos.ELA.Arbiter/net/servers/httprestful"
+	"github.com/elastos/Elastos.ELA.Arbiter/net/servers/httpwebsocket"
+	"github.com/elastos/Elastos.ELA.Arbiter/notifier"
 	"github.com/elastos/Elastos.ELA.Arbiter/password"
+	"github.com/elastos/Elastos.ELA.Arbiter/rpc"
 	"github.com/elastos/Elastos.ELA.Arbiter/sideauxpow"
+	"github.com/elastos/Elastos.ELA.Arbiter/signer"
 	"github.com/elastos/Elastos.ELA.Arbiter/store"
 
 	"github.com/elastos/Elastos.ELA.SPV/interface"
@@ -30,6 +38,7 @@ var (
 	LogsPath             = filepath.Join(config.DataPath, config.LogDir)
 	ArbiterLogOutputPath = filepath.Join(LogsPath, config.ArbiterDir)
 	SpvLogOutputPath     = filepath.Join(LogsPath, config.SpvDir)
+	SignerLogOutputPath  = filepath.Join(LogsPath, config.SignerDir)
 )
 
 const (
@@ -42,6 +51,12 @@ const (
 
 var walletPath string
 var pstr string
+var pfile string
+var pfd int
+var pcmd string
+var configPath string
+var configSets overrideFlag
+var configSetFiles overrideFlag
 
 func init() {
 	v := versionFlag{}
@@ -49,6 +64,12 @@ func init() {
 	flag.StringVar(&walletPath, "wallet", "", "wallet path, default: keystore.dat")
 	flag.StringVar(&walletPath, "w", "", "wallet path, default: keystore.dat")
 	flag.StringVar(&pstr, "p", "", "wallet password")
+	flag.StringVar(&pfile, "pfile", "", "wallet password file, should not be accessible by group or others")
+	flag.IntVar(&pfd, "pfd", -1, "file descriptor to read wallet password from")
+	flag.StringVar(&pcmd, "pcmd", "", "secret provider command printing wallet password to stdout")
+	flag.StringVar(&configPath, "conf", config.DefaultConfigFilename, "config file path")
+	flag.Var(&configSets, "set", "override config value, e.g. -set MainNode.Rpc.Pass=PASS, can be repeated")
+	flag.Var(&configSetFiles, "set-file", "override config value with the content of file, e.g. -set-file MainNode.Rpc.Pass=/run/secrets/pass, can be repeated")
 	flag.Parse()
 }
 
@@ -63,8 +84,91 @@ func (r *versionFlag) Set(s string) error {
 	return nil
 }
 
-func initialize() {
-	config.Initialize()
+type overrideFlag []string
+
+func (f *overrideFlag) String() string { return strings.Join(*f, ",") }
+func (f *overrideFlag) Set(s string) error {
+	*f = append(*f, s)
+	return nil
+}
+
+// configOverrides returns the config overrides of ARBITER_* environment
+// variables and command line flags, flags take precedence.
+func configOverrides() ([]*config.Override, error) {
+	overrides, err := config.EnvOverrides(os.Environ())
+	if err != nil {
+		return nil, err
+	}
+	if walletPath != "" {
+		overrides = append(overrides, &config.Override{
+			Path: []string{"WalletPath"}, Value: walletPath, Source: "flag -w"})
+	}
+	for _, set := range configSets {
+		o, err := config.FlagOverride(set, false)
+		if err != nil {
+			return nil, err
+		}
+		overrides = append(overrides, o)
+	}
+	for _, set := range configSetFiles {
+		o, err := config.FlagOverride(set, true)
+		if err != nil {
+			return nil, err
+		}
+		overrides = append(overrides, o)
+	}
+	return overrides, nil
+}
+
+// configCommand runs the "config" sub commands, it returns the exit code.
+func configCommand(args []string) int {
+	if len(args) == 0 || len(args) > 2 || (args[0] != "check" && args[0] != "show") {
+		fmt.Println("usage: arbiter [flags] config check|show [config file]")
+		return 2
+	}
+	path := configPath
+	if len(args) == 2 {
+		path = args[1]
+	}
+	overrides, err := configOverrides()
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	cfg, err := config.Load(path, overrides...)
+	if err != nil {
+		fmt.Printf("%s: %v\n", path, err)
+		return 1
+	}
+	if args[0] == "check" {
+		fmt.Printf("%s: configuration is valid\n", path)
+		return 0
+	}
+
+	redacted, err := cfg.Redacted()
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	data, err := json.MarshalIndent(map[string]interface{}{"Configuration": redacted}, "", "  ")
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	fmt.Println(string(data))
+	return 0
+}
+
+func initialize(arbiterLogPath string) {
+	overrides, err := configOverrides()
+	if err != nil {
+		fmt.Printf("Load config overrides failed: %v\n", err)
+		os.Exit(1)
+	}
+	if err := config.Initialize(configPath, overrides...); err != nil {
+		fmt.Printf("Load config file %s failed: %v\n", configPath, err)
+		os.Exit(1)
+	}
 
 	// Initialize functions
 	functions.GetTransactionByTxType = elatx.GetTransaction
@@ -102,41 +206,92 @@ func initialize() {
 	}
 
 	log.Init(
-		ArbiterLogOutputPath,
+		arbiterLogPath,
 		config.Parameters.PrintLevel,
 		arbiterMaxPerLogFileSize,
 		arbiterMaxLogsFolderSize,
 	)
 
-	if walletPath != "" {
-		config.Parameters.WalletPath = walletPath
-	}
 	log.Info("Arbiter version:", config.Version)
-	log.Info("path:", walletPath)
+}
+
+// openWallet opens the keystore with the password from flags or user input.
+func openWallet() *account.Client {
+	log.Info("path:", config.Parameters.WalletPath)
 
 	log.Info("Init wallet.")
-	passwd, err := password.GetAccountPassword(pstr)
+	passwd, err := password.GetAccountPasswordFrom(&password.Source{
+		Password: pstr,
+		File:     pfile,
+		Fd:       pfd,
+		Command:  pcmd,
+	})
 	if err != nil {
-		log.Fatal("Get password error.")
+		log.Fatal("Get password error: ", err)
 		os.Exit(1)
 	}
 
 	c, err := account.Open(config.Parameters.WalletPath, passwd)
+	password.Zero(passwd)
 	if err != nil || c == nil {
 		log.Fatal("error: open wallet failed, ", err)
 		os.Exit(1)
 	}
+	return c
+}
 
-	sideauxpow.Init(c)
-	arbitrator.Init(c)
-	sidechain.Init()
+// initSigner connects to the signer daemon if Signer.Socket is configured,
+// otherwise opens the keystore in process.
+func initSigner() signer.Signer {
+	if config.Parameters.Signer == nil || config.Parameters.Signer.Socket == "" {
+		return signer.NewKeystore(openWallet())
+	}
+
+	log.Info("Connect signer ", config.Parameters.Signer.Socket)
+	s, err := signer.NewRemote(config.Parameters.Signer.Socket)
+	if err != nil {
+		log.Fatal("error: connect signer failed, ", err)
+		os.Exit(1)
+	}
+	return s
+}
+
+// signerCommand runs the signer daemon serving the keystore on Signer.Socket,
+// it returns the exit code.
+func signerCommand() int {
+	initialize(SignerLogOutputPath)
+	if config.Parameters.Signer == nil || config.Parameters.Signer.Socket == "" {
+		log.Fatal("Signer.Socket not configured")
+		return 1
+	}
+	policy, err := signer.NewPolicy(config.Parameters.Signer)
+	if err != nil {
+		log.Fatal("Invalid signer policy: ", err)
+		return 1
+	}
+
+	server := signer.NewServer(signer.NewKeystore(openWallet()), policy)
+	if err := server.ListenAndServe(config.Parameters.Signer.Socket); err != nil {
+		log.Fatal("Signer stopped: ", err)
+		return 1
+	}
+	return 0
 }
 
 func setSideChainAccountMonitor(arb arbitrator.Arbitrator) {
 	sidechain.SideChainAccountMonitor.ParentArbitrator = arb
-	for i, side := range arb.GetSideChainManager().GetAllChains() {
+	for _, sideNode := range config.Parameters.SideNodeList {
+		if arbitrator.IsSideChainRetired(sideNode.GenesisBlockAddress) {
+			log.Info("Skip retired side chain:", sideNode.GenesisBlockAddress)
+			arb.GetSideChainManager().RemoveChain(sideNode.GenesisBlockAddress)
+			continue
+		}
+		side, ok := arb.GetSideChainManager().GetChain(sideNode.GenesisBlockAddress)
+		if !ok {
+			continue
+		}
 		sidechain.SideChainAccountMonitor.AddListener(side)
-		go sidechain.SideChainAccountMonitor.SyncChainData(config.Parameters.SideNodeList[i], side, config.Parameters.SideNodeList[i].EffectiveHeight)
+		go sidechain.SideChainAccountMonitor.SyncChainData(sideNode, side, sideNode.EffectiveHeight)
 	}
 
 }
@@ -163,9 +318,28 @@ func initP2P(arbitrator arbitrator.Arbitrator) error {
 }
 
 func main() {
-	initialize()
+	if flag.Arg(0) == "config" {
+		os.Exit(configCommand(flag.Args()[1:]))
+	}
 
-	log.Info("1. Init chain utxo cache.")
+	if flag.Arg(0) == "signer" {
+		os.Exit(signerCommand())
+	}
+
+	initialize(ArbiterLogOutputPath)
+	arbiterSigner := initSigner()
+	sideauxpow.Init(arbiterSigner)
+	arbitrator.Init(arbiterSigner)
+	sidechain.Init()
+	for _, node := range config.Parameters.SideNodeList {
+		rpc.RegisterEndpoints(node.Name, node.Endpoints())
+	}
+
+	// subscribe before anything that may raise an alert
+	log.Info("1. Start webhook notifier.")
+	notifier.Start()
+
+	log.Info("2. Init chain utxo cache.")
 	dataStore, err := store.OpenDataStore()
 	if err != nil {
 		log.Fatalf("Data store open failed error: [s%]", err.Error())
@@ -173,7 +347,7 @@ func main() {
 	}
 	store.DbCache = *dataStore
 
-	log.Info("2. Init finished transaction cache.")
+	log.Info("3. Init finished transaction cache.")
 	finishedDataStore, err := store.OpenFinishedTxsDataStore()
 	if err != nil {
 		log.Fatalf("Side chain monitor setup error: [s%]", err.Error())
@@ -181,9 +355,95 @@ func main() {
 	}
 	store.FinishedTxsDbCache = finishedDataStore
 
+	pendingDataStore, err := store.OpenPendingTxsDataStore()
+	if err != nil {
+		log.Fatalf("Pending transactions data store open failed error: [%s]", err.Error())
+		os.Exit(1)
+	}
+	store.PendingTxsDbCache = pendingDataStore
+
+	proposalDataStore, err := store.OpenProposalDataStore()
+	if err != nil {
+		log.Fatalf("Proposal data store open failed error: [%s]", err.Error())
+		os.Exit(1)
+	}
+	store.ProposalDbCache = proposalDataStore
+
+	smallCrossDataStore, err := store.OpenSmallCrossTxsDataStore()
+	if err != nil {
+		log.Fatalf("Small cross transfers data store open failed error: [%s]", err.Error())
+		os.Exit(1)
+	}
+	store.SmallCrossTxsDbCache = smallCrossDataStore
+
+	sideMiningDataStore, err := store.OpenSideMiningDataStore()
+	if err != nil {
+		log.Fatalf("Side mining data store open failed error: [%s]", err.Error())
+		os.Exit(1)
+	}
+	store.SideMiningDbCache = sideMiningDataStore
+
+	frozenAddressDataStore, err := store.OpenFrozenAddressDataStore()
+	if err != nil {
+		log.Fatalf("Frozen address data store open failed error: [%s]", err.Error())
+		os.Exit(1)
+	}
+	store.FrozenAddressDbCache = frozenAddressDataStore
+
+	registrationDataStore, err := store.OpenRegistrationDataStore()
+	if err != nil {
+		log.Fatalf("Registration data store open failed error: [%s]", err.Error())
+		os.Exit(1)
+	}
+	store.RegistrationDbCache = registrationDataStore
+
+	decommissionDataStore, err := store.OpenDecommissionDataStore()
+	if err != nil {
+		log.Fatalf("Decommission data store open failed error: [%s]", err.Error())
+		os.Exit(1)
+	}
+	store.DecommissionDbCache = decommissionDataStore
+
+	pauseDataStore, err := store.OpenPauseDataStore()
+	if err != nil {
+		log.Fatalf("Pause data store open failed error: [%s]", err.Error())
+		os.Exit(1)
+	}
+	store.PauseDbCache = pauseDataStore
+
+	reconciliationDataStore, err := store.OpenReconciliationDataStore()
+	if err != nil {
+		log.Fatalf("Reconciliation data store open failed error: [%s]", err.Error())
+		os.Exit(1)
+	}
+	store.ReconciliationDbCache = reconciliationDataStore
+	cs.ProposalPolicySingleton = cs.NewProposalPolicy(config.Parameters.Configuration, proposalDataStore)
+	arbitrator.WithdrawRulesSingleton = arbitrator.NewWithdrawRules(config.Parameters.Configuration)
+	arbitrator.FrozenRegistrySingleton, err = arbitrator.NewFrozenRegistry(
+		config.Parameters.FrozenAddresses, frozenAddressDataStore)
+	if err != nil {
+		log.Fatalf("Frozen address registry load failed error: [%s]", err.Error())
+		os.Exit(1)
+	}
+	arbitrator.DecommissionSingleton, err = arbitrator.NewDecommissionRegistry(decommissionDataStore)
+	if err != nil {
+		log.Fatalf("Decommission registry load failed error: [%s]", err.Error())
+		os.Exit(1)
+	}
+	arbitrator.CircuitBreakerSingleton, err = arbitrator.NewCircuitBreaker(
+		config.Parameters.Configuration, pauseDataStore)
+	if err != nil {
+		log.Fatalf("Circuit breaker load failed error: [%s]", err.Error())
+		os.Exit(1)
+	}
+
 	currentArbitrator := arbitrator.ArbitratorGroupSingleton.GetCurrentArbitrator()
+	if err := sidechain.LoadApprovedSideChains(currentArbitrator); err != nil {
+		log.Fatalf("Approved side chains load failed error: [%s]", err.Error())
+		os.Exit(1)
+	}
 
-	log.Info("3. Start arbitrator P2P networks.")
+	log.Info("4. Start arbitrator P2P networks.")
 	if err := initP2P(currentArbitrator); err != nil {
 		log.Fatal(err)
 		os.Exit(1)
@@ -191,37 +451,46 @@ func main() {
 
 	setSideChainAccountMonitor(currentArbitrator)
 
-	log.Info("4. Init configurations.")
+	log.Info("5. Init configurations.")
 	if err := arbitrator.ArbitratorGroupSingleton.InitArbitrators(); err != nil {
 		log.Fatal(err)
 		os.Exit(1)
 	}
 
-	log.Info("5. Start arbitrator spv module.")
+	log.Info("6. Start arbitrator spv module.")
 	if err := currentArbitrator.StartSpvModule(); err != nil {
 		log.Fatal(err)
 		os.Exit(1)
 	}
 
-	log.Info("6. Start arbitrator group monitor.")
+	log.Info("7. Start arbitrator group monitor.")
 	go arbitrator.ArbitratorGroupSingleton.SyncLoop()
 
-	log.Info("7. Start servers.")
+	log.Info("8. Start servers.")
+	httpwebsocket.Start()
 	pServer := new(http.Server)
 	go httpjsonrpc.StartRPCServer(pServer)
+	go httprestful.StartServer()
 
-	log.Info("8. Start check and remove cross chain transactions from db.")
+	log.Info("9. Start check and remove cross chain transactions from db.")
 	go currentArbitrator.CheckAndRemoveCrossChainTransactionsFromDBLoop()
+	go currentArbitrator.RetryDepositTransactionsLoop()
 
-	log.Info("9. Start side chain account divide.")
+	log.Info("10. Start side chain account divide.")
 	go sideauxpow.SidechainAccountDivide()
 
-	log.Info("10. Start small crosschain transfer monitor.")
+	log.Info("11. Start small crosschain transfer monitor.")
 	go arbitrator.MonitorSmallCrossTransfer()
 
-	log.Info("11. Start invalid withdraw transaction monitor.")
+	log.Info("12. Start invalid withdraw transaction monitor.")
 	go arbitrator.MonitorInvalidWithdrawTransaction()
 
+	log.Info("13. Start side chain rpc endpoints health check.")
+	go rpc.HealthCheckLoop()
+
+	log.Info("14. Start cross chain accounting reconciliation.")
+	go arbitrator.ReconciliationLoop()
+
 	sidechain.Initialized = true
 
 	select {}
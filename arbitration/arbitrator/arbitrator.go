@@ -3,20 +3,21 @@ package arbitrator
 import (
 	"bytes"
 	"encoding/hex"
+	"fmt"
 	"math/big"
 	"path/filepath"
 	"sync"
 	"time"
 
 	. "github.com/elastos/Elastos.ELA.Arbiter/arbitration/base"
-	crypto2 "github.com/elastos/Elastos.ELA.Arbiter/arbitration/crypto"
 	"github.com/elastos/Elastos.ELA.Arbiter/config"
+	"github.com/elastos/Elastos.ELA.Arbiter/events"
 	"github.com/elastos/Elastos.ELA.Arbiter/log"
 	"github.com/elastos/Elastos.ELA.Arbiter/rpc"
+	"github.com/elastos/Elastos.ELA.Arbiter/signer"
 	"github.com/elastos/Elastos.ELA.Arbiter/store"
 
 	. "github.com/elastos/Elastos.ELA.SPV/interface"
-	"github.com/elastos/Elastos.ELA/account"
 	"github.com/elastos/Elastos.ELA/common"
 	it "github.com/elastos/Elastos.ELA/core/types/interfaces"
 	"github.com/elastos/Elastos.ELA/core/types/payload"
@@ -42,7 +43,7 @@ type Arbitrator interface {
 	GetSideChainManager() SideChainManager
 	GetMainChain() MainChain
 
-	InitAccount(client *account.Client)
+	InitSigner(s signer.Signer)
 	StartSpvModule() error
 
 	//deposit
@@ -70,14 +71,18 @@ type Arbitrator interface {
 
 	// schnorr withdraw
 	BroadcastSchnorrWithdrawProposal2(txn it.Transaction)
-	BroadcastSchnorrWithdrawProposal3(nonceHash common.Uint256, txn it.Transaction, pks [][]byte, e *big.Int)
+	BroadcastSchnorrWithdrawProposal3(nonceHash common.Uint256, txn it.Transaction, pks [][]byte,
+		e, rx, ry *big.Int)
 	// schnorr crypto
-	GetSchnorrR() (k0 *big.Int, rx *big.Int, ry *big.Int, px *big.Int, py *big.Int, err error)
-	GetSchnorrS(e *big.Int) *big.Int
+	GetSchnorrR(session common.Uint256) (rx *big.Int, ry *big.Int, px *big.Int, py *big.Int, err error)
+	GetSchnorrS(session common.Uint256, txn it.Transaction, rx, ry, px, py *big.Int) (*big.Int, error)
+	GetSchnorrLegacyR() (k0 *big.Int, rx *big.Int, ry *big.Int, px *big.Int, py *big.Int, err error)
+	GetSchnorrLegacyS(e *big.Int, txn it.Transaction) (*big.Int, error)
 
 	BroadcastSidechainIllegalData(data *payload.SidechainIllegalData)
 
 	CheckAndRemoveCrossChainTransactionsFromDBLoop()
+	RetryDepositTransactionsLoop()
 }
 
 type ArbitratorImpl struct {
@@ -87,7 +92,7 @@ type ArbitratorImpl struct {
 	mainChainImpl        MainChain
 	mainChainClientImpl  MainChainClient
 	sideChainManagerImpl SideChainManager
-	client               *account.Client
+	signer               signer.Signer
 }
 
 func (ar *ArbitratorImpl) GetSideChainManager() SideChainManager {
@@ -95,9 +100,7 @@ func (ar *ArbitratorImpl) GetSideChainManager() SideChainManager {
 }
 
 func (ar *ArbitratorImpl) GetPublicKey() *crypto.PublicKey {
-	mainAccount := ar.client.GetMainAccount()
-
-	return mainAccount.PubKey()
+	return ar.signer.PublicKey()
 }
 
 func (ar *ArbitratorImpl) OnDutyArbitratorChanged(onDuty bool) {
@@ -158,21 +161,23 @@ func (ar *ArbitratorImpl) GetComplainSolving() ComplainSolving {
 }
 
 func (ar *ArbitratorImpl) Sign(content []byte) ([]byte, error) {
-	mainAccount := ar.client.GetMainAccount()
+	return ar.signer.Sign(content)
+}
 
-	return mainAccount.Sign(content)
+func (ar *ArbitratorImpl) GetSchnorrR(session common.Uint256) (rx *big.Int, ry *big.Int, px *big.Int, py *big.Int, err error) {
+	return ar.signer.SchnorrR(session)
 }
 
-func (ar *ArbitratorImpl) GetSchnorrR() (k0 *big.Int, rx *big.Int, ry *big.Int, px *big.Int, py *big.Int, err error) {
-	mainAccount := ar.client.GetMainAccount()
-	privKey := new(big.Int).SetBytes(mainAccount.PrivateKey)
-	return crypto2.GetR(privKey)
+func (ar *ArbitratorImpl) GetSchnorrS(session common.Uint256, txn it.Transaction, rx, ry, px, py *big.Int) (*big.Int, error) {
+	return ar.signer.SchnorrS(session, txn, rx, ry, px, py)
 }
 
-func (ar *ArbitratorImpl) GetSchnorrS(e *big.Int) *big.Int {
-	mainAccount := ar.client.GetMainAccount()
-	privKey := new(big.Int).SetBytes(mainAccount.PrivateKey)
-	return crypto2.GetEMulPrivateKey(privKey, e)
+func (ar *ArbitratorImpl) GetSchnorrLegacyR() (k0 *big.Int, rx *big.Int, ry *big.Int, px *big.Int, py *big.Int, err error) {
+	return ar.signer.SchnorrLegacyR()
+}
+
+func (ar *ArbitratorImpl) GetSchnorrLegacyS(e *big.Int, txn it.Transaction) (*big.Int, error) {
+	return ar.signer.SchnorrLegacyS(e, txn)
 }
 
 func (ar *ArbitratorImpl) IsOnDutyOfMain() bool {
@@ -290,14 +295,73 @@ func (ar *ArbitratorImpl) SendDepositTransactions(spvTxs []*SpvTransaction, gene
 		log.Error("[SendDepositTransactions] Get side chain from genesis address failed, genesis address:", genesisAddress)
 		return
 	}
+	// paused deposits are kept and sent once resumed
+	if IsDepositPaused(genesisAddress) {
+		log.Info("[SendDepositTransactions] deposits of side chain", genesisAddress, "are paused")
+		return
+	}
+	// deposits are not sent until their proofs are confirmed by the spv headers
+	spvTxs = verifyDeposits(spvProofChecker{}, spvTxs, genesisAddress,
+		sideChain.GetCurrentConfig().GetConfirmationDepth())
+
+	// deposits failed with transient errors are not sent until the next retry
+	now := time.Now().Unix()
+	infos := depositRetryInfos(genesisAddress)
+	var dueTxs []*SpvTransaction
+	var attemptedTxHashes []string
 	for _, tx := range spvTxs {
+		hash := tx.MainChainTransaction.Hash().String()
+		if info, ok := infos[hash]; ok && info.NextRetry > now {
+			log.Info("Send deposit transaction delayed until", time.Unix(info.NextRetry, 0),
+				", main chain tx hash:", hash)
+			continue
+		}
+		dueTxs = append(dueTxs, tx)
+		attemptedTxHashes = append(attemptedTxHashes, hash)
+	}
+	if err := store.PendingTxsDbCache.AddPendingTxsAttempt(store.PendingDeposit, attemptedTxHashes, genesisAddress); err != nil {
+		log.Warn("[SendDepositTransactions] record deposit attempts failed:", err)
+	}
+	for _, tx := range dueTxs {
 		hash := tx.MainChainTransaction.Hash()
 		resp, err := sideChain.SendTransaction(&hash)
-		if err != nil || resp.Error != nil && resp.Code != ErrInvalidMainchainTx {
-			log.Warn("Send deposit transaction failed, move to finished db, main chain tx hash:", hash.String())
+		result, reason := classifyDepositResponse(resp, err)
+		if result == depositTransient && config.Parameters.DepositRetry == nil {
+			if resendWithoutRetryConfig(resp, err) {
+				log.Warn("Send deposit transaction failed, need to resend, main chain tx hash:", hash.String(),
+					"reason:", reason)
+				continue
+			}
+			result = depositPermanent
+		} else if result == depositTransient {
+			attempts, firstSeen := 1, now
+			if info, ok := infos[hash.String()]; ok {
+				attempts, firstSeen = info.RetryCount+2, info.FirstSeen
+			}
+			nextRetry, ok := nextDepositRetry(config.Parameters.DepositRetry, attempts, firstSeen, now)
+			if ok {
+				log.Warn("Send deposit transaction failed, need to resend, main chain tx hash:", hash.String(),
+					"attempts:", attempts, "reason:", reason)
+				err := store.PendingTxsDbCache.UpdatePendingTxRetry(store.PendingDeposit, hash.String(),
+					genesisAddress, nextRetry, reason)
+				if err != nil {
+					log.Warn("[SendDepositTransactions] record deposit retry failed:", err)
+				}
+				continue
+			}
+			reason = fmt.Sprintf("retry budget exceeded after %d attempts, %s", attempts, reason)
+		}
+		if result != depositSucceeded {
+			log.Warn("Send deposit transaction failed, move to finished db, main chain tx hash:", hash.String(),
+				"reason:", reason)
+			events.Notify(events.ETSubmitFailed, &events.Alert{
+				GenesisBlockAddress: genesisAddress,
+				Subject:             "recharge " + hash.String(),
+				Message:             "send deposit transaction " + hash.String() + " failed: " + reason,
+			})
 			failedMainChainTxHashes = append(failedMainChainTxHashes, hash.String())
 			failedGenesisAddresses = append(failedGenesisAddresses, genesisAddress)
-		} else if resp.Error == nil && resp.Result != nil || resp.Error != nil && resp.Code == SCErrMainchainTxDuplicate {
+		} else {
 			if resp.Error != nil {
 				log.Info("Send deposit found transaction has been processed, move to finished db, main chain tx hash:", hash.String())
 			} else {
@@ -308,10 +372,17 @@ func (ar *ArbitratorImpl) SendDepositTransactions(spvTxs []*SpvTransaction, gene
 					log.Info("Send deposit transaction, received invalid response")
 				}
 			}
+			rechargeTx := &events.CrossChainTx{
+				GenesisBlockAddress:      genesisAddress,
+				MainChainTransactionHash: hash.String(),
+				Addresses:                depositTargetAddresses(tx.MainChainTransaction, genesisAddress),
+			}
+			if txHash, ok := resp.Result.(string); ok && resp.Error == nil {
+				rechargeTx.SideChainTransactionHashes = []string{txHash}
+			}
+			events.Notify(events.ETDepositRecharged, rechargeTx)
 			succeedMainChainTxHashes = append(succeedMainChainTxHashes, hash.String())
 			succeedGenesisAddresses = append(succeedGenesisAddresses, genesisAddress)
-		} else {
-			log.Warn("Send deposit transaction failed, need to resend, main chain tx hash:", hash.String())
 		}
 	}
 
@@ -343,6 +414,10 @@ func (ar *ArbitratorImpl) SendSmallCrossDepositTransactions(knownTx []*SmallCros
 		log.Error("[SendSmallCrossDepositTransactions] Get side chain from genesis address failed, genesis address:", genesisAddress)
 		return
 	}
+	if IsDepositPaused(genesisAddress) {
+		log.Info("[SendSmallCrossDepositTransactions] deposits of side chain", genesisAddress, "are paused")
+		return
+	}
 	for _, tx := range knownTx {
 		buf := new(bytes.Buffer)
 		tx.MainTx.Serialize(buf)
@@ -352,11 +427,30 @@ func (ar *ArbitratorImpl) SendSmallCrossDepositTransactions(knownTx []*SmallCros
 		if sideChain.IsSendSmallCrxTx(hash) {
 			continue
 		}
-		_, err := sideChain.SendSmallCrossTransaction(rawTx, signature, hash)
+		resp, err := sideChain.SendSmallCrossTransaction(rawTx, signature, hash)
 		if err != nil {
 			log.Info("Send deposit transaction Error", err.Error())
 		}
+		state, response := smallCrossTxState(resp, err)
+		if err := store.SmallCrossTxsDbCache.UpdateSmallCrossTxState(hash, genesisAddress, state, response); err != nil {
+			log.Error("[SendSmallCrossDepositTransactions] update small cross transfer error:", err)
+		}
+	}
+}
+
+// smallCrossTxState returns the state and the response recorded of sending a
+// small cross transfer to the side chain.
+func smallCrossTxState(resp rpc.Response, err error) (string, string) {
+	if err != nil {
+		return store.SmallCrossTxFailed, err.Error()
+	}
+	if resp.Error != nil {
+		return store.SmallCrossTxFailed, resp.Error.Message
+	}
+	if r, ok := resp.Result.(bool); ok && r {
+		return store.SmallCrossTxSent, "true"
 	}
+	return store.SmallCrossTxFailed, fmt.Sprint(resp.Result)
 }
 
 func (ar *ArbitratorImpl) BroadcastSchnorrWithdrawProposal2(txn it.Transaction) {
@@ -367,8 +461,8 @@ func (ar *ArbitratorImpl) BroadcastSchnorrWithdrawProposal2(txn it.Transaction)
 }
 
 func (ar *ArbitratorImpl) BroadcastSchnorrWithdrawProposal3(
-	nonceHash common.Uint256, txn it.Transaction, pks [][]byte, e *big.Int) {
-	err := ar.mainChainImpl.BroadcastSchnorrWithdrawProposal3(nonceHash, txn, pks, e)
+	nonceHash common.Uint256, txn it.Transaction, pks [][]byte, e, rx, ry *big.Int) {
+	err := ar.mainChainImpl.BroadcastSchnorrWithdrawProposal3(nonceHash, txn, pks, e, rx, ry)
 	if err != nil {
 		log.Warn(err.Error())
 	}
@@ -434,8 +528,8 @@ func (ar *ArbitratorImpl) SetSideChainManager(manager SideChainManager) {
 	ar.sideChainManagerImpl = manager
 }
 
-func (ar *ArbitratorImpl) InitAccount(client *account.Client) {
-	ar.client = client
+func (ar *ArbitratorImpl) InitSigner(s signer.Signer) {
+	ar.signer = s
 }
 
 func (ar *ArbitratorImpl) StartSpvModule() error {
@@ -454,6 +548,10 @@ func (ar *ArbitratorImpl) StartSpvModule() error {
 	}
 
 	for _, sideNode := range config.Parameters.SideNodeList {
+		if IsSideChainRetired(sideNode.GenesisBlockAddress) {
+			log.Info("[StartSpvModule] skip retired side chain:", sideNode.GenesisBlockAddress)
+			continue
+		}
 		if sideNode.PowChain {
 			log.Info("[StartSpvModule] register auxpow listener:", sideNode.MiningAddr)
 			auxpowListener := &AuxpowListener{ListenAddress: sideNode.MiningAddr}
@@ -502,7 +600,73 @@ func (ar *ArbitratorImpl) CheckAndRemoveCrossChainTransactionsFromDBLoop() {
 		if err != nil {
 			log.Warn("Check and remove return deposit transactions from db error:", err)
 		}
+		err = prunePendingTxsInfo()
+		if err != nil {
+			log.Warn("Prune pending transactions info error:", err)
+		}
+		err = pruneSmallCrossTxs(ar.GetSideChainManager())
+		if err != nil {
+			log.Warn("Prune small cross transfers error:", err)
+		}
 		log.Info("Check and remove cross chain transactions from dbcache finished")
 		time.Sleep(time.Millisecond * config.Parameters.ClearTransactionInterval)
 	}
 }
+
+// prunePendingTxsInfo removes the attempts info of transactions which are no
+// longer pending in MainChainTxs or SideChainTxs.
+func prunePendingTxsInfo() error {
+	pending := make(map[string]struct{})
+	txHashes, genesisAddresses, err := store.DbCache.MainChainStore.GetAllMainChainTxHashes()
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(txHashes); i++ {
+		pending[store.PendingDeposit+txHashes[i]+genesisAddresses[i]] = struct{}{}
+	}
+	for _, dbStore := range store.DbCache.SideChainStore {
+		txHashes, err := dbStore.GetAllSideChainTxHashes()
+		if err != nil {
+			return err
+		}
+		for _, txHash := range txHashes {
+			pending[store.PendingWithdraw+txHash+dbStore.GenesisBlockAddress()] = struct{}{}
+		}
+	}
+
+	for _, kind := range []string{store.PendingDeposit, store.PendingWithdraw} {
+		infos, err := store.PendingTxsDbCache.GetPendingTxsInfo(kind)
+		if err != nil {
+			return err
+		}
+		var staleTxHashes, staleGenesisAddresses []string
+		for _, info := range infos {
+			if _, ok := pending[kind+info.TransactionHash+info.GenesisBlockAddress]; !ok {
+				staleTxHashes = append(staleTxHashes, info.TransactionHash)
+				staleGenesisAddresses = append(staleGenesisAddresses, info.GenesisBlockAddress)
+			}
+		}
+		if len(staleTxHashes) == 0 {
+			continue
+		}
+		if err := store.PendingTxsDbCache.RemovePendingTxsInfo(kind, staleTxHashes, staleGenesisAddresses); err != nil {
+			return err
+		}
+	}
+
+	verifications, err := store.PendingTxsDbCache.GetDepositVerifications()
+	if err != nil {
+		return err
+	}
+	var staleTxHashes, staleGenesisAddresses []string
+	for _, v := range verifications {
+		if _, ok := pending[store.PendingDeposit+v.TransactionHash+v.GenesisBlockAddress]; !ok {
+			staleTxHashes = append(staleTxHashes, v.TransactionHash)
+			staleGenesisAddresses = append(staleGenesisAddresses, v.GenesisBlockAddress)
+		}
+	}
+	if len(staleTxHashes) == 0 {
+		return nil
+	}
+	return store.PendingTxsDbCache.RemoveDepositVerifications(staleTxHashes, staleGenesisAddresses)
+}
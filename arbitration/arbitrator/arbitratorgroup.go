@@ -11,8 +11,8 @@ import (
 	"github.com/elastos/Elastos.ELA.Arbiter/config"
 	"github.com/elastos/Elastos.ELA.Arbiter/log"
 	"github.com/elastos/Elastos.ELA.Arbiter/rpc"
+	"github.com/elastos/Elastos.ELA.Arbiter/signer"
 
-	"github.com/elastos/Elastos.ELA/account"
 	"github.com/elastos/Elastos.ELA/crypto"
 )
 
@@ -195,7 +195,7 @@ func (group *ArbitratorGroupImpl) SetListener(listener ArbitratorGroupListener)
 	group.isListenerOnDuty = false
 }
 
-func Init(client *account.Client) {
+func Init(s signer.Signer) {
 	ArbitratorGroupSingleton = &ArbitratorGroupImpl{
 		timeoutLimit:     1000,
 		currentHeight:    new(uint32),
@@ -204,9 +204,9 @@ func Init(client *account.Client) {
 	}
 
 	currentArbitrator := &ArbitratorImpl{mainOnDutyMux: new(sync.Mutex)}
-	currentArbitrator.InitAccount(client)
+	currentArbitrator.InitSigner(s)
 
-	pk, _ := client.GetMainAccount().PublicKey.EncodePoint(true)
+	pk, _ := s.PublicKey().EncodePoint(true)
 	log.Info("Init current arbiter", hex.EncodeToString(pk))
 
 	ArbitratorGroupSingleton.currentArbitrator = currentArbitrator
@@ -2,13 +2,21 @@ package arbitrator
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
 
 	"github.com/elastos/Elastos.ELA.Arbiter/config"
+	"github.com/elastos/Elastos.ELA.Arbiter/events"
 	"github.com/elastos/Elastos.ELA.Arbiter/log"
+	"github.com/elastos/Elastos.ELA.Arbiter/rpc"
+	"github.com/elastos/Elastos.ELA.Arbiter/store"
 
 	"github.com/elastos/Elastos.ELA.SPV/bloom"
 	spv "github.com/elastos/Elastos.ELA.SPV/interface"
 	"github.com/elastos/Elastos.ELA.SPV/interface/iutil"
+	"github.com/elastos/Elastos.ELA/auxpow"
 	"github.com/elastos/Elastos.ELA/common"
 	elacommon "github.com/elastos/Elastos.ELA/core/types/common"
 	it "github.com/elastos/Elastos.ELA/core/types/interfaces"
@@ -20,6 +28,8 @@ type AuxpowListener struct {
 	ListenAddress string
 
 	notifyQueue chan *notifyTask
+	retryMux    sync.Mutex
+	retries     []*auxpowSubmission
 }
 
 func (l *AuxpowListener) Address() string {
@@ -39,26 +49,110 @@ func (l *AuxpowListener) Rollback(height uint32) {}
 func (l *AuxpowListener) Notify(id common.Uint256, proof bloom.MerkleProof, tx it.Transaction) {
 	l.notifyQueue <- &notifyTask{id, &proof, tx}
 	log.Info("[Notify-Auxpow][", l.ListenAddress, "] find side aux pow transaction, hash:", tx.Hash().String())
+	if err := store.SideMiningDbCache.UpdateSideMiningInclusion(tx.Hash().String(), proof.Height); err != nil {
+		log.Warn("[Notify-Auxpow] record side mining inclusion failed:", err)
+	}
 	err := SpvService.SubmitTransactionReceipt(id, tx.Hash())
 	if err != nil {
 		return
 	}
 }
 
+// recordSideMiningResult records the result of submitting the auxpow of the
+// SideChainPow transaction.
+func recordSideMiningResult(txHash string, result, errMsg string) {
+	if err := store.SideMiningDbCache.UpdateSideMiningResult(txHash, result, errMsg); err != nil {
+		log.Warn("[Notify-Auxpow] record side mining result failed:", err)
+	}
+}
+
+// auxpowSubmission is the auxpow of a SideChainPow transaction to be
+// submitted to the side chain.
+type auxpowSubmission struct {
+	txHash      string
+	sideChain   SideChain
+	genesisHash common.Uint256
+	blockHash   string
+	blockHeight uint32
+	auxpow      string
+	attempts    int
+	nextRetry   time.Time
+}
+
 func (l *AuxpowListener) ProcessNotifyData(tasks []*notifyTask) {
-	task := tasks[len(tasks)-1]
-	log.Info("[Notify-ProcessNotifyData][", l.ListenAddress, "] process hash:", task.tx.Hash().String(), "len tasks:", len(tasks))
+	log.Info("[Notify-ProcessNotifyData][", l.ListenAddress, "] process len tasks:", len(tasks))
+	// the side chain heights are queried once for the tasks
+	heights := make(map[common.Uint256]uint32)
+	for _, task := range tasks {
+		submission, err := l.buildSubmission(task, heights)
+		if err != nil {
+			log.Error("[Notify-Auxpow] process side aux pow transaction", task.tx.Hash().String(), "failed:", err)
+			continue
+		}
+		if submission == nil {
+			continue
+		}
+		submission.sideChain.UpdateLastNotifySideMiningHeight(submission.genesisHash)
+		l.submit(submission)
+	}
+}
+
+// buildSubmission builds and verifies the auxpow of the SideChainPow
+// transaction, nil is returned if the side chain has moved on or is retired.
+func (l *AuxpowListener) buildSubmission(task *notifyTask, heights map[common.Uint256]uint32) (*auxpowSubmission, error) {
+	log.Info("[Notify-ProcessNotifyData][", l.ListenAddress, "] process hash:", task.tx.Hash().String())
+	p, ok := task.tx.Payload().(*payload.SideChainPow)
+	if !ok {
+		return nil, errors.New("invalid payload type")
+	}
+	submission := &auxpowSubmission{
+		txHash:      task.tx.Hash().String(),
+		genesisHash: p.SideGenesisHash,
+		blockHash:   p.SideBlockHash.String(),
+		blockHeight: p.BlockHeight,
+	}
+	genesishashString := p.SideGenesisHash.String()
+	for _, sideNode := range config.SideNodes() {
+		if sideNode.GenesisBlock == genesishashString {
+			if IsSideChainRetired(sideNode.GenesisBlockAddress) {
+				log.Info("[Notify-Auxpow] ignore side aux pow transaction of retired side chain:",
+					sideNode.GenesisBlockAddress)
+				return nil, nil
+			}
+			sc, ok := ArbitratorGroupSingleton.GetCurrentArbitrator().
+				GetSideChainManager().GetChain(sideNode.GenesisBlockAddress)
+			if ok {
+				submission.sideChain = sc
+			}
+		}
+	}
+	if submission.sideChain == nil {
+		return nil, errors.New("can not find side chain from genesis block hash: [" + genesishashString + "]")
+	}
+	currentHeight, ok := heights[p.SideGenesisHash]
+	if !ok {
+		height, err := submission.sideChain.GetCurrentHeight()
+		if err != nil {
+			// submitted anyway, the failure is retried if the side chain is
+			// unreachable
+			log.Warn("[Notify-Auxpow] side chain GetCurrentHeight failed:", err)
+			height = p.BlockHeight
+		}
+		heights[p.SideGenesisHash], currentHeight = height, height
+	}
+	if !submission.current(currentHeight) || !submission.mining() {
+		return nil, nil
+	}
+
 	err := SpvService.VerifyTransaction(*task.proof, task.tx)
 	if err != nil {
-		log.Error("verify transaction error: ", err)
-		return
+		return nil, errors.New("verify transaction error: " + err.Error())
 	}
 
 	// Get Header from main chain
 	header, err := SpvService.HeaderStore().Get(&task.proof.BlockHash)
 	if err != nil {
-		log.Error("can not get block from main chain")
-		return
+		return nil, errors.New("can not get block from main chain")
 	}
 
 	// Check if merkleroot is match
@@ -72,98 +166,253 @@ func (l *AuxpowListener) ProcessNotifyData(tasks []*notifyTask) {
 	txId := task.tx.Hash()
 	merkleBranch, err := bloom.GetTxMerkleBranch(merkleBlock, &txId)
 	if err != nil {
-		log.Error("can not get merkle branch")
-		return
+		return nil, errors.New("can not get merkle branch")
+	}
+
+	elaHeader, ok := header.BlockHeader.(*iutil.Header)
+	if !ok {
+		return nil, errors.New("invalid elaHeader")
+	}
+	if err := verifySideAuxpow(task.tx, merkleBranch, elaHeader, task.proof.BlockHash); err != nil {
+		return nil, errors.New("verify side aux pow failed: " + err.Error())
 	}
 
 	// serialize main chain tx
 	buf := new(bytes.Buffer)
 	if err := task.tx.Serialize(buf); err != nil {
-		log.Error("invalid payload tx")
-		return
+		return nil, errors.New("invalid payload tx")
 	}
 
 	// serialize merkle branch
 	if err := common.WriteUint32(buf, uint32(len(merkleBranch.Branches))); err != nil {
-		log.Error("serialize merkle branch count failed:", err.Error())
-		return
+		return nil, errors.New("serialize merkle branch count failed: " + err.Error())
 	}
 	for _, branch := range merkleBranch.Branches {
 		err = branch.Serialize(buf)
 		if err != nil {
-			log.Error("serialize merkle branch failed:", err.Error())
-			return
+			return nil, errors.New("serialize merkle branch failed: " + err.Error())
 		}
 	}
 	if err := common.WriteUint32(buf, uint32(merkleBranch.Index)); err != nil {
-		log.Error("serialize merkle branch index failed:", err.Error())
-		return
+		return nil, errors.New("serialize merkle branch index failed: " + err.Error())
 	}
 
 	// serialize ela header
-	elaHeader := header.BlockHeader.(*iutil.Header)
 	if err := elaHeader.Serialize(buf); err != nil {
-		log.Error("invalid elaHeader")
-		return
+		return nil, errors.New("invalid elaHeader")
 	}
+	submission.auxpow = common.BytesToHexString(buf.Bytes())
+	return submission, nil
+}
 
-	sideAuxpowString := common.BytesToHexString(buf.Bytes())
+// current returns true if the side chain is still mining the side block of
+// the auxpow, the auxpow is recorded as skipped otherwise.
+func (s *auxpowSubmission) current(currentHeight uint32) bool {
+	if currentHeight != s.blockHeight {
+		log.Warn("No need to submit auxpow, current side chain height:",
+			currentHeight, " block height:", s.blockHeight)
+		recordSideMiningResult(s.txHash, store.SideMiningSkipped,
+			fmt.Sprintf("side chain height %d, block height %d", currentHeight, s.blockHeight))
+		return false
+	}
+	return true
+}
 
-	p, ok := task.tx.Payload().(*payload.SideChainPow)
-	if !ok {
-		log.Error("invalid payload type")
+// mining returns true if the side block of the auxpow is the aux block the
+// side chain is mining, the auxpow is recorded as skipped otherwise.
+func (s *auxpowSubmission) mining() bool {
+	auxBlockHash, err := s.sideChain.GetAuxBlockHash()
+	if err != nil {
+		// submitted anyway, the failure is retried if the side chain is
+		// unreachable
+		log.Warn("[Notify-Auxpow] side chain GetAuxBlockHash failed:", err)
+		return true
+	}
+	if auxBlockHash.String() != s.blockHash {
+		log.Warn("No need to submit auxpow, side chain mining block:", auxBlockHash.String(),
+			"block hash:", s.blockHash)
+		recordSideMiningResult(s.txHash, store.SideMiningSkipped,
+			fmt.Sprintf("side chain mining block %s, block hash %s", auxBlockHash.String(), s.blockHash))
+		return false
+	}
+	return true
+}
+
+// submit submits the auxpow to the side chain, the failed auxpow is queued
+// to be submitted again until AuxpowRetry is exceeded. The auxpow refused by
+// the side chain is not submitted again.
+func (l *AuxpowListener) submit(s *auxpowSubmission) {
+	genesishashString := s.genesisHash.String()
+	err := s.sideChain.SubmitAuxpow(genesishashString, s.blockHash, s.auxpow)
+	if err == nil {
+		s.sideChain.UpdateLastSubmitAuxpowHeight(s.genesisHash)
+		recordSideMiningResult(s.txHash, store.SideMiningSubmitted, "")
 		return
 	}
-	blockhashString := p.SideBlockHash.String()
-	genesishashString := p.SideGenesisHash.String()
-	blockHeight := p.BlockHeight
 
-	var sideChain SideChain
-	for _, sideNode := range config.Parameters.SideNodeList {
-		log.Info("side node genesis block:", sideNode.GetGenesisBlock(),
-			"side aux pow tx genesis hash:", genesishashString)
-		if sideNode.GenesisBlock == genesishashString {
-			sc, ok := ArbitratorGroupSingleton.GetCurrentArbitrator().
-				GetSideChainManager().GetChain(sideNode.GenesisBlockAddress)
-			if ok {
-				currentHeight, err := sc.GetCurrentHeight()
-				if err != nil {
-					log.Error("side chain GetCurrentHeight failed")
-					return
-				}
-				if currentHeight == blockHeight {
-					sideChain = sc
-				} else {
-					log.Warn("No need to submit auxpow, current side chain height:",
-						currentHeight, " block height:", blockHeight)
-					return
-				}
-			}
+	s.attempts++
+	delay, ok := auxpowRetryDelay(config.Parameters.AuxpowRetry, s.attempts)
+	if ok && !auxpowRejected(err) {
+		log.Warn("[Notify-Auxpow] submit SideAuxpow error, need to resubmit, attempts:", s.attempts, "error:", err)
+		s.nextRetry = time.Now().Add(delay)
+		recordSideMiningResult(s.txHash, store.SideMiningSent, err.Error())
+		l.addRetry(s)
+		return
+	}
+	log.Error("[Notify-Auxpow] submit SideAuxpow error: ", err)
+	events.Notify(events.ETSubmitFailed, &events.Alert{
+		GenesisBlockAddress: s.sideChain.GetKey(),
+		Subject:             "submitauxpow " + s.blockHash,
+		Message: fmt.Sprintf("submit auxpow of side block %s failed after %d attempts: %s",
+			s.blockHash, s.attempts, err.Error()),
+	})
+	recordSideMiningResult(s.txHash, store.SideMiningFailed, err.Error())
+}
+
+// auxpowRejected returns true if the side chain received the auxpow and
+// refused it, rather than being unreachable.
+func auxpowRejected(err error) bool {
+	var rejected *rpc.RejectedError
+	return errors.As(err, &rejected)
+}
+
+// addRetry queues the auxpow and records it, so that it is still submitted
+// again after restart.
+func (l *AuxpowListener) addRetry(s *auxpowSubmission) {
+	l.retryMux.Lock()
+	l.retries = append(l.retries, s)
+	l.retryMux.Unlock()
+
+	err := store.SideMiningDbCache.SetAuxpowRetry(&store.AuxpowRetry{
+		TransactionHash: s.txHash,
+		GenesisHash:     s.genesisHash.String(),
+		SideBlockHash:   s.blockHash,
+		SideBlockHeight: s.blockHeight,
+		Auxpow:          s.auxpow,
+		Attempts:        s.attempts,
+		NextRetry:       s.nextRetry.Unix(),
+	})
+	if err != nil {
+		log.Warn("[Notify-Auxpow] record auxpow retry failed:", err)
+	}
+}
+
+// loadRetries queues the auxpows of the listened side chains recorded to be
+// submitted again before restart.
+func (l *AuxpowListener) loadRetries() {
+	for _, sideNode := range config.SideNodes() {
+		if sideNode.MiningAddr != l.ListenAddress {
+			continue
+		}
+		genesisHash, err := common.Uint256FromReversedHexString(sideNode.GenesisBlock)
+		if err != nil {
+			continue
+		}
+		sc, ok := ArbitratorGroupSingleton.GetCurrentArbitrator().
+			GetSideChainManager().GetChain(sideNode.GenesisBlockAddress)
+		if !ok {
+			continue
+		}
+		retries, err := store.SideMiningDbCache.GetAuxpowRetries(genesisHash.String())
+		if err != nil {
+			log.Warn("[Notify-Auxpow] load auxpow retries failed:", err)
+			continue
+		}
+		for _, r := range retries {
+			l.retries = append(l.retries, &auxpowSubmission{
+				txHash:      r.TransactionHash,
+				sideChain:   sc,
+				genesisHash: *genesisHash,
+				blockHash:   r.SideBlockHash,
+				blockHeight: r.SideBlockHeight,
+				auxpow:      r.Auxpow,
+				attempts:    r.Attempts,
+				nextRetry:   time.Unix(r.NextRetry, 0),
+			})
 		}
 	}
+}
 
-	if sideChain == nil {
-		log.Error("arbiter not find side chain")
-		allChains := ArbitratorGroupSingleton.GetCurrentArbitrator().GetSideChainManager().GetAllChains()
-		for index, chain := range allChains {
-			log.Error("side chain", index, ":", chain.GetKey())
+// retryLoop submits the failed auxpows again when they are due.
+func (l *AuxpowListener) retryLoop() {
+	for {
+		time.Sleep(time.Second)
+		now := time.Now()
+		var due []*auxpowSubmission
+		l.retryMux.Lock()
+		retries := l.retries[:0]
+		for _, s := range l.retries {
+			if s.nextRetry.After(now) {
+				retries = append(retries, s)
+			} else {
+				due = append(due, s)
+			}
 		}
+		l.retries = retries
+		l.retryMux.Unlock()
 
-		log.Error("can not find side chain from genesis block hash: [", genesishashString, "]")
-		return
+		for _, s := range due {
+			if err := store.SideMiningDbCache.RemoveAuxpowRetry(s.txHash); err != nil {
+				log.Warn("[Notify-Auxpow] remove auxpow retry failed:", err)
+			}
+			currentHeight, err := s.sideChain.GetCurrentHeight()
+			if err != nil {
+				log.Warn("[Notify-Auxpow] side chain GetCurrentHeight failed:", err)
+				currentHeight = s.blockHeight
+			}
+			if s.current(currentHeight) && s.mining() {
+				l.submit(s)
+			}
+		}
+	}
+}
+
+// auxpowRetryDelay returns the delay of submitting the auxpow again after
+// the attempts failed, ok is false if AuxpowRetry is exceeded.
+func auxpowRetryDelay(cfg *config.AuxpowRetryConfig, attempts int) (delay time.Duration, ok bool) {
+	if cfg == nil {
+		return 0, false
+	}
+	if cfg.MaxAttempts > 0 && attempts >= cfg.MaxAttempts {
+		return 0, false
 	}
+	delay = time.Millisecond * cfg.InitialInterval
+	maxInterval := time.Millisecond * cfg.MaxInterval
+	for i := 1; i < attempts && delay < maxInterval; i++ {
+		delay *= 2
+	}
+	if delay > maxInterval {
+		delay = maxInterval
+	}
+	return delay, true
+}
 
-	sideChain.UpdateLastNotifySideMiningHeight(p.SideGenesisHash)
-	err = sideChain.SubmitAuxpow(genesishashString, blockhashString, sideAuxpowString)
-	if err != nil {
-		log.Error("[Notify-Auxpow] submit SideAuxpow error: ", err)
-		return
+// verifySideAuxpow checks the merkle branch proves the SideChainPow
+// transaction is in the main chain block, and the transaction commits to a
+// side block.
+func verifySideAuxpow(tx it.Transaction, branch *bloom.MerkleBranch, header *iutil.Header, blockHash common.Uint256) error {
+	p, ok := tx.Payload().(*payload.SideChainPow)
+	if !ok {
+		return errors.New("invalid payload type")
+	}
+	if p.SideBlockHash == (common.Uint256{}) {
+		return errors.New("empty side block hash")
+	}
+	if hash := header.Hash(); !hash.IsEqual(blockHash) {
+		return fmt.Errorf("main chain header hash %s, expect %s", hash.String(), blockHash.String())
+	}
+	root := auxpow.GetMerkleRoot(tx.Hash(), branch.Branches, branch.Index)
+	if !root.IsEqual(header.MerkleRoot()) {
+		return fmt.Errorf("merkle root %s of side block %s, expect %s", root.String(),
+			p.SideBlockHash.String(), header.MerkleRoot().String())
 	}
-	sideChain.UpdateLastSubmitAuxpowHeight(p.SideGenesisHash)
+	return nil
 }
 
 func (l *AuxpowListener) start() {
 	l.notifyQueue = make(chan *notifyTask, 10000)
+	l.loadRetries()
+	go l.retryLoop()
 	go func() {
 		var tasks []*notifyTask
 		for {
@@ -178,7 +427,6 @@ func (l *AuxpowListener) start() {
 				}
 			default:
 				if len(tasks) > 0 {
-					//only deal with the last one task
 					l.ProcessNotifyData(tasks)
 					tasks = make([]*notifyTask, 0)
 				}
@@ -0,0 +1,79 @@
+package arbitrator
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/elastos/Elastos.ELA.Arbiter/config"
+	"github.com/elastos/Elastos.ELA.Arbiter/rpc"
+
+	"github.com/elastos/Elastos.ELA.SPV/bloom"
+	"github.com/elastos/Elastos.ELA.SPV/interface/iutil"
+	"github.com/elastos/Elastos.ELA/auxpow"
+	"github.com/elastos/Elastos.ELA/common"
+	elatx "github.com/elastos/Elastos.ELA/core/transaction"
+	elacommon "github.com/elastos/Elastos.ELA/core/types/common"
+	"github.com/elastos/Elastos.ELA/core/types/payload"
+)
+
+func TestVerifySideAuxpow(t *testing.T) {
+	tx := elatx.CreateTransaction(
+		elacommon.TxVersion09,
+		elacommon.SideChainPow,
+		payload.SideChainPowVersion,
+		&payload.SideChainPow{SideBlockHash: common.Uint256{1}, SideGenesisHash: common.Uint256{2}, BlockHeight: 100},
+		[]*elacommon.Attribute{},
+		[]*elacommon.Input{},
+		[]*elacommon.Output{},
+		0,
+		nil,
+	)
+	branch := &bloom.MerkleBranch{Branches: []common.Uint256{{3}, {4}}, Index: 1}
+	header := &iutil.Header{Header: &elacommon.Header{
+		MerkleRoot: auxpow.GetMerkleRoot(tx.Hash(), branch.Branches, branch.Index),
+	}}
+	if err := verifySideAuxpow(tx, branch, header, header.Hash()); err != nil {
+		t.Errorf("valid auxpow refused: %v", err)
+	}
+	if err := verifySideAuxpow(tx, branch, header, common.Uint256{5}); err == nil {
+		t.Error("auxpow of another main chain block should be refused")
+	}
+	wrongIndex := &bloom.MerkleBranch{Branches: branch.Branches, Index: 2}
+	if err := verifySideAuxpow(tx, wrongIndex, header, header.Hash()); err == nil {
+		t.Error("auxpow with wrong merkle branch should be refused")
+	}
+}
+
+func TestAuxpowRetryDelay(t *testing.T) {
+	cfg := &config.AuxpowRetryConfig{
+		MaxAttempts:     4,
+		InitialInterval: 2000,
+		MaxInterval:     5000,
+	}
+	expected := []time.Duration{2 * time.Second, 4 * time.Second, 5 * time.Second}
+	for i, e := range expected {
+		delay, ok := auxpowRetryDelay(cfg, i+1)
+		if !ok || delay != e {
+			t.Errorf("attempts %d: expect delay %s, got %s, %v", i+1, e, delay, ok)
+		}
+	}
+	if _, ok := auxpowRetryDelay(cfg, 4); ok {
+		t.Error("auxpow should be given up after MaxAttempts")
+	}
+	if _, ok := auxpowRetryDelay(nil, 1); ok {
+		t.Error("auxpow should not be retried without AuxpowRetry")
+	}
+}
+
+func TestAuxpowRejected(t *testing.T) {
+	rejected := &rpc.RejectedError{Code: -1, Message: "block hash unknown"}
+	if !auxpowRejected(rejected) || !auxpowRejected(fmt.Errorf("submit: %w", rejected)) {
+		t.Error("auxpow refused by the side chain should not be submitted again")
+	}
+	if auxpowRejected(&net.OpError{Op: "dial", Err: errors.New("connection refused")}) {
+		t.Error("auxpow should be submitted again if the side chain is unreachable")
+	}
+}
@@ -0,0 +1,273 @@
+package arbitrator
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/elastos/Elastos.ELA.Arbiter/arbitration/base"
+	"github.com/elastos/Elastos.ELA.Arbiter/config"
+	"github.com/elastos/Elastos.ELA.Arbiter/events"
+	"github.com/elastos/Elastos.ELA.Arbiter/log"
+	"github.com/elastos/Elastos.ELA.Arbiter/store"
+
+	"github.com/elastos/Elastos.ELA/common"
+)
+
+// CircuitBreakerSingleton holds the deposits and withdraws of side chains
+// paused, nothing is paused if it is nil.
+var CircuitBreakerSingleton *CircuitBreaker
+
+// withdrawVolume is the cross chain amount of withdraws found at a time.
+type withdrawVolume struct {
+	time   time.Time
+	amount common.Fixed64
+}
+
+// withdrawBreaker is the withdraw volume breaker of a side chain.
+type withdrawBreaker struct {
+	limit   common.Fixed64
+	window  time.Duration
+	volumes []withdrawVolume
+}
+
+// CircuitBreaker pauses the deposits or withdraws of side chains by the
+// operator, or by the breakers tripped when withdraws found in the window
+// exceed the limit or illegal evidence is found. The pauses are kept in the
+// store until resumed by the operator.
+type CircuitBreaker struct {
+	mux      sync.Mutex
+	pauses   map[string]*store.SideChainPause
+	breakers map[string]*withdrawBreaker
+
+	db store.PauseDataStore
+}
+
+// NewCircuitBreaker returns the circuit breaker of the side chains of
+// configuration and the pauses in the store.
+func NewCircuitBreaker(cfg *config.Configuration, db store.PauseDataStore) (*CircuitBreaker, error) {
+	b := &CircuitBreaker{
+		pauses:   make(map[string]*store.SideChainPause),
+		breakers: make(map[string]*withdrawBreaker),
+		db:       db,
+	}
+	for _, node := range cfg.SideNodeList {
+		b.AddSideNode(node)
+	}
+	pauses, err := db.GetPauses()
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range pauses {
+		b.pauses[p.GenesisBlockAddress+p.Kind] = p
+	}
+	return b, nil
+}
+
+// AddSideNode registers the withdraw breaker of the side node if its limit is
+// set, the withdraws counted by a breaker registered before are kept.
+func (b *CircuitBreaker) AddSideNode(node *config.SideNodeConfig) {
+	if node.WithdrawBreakerLimit <= 0 {
+		return
+	}
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	if _, ok := b.breakers[node.GenesisBlockAddress]; ok {
+		return
+	}
+	b.breakers[node.GenesisBlockAddress] = &withdrawBreaker{
+		limit:  node.WithdrawBreakerLimit,
+		window: node.GetWithdrawBreakerWindow(),
+	}
+}
+
+// Pause pauses the deposits or withdraws of the side chain by the operator.
+func (b *CircuitBreaker) Pause(genesisAddress, kind, reason string) error {
+	if kind != store.PendingDeposit && kind != store.PendingWithdraw {
+		return errors.New("kind should be deposit or withdraw")
+	}
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	if p, ok := b.pauses[genesisAddress+kind]; ok {
+		return errors.New(kind + "s already paused by " + p.PausedBy)
+	}
+	return b.pause(genesisAddress, kind, store.PausedByOperator, reason, time.Now())
+}
+
+// Resume resumes the deposits or withdraws of the side chain paused by the
+// operator or a breaker, the withdraws counted by the breaker are cleared.
+func (b *CircuitBreaker) Resume(genesisAddress, kind string) error {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	if _, ok := b.pauses[genesisAddress+kind]; !ok {
+		return errors.New(kind + "s are not paused")
+	}
+	if err := b.db.RemovePause(genesisAddress, kind); err != nil {
+		return err
+	}
+	delete(b.pauses, genesisAddress+kind)
+	if w, ok := b.breakers[genesisAddress]; ok && kind == store.PendingWithdraw {
+		w.volumes = nil
+	}
+	return nil
+}
+
+// Paused returns the pause of the deposits or withdraws of the side chain.
+func (b *CircuitBreaker) Paused(genesisAddress, kind string) (*store.SideChainPause, bool) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	p, ok := b.pauses[genesisAddress+kind]
+	if !ok {
+		return nil, false
+	}
+	c := *p
+	return &c, true
+}
+
+// Pauses returns the deposits and withdraws of side chains paused.
+func (b *CircuitBreaker) Pauses() []*store.SideChainPause {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	result := make([]*store.SideChainPause, 0, len(b.pauses))
+	for _, p := range b.pauses {
+		c := *p
+		result = append(result, &c)
+	}
+	return result
+}
+
+// RecordWithdraws counts the withdraws found on the side chain, the
+// withdraws are paused if the ones found in the window exceed the limit.
+func (b *CircuitBreaker) RecordWithdraws(genesisAddress string, txs []*base.WithdrawTx, now time.Time) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	w, ok := b.breakers[genesisAddress]
+	if !ok {
+		return
+	}
+	for _, tx := range txs {
+		var amount common.Fixed64
+		for _, asset := range tx.WithdrawInfo.WithdrawAssets {
+			amount += *asset.CrossChainAmount
+		}
+		w.volumes = append(w.volumes, withdrawVolume{time: now, amount: amount})
+	}
+
+	var total common.Fixed64
+	start := now.Add(-w.window)
+	volumes := w.volumes[:0]
+	for _, v := range w.volumes {
+		if v.time.After(start) {
+			volumes = append(volumes, v)
+			total += v.amount
+		}
+	}
+	w.volumes = volumes
+	if total <= w.limit {
+		return
+	}
+	if _, ok := b.pauses[genesisAddress+store.PendingWithdraw]; ok {
+		return
+	}
+	b.trip(genesisAddress, store.BreakerWithdrawVolume, fmt.Sprintf("withdraws %s found in %s "+
+		"exceed limit %s", total, w.window, w.limit), now, store.PendingWithdraw)
+}
+
+// TripIllegalEvidence pauses the deposits and withdraws of the side chain
+// since illegal evidence is found on it.
+func (b *CircuitBreaker) TripIllegalEvidence(genesisAddress, detail string, now time.Time) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	var kinds []string
+	for _, kind := range []string{store.PendingDeposit, store.PendingWithdraw} {
+		if _, ok := b.pauses[genesisAddress+kind]; !ok {
+			kinds = append(kinds, kind)
+		}
+	}
+	if len(kinds) == 0 {
+		return
+	}
+	b.trip(genesisAddress, store.BreakerIllegalEvidence, detail, now, kinds...)
+}
+
+// trip pauses the kinds of transactions of the side chain by the breaker,
+// the trip is recorded and alerted.
+func (b *CircuitBreaker) trip(genesisAddress, breaker, detail string, now time.Time, kinds ...string) {
+	log.Warn("[CircuitBreaker] breaker", breaker, "of side chain", genesisAddress, "tripped:", detail)
+	for _, kind := range kinds {
+		if err := b.pause(genesisAddress, kind, breaker, detail, now); err != nil {
+			log.Error("[CircuitBreaker] pause", kind, "failed:", err)
+		}
+	}
+	err := b.db.AddBreakerTrip(&store.BreakerTrip{
+		GenesisBlockAddress: genesisAddress,
+		Breaker:             breaker,
+		Detail:              detail,
+		TripTime:            now.Unix(),
+	})
+	if err != nil {
+		log.Error("[CircuitBreaker] add breaker trip failed:", err)
+	}
+	events.Notify(events.ETCircuitBreakerTripped, &events.Alert{
+		GenesisBlockAddress: genesisAddress,
+		Subject:             breaker,
+		Message:             detail,
+	})
+}
+
+func (b *CircuitBreaker) pause(genesisAddress, kind, pausedBy, reason string, now time.Time) error {
+	p := &store.SideChainPause{
+		GenesisBlockAddress: genesisAddress,
+		Kind:                kind,
+		PausedBy:            pausedBy,
+		Reason:              reason,
+		PauseTime:           now.Unix(),
+	}
+	if err := b.db.AddPause(p); err != nil {
+		return err
+	}
+	b.pauses[genesisAddress+kind] = p
+	return nil
+}
+
+// SendResumedTransactions sends the cached deposits and return deposits, or
+// the cached withdraws of the side chain once they are resumed, the invalid
+// withdraws are reported again by MonitorInvalidWithdrawTransaction.
+func SendResumedTransactions(genesisAddress, kind string) {
+	ar, ok := ArbitratorGroupSingleton.GetCurrentArbitrator().(*ArbitratorImpl)
+	if !ok || !ar.IsOnDutyOfMain() {
+		return
+	}
+	sc, ok := ar.sideChainManagerImpl.GetChain(genesisAddress)
+	if !ok {
+		return
+	}
+	switch kind {
+	case store.PendingDeposit:
+		ar.ProcessDepositTransactions()
+		if ArbitratorGroupSingleton.GetCurrentHeight() >= config.Parameters.ReturnCrossChainCoinStartHeight {
+			sc.SendCachedReturnDepositTxs()
+		}
+	case store.PendingWithdraw:
+		sc.SendCachedWithdrawTxs(store.DbCache.MainChainStore.CurrentHeight(store.QueryHeightCode))
+	}
+}
+
+// IsDepositPaused returns if the deposits to the side chain are paused.
+func IsDepositPaused(genesisAddress string) bool {
+	if CircuitBreakerSingleton == nil {
+		return false
+	}
+	_, ok := CircuitBreakerSingleton.Paused(genesisAddress, store.PendingDeposit)
+	return ok
+}
+
+// IsWithdrawPaused returns if the withdraws from the side chain are paused.
+func IsWithdrawPaused(genesisAddress string) bool {
+	if CircuitBreakerSingleton == nil {
+		return false
+	}
+	_, ok := CircuitBreakerSingleton.Paused(genesisAddress, store.PendingWithdraw)
+	return ok
+}
@@ -0,0 +1,154 @@
+package arbitrator
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/elastos/Elastos.ELA.Arbiter/arbitration/base"
+	"github.com/elastos/Elastos.ELA.Arbiter/config"
+	"github.com/elastos/Elastos.ELA.Arbiter/log"
+	"github.com/elastos/Elastos.ELA.Arbiter/store"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/contract"
+)
+
+func TestMain(m *testing.M) {
+	dir, err := ioutil.TempDir("", "arbiter-arbitrator")
+	if err != nil {
+		panic(err)
+	}
+	log.Init(filepath.Join(dir, "logs"), 1, 0, 0)
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+type pauseStoreMock struct {
+	pauses map[string]*store.SideChainPause
+	trips  []*store.BreakerTrip
+}
+
+func (m *pauseStoreMock) AddPause(p *store.SideChainPause) error {
+	if _, ok := m.pauses[p.GenesisBlockAddress+p.Kind]; !ok {
+		c := *p
+		m.pauses[p.GenesisBlockAddress+p.Kind] = &c
+	}
+	return nil
+}
+
+func (m *pauseStoreMock) RemovePause(genesisAddress, kind string) error {
+	delete(m.pauses, genesisAddress+kind)
+	return nil
+}
+
+func (m *pauseStoreMock) GetPauses() ([]*store.SideChainPause, error) {
+	var pauses []*store.SideChainPause
+	for _, p := range m.pauses {
+		pauses = append(pauses, p)
+	}
+	return pauses, nil
+}
+
+func (m *pauseStoreMock) AddBreakerTrip(trip *store.BreakerTrip) error {
+	m.trips = append(m.trips, trip)
+	return nil
+}
+
+func (m *pauseStoreMock) GetBreakerTrips(genesisAddress string) ([]*store.BreakerTrip, error) {
+	return m.trips, nil
+}
+
+func (m *pauseStoreMock) ResetDataStore(dbName string) error { return nil }
+
+func TestCircuitBreaker(t *testing.T) {
+	db := &pauseStoreMock{pauses: make(map[string]*store.SideChainPause)}
+	b, err := NewCircuitBreaker(&config.Configuration{
+		SideNodeList: []*config.SideNodeConfig{
+			{GenesisBlockAddress: "limited", WithdrawBreakerLimit: 1000, WithdrawBreakerWindow: 60000},
+			{GenesisBlockAddress: "unlimited"},
+		},
+	}, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	CircuitBreakerSingleton = b
+	defer func() { CircuitBreakerSingleton = nil }()
+
+	addr := testAddress(contract.PrefixStandard, 1)
+	newTx := func(b byte, amount common.Fixed64) *base.WithdrawTx {
+		return &base.WithdrawTx{
+			Txid: &common.Uint256{b},
+			WithdrawInfo: &base.WithdrawInfo{WithdrawAssets: []*base.WithdrawAsset{
+				testAsset(addr, amount+100, amount),
+			}},
+		}
+	}
+
+	// withdraws out of the window are not counted
+	now := time.Now()
+	b.RecordWithdraws("limited", []*base.WithdrawTx{newTx(1, 600)}, now.Add(-2*time.Minute))
+	b.RecordWithdraws("limited", []*base.WithdrawTx{newTx(2, 600)}, now)
+	b.RecordWithdraws("unlimited", []*base.WithdrawTx{newTx(3, 100000)}, now)
+	if IsWithdrawPaused("limited") || IsWithdrawPaused("unlimited") {
+		t.Fatal("withdraws should not be paused under the limit")
+	}
+
+	b.RecordWithdraws("limited", []*base.WithdrawTx{newTx(4, 300), newTx(5, 200)}, now)
+	if !IsWithdrawPaused("limited") || IsDepositPaused("limited") {
+		t.Fatal("only withdraws should be paused over the limit")
+	}
+	if p, _ := b.Paused("limited", store.PendingWithdraw); p.PausedBy != store.BreakerWithdrawVolume {
+		t.Errorf("withdraws should be paused by the volume breaker, got %s", p.PausedBy)
+	}
+	b.RecordWithdraws("limited", []*base.WithdrawTx{newTx(6, 300)}, now)
+	if len(db.trips) != 1 || db.trips[0].Breaker != store.BreakerWithdrawVolume {
+		t.Errorf("breaker should trip once, got %d trips", len(db.trips))
+	}
+
+	// the window is cleared once resumed
+	if err := b.Resume("limited", store.PendingWithdraw); err != nil {
+		t.Fatal(err)
+	}
+	b.RecordWithdraws("limited", []*base.WithdrawTx{newTx(7, 300)}, now)
+	if IsWithdrawPaused("limited") {
+		t.Error("withdraws counted before resuming should be cleared")
+	}
+
+	if err := b.Pause("unlimited", store.PendingDeposit, "reorg"); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Pause("unlimited", store.PendingDeposit, "again"); err == nil {
+		t.Error("deposits should not be paused twice")
+	}
+	if err := b.Pause("unlimited", "nft", ""); err == nil {
+		t.Error("unknown kind should not be paused")
+	}
+	b.TripIllegalEvidence("unlimited", "double signed", now)
+	if !IsDepositPaused("unlimited") || !IsWithdrawPaused("unlimited") {
+		t.Fatal("deposits and withdraws should be paused by illegal evidence")
+	}
+	if p, _ := b.Paused("unlimited", store.PendingDeposit); p.PausedBy != store.PausedByOperator {
+		t.Errorf("pause of the operator should be kept, got %s", p.PausedBy)
+	}
+	if len(db.trips) != 2 || db.trips[1].Breaker != store.BreakerIllegalEvidence {
+		t.Error("illegal evidence trip recorded wrongly")
+	}
+
+	// the breaker of a side node added later is registered
+	b.AddSideNode(&config.SideNodeConfig{GenesisBlockAddress: "added",
+		WithdrawBreakerLimit: 1000, WithdrawBreakerWindow: 60000})
+	b.RecordWithdraws("added", []*base.WithdrawTx{newTx(8, 1200)}, now)
+	if !IsWithdrawPaused("added") {
+		t.Error("withdraws of the side node added should be paused over the limit")
+	}
+
+	reloaded, _ := NewCircuitBreaker(&config.Configuration{}, db)
+	if len(reloaded.Pauses()) != 3 {
+		t.Errorf("circuit breaker reloaded wrongly, got %d pauses", len(reloaded.Pauses()))
+	}
+}
@@ -0,0 +1,140 @@
+package arbitrator
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/elastos/Elastos.ELA.Arbiter/store"
+)
+
+// DecommissionSingleton holds the side chains being decommissioned, no side
+// chain is decommissioned if it is nil.
+var DecommissionSingleton *DecommissionRegistry
+
+// DecommissionRegistry is the side chains decommissioned, they refuse new
+// deposits while draining and are no longer synced once retired.
+type DecommissionRegistry struct {
+	mux           sync.Mutex
+	decommissions map[string]*store.Decommission
+
+	db store.DecommissionDataStore
+}
+
+// NewDecommissionRegistry returns the registry of the side chains
+// decommissioned in the store.
+func NewDecommissionRegistry(db store.DecommissionDataStore) (*DecommissionRegistry, error) {
+	r := &DecommissionRegistry{
+		decommissions: make(map[string]*store.Decommission),
+		db:            db,
+	}
+	decommissions, err := db.GetDecommissions()
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range decommissions {
+		r.decommissions[d.GenesisBlockAddress] = d
+	}
+	return r, nil
+}
+
+// Decommission starts draining the side chain, it can not be undone.
+func (r *DecommissionRegistry) Decommission(genesisAddress, reason string) error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if d, ok := r.decommissions[genesisAddress]; ok {
+		return errors.New("side chain already " + d.Stage)
+	}
+	d := &store.Decommission{
+		GenesisBlockAddress: genesisAddress,
+		Stage:               store.DecommissionDraining,
+		Reason:              reason,
+		StartTime:           time.Now().Unix(),
+	}
+	if err := r.db.AddDecommission(d); err != nil {
+		return err
+	}
+	r.decommissions[genesisAddress] = d
+	return nil
+}
+
+// Retire marks the drained side chain retired.
+func (r *DecommissionRegistry) Retire(genesisAddress string) error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	d, ok := r.decommissions[genesisAddress]
+	if !ok {
+		return errors.New("side chain is not decommissioned")
+	}
+	if d.Stage == store.DecommissionRetired {
+		return nil
+	}
+	if err := r.db.SetDecommissionRetired(genesisAddress); err != nil {
+		return err
+	}
+	d.Stage = store.DecommissionRetired
+	d.RetireTime = time.Now().Unix()
+	return nil
+}
+
+// AddReturnedDeposit counts the deposit to the side chain returned since it
+// is decommissioned.
+func (r *DecommissionRegistry) AddReturnedDeposit(genesisAddress string) error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	d, ok := r.decommissions[genesisAddress]
+	if !ok {
+		return errors.New("side chain is not decommissioned")
+	}
+	if err := r.db.AddReturnedDeposits(genesisAddress, 1); err != nil {
+		return err
+	}
+	d.ReturnedDeposits++
+	return nil
+}
+
+// Stage returns the decommission stage of the side chain, empty if it is not
+// decommissioned.
+func (r *DecommissionRegistry) Stage(genesisAddress string) string {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if d, ok := r.decommissions[genesisAddress]; ok {
+		return d.Stage
+	}
+	return ""
+}
+
+// StartTime returns the unix time the side chain is decommissioned, 0 if it
+// is not decommissioned.
+func (r *DecommissionRegistry) StartTime(genesisAddress string) int64 {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if d, ok := r.decommissions[genesisAddress]; ok {
+		return d.StartTime
+	}
+	return 0
+}
+
+// Decommissions returns the side chains decommissioned.
+func (r *DecommissionRegistry) Decommissions() []*store.Decommission {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	result := make([]*store.Decommission, 0, len(r.decommissions))
+	for _, d := range r.decommissions {
+		c := *d
+		result = append(result, &c)
+	}
+	return result
+}
+
+// IsSideChainDecommissioned returns if the side chain is draining or
+// retired.
+func IsSideChainDecommissioned(genesisAddress string) bool {
+	return DecommissionSingleton != nil && DecommissionSingleton.Stage(genesisAddress) != ""
+}
+
+// IsSideChainRetired returns if the side chain is retired.
+func IsSideChainRetired(genesisAddress string) bool {
+	return DecommissionSingleton != nil &&
+		DecommissionSingleton.Stage(genesisAddress) == store.DecommissionRetired
+}
@@ -0,0 +1,89 @@
+package arbitrator
+
+import (
+	"testing"
+
+	"github.com/elastos/Elastos.ELA.Arbiter/store"
+)
+
+type decommissionStoreMock struct {
+	decommissions map[string]*store.Decommission
+}
+
+func (m *decommissionStoreMock) AddDecommission(d *store.Decommission) error {
+	c := *d
+	m.decommissions[d.GenesisBlockAddress] = &c
+	return nil
+}
+
+func (m *decommissionStoreMock) AddReturnedDeposits(genesisAddress string, count int) error {
+	m.decommissions[genesisAddress].ReturnedDeposits += count
+	return nil
+}
+
+func (m *decommissionStoreMock) SetDecommissionRetired(genesisAddress string) error {
+	m.decommissions[genesisAddress].Stage = store.DecommissionRetired
+	return nil
+}
+
+func (m *decommissionStoreMock) GetDecommissions() ([]*store.Decommission, error) {
+	var decommissions []*store.Decommission
+	for _, d := range m.decommissions {
+		c := *d
+		decommissions = append(decommissions, &c)
+	}
+	return decommissions, nil
+}
+
+func (m *decommissionStoreMock) ResetDataStore(dbName string) error { return nil }
+
+func TestDecommissionRegistry(t *testing.T) {
+	db := &decommissionStoreMock{decommissions: make(map[string]*store.Decommission)}
+	r, err := NewDecommissionRegistry(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	DecommissionSingleton = r
+	defer func() { DecommissionSingleton = nil }()
+
+	const genesis = "XKUh4GLhFJiqAMTF6HyWQrV9pK9HcGUdfJ"
+	if IsSideChainDecommissioned(genesis) {
+		t.Error("side chain should not be decommissioned")
+	}
+	if err := r.AddReturnedDeposit(genesis); err == nil {
+		t.Error("returned deposit of active side chain should be refused")
+	}
+	if err := r.Retire(genesis); err == nil {
+		t.Error("active side chain should not be retired")
+	}
+
+	if err := r.Decommission(genesis, "sunset"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Decommission(genesis, "again"); err == nil {
+		t.Error("side chain should not be decommissioned twice")
+	}
+	if !IsSideChainDecommissioned(genesis) || IsSideChainRetired(genesis) {
+		t.Error("side chain should be draining")
+	}
+	r.AddReturnedDeposit(genesis)
+	r.AddReturnedDeposit(genesis)
+
+	if err := r.Retire(genesis); err != nil {
+		t.Fatal(err)
+	}
+	if !IsSideChainDecommissioned(genesis) || !IsSideChainRetired(genesis) {
+		t.Error("side chain should be retired")
+	}
+
+	// reloaded from the store
+	r, err = NewDecommissionRegistry(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decommissions := r.Decommissions()
+	if len(decommissions) != 1 || decommissions[0].Stage != store.DecommissionRetired ||
+		decommissions[0].Reason != "sunset" || decommissions[0].ReturnedDeposits != 2 {
+		t.Errorf("unexpected decommissions %+v", decommissions)
+	}
+}
@@ -1,12 +1,20 @@
 package arbitrator
 
 import (
+	"bytes"
+	"errors"
+
 	. "github.com/elastos/Elastos.ELA.Arbiter/arbitration/base"
+	"github.com/elastos/Elastos.ELA.Arbiter/config"
+	"github.com/elastos/Elastos.ELA.Arbiter/events"
 	"github.com/elastos/Elastos.ELA.Arbiter/log"
+	"github.com/elastos/Elastos.ELA.Arbiter/rpc"
 	"github.com/elastos/Elastos.ELA.Arbiter/store"
 	"github.com/elastos/Elastos.ELA/common"
 	elacommon "github.com/elastos/Elastos.ELA/core/types/common"
 	it "github.com/elastos/Elastos.ELA/core/types/interfaces"
+	"github.com/elastos/Elastos.ELA/core/types/outputpayload"
+	"github.com/elastos/Elastos.ELA/core/types/payload"
 
 	"github.com/elastos/Elastos.ELA.SPV/bloom"
 	. "github.com/elastos/Elastos.ELA.SPV/interface"
@@ -40,6 +48,18 @@ func (l *DepositListener) ProcessNotifyData(tasks []*notifyTask) {
 	var ids []common.Uint256
 	var txs []*MainChainTransaction
 	for _, data := range tasks {
+		quarantined, err := store.PendingTxsDbCache.HasQuarantinedTx(store.PendingDeposit,
+			data.tx.Hash().String(), l.ListenAddress)
+		if err == nil && quarantined {
+			log.Warn("[Notify-Process] ignore quarantined deposit transaction:", data.tx.Hash().String())
+			SpvService.SubmitTransactionReceipt(data.id, data.tx.Hash())
+			continue
+		}
+		if IsSideChainDecommissioned(l.ListenAddress) {
+			l.returnDeposit(data)
+			SpvService.SubmitTransactionReceipt(data.id, data.tx.Hash())
+			continue
+		}
 		ids = append(ids, data.id)
 		txs = append(txs, &MainChainTransaction{
 			TransactionHash:     data.tx.Hash().String(),
@@ -59,6 +79,23 @@ func (l *DepositListener) ProcessNotifyData(tasks []*notifyTask) {
 		SpvService.SubmitTransactionReceipt(ids[i], txs[i].Transaction.Hash())
 	}
 
+	for i := 0; i < len(result); i++ {
+		if !result[i] {
+			// the deposit is notified again once its block is reorganized
+			// and it is packed in a new block, the proof of the new block
+			// replaces the stale one
+			if err := store.DbCache.MainChainStore.UpdateMainChainTxProof(txs[i]); err != nil {
+				log.Warn("[Notify-Process] update merkle proof failed:", err)
+			}
+			continue
+		}
+		events.Notify(events.ETDepositSeen, &events.CrossChainTx{
+			GenesisBlockAddress:      l.ListenAddress,
+			MainChainTransactionHash: txs[i].TransactionHash,
+			Addresses:                depositTargetAddresses(txs[i].Transaction, l.ListenAddress),
+		})
+	}
+
 	if !ArbitratorGroupSingleton.GetCurrentArbitrator().IsOnDutyOfMain() {
 		log.Warn("[Notify-Process] i am not onduty")
 		return
@@ -80,6 +117,149 @@ func (l *DepositListener) ProcessNotifyData(tasks []*notifyTask) {
 func (l *DepositListener) Rollback(height uint32) {
 }
 
+// returnDeposit returns the deposit to the decommissioned side chain by the
+// failed deposit path, the deposits to a retired side chain are ignored.
+func (l *DepositListener) returnDeposit(data *notifyTask) {
+	txHash := data.tx.Hash().String()
+	if IsSideChainRetired(l.ListenAddress) {
+		log.Warn("[Notify-Process] ignore deposit transaction to retired side chain:", txHash)
+		return
+	}
+	dbStore := store.DbCache.GetDataStoreGenesisBlocAddress(l.ListenAddress)
+	if dbStore == nil {
+		log.Error("[Notify-Process] can't find db store by genesis block address:", l.ListenAddress)
+		return
+	}
+	failedTx, err := NewFailedDepositTx(data.tx, l.ListenAddress)
+	if err != nil {
+		log.Error("[Notify-Process] return deposit transaction", txHash, "failed:", err)
+		return
+	}
+	buf := new(bytes.Buffer)
+	if err := failedTx.Serialize(buf); err != nil {
+		log.Error("[Notify-Process] failedTx serialize error", err)
+		return
+	}
+	if err := dbStore.AddReturnDepositTx(txHash, l.ListenAddress, buf.Bytes()); err != nil {
+		log.Error("[Notify-Process] AddReturnDepositTx error:", err)
+		return
+	}
+	if err := DecommissionSingleton.AddReturnedDeposit(l.ListenAddress); err != nil {
+		log.Warn("[Notify-Process] count returned deposit failed:", err)
+	}
+	log.Info("[Notify-Process] return deposit transaction to decommissioned side chain:", txHash)
+}
+
+// NewFailedDepositTx returns the failed deposit of the deposit transaction
+// to the side chain, it is returned to the address of the first input.
+func NewFailedDepositTx(originTx it.Transaction, genesisAddress string) (*FailedDepositTx, error) {
+	if len(originTx.Inputs()) == 0 {
+		return nil, errors.New("deposit transaction has no input")
+	}
+	referTxid := originTx.Inputs()[0].Previous.TxID
+	referIndex := originTx.Inputs()[0].Previous.Index
+	referReversedTx := common.BytesToHexString(common.BytesReverse(referTxid.Bytes()))
+	referTxn, err := rpc.GetTransaction(referReversedTx, config.Parameters.MainNode.Rpc)
+	if err != nil {
+		return nil, err
+	}
+	if int(referIndex) >= len(referTxn.Outputs()) {
+		return nil, errors.New("invalid refer output index")
+	}
+	address, err := referTxn.Outputs()[referIndex].ProgramHash.ToAddress()
+	if err != nil {
+		return nil, errors.New("program hash to address error: " + err.Error())
+	}
+	crossChainHash, err := common.Uint168FromAddress(genesisAddress)
+	if err != nil {
+		return nil, errors.New("GenesisBlockAddress to hash error: " + err.Error())
+	}
+	p, ok := originTx.Payload().(*payload.TransferCrossChainAsset)
+	if !ok {
+		return nil, errors.New("invalid payload type need TransferCrossChainAsset")
+	}
+
+	var depositAmount common.Fixed64
+	var crossChainAmount common.Fixed64
+	switch originTx.PayloadVersion() {
+	case payload.TransferCrossChainVersion:
+		for i, cca := range p.CrossChainAmounts {
+			idx := p.OutputIndexes[i]
+			// output to current side chain
+			if int(idx) >= len(originTx.Outputs()) ||
+				!crossChainHash.IsEqual(originTx.Outputs()[idx].ProgramHash) {
+				continue
+			}
+			depositAmount += originTx.Outputs()[idx].Value
+			crossChainAmount += cca
+		}
+	case payload.TransferCrossChainVersionV1:
+		for _, o := range originTx.Outputs() {
+			if o.Type != elacommon.OTCrossChain {
+				continue
+			}
+			// output to current side chain
+			if !crossChainHash.IsEqual(o.ProgramHash) {
+				continue
+			}
+			op, ok := o.Payload.(*outputpayload.CrossChainOutput)
+			if !ok {
+				continue
+			}
+			depositAmount += o.Value
+			crossChainAmount += op.TargetAmount
+		}
+	}
+
+	originHash := originTx.Hash()
+	return &FailedDepositTx{
+		Txid: &originHash,
+		DepositInfo: &DepositInfo{
+			TargetAddress:    address,
+			Amount:           &depositAmount,
+			CrossChainAmount: &crossChainAmount,
+		}}, nil
+}
+
+// depositTargetAddresses returns the side chain addresses the deposit
+// transaction pays to.
+func depositTargetAddresses(tx it.Transaction, genesisAddress string) []string {
+	crossChainHash, err := common.Uint168FromAddress(genesisAddress)
+	if err != nil {
+		return nil
+	}
+	p, ok := tx.Payload().(*payload.TransferCrossChainAsset)
+	if !ok {
+		return nil
+	}
+
+	var addresses []string
+	switch tx.PayloadVersion() {
+	case payload.TransferCrossChainVersion:
+		for i, idx := range p.OutputIndexes {
+			if int(idx) >= len(tx.Outputs()) || i >= len(p.CrossChainAddresses) {
+				continue
+			}
+			if !crossChainHash.IsEqual(tx.Outputs()[idx].ProgramHash) {
+				continue
+			}
+			addresses = append(addresses, p.CrossChainAddresses[i])
+		}
+	case payload.TransferCrossChainVersionV1:
+		for _, o := range tx.Outputs() {
+			if o.Type != elacommon.OTCrossChain || !crossChainHash.IsEqual(o.ProgramHash) {
+				continue
+			}
+			op, ok := o.Payload.(*outputpayload.CrossChainOutput)
+			if !ok {
+				continue
+			}
+			addresses = append(addresses, op.TargetAddress)
+		}
+	}
+	return addresses
+}
+
 type notifyTask struct {
 	id    common.Uint256
 	proof *bloom.MerkleProof
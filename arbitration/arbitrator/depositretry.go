@@ -0,0 +1,158 @@
+package arbitrator
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/elastos/Elastos.ELA.Arbiter/config"
+	"github.com/elastos/Elastos.ELA.Arbiter/log"
+	"github.com/elastos/Elastos.ELA.Arbiter/rpc"
+	"github.com/elastos/Elastos.ELA.Arbiter/store"
+)
+
+const (
+	SCErrInternal            int64 = 45002
+	SCErrXmitFail            int64 = 45014
+	SCErrTransactionPoolSize int64 = 45024
+	RPCErrInternal           int64 = -32603
+)
+
+// transientDepositErrors are the side chain error codes of sending a deposit
+// transaction which may succeed if the transaction is sent again later.
+var transientDepositErrors = map[int64]struct{}{
+	ErrInvalidMainchainTx:    {},
+	SCErrInternal:            {},
+	SCErrXmitFail:            {},
+	SCErrTransactionPoolSize: {},
+	RPCErrInternal:           {},
+}
+
+type depositResult int
+
+const (
+	depositSucceeded depositResult = iota
+	depositTransient
+	depositPermanent
+)
+
+// classifyDepositResponse classifies the result of sending a deposit
+// transaction to the side chain, reason describes the failure.
+func classifyDepositResponse(resp rpc.Response, err error) (result depositResult, reason string) {
+	if err != nil {
+		return depositTransient, err.Error()
+	}
+	if resp.Error != nil {
+		if resp.Code == SCErrMainchainTxDuplicate {
+			return depositSucceeded, ""
+		}
+		reason = fmt.Sprintf("code %d, %s", resp.Code, resp.Message)
+		if _, ok := transientDepositErrors[resp.Code]; ok {
+			return depositTransient, reason
+		}
+		return depositPermanent, reason
+	}
+	if resp.Result == nil {
+		return depositTransient, "empty response"
+	}
+	return depositSucceeded, ""
+}
+
+// resendWithoutRetryConfig returns true if the deposit failed is sent again on
+// next pass without DepositRetry config, which is the case if the side chain
+// has not seen the main chain transaction yet or returns nothing. Other
+// failures are final as there is no retry budget.
+func resendWithoutRetryConfig(resp rpc.Response, err error) bool {
+	if err != nil {
+		return false
+	}
+	if resp.Error != nil {
+		return resp.Code == ErrInvalidMainchainTx
+	}
+	return resp.Result == nil
+}
+
+// nextDepositRetry returns the unix time of sending the deposit transaction
+// again after attempts failed, the interval starts from InitialInterval and is
+// doubled after each attempt up to MaxInterval. ok is false if the retry
+// budget is exceeded.
+func nextDepositRetry(cfg *config.DepositRetryConfig, attempts int, firstSeen, now int64) (next int64, ok bool) {
+	if cfg == nil {
+		return 0, false
+	}
+	if cfg.MaxAttempts > 0 && attempts >= cfg.MaxAttempts {
+		return 0, false
+	}
+	if cfg.MaxDuration > 0 &&
+		time.Duration(now-firstSeen)*time.Second >= time.Millisecond*cfg.MaxDuration {
+		return 0, false
+	}
+
+	interval := time.Millisecond * cfg.InitialInterval
+	maxInterval := time.Millisecond * cfg.MaxInterval
+	for i := 1; i < attempts && interval < maxInterval; i++ {
+		interval *= 2
+	}
+	if interval > maxInterval {
+		interval = maxInterval
+	}
+	return now + int64(interval/time.Second), true
+}
+
+// depositRetryInfos returns the attempts info of pending deposit transactions
+// of the side chain by transaction hash.
+func depositRetryInfos(genesisAddress string) map[string]*store.PendingTxInfo {
+	result := make(map[string]*store.PendingTxInfo)
+	infos, err := store.PendingTxsDbCache.GetPendingTxsInfo(store.PendingDeposit)
+	if err != nil {
+		log.Warn("[SendDepositTransactions] get deposit attempts failed:", err)
+		return result
+	}
+	for _, info := range infos {
+		if info.GenesisBlockAddress == genesisAddress {
+			result[info.TransactionHash] = info
+		}
+	}
+	return result
+}
+
+// hasDueDepositRetries returns true if any deposit transaction failed with a
+// transient error is due to be sent again, or is waiting for confirmations.
+func hasDueDepositRetries(now int64) bool {
+	infos, err := store.PendingTxsDbCache.GetPendingTxsInfo(store.PendingDeposit)
+	if err != nil {
+		log.Warn("Get deposit attempts failed:", err)
+		return false
+	}
+	for _, info := range infos {
+		if info.NextRetry > 0 && info.NextRetry <= now {
+			return true
+		}
+	}
+	verifications, err := store.PendingTxsDbCache.GetDepositVerifications()
+	if err != nil {
+		log.Warn("Get deposit verifications failed:", err)
+		return false
+	}
+	for _, v := range verifications {
+		if v.Status == store.DepositUnconfirmed {
+			return true
+		}
+	}
+	return false
+}
+
+// RetryDepositTransactionsLoop sends the deposit transactions failed with
+// transient errors again when they are due, if on duty of main.
+func (ar *ArbitratorImpl) RetryDepositTransactionsLoop() {
+	retry := config.Parameters.DepositRetry
+	if retry == nil {
+		return
+	}
+	for {
+		time.Sleep(time.Millisecond * retry.InitialInterval)
+		if !ar.IsOnDutyOfMain() || !hasDueDepositRetries(time.Now().Unix()) {
+			continue
+		}
+		ar.ProcessDepositTransactions()
+	}
+}
@@ -0,0 +1,77 @@
+package arbitrator
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA.Arbiter/config"
+	"github.com/elastos/Elastos.ELA.Arbiter/rpc"
+)
+
+func TestClassifyDepositResponse(t *testing.T) {
+	cases := []struct {
+		resp   rpc.Response
+		err    error
+		result depositResult
+	}{
+		{rpc.Response{Result: "hash"}, nil, depositSucceeded},
+		{rpc.Response{Error: &rpc.Error{Code: SCErrMainchainTxDuplicate}}, nil, depositSucceeded},
+		{rpc.Response{}, errors.New("connection refused"), depositTransient},
+		{rpc.Response{}, nil, depositTransient},
+		{rpc.Response{Error: &rpc.Error{Code: ErrInvalidMainchainTx}}, nil, depositTransient},
+		{rpc.Response{Error: &rpc.Error{Code: SCErrInternal}}, nil, depositTransient},
+		{rpc.Response{Error: &rpc.Error{Code: 45010, Message: "double spend"}}, nil, depositPermanent},
+	}
+	for i, c := range cases {
+		result, reason := classifyDepositResponse(c.resp, c.err)
+		if result != c.result {
+			t.Errorf("case %d: expect result %d, got %d", i, c.result, result)
+		}
+		if result != depositSucceeded && reason == "" {
+			t.Errorf("case %d: reason of failure not set", i)
+		}
+	}
+}
+
+func TestResendWithoutRetryConfig(t *testing.T) {
+	if !resendWithoutRetryConfig(rpc.Response{Error: &rpc.Error{Code: ErrInvalidMainchainTx}}, nil) {
+		t.Error("deposit not seen by side chain yet should be sent again")
+	}
+	if !resendWithoutRetryConfig(rpc.Response{}, nil) {
+		t.Error("deposit of empty response should be sent again")
+	}
+	if resendWithoutRetryConfig(rpc.Response{}, errors.New("connection refused")) {
+		t.Error("deposit failed to be sent should fail")
+	}
+	if resendWithoutRetryConfig(rpc.Response{Error: &rpc.Error{Code: SCErrInternal}}, nil) {
+		t.Error("deposit refused by side chain should fail")
+	}
+}
+
+func TestNextDepositRetry(t *testing.T) {
+	cfg := &config.DepositRetryConfig{
+		MaxAttempts:     5,
+		InitialInterval: 10000,
+		MaxInterval:     30000,
+		MaxDuration:     3600000,
+	}
+	for attempts, interval := range []int64{1: 10, 2: 20, 3: 30, 4: 30} {
+		if attempts == 0 {
+			continue
+		}
+		next, ok := nextDepositRetry(cfg, attempts, 1000, 1000)
+		if !ok || next != 1000+interval {
+			t.Errorf("attempts %d: expect next retry after %ds, got %d, %v",
+				attempts, interval, next-1000, ok)
+		}
+	}
+	if _, ok := nextDepositRetry(cfg, 5, 1000, 1000); ok {
+		t.Error("retry should stop after MaxAttempts")
+	}
+	if _, ok := nextDepositRetry(cfg, 2, 1000, 4600); ok {
+		t.Error("retry should stop after MaxDuration")
+	}
+	if _, ok := nextDepositRetry(nil, 1, 1000, 1000); ok {
+		t.Error("deposit should fail at once without retry config")
+	}
+}
@@ -0,0 +1,160 @@
+package arbitrator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	. "github.com/elastos/Elastos.ELA.Arbiter/arbitration/base"
+	"github.com/elastos/Elastos.ELA.Arbiter/events"
+	"github.com/elastos/Elastos.ELA.Arbiter/log"
+	"github.com/elastos/Elastos.ELA.Arbiter/store"
+
+	"github.com/elastos/Elastos.ELA.SPV/bloom"
+	"github.com/elastos/Elastos.ELA/common"
+	it "github.com/elastos/Elastos.ELA/core/types/interfaces"
+)
+
+// proofChecker is the spv headers used to verify the proofs of deposits.
+type proofChecker interface {
+	BestHeight() (uint32, error)
+	BlockHash(height uint32) (common.Uint256, error)
+	VerifyTransaction(proof bloom.MerkleProof, tx it.Transaction) error
+}
+
+type spvProofChecker struct{}
+
+func (spvProofChecker) BestHeight() (uint32, error) {
+	header, err := SpvService.HeaderStore().GetBest()
+	if err != nil {
+		return 0, err
+	}
+	return header.Height, nil
+}
+
+func (spvProofChecker) BlockHash(height uint32) (common.Uint256, error) {
+	header, err := SpvService.HeaderStore().GetByHeight(height)
+	if err != nil {
+		return common.Uint256{}, err
+	}
+	return header.Hash(), nil
+}
+
+func (spvProofChecker) VerifyTransaction(proof bloom.MerkleProof, tx it.Transaction) error {
+	return SpvService.VerifyTransaction(proof, tx)
+}
+
+// verifyDepositProof verifies the merkle proof of the deposit transaction is
+// in the best chain and confirmed by depth blocks.
+func verifyDepositProof(checker proofChecker, spvTx *SpvTransaction, depth uint32) *store.DepositVerification {
+	v := &store.DepositVerification{
+		TransactionHash: spvTx.MainChainTransaction.Hash().String(),
+		Status:          store.DepositInvalid,
+	}
+	if spvTx.Proof == nil {
+		v.Error = "merkle proof not found"
+		return v
+	}
+	proof := spvTx.Proof
+	v.BlockHeight = proof.Height
+
+	bestHeight, err := checker.BestHeight()
+	if err != nil {
+		v.Status, v.Error = store.DepositUnconfirmed, "get best height failed, "+err.Error()
+		return v
+	}
+	if proof.Height > bestHeight {
+		v.Status, v.Error = store.DepositUnconfirmed, "block of proof not synced"
+		return v
+	}
+	// the block of the proof may have been reorganized, the deposit waits for
+	// the proof of the block packing it in the best chain
+	blockHash, err := checker.BlockHash(proof.Height)
+	if err == nil && !blockHash.IsEqual(proof.BlockHash) {
+		err = errors.New("block of proof not in best chain")
+	}
+	if err != nil {
+		v.Status, v.Error = store.DepositUnconfirmed, err.Error()
+		return v
+	}
+	if err := checker.VerifyTransaction(*proof, spvTx.MainChainTransaction); err != nil {
+		v.Error = err.Error()
+		return v
+	}
+
+	v.Confirmations = bestHeight - proof.Height + 1
+	if v.Confirmations < depth {
+		v.Status = store.DepositUnconfirmed
+		v.Error = fmt.Sprintf("%d confirmations, %d needed", v.Confirmations, depth)
+		return v
+	}
+	v.Status = store.DepositVerified
+	return v
+}
+
+// verifyDeposits returns the deposit transactions verified by the spv
+// headers, the verification results are recorded. The deposits of invalid
+// proofs are alerted and quarantined, so they are alerted only once.
+func verifyDeposits(checker proofChecker, spvTxs []*SpvTransaction, genesisAddress string, depth uint32) []*SpvTransaction {
+	var verified []*SpvTransaction
+	for _, tx := range spvTxs {
+		v := verifyDepositProof(checker, tx, depth)
+		v.GenesisBlockAddress = genesisAddress
+		if err := store.PendingTxsDbCache.SetDepositVerification(v); err != nil {
+			log.Warn("[SendDepositTransactions] record deposit verification failed:", err)
+		}
+		switch v.Status {
+		case store.DepositVerified:
+			verified = append(verified, tx)
+		case store.DepositUnconfirmed:
+			log.Info("Send deposit transaction delayed, main chain tx hash:", v.TransactionHash,
+				"reason:", v.Error)
+		default:
+			log.Warn("Send deposit transaction refused, main chain tx hash:", v.TransactionHash,
+				"reason:", v.Error)
+			events.Notify(events.ETSubmitFailed, &events.Alert{
+				GenesisBlockAddress: genesisAddress,
+				Subject:             "recharge " + v.TransactionHash,
+				Message:             "deposit transaction " + v.TransactionHash + " has an invalid proof: " + v.Error,
+			})
+			if err := quarantineDeposit(tx, genesisAddress, "invalid proof: "+v.Error); err != nil {
+				log.Error("[SendDepositTransactions] quarantine deposit failed:", err)
+			}
+		}
+	}
+	return verified
+}
+
+// quarantineDeposit moves the deposit transaction to quarantine, it is no
+// longer processed until restored.
+func quarantineDeposit(spvTx *SpvTransaction, genesisAddress, reason string) error {
+	hash := spvTx.MainChainTransaction.Hash().String()
+	tx := &store.QuarantinedTx{
+		Kind:                store.PendingDeposit,
+		TransactionHash:     hash,
+		GenesisBlockAddress: genesisAddress,
+		Reason:              reason,
+	}
+	buf := new(bytes.Buffer)
+	if err := spvTx.MainChainTransaction.Serialize(buf); err != nil {
+		return err
+	}
+	tx.TransactionData = buf.Bytes()
+	if spvTx.Proof != nil {
+		buf = new(bytes.Buffer)
+		if err := spvTx.Proof.Serialize(buf); err != nil {
+			return err
+		}
+		tx.MerkleProof = buf.Bytes()
+		tx.BlockHeight = spvTx.Proof.Height
+	}
+
+	if err := store.PendingTxsDbCache.AddQuarantinedTx(tx); err != nil {
+		return err
+	}
+	if err := store.DbCache.MainChainStore.RemoveMainChainTx(hash, genesisAddress); err != nil {
+		store.PendingTxsDbCache.RemoveQuarantinedTx(store.PendingDeposit, hash, genesisAddress)
+		return err
+	}
+	return store.PendingTxsDbCache.RemovePendingTxsInfo(store.PendingDeposit, []string{hash}, []string{genesisAddress})
+}
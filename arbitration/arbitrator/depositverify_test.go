@@ -0,0 +1,98 @@
+package arbitrator
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/elastos/Elastos.ELA.Arbiter/arbitration/base"
+	"github.com/elastos/Elastos.ELA.Arbiter/store"
+
+	"github.com/elastos/Elastos.ELA.SPV/bloom"
+	"github.com/elastos/Elastos.ELA/common"
+	elatx "github.com/elastos/Elastos.ELA/core/transaction"
+	elacommon "github.com/elastos/Elastos.ELA/core/types/common"
+	it "github.com/elastos/Elastos.ELA/core/types/interfaces"
+	"github.com/elastos/Elastos.ELA/core/types/payload"
+)
+
+// testProofChecker is a best chain of blocks whose hashes are their heights.
+type testProofChecker struct {
+	bestHeight uint32
+	invalidTx  bool
+}
+
+func (c *testProofChecker) BestHeight() (uint32, error) {
+	return c.bestHeight, nil
+}
+
+func (c *testProofChecker) BlockHash(height uint32) (common.Uint256, error) {
+	if height > c.bestHeight {
+		return common.Uint256{}, errors.New("header not found")
+	}
+	return common.Uint256{byte(height)}, nil
+}
+
+func (c *testProofChecker) VerifyTransaction(proof bloom.MerkleProof, tx it.Transaction) error {
+	if c.invalidTx {
+		return errors.New("transaction not in merkle tree")
+	}
+	return nil
+}
+
+func newTestDeposit(height uint32, blockHash common.Uint256) *SpvTransaction {
+	tx := elatx.CreateTransaction(
+		elacommon.TxVersion09,
+		elacommon.TransferCrossChainAsset,
+		payload.TransferCrossChainVersion,
+		&payload.TransferCrossChainAsset{},
+		[]*elacommon.Attribute{},
+		[]*elacommon.Input{{Previous: elacommon.OutPoint{TxID: common.Uint256{1}}}},
+		[]*elacommon.Output{},
+		0,
+		nil,
+	)
+	return &SpvTransaction{
+		MainChainTransaction: tx,
+		Proof:                &bloom.MerkleProof{BlockHash: blockHash, Height: height},
+	}
+}
+
+func TestVerifyDepositProof(t *testing.T) {
+	cases := []struct {
+		name          string
+		checker       *testProofChecker
+		deposit       *SpvTransaction
+		status        string
+		confirmations uint32
+	}{
+		{"confirmed", &testProofChecker{bestHeight: 20}, newTestDeposit(15, common.Uint256{15}),
+			store.DepositVerified, 6},
+		{"below depth", &testProofChecker{bestHeight: 19}, newTestDeposit(15, common.Uint256{15}),
+			store.DepositUnconfirmed, 5},
+		{"not synced", &testProofChecker{bestHeight: 10}, newTestDeposit(15, common.Uint256{15}),
+			store.DepositUnconfirmed, 0},
+		{"not in best chain", &testProofChecker{bestHeight: 20}, newTestDeposit(15, common.Uint256{16}),
+			store.DepositUnconfirmed, 0},
+		{"merkle mismatch", &testProofChecker{bestHeight: 20, invalidTx: true}, newTestDeposit(15, common.Uint256{15}),
+			store.DepositInvalid, 0},
+	}
+	for _, c := range cases {
+		v := verifyDepositProof(c.checker, c.deposit, 6)
+		if v.Status != c.status || v.Confirmations != c.confirmations {
+			t.Errorf("%s: expect %s with %d confirmations, got %s with %d, %s",
+				c.name, c.status, c.confirmations, v.Status, v.Confirmations, v.Error)
+		}
+		if v.Status != store.DepositVerified && v.Error == "" {
+			t.Errorf("%s: reason not set", c.name)
+		}
+		if v.BlockHeight != 15 {
+			t.Errorf("%s: expect block height 15, got %d", c.name, v.BlockHeight)
+		}
+	}
+
+	deposit := newTestDeposit(15, common.Uint256{15})
+	deposit.Proof = nil
+	if v := verifyDepositProof(&testProofChecker{bestHeight: 20}, deposit, 6); v.Status != store.DepositInvalid {
+		t.Errorf("deposit without proof should be invalid, got %s", v.Status)
+	}
+}
@@ -0,0 +1,270 @@
+package arbitrator
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/elastos/Elastos.ELA.Arbiter/arbitration/base"
+	"github.com/elastos/Elastos.ELA.Arbiter/events"
+	"github.com/elastos/Elastos.ELA.Arbiter/log"
+	"github.com/elastos/Elastos.ELA.Arbiter/store"
+
+	"github.com/elastos/Elastos.ELA/common"
+)
+
+// configFrozenReason is the reason of the addresses frozen by
+// FrozenAddresses of config.
+const configFrozenReason = "FrozenAddresses of config"
+
+// FrozenRegistrySingleton holds the frozen addresses and the withdraw
+// transactions blocked by them, only FrozenAddresses of config are frozen
+// if it is nil.
+var FrozenRegistrySingleton *FrozenRegistry
+
+// WithdrawBlockedError is returned for the withdraw transactions paying to a
+// frozen address, they are held until released or returned.
+type WithdrawBlockedError struct {
+	Address string
+	Reason  string
+}
+
+func (e *WithdrawBlockedError) Error() string {
+	return "address " + e.Address + " is frozen: " + e.Reason
+}
+
+// IsWithdrawBlocked returns if the withdraw transaction is held by a frozen
+// address instead of being rejected.
+func IsWithdrawBlocked(err error) bool {
+	_, ok := err.(*WithdrawBlockedError)
+	return ok
+}
+
+// FrozenRegistry is the frozen addresses of config and the ones frozen at
+// runtime, the latter take effect at once and are kept in the store.
+type FrozenRegistry struct {
+	mux       sync.Mutex
+	static    map[string]struct{}
+	addresses map[string]*store.FrozenAddress
+	blocked   map[string]*store.BlockedWithdraw
+
+	db store.FrozenAddressDataStore
+}
+
+// NewFrozenRegistry returns the registry of the frozen addresses of config
+// and the ones in the store, db may be nil if nothing is kept.
+func NewFrozenRegistry(configAddresses []string, db store.FrozenAddressDataStore) (*FrozenRegistry, error) {
+	r := &FrozenRegistry{
+		static:    make(map[string]struct{}),
+		addresses: make(map[string]*store.FrozenAddress),
+		blocked:   make(map[string]*store.BlockedWithdraw),
+		db:        db,
+	}
+	for _, addr := range configAddresses {
+		r.static[addr] = struct{}{}
+	}
+	if db == nil {
+		return r, nil
+	}
+
+	addrs, err := db.GetFrozenAddresses()
+	if err != nil {
+		return nil, err
+	}
+	for _, addr := range addrs {
+		r.addresses[addr.Address] = addr
+	}
+	withdraws, err := db.GetBlockedWithdraws()
+	if err != nil {
+		return nil, err
+	}
+	for _, w := range withdraws {
+		r.blocked[w.TransactionHash+w.GenesisBlockAddress] = w
+	}
+	return r, nil
+}
+
+// Freeze freezes the address until expiry, the unix time, or for ever if
+// expiry is 0.
+func (r *FrozenRegistry) Freeze(address, reason string, expiry int64) error {
+	if _, err := common.Uint168FromAddress(address); err != nil {
+		return fmt.Errorf("invalid address %q", address)
+	}
+	now := time.Now().Unix()
+	if expiry != 0 && expiry <= now {
+		return errors.New("expiry should be later than now")
+	}
+	addr := &store.FrozenAddress{
+		Address:    address,
+		Reason:     reason,
+		Expiry:     expiry,
+		RecordTime: now,
+	}
+
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if r.db == nil {
+		return errors.New("frozen address store not opened")
+	}
+	if err := r.db.AddFrozenAddress(addr); err != nil {
+		return err
+	}
+	r.addresses[address] = addr
+	return nil
+}
+
+// Unfreeze removes the address frozen at runtime, the addresses frozen by
+// config are kept.
+func (r *FrozenRegistry) Unfreeze(address string) error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if _, ok := r.addresses[address]; !ok {
+		if _, ok := r.static[address]; ok {
+			return errors.New("address is frozen by config")
+		}
+		return errors.New("address is not frozen")
+	}
+	if err := r.db.RemoveFrozenAddress(address); err != nil {
+		return err
+	}
+	delete(r.addresses, address)
+	return nil
+}
+
+// FrozenAddresses returns the frozen addresses including the expired ones,
+// the addresses of config are listed first.
+func (r *FrozenRegistry) FrozenAddresses() []*store.FrozenAddress {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	result := make([]*store.FrozenAddress, 0, len(r.static)+len(r.addresses))
+	for addr := range r.static {
+		if _, ok := r.addresses[addr]; !ok {
+			result = append(result, &store.FrozenAddress{Address: addr, Reason: configFrozenReason})
+		}
+	}
+	for _, addr := range r.addresses {
+		a := *addr
+		result = append(result, &a)
+	}
+	return result
+}
+
+// BlockedWithdraws returns the withdraw transactions blocked by frozen
+// addresses.
+func (r *FrozenRegistry) BlockedWithdraws() []*store.BlockedWithdraw {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	result := make([]*store.BlockedWithdraw, 0, len(r.blocked))
+	for _, w := range r.blocked {
+		b := *w
+		result = append(result, &b)
+	}
+	return result
+}
+
+// Release lets the blocked withdraw transaction be proposed as usual even if
+// the address is still frozen, the addresses frozen by config are denied by
+// the proposal policy and can not be released.
+func (r *FrozenRegistry) Release(genesisAddress, txHash string) error {
+	return r.resolve(genesisAddress, txHash, store.WithdrawReleased)
+}
+
+// Return lets the blocked withdraw transaction be reported to the side chain
+// as an invalid withdraw.
+func (r *FrozenRegistry) Return(genesisAddress, txHash string) error {
+	return r.resolve(genesisAddress, txHash, store.WithdrawReturned)
+}
+
+func (r *FrozenRegistry) resolve(genesisAddress, txHash, status string) error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	w, ok := r.blocked[txHash+genesisAddress]
+	if !ok {
+		return errors.New("not found in blocked withdraw transactions")
+	}
+	if w.Status != store.WithdrawBlocked {
+		return errors.New("withdraw transaction already " + w.Status)
+	}
+	if _, ok := r.static[w.Address]; ok && status == store.WithdrawReleased {
+		return errors.New("address is frozen by config")
+	}
+	if err := r.db.UpdateBlockedWithdrawStatus(txHash, genesisAddress, status); err != nil {
+		return err
+	}
+	w.Status = status
+	w.UpdateTime = time.Now().Unix()
+	return nil
+}
+
+// frozen returns the reason if the address is frozen at the time.
+func (r *FrozenRegistry) frozen(address string, now time.Time) (string, bool) {
+	if addr, ok := r.addresses[address]; ok &&
+		(addr.Expiry == 0 || now.Unix() < addr.Expiry) {
+		return addr.Reason, true
+	}
+	if _, ok := r.static[address]; ok {
+		return configFrozenReason, true
+	}
+	return "", false
+}
+
+// CheckWithdraw returns WithdrawBlockedError if the withdraw transaction pays
+// to a frozen address and is not released, the withdraw blocked is recorded
+// and alerted the first time. Error is returned for the withdraw returned.
+func (r *FrozenRegistry) CheckWithdraw(genesisAddress string, tx *base.WithdrawTx, now time.Time) error {
+	txHash := tx.Txid.String()
+	key := txHash + genesisAddress
+
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	w, recorded := r.blocked[key]
+	if recorded {
+		switch w.Status {
+		case store.WithdrawReleased:
+			return nil
+		case store.WithdrawReturned:
+			return fmt.Errorf("returned since address %s is frozen: %s", w.Address, w.Reason)
+		}
+	}
+
+	for _, asset := range tx.WithdrawInfo.WithdrawAssets {
+		reason, ok := r.frozen(asset.TargetAddress, now)
+		if !ok {
+			continue
+		}
+		if !recorded && r.db != nil {
+			w = &store.BlockedWithdraw{
+				TransactionHash:     txHash,
+				GenesisBlockAddress: genesisAddress,
+				Address:             asset.TargetAddress,
+				Reason:              reason,
+				Status:              store.WithdrawBlocked,
+				BlockTime:           now.Unix(),
+				UpdateTime:          now.Unix(),
+			}
+			if err := r.db.AddBlockedWithdraw(w); err != nil {
+				log.Error("[FrozenRegistry] add blocked withdraw failed:", err)
+			} else {
+				r.blocked[key] = w
+				events.Notify(events.ETWithdrawBlocked, &events.Alert{
+					GenesisBlockAddress: genesisAddress,
+					Subject:             txHash,
+					Message:             "address " + asset.TargetAddress + " is frozen: " + reason,
+				})
+			}
+		}
+		return &WithdrawBlockedError{Address: asset.TargetAddress, Reason: reason}
+	}
+
+	// The address is unfrozen or expired since the withdraw was blocked.
+	if recorded && r.db != nil {
+		if err := r.db.UpdateBlockedWithdrawStatus(txHash, genesisAddress, store.WithdrawReleased); err != nil {
+			log.Error("[FrozenRegistry] release blocked withdraw failed:", err)
+		} else {
+			w.Status = store.WithdrawReleased
+			w.UpdateTime = now.Unix()
+		}
+	}
+	return nil
+}
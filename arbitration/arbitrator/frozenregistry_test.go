@@ -0,0 +1,128 @@
+package arbitrator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/elastos/Elastos.ELA.Arbiter/arbitration/base"
+	"github.com/elastos/Elastos.ELA.Arbiter/store"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/contract"
+)
+
+type frozenAddressStoreMock struct {
+	addresses map[string]*store.FrozenAddress
+	withdraws map[string]*store.BlockedWithdraw
+}
+
+func (m *frozenAddressStoreMock) AddFrozenAddress(addr *store.FrozenAddress) error {
+	m.addresses[addr.Address] = addr
+	return nil
+}
+
+func (m *frozenAddressStoreMock) RemoveFrozenAddress(address string) error {
+	delete(m.addresses, address)
+	return nil
+}
+
+func (m *frozenAddressStoreMock) GetFrozenAddresses() ([]*store.FrozenAddress, error) {
+	var addrs []*store.FrozenAddress
+	for _, addr := range m.addresses {
+		addrs = append(addrs, addr)
+	}
+	return addrs, nil
+}
+
+func (m *frozenAddressStoreMock) AddBlockedWithdraw(w *store.BlockedWithdraw) error {
+	if _, ok := m.withdraws[w.TransactionHash]; !ok {
+		b := *w
+		m.withdraws[w.TransactionHash] = &b
+	}
+	return nil
+}
+
+func (m *frozenAddressStoreMock) UpdateBlockedWithdrawStatus(txHash, genesisAddress, status string) error {
+	m.withdraws[txHash].Status = status
+	return nil
+}
+
+func (m *frozenAddressStoreMock) GetBlockedWithdraws() ([]*store.BlockedWithdraw, error) {
+	var withdraws []*store.BlockedWithdraw
+	for _, w := range m.withdraws {
+		withdraws = append(withdraws, w)
+	}
+	return withdraws, nil
+}
+
+func (m *frozenAddressStoreMock) ResetDataStore(dbName string) error { return nil }
+
+func TestFrozenRegistry(t *testing.T) {
+	configFrozen := testAddress(contract.PrefixStandard, 1)
+	frozen := testAddress(contract.PrefixStandard, 2)
+	db := &frozenAddressStoreMock{
+		addresses: make(map[string]*store.FrozenAddress),
+		withdraws: make(map[string]*store.BlockedWithdraw),
+	}
+	r, _ := NewFrozenRegistry([]string{configFrozen}, db)
+	now := time.Now()
+	if err := r.Freeze(frozen, "stolen", now.Unix()+3600); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Freeze("EXXX", "", 0); err == nil {
+		t.Error("invalid address should not be frozen")
+	}
+	if err := r.Unfreeze(configFrozen); err == nil {
+		t.Error("address frozen by config should not be unfrozen")
+	}
+
+	newTx := func(b byte, addr string) *base.WithdrawTx {
+		return &base.WithdrawTx{
+			Txid: &common.Uint256{b},
+			WithdrawInfo: &base.WithdrawInfo{WithdrawAssets: []*base.WithdrawAsset{
+				testAsset(addr, 210000, 200000),
+			}},
+		}
+	}
+	tx := newTx(1, frozen)
+	if err := r.CheckWithdraw("genesis", tx, now); !IsWithdrawBlocked(err) {
+		t.Fatalf("withdraw to frozen address should be blocked, got %v", err)
+	}
+	if w := db.withdraws[tx.Txid.String()]; w == nil || w.Status != store.WithdrawBlocked || w.Reason != "stolen" {
+		t.Errorf("blocked withdraw recorded wrongly, %+v", w)
+	}
+	if err := r.CheckWithdraw("genesis", tx, now.Add(2*time.Hour)); err != nil {
+		t.Errorf("withdraw should pass after the address expired, got %v", err)
+	}
+	if w := db.withdraws[tx.Txid.String()]; w.Status != store.WithdrawReleased {
+		t.Errorf("withdraw should be released after the address expired, got %s", w.Status)
+	}
+
+	returned := newTx(2, frozen)
+	r.CheckWithdraw("genesis", returned, now)
+	if err := r.Return("genesis", returned.Txid.String()); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.CheckWithdraw("genesis", returned, now); err == nil || IsWithdrawBlocked(err) {
+		t.Errorf("returned withdraw should be rejected, got %v", err)
+	}
+	if err := r.Release("genesis", returned.Txid.String()); err == nil {
+		t.Error("returned withdraw should not be released")
+	}
+
+	static := newTx(3, configFrozen)
+	if err := r.CheckWithdraw("genesis", static, now); !IsWithdrawBlocked(err) {
+		t.Errorf("withdraw to address frozen by config should be blocked, got %v", err)
+	}
+	if err := r.Release("genesis", static.Txid.String()); err == nil {
+		t.Error("withdraw to address frozen by config should not be released")
+	}
+
+	if err := r.Unfreeze(frozen); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, _ := NewFrozenRegistry([]string{configFrozen}, db)
+	if len(reloaded.FrozenAddresses()) != 1 || len(reloaded.BlockedWithdraws()) != 3 {
+		t.Error("frozen registry reloaded wrongly")
+	}
+}
@@ -29,6 +29,10 @@ func MonitorInvalidWithdrawTransaction() {
 				if !sc.GetCurrentConfig().SupportInvalidWithdraw {
 					continue
 				}
+				if IsWithdrawPaused(sc.GetKey()) {
+					log.Info("[MonitorInvalidWithdrawTransaction] withdraws of side chain ", sc.GetKey(), " are paused")
+					continue
+				}
 
 				dbStore := store.DbCache.GetDataStoreByDBName(sc.GetCurrentConfig().Name)
 				if dbStore == nil {
@@ -57,27 +61,22 @@ func MonitorInvalidWithdrawTransaction() {
 				// get all invalid transactions
 				invalidTransactions := make([]*base.WithdrawTx, 0)
 				for _, tx := range unsolvedTransactions {
-					ignore := false
-					for _, w := range tx.WithdrawInfo.WithdrawAssets {
-						if *w.CrossChainAmount <= 0 ||
-							*w.Amount-*w.CrossChainAmount < MinCrossChainTxFee {
-							ignore = true
-							break
-						}
-						_, err := common.Uint168FromAddress(w.TargetAddress)
-						if err != nil {
-							ignore = true
-							break
-						}
-					}
-					if ignore {
-						invalidTransactions = append(invalidTransactions, tx)
+					// Only the withdraw breaking the rules of the main chain
+					// is invalid, the one breaking the policy of the arbiter
+					// or blocked by a frozen address is held and checked
+					// again in the next pass. The reason recorded is kept
+					// since the target data too large is not stored.
+					err := CheckWithdrawTx(sc.GetKey(), tx)
+					if tx.RejectReason == "" && !IsWithdrawInvalid(err) {
 						continue
 					}
-
-					if len(tx.WithdrawInfo.WithdrawAssets) == 0 {
-						invalidTransactions = append(invalidTransactions, tx)
+					if tx.RejectReason == "" {
+						tx.RejectReason = err.Error()
+						if err := dbStore.SetSideChainTxRejectReason(tx.Txid.String(), tx.RejectReason); err != nil {
+							log.Warn("[MonitorInvalidWithdrawTransaction] record reject reason failed:", err)
+						}
 					}
+					invalidTransactions = append(invalidTransactions, tx)
 				}
 
 				// get all not processed invalid withdraw transactions
@@ -138,11 +137,13 @@ func MonitorInvalidWithdrawTransaction() {
 					}
 
 					// send transaction to side chain.
-					_, err = sc.SendInvalidWithdrawTransaction(signature, common.ToReversedString(*tx.Txid))
+					_, err = sc.SendInvalidWithdrawTransaction(signature,
+						common.ToReversedString(*tx.Txid), tx.RejectReason)
 					if err != nil {
 						log.Error("[SendInvalidWithdrawTransactions] Error", err.Error())
 					} else {
-						log.Info("[SendInvalidWithdrawTransactions] transactions hash: ", txHash)
+						log.Info("[SendInvalidWithdrawTransactions] transactions hash: ", txHash,
+							" reason: ", tx.RejectReason)
 					}
 				}
 			}
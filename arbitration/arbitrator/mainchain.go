@@ -36,7 +36,8 @@ type MainChain interface {
 
 	//schnorr withdraw
 	BroadcastSchnorrWithdrawProposal2(txn it.Transaction) error
-	BroadcastSchnorrWithdrawProposal3(nonceHash common.Uint256, txn it.Transaction, pks [][]byte, e *big.Int) error
+	BroadcastSchnorrWithdrawProposal3(nonceHash common.Uint256, txn it.Transaction, pks [][]byte,
+		e, rx, ry *big.Int) error
 
 	SyncMainChainCachedTxs() error
 	CheckAndRemoveDepositTransactionsFromDB() error
@@ -0,0 +1,196 @@
+package arbitrator
+
+import (
+	"errors"
+	"time"
+
+	"github.com/elastos/Elastos.ELA.Arbiter/config"
+	"github.com/elastos/Elastos.ELA.Arbiter/events"
+	"github.com/elastos/Elastos.ELA.Arbiter/log"
+	"github.com/elastos/Elastos.ELA.Arbiter/rpc"
+	"github.com/elastos/Elastos.ELA.Arbiter/store"
+
+	"github.com/elastos/Elastos.ELA/common"
+)
+
+// reconciliationSource pulls the amounts of a side chain to reconcile.
+type reconciliationSource interface {
+	// Locked returns the balance of the genesis address on the main chain.
+	Locked(genesisAddress string) (common.Fixed64, error)
+
+	// Supply returns the ELA circulating on the side chain.
+	Supply(node *config.SideNodeConfig) (common.Fixed64, error)
+
+	// PendingWithdraws returns the amount of withdraws burned on the side
+	// chain and not paid on the main chain yet.
+	PendingWithdraws(genesisAddress string) (common.Fixed64, error)
+
+	// PendingDeposits returns the amount of deposits locked on the main chain
+	// and not minted on the side chain yet.
+	PendingDeposits(genesisAddress string) (common.Fixed64, error)
+}
+
+// reconcile compares the ELA locked at the genesis address of the side chain
+// with the side chain supply, the withdraws burned and the deposits not
+// minted yet are still locked. The report is flagged if the discrepancy is
+// beyond the tolerance, the amounts are unknown if Error is set.
+func reconcile(source reconciliationSource, node *config.SideNodeConfig,
+	tolerance common.Fixed64, now time.Time) *store.ReconciliationReport {
+	report := &store.ReconciliationReport{
+		GenesisBlockAddress: node.GenesisBlockAddress,
+		ReportTime:          now.Unix(),
+	}
+	var err error
+	if report.Locked, err = source.Locked(node.GenesisBlockAddress); err != nil {
+		report.Error = "get locked amount failed: " + err.Error()
+		return report
+	}
+	if report.Supply, err = source.Supply(node); err != nil {
+		report.Error = "get side chain supply failed: " + err.Error()
+		return report
+	}
+	if report.PendingWithdraws, err = source.PendingWithdraws(node.GenesisBlockAddress); err != nil {
+		report.Error = "get pending withdraws failed: " + err.Error()
+		return report
+	}
+	if report.PendingDeposits, err = source.PendingDeposits(node.GenesisBlockAddress); err != nil {
+		report.Error = "get pending deposits failed: " + err.Error()
+		return report
+	}
+
+	report.Discrepancy = report.Locked - report.Supply - report.PendingWithdraws - report.PendingDeposits
+	discrepancy := report.Discrepancy
+	if discrepancy < 0 {
+		discrepancy = -discrepancy
+	}
+	report.Flagged = discrepancy > tolerance
+	return report
+}
+
+// reconcileSideChains reconciles the side chains not retired, the reports are
+// stored and the flagged ones are alerted. The reports older than the
+// retention are pruned.
+func reconcileSideChains(nodes []*config.SideNodeConfig, cfg *config.ReconciliationConfig,
+	source reconciliationSource, db store.ReconciliationDataStore) []*store.ReconciliationReport {
+	var reports []*store.ReconciliationReport
+	now := time.Now()
+	for _, node := range nodes {
+		if IsSideChainRetired(node.GenesisBlockAddress) {
+			continue
+		}
+		report := reconcile(source, node, cfg.Tolerance, now)
+		if report.Error != "" {
+			log.Warn("[Reconciliation] side chain", node.Name, "not reconciled:", report.Error)
+		} else if report.Flagged {
+			log.Warn("[Reconciliation] side chain", node.Name, "locked", report.Locked, "supply",
+				report.Supply, "pending withdraws", report.PendingWithdraws, "pending deposits",
+				report.PendingDeposits, "discrepancy", report.Discrepancy, "beyond tolerance", cfg.Tolerance)
+			events.Notify(events.ETReconciliationDiscrepancy, &events.Alert{
+				GenesisBlockAddress: node.GenesisBlockAddress,
+				Subject:             node.Name,
+				Message: "discrepancy " + report.Discrepancy.String() + " beyond tolerance " +
+					cfg.Tolerance.String(),
+			})
+		}
+		if err := db.AddReconciliationReport(report); err != nil {
+			log.Error("[Reconciliation] add report failed:", err)
+		}
+		reports = append(reports, report)
+	}
+	if err := db.RemoveReconciliationReportsBefore(now.Add(-cfg.GetRetention()).Unix()); err != nil {
+		log.Error("[Reconciliation] prune reports failed:", err)
+	}
+	return reports
+}
+
+// ReconciliationLoop reconciles the side chains in the interval of
+// Reconciliation config, the first reconciliation waits an interval for the
+// chains to be synced.
+func ReconciliationLoop() {
+	cfg := config.Parameters.Reconciliation
+	if cfg == nil {
+		return
+	}
+	source := &chainReconciliationSource{cfg: cfg}
+	for {
+		time.Sleep(time.Millisecond * cfg.Interval)
+		reconcileSideChains(config.SideNodes(), cfg, source, store.ReconciliationDbCache)
+	}
+}
+
+// chainReconciliationSource pulls the amounts from the main node, the side
+// nodes and the cross chain transactions in the store.
+type chainReconciliationSource struct {
+	cfg *config.ReconciliationConfig
+}
+
+func (s *chainReconciliationSource) Locked(genesisAddress string) (common.Fixed64, error) {
+	utxos, err := rpc.GetUnspentUtxo([]string{genesisAddress}, config.Parameters.MainNode.Rpc)
+	if err != nil {
+		return 0, err
+	}
+	var locked common.Fixed64
+	for _, utxo := range utxos {
+		amount, err := common.StringToFixed64(utxo.Amount)
+		if err != nil {
+			return 0, err
+		}
+		locked += *amount
+	}
+	return locked, nil
+}
+
+func (s *chainReconciliationSource) Supply(node *config.SideNodeConfig) (common.Fixed64, error) {
+	method := s.cfg.GetSupplyMethod(node)
+	if method == "" {
+		return 0, errors.New("supply method not set")
+	}
+	return rpc.GetCirculatingSupply(method, node.Rpc)
+}
+
+func (s *chainReconciliationSource) PendingWithdraws(genesisAddress string) (common.Fixed64, error) {
+	dbStore := store.DbCache.GetDataStoreGenesisBlocAddress(genesisAddress)
+	if dbStore == nil {
+		return 0, errors.New("side chain store not found")
+	}
+	txHashes, err := dbStore.GetAllSideChainTxHashes()
+	if err != nil || len(txHashes) == 0 {
+		return 0, err
+	}
+	txs, err := dbStore.GetSideChainTxsFromHashes(txHashes)
+	if err != nil {
+		return 0, err
+	}
+	// The whole amount is burned on the side chain, the part beyond the cross
+	// chain amount is paid as the fee of the withdraw on the main chain.
+	var amount common.Fixed64
+	for _, tx := range txs {
+		for _, asset := range tx.WithdrawInfo.WithdrawAssets {
+			amount += *asset.Amount
+		}
+	}
+	return amount, nil
+}
+
+func (s *chainReconciliationSource) PendingDeposits(genesisAddress string) (common.Fixed64, error) {
+	crossChainHash, err := common.Uint168FromAddress(genesisAddress)
+	if err != nil {
+		return 0, err
+	}
+	txs, err := store.DbCache.MainChainStore.GetAllMainChainTxs()
+	if err != nil {
+		return 0, err
+	}
+	var amount common.Fixed64
+	for _, tx := range txs {
+		if tx.GenesisBlockAddress != genesisAddress {
+			continue
+		}
+		for _, o := range tx.Transaction.Outputs() {
+			if crossChainHash.IsEqual(o.ProgramHash) {
+				amount += o.Value
+			}
+		}
+	}
+	return amount, nil
+}
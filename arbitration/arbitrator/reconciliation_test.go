@@ -0,0 +1,124 @@
+package arbitrator
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/elastos/Elastos.ELA.Arbiter/config"
+	"github.com/elastos/Elastos.ELA.Arbiter/store"
+
+	"github.com/elastos/Elastos.ELA/common"
+)
+
+type reconciliationSourceMock struct {
+	locked           map[string]common.Fixed64
+	supply           map[string]common.Fixed64
+	pendingWithdraws map[string]common.Fixed64
+	pendingDeposits  map[string]common.Fixed64
+}
+
+func (m *reconciliationSourceMock) Locked(genesisAddress string) (common.Fixed64, error) {
+	return m.locked[genesisAddress], nil
+}
+
+func (m *reconciliationSourceMock) Supply(node *config.SideNodeConfig) (common.Fixed64, error) {
+	supply, ok := m.supply[node.GenesisBlockAddress]
+	if !ok {
+		return 0, errors.New("side chain unreachable")
+	}
+	return supply, nil
+}
+
+func (m *reconciliationSourceMock) PendingWithdraws(genesisAddress string) (common.Fixed64, error) {
+	return m.pendingWithdraws[genesisAddress], nil
+}
+
+func (m *reconciliationSourceMock) PendingDeposits(genesisAddress string) (common.Fixed64, error) {
+	return m.pendingDeposits[genesisAddress], nil
+}
+
+type reconciliationStoreMock struct {
+	reports []*store.ReconciliationReport
+}
+
+func (m *reconciliationStoreMock) RemoveReconciliationReportsBefore(reportTime int64) error {
+	var reports []*store.ReconciliationReport
+	for _, r := range m.reports {
+		if r.ReportTime >= reportTime {
+			reports = append(reports, r)
+		}
+	}
+	m.reports = reports
+	return nil
+}
+
+func (m *reconciliationStoreMock) AddReconciliationReport(report *store.ReconciliationReport) error {
+	m.reports = append(m.reports, report)
+	return nil
+}
+
+func (m *reconciliationStoreMock) GetReconciliationReports(genesisAddress string, from, to int64) ([]*store.ReconciliationReport, error) {
+	return m.reports, nil
+}
+
+func (m *reconciliationStoreMock) ResetDataStore(dbName string) error { return nil }
+
+func TestReconcile(t *testing.T) {
+	source := &reconciliationSourceMock{
+		locked:           map[string]common.Fixed64{"balanced": 1000, "leaking": 1000},
+		supply:           map[string]common.Fixed64{"balanced": 800, "leaking": 800},
+		pendingWithdraws: map[string]common.Fixed64{"balanced": 150, "leaking": 50},
+		pendingDeposits:  map[string]common.Fixed64{"balanced": 45},
+	}
+	now := time.Now()
+
+	report := reconcile(source, &config.SideNodeConfig{GenesisBlockAddress: "balanced"}, 10, now)
+	if report.Error != "" || report.Discrepancy != 5 || report.Flagged || report.ReportTime != now.Unix() {
+		t.Errorf("discrepancy within tolerance should not be flagged, %+v", report)
+	}
+	report = reconcile(source, &config.SideNodeConfig{GenesisBlockAddress: "leaking"}, 10, now)
+	if report.Discrepancy != 150 || !report.Flagged {
+		t.Errorf("discrepancy beyond tolerance should be flagged, %+v", report)
+	}
+	source.supply["leaking"] = 1100
+	report = reconcile(source, &config.SideNodeConfig{GenesisBlockAddress: "leaking"}, 10, now)
+	if report.Discrepancy != -150 || !report.Flagged {
+		t.Errorf("supply beyond the locked amount should be flagged, %+v", report)
+	}
+	report = reconcile(source, &config.SideNodeConfig{GenesisBlockAddress: "unreachable"}, 10, now)
+	if report.Error == "" || report.Flagged {
+		t.Errorf("side chain not reconciled should report the error, %+v", report)
+	}
+}
+
+func TestReconcileSideChains(t *testing.T) {
+	r, err := NewDecommissionRegistry(&decommissionStoreMock{decommissions: make(map[string]*store.Decommission)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	DecommissionSingleton = r
+	defer func() { DecommissionSingleton = nil }()
+	r.Decommission("retired", "sunset")
+	r.Retire("retired")
+
+	source := &reconciliationSourceMock{
+		locked: map[string]common.Fixed64{"balanced": 1000, "leaking": 1000},
+		supply: map[string]common.Fixed64{"balanced": 1000, "leaking": 500, "retired": 0},
+	}
+	db := &reconciliationStoreMock{reports: []*store.ReconciliationReport{
+		{GenesisBlockAddress: "balanced", ReportTime: time.Now().Add(-31 * 24 * time.Hour).Unix()},
+	}}
+	nodes := []*config.SideNodeConfig{
+		{Name: "balanced", GenesisBlockAddress: "balanced"},
+		{Name: "leaking", GenesisBlockAddress: "leaking"},
+		{Name: "retired", GenesisBlockAddress: "retired"},
+	}
+	reports := reconcileSideChains(nodes, &config.ReconciliationConfig{Tolerance: 10}, source, db)
+	if len(reports) != 2 || len(db.reports) != 2 {
+		t.Fatalf("expect reports of 2 side chains not retired and the old report pruned, got %d", len(db.reports))
+	}
+	if db.reports[0].Flagged || !db.reports[1].Flagged || db.reports[1].GenesisBlockAddress != "leaking" {
+		t.Errorf("reports stored wrongly, %+v %+v", db.reports[0], db.reports[1])
+	}
+}
@@ -2,6 +2,7 @@ package arbitrator
 
 import (
 	"github.com/elastos/Elastos.ELA.Arbiter/arbitration/base"
+	"github.com/elastos/Elastos.ELA.Arbiter/config"
 )
 
 type SideChain interface {
@@ -15,14 +16,18 @@ type SideChain interface {
 	GetWithdrawTransaction(txHash string) (*base.WithdrawTxInfo, error)
 	GetFailedDepositTransaction(txHash string) (bool, error)
 	CheckIllegalEvidence(evidence *base.SidechainIllegalDataInfo) (bool, error)
+	CreateAndBroadcastWithdrawProposal(txnHashes []string) error
 }
 
 type SideChainManager interface {
 	GetChain(key string) (SideChain, bool)
 	GetAllChains() []SideChain
 	AddChain(key string, chain SideChain)
+	RemoveChain(key string)
 	StartSideChainMining()
 	CheckAndRemoveWithdrawTransactionsFromDB() error
 	CheckAndRemoveReturnDepositTransactionsFromDB() error
 	OnReceivedRegisteredSideChain(info base.RegisterSidechainRpcInfo) error
+	ApproveRegisteredSideChain(txHash string, node *config.SideNodeConfig) error
+	RejectRegisteredSideChain(txHash, reason string) error
 }
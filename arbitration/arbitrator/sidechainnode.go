@@ -10,6 +10,7 @@ import (
 
 type SideChainNode interface {
 	GetCurrentHeight() (uint32, error)
+	GetAuxBlockHash() (common.Uint256, error)
 	GetBlockByHeight(height uint32) (*base.BlockInfo, error)
 	GetCurrentConfig() *config.SideNodeConfig
 	SendTransaction(txHash *common.Uint256) (rpc.Response, error)
@@ -17,5 +18,5 @@ type SideChainNode interface {
 	IsSendSmallCrxTx(tx string) bool
 
 	GetProcessedInvalidWithdrawTransactions(txs []string) ([]string, error)
-	SendInvalidWithdrawTransaction(signature []byte, hash string) (rpc.Response, error)
+	SendInvalidWithdrawTransaction(signature []byte, hash, reason string) (rpc.Response, error)
 }
@@ -9,9 +9,14 @@ import (
 	"github.com/elastos/Elastos.ELA.Arbiter/config"
 	"github.com/elastos/Elastos.ELA.Arbiter/log"
 	"github.com/elastos/Elastos.ELA.Arbiter/rpc"
+	"github.com/elastos/Elastos.ELA.Arbiter/store"
 	elatx "github.com/elastos/Elastos.ELA/core/transaction"
 )
 
+// SmallCrossTxRetention is the max time a small cross transfer is tracked
+// if it never appears in the side chain.
+const SmallCrossTxRetention = 7 * 24 * time.Hour
+
 func MonitorSmallCrossTransfer() {
 	for {
 		select {
@@ -61,6 +66,10 @@ func MonitorSmallCrossTransfer() {
 					log.Error("[Small-Transfer] get xaddress failed ", err.Error())
 					break
 				}
+				if IsSideChainDecommissioned(xAddr) {
+					log.Warn("[Small-Transfer] ignore transfer to decommissioned side chain", xAddr)
+					continue
+				}
 				side, ok := currentArbitrator.GetChain(xAddr)
 				if !ok {
 					log.Error("[Small-Transfer] unrecognized xAddr", xAddr)
@@ -76,19 +85,47 @@ func MonitorSmallCrossTransfer() {
 				}
 			}
 
+			// the transfers not recorded may have been pruned after they
+			// appeared in the side chain, they are not signed again
+			finished := finishSmallCrossTxs(currentArbitrator.GetSideChainManager(), txs)
+
 			sendingTxs := make(map[string][]*base.SmallCrossTransaction, 0)
 			for i := 0; i < len(txs); i++ {
+				if _, ok := finished[txs[i].TransactionHash]; ok {
+					continue
+				}
 				knownTxs, ok := sendingTxs[txs[i].GenesisBlockAddress]
 				if !ok {
 					knownTxs = make([]*base.SmallCrossTransaction, 0)
 					sendingTxs[txs[i].GenesisBlockAddress] = knownTxs
 				}
-				buf := new(bytes.Buffer)
-				txs[i].Transaction.Serialize(buf)
-				signature, err := currentArbitrator.Sign(buf.Bytes())
-				if err != nil {
-					log.Error("[Small-Transfer] currentArbiter sign error ", err.Error())
-					break
+				// the transfers recorded are not signed again, and the ones
+				// accepted by the side chain are not sent again
+				var signature []byte
+				known, err := store.SmallCrossTxsDbCache.GetSmallCrossTx(txs[i].TransactionHash,
+					txs[i].GenesisBlockAddress)
+				if err == nil {
+					if known.State == store.SmallCrossTxSent || known.State == store.SmallCrossTxFinished {
+						continue
+					}
+					signature = known.Signature
+				} else {
+					buf := new(bytes.Buffer)
+					txs[i].Transaction.Serialize(buf)
+					signature, err = currentArbitrator.Sign(buf.Bytes())
+					if err != nil {
+						log.Error("[Small-Transfer] currentArbiter sign error ", err.Error())
+						break
+					}
+					err = store.SmallCrossTxsDbCache.AddSmallCrossTx(&store.SmallCrossTx{
+						TransactionHash:     txs[i].TransactionHash,
+						GenesisBlockAddress: txs[i].GenesisBlockAddress,
+						TransactionData:     buf.Bytes(),
+						Signature:           signature,
+					})
+					if err != nil {
+						log.Error("[Small-Transfer] record small cross transfer error ", err.Error())
+					}
 				}
 				knownTxs = append(knownTxs, &base.SmallCrossTransaction{MainTx: txs[i].Transaction, Signature: signature})
 				sendingTxs[txs[i].GenesisBlockAddress] = knownTxs
@@ -105,3 +142,81 @@ func MonitorSmallCrossTransfer() {
 	}
 
 }
+
+// finishSmallCrossTxs records the small cross transfers not recorded yet but
+// already appeared in the side chain as finished, and returns their hashes.
+func finishSmallCrossTxs(sideChainManager SideChainManager, txs []*base.MainChainTransaction) map[string]struct{} {
+	txHashes := make(map[string][]string)
+	for _, tx := range txs {
+		if _, err := store.SmallCrossTxsDbCache.GetSmallCrossTx(tx.TransactionHash,
+			tx.GenesisBlockAddress); err == nil {
+			continue
+		}
+		txHashes[tx.GenesisBlockAddress] = append(txHashes[tx.GenesisBlockAddress], tx.TransactionHash)
+	}
+
+	finished := make(map[string]struct{})
+	for genesisAddress, existTxs := range existSmallCrossTxs(sideChainManager, txHashes) {
+		genesisAddresses := make([]string, 0, len(existTxs))
+		for _, hash := range existTxs {
+			genesisAddresses = append(genesisAddresses, genesisAddress)
+			finished[hash] = struct{}{}
+		}
+		if err := store.SmallCrossTxsDbCache.FinishSmallCrossTxs(existTxs, genesisAddresses); err != nil {
+			log.Error("[Small-Transfer] record finished small cross transfers error ", err.Error())
+		}
+	}
+	return finished
+}
+
+// existSmallCrossTxs returns the transfers appeared in the side chains by
+// genesis address.
+func existSmallCrossTxs(sideChainManager SideChainManager, txHashes map[string][]string) map[string][]string {
+	result := make(map[string][]string)
+	for genesisAddress, hashes := range txHashes {
+		sideChain, ok := sideChainManager.GetChain(genesisAddress)
+		if !ok {
+			continue
+		}
+		existTxs, err := sideChain.GetExistDepositTransactions(hashes)
+		if err != nil {
+			log.Warn("[Small-Transfer] get exist deposit transactions failed:", err)
+			continue
+		}
+		if len(existTxs) != 0 {
+			result[genesisAddress] = existTxs
+		}
+	}
+	return result
+}
+
+// pruneSmallCrossTxs marks the small cross transfers which have appeared in
+// the side chain as finished, and removes the ones tracked longer than
+// SmallCrossTxRetention.
+func pruneSmallCrossTxs(sideChainManager SideChainManager) error {
+	txs, err := store.SmallCrossTxsDbCache.GetSmallCrossTxs("", "")
+	if err != nil {
+		return err
+	}
+	txHashes := make(map[string][]string)
+	for _, tx := range txs {
+		if tx.State == store.SmallCrossTxFinished {
+			continue
+		}
+		txHashes[tx.GenesisBlockAddress] = append(txHashes[tx.GenesisBlockAddress], tx.TransactionHash)
+	}
+
+	for genesisAddress, existTxs := range existSmallCrossTxs(sideChainManager, txHashes) {
+		genesisAddresses := make([]string, 0, len(existTxs))
+		for range existTxs {
+			genesisAddresses = append(genesisAddresses, genesisAddress)
+		}
+		if err := store.SmallCrossTxsDbCache.FinishSmallCrossTxs(existTxs, genesisAddresses); err != nil {
+			return err
+		}
+		log.Info("[Small-Transfer] finished small cross transfers appeared in side chain:", len(existTxs))
+	}
+
+	return store.SmallCrossTxsDbCache.RemoveSmallCrossTxsBefore(
+		time.Now().Add(-SmallCrossTxRetention).Unix())
+}
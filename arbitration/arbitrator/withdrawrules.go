@@ -0,0 +1,163 @@
+package arbitrator
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/elastos/Elastos.ELA.Arbiter/arbitration/base"
+	"github.com/elastos/Elastos.ELA.Arbiter/config"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/contract"
+)
+
+// WithdrawRulesSingleton is applied to the withdraw transactions found on
+// side chains, proposed and signed, the rules of config.Parameters are
+// applied if it is nil.
+var WithdrawRulesSingleton *WithdrawRules
+
+// WithdrawRules checks the assets of withdraw transactions from side chains.
+// A withdraw breaking the rules of the main chain is invalid and returned to
+// the side chain, while the one breaking the policy of the arbiter is held
+// and checked again later since the policy may change.
+type WithdrawRules struct {
+	prefixes          map[contract.PrefixType]struct{}
+	minFee            common.Fixed64
+	maxTargetDataSize int
+}
+
+// InvalidWithdrawError is returned for the withdraw transactions breaking
+// the rules of the main chain, they are never proposed.
+type InvalidWithdrawError struct {
+	Reason string
+}
+
+func (e *InvalidWithdrawError) Error() string {
+	return e.Reason
+}
+
+// IsWithdrawInvalid returns true if the error is InvalidWithdrawError.
+func IsWithdrawInvalid(err error) bool {
+	_, ok := err.(*InvalidWithdrawError)
+	return ok
+}
+
+// NewWithdrawRules returns the withdraw rules of configuration, the address
+// prefix types are not limited if WithdrawRules is not set.
+func NewWithdrawRules(cfg *config.Configuration) *WithdrawRules {
+	r := &WithdrawRules{
+		minFee:            MinCrossChainTxFee,
+		maxTargetDataSize: config.MaxTargetDataSize,
+	}
+	if wc := cfg.WithdrawRules; wc != nil {
+		r.prefixes = make(map[contract.PrefixType]struct{})
+		for _, name := range wc.AddressPrefixes {
+			if prefix, ok := config.AddressPrefixes[name]; ok {
+				r.prefixes[prefix] = struct{}{}
+			}
+		}
+		if wc.MinFee > r.minFee {
+			r.minFee = wc.MinFee
+		}
+		if wc.MaxTargetDataSize > 0 && wc.MaxTargetDataSize < r.maxTargetDataSize {
+			r.maxTargetDataSize = wc.MaxTargetDataSize
+		}
+	}
+	return r
+}
+
+// checkAssetValid returns error with the reason if the withdraw asset breaks
+// the rules of the main chain.
+func checkAssetValid(w *base.WithdrawAsset) error {
+	programHash, err := common.Uint168FromAddress(w.TargetAddress)
+	if err != nil {
+		return fmt.Errorf("invalid address %q", w.TargetAddress)
+	}
+	if addr, err := programHash.ToAddress(); err != nil || addr != w.TargetAddress {
+		return fmt.Errorf("invalid address %q", w.TargetAddress)
+	}
+	if w.Amount == nil || w.CrossChainAmount == nil {
+		return errors.New("amount not set")
+	}
+	if *w.CrossChainAmount <= 0 {
+		return fmt.Errorf("cross chain amount %s should be greater than 0",
+			w.CrossChainAmount)
+	}
+	if fee := *w.Amount - *w.CrossChainAmount; fee < MinCrossChainTxFee {
+		return fmt.Errorf("fee %s is less than the minimum fee %s", fee, MinCrossChainTxFee)
+	}
+	if len(w.TargetData) > config.MaxTargetDataSize {
+		return fmt.Errorf("target data size %d is more than %d", len(w.TargetData),
+			config.MaxTargetDataSize)
+	}
+	return nil
+}
+
+// CheckAsset returns error with the reason if the withdraw asset breaks the
+// rules, sideNode is the side chain withdrawn from.
+func (r *WithdrawRules) CheckAsset(sideNode *config.SideNodeConfig, w *base.WithdrawAsset) error {
+	if err := checkAssetValid(w); err != nil {
+		return &InvalidWithdrawError{Reason: err.Error()}
+	}
+
+	programHash, _ := common.Uint168FromAddress(w.TargetAddress)
+	if _, ok := r.prefixes[contract.PrefixType(programHash[0])]; r.prefixes != nil && !ok {
+		return fmt.Errorf("address %s of prefix type 0x%02x is not allowed",
+			w.TargetAddress, programHash[0])
+	}
+	if fee := *w.Amount - *w.CrossChainAmount; fee < r.minFee {
+		return fmt.Errorf("fee %s is less than the minimum fee %s", fee, r.minFee)
+	}
+	if sideNode != nil && sideNode.MinWithdrawAmount > 0 && *w.CrossChainAmount < sideNode.MinWithdrawAmount {
+		return fmt.Errorf("cross chain amount %s is less than the minimum %s of %s",
+			w.CrossChainAmount, sideNode.MinWithdrawAmount, sideNode.Name)
+	}
+	if len(w.TargetData) > r.maxTargetDataSize {
+		return fmt.Errorf("target data size %d is more than %d", len(w.TargetData),
+			r.maxTargetDataSize)
+	}
+	return nil
+}
+
+// Check returns error with the reason if the withdraw transaction is
+// rejected, InvalidWithdrawError is returned if it breaks the rules of the
+// main chain.
+func (r *WithdrawRules) Check(sideNode *config.SideNodeConfig, tx *base.WithdrawTx) error {
+	if tx.WithdrawInfo == nil || len(tx.WithdrawInfo.WithdrawAssets) == 0 {
+		return &InvalidWithdrawError{Reason: "no withdraw asset"}
+	}
+	for i, w := range tx.WithdrawInfo.WithdrawAssets {
+		if err := r.CheckAsset(sideNode, w); IsWithdrawInvalid(err) {
+			return &InvalidWithdrawError{Reason: fmt.Sprintf("asset %d: %v", i, err)}
+		} else if err != nil {
+			return fmt.Errorf("asset %d: %v", i, err)
+		}
+	}
+	return nil
+}
+
+// CheckWithdrawTx applies the withdraw rules and the frozen addresses to the
+// withdraw transaction from the side chain of genesisAddress,
+// WithdrawBlockedError is returned if it is held by a frozen address.
+func CheckWithdrawTx(genesisAddress string, tx *base.WithdrawTx) error {
+	rules := WithdrawRulesSingleton
+	if rules == nil {
+		rules = NewWithdrawRules(config.Parameters.Configuration)
+	}
+	registry := FrozenRegistrySingleton
+	if registry == nil {
+		registry, _ = NewFrozenRegistry(config.Parameters.FrozenAddresses, nil)
+	}
+	var sideNode *config.SideNodeConfig
+	for _, node := range config.SideNodes() {
+		if node.GenesisBlockAddress == genesisAddress {
+			sideNode = node
+			break
+		}
+	}
+	if err := rules.Check(sideNode, tx); err != nil {
+		return err
+	}
+	return registry.CheckWithdraw(genesisAddress, tx, time.Now())
+}
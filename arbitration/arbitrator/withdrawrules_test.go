@@ -0,0 +1,107 @@
+package arbitrator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA.Arbiter/arbitration/base"
+	"github.com/elastos/Elastos.ELA.Arbiter/config"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/contract"
+)
+
+func testAddress(prefix contract.PrefixType, b byte) string {
+	programHash := common.Uint168{byte(prefix), b}
+	addr, _ := programHash.ToAddress()
+	return addr
+}
+
+func testAsset(addr string, amount, crossChainAmount common.Fixed64) *base.WithdrawAsset {
+	return &base.WithdrawAsset{
+		TargetAddress:    addr,
+		Amount:           &amount,
+		CrossChainAmount: &crossChainAmount,
+	}
+}
+
+func TestWithdrawRules(t *testing.T) {
+	standard := testAddress(contract.PrefixStandard, 1)
+	rules := NewWithdrawRules(&config.Configuration{
+		WithdrawRules: &config.WithdrawRulesConfig{
+			AddressPrefixes:   []string{"standard", "multisig"},
+			MinFee:            10000,
+			MaxTargetDataSize: 4,
+		},
+	})
+	sideNode := &config.SideNodeConfig{
+		Name:              "ESC",
+		MinWithdrawAmount: 100000,
+	}
+
+	bigData := testAsset(standard, 210000, 200000)
+	bigData.TargetData = []byte("12345")
+	tooBigData := testAsset(standard, 210000, 200000)
+	tooBigData.TargetData = make([]byte, config.MaxTargetDataSize+1)
+	cases := []struct {
+		asset   *base.WithdrawAsset
+		reason  string
+		invalid bool
+	}{
+		{testAsset(standard, 210000, 200000), "", false},
+		{testAsset(testAddress(contract.PrefixMultiSig, 1), 210000, 200000), "", false},
+		{testAsset("EXXX", 210000, 200000), "invalid address", true},
+		{testAsset(testAddress(contract.PrefixDPoSV2, 1), 210000, 200000), "is not allowed", false},
+		{testAsset(standard, 10000, 0), "should be greater than 0", true},
+		{testAsset(standard, 205000, 200000), "less than the minimum fee", true},
+		{testAsset(standard, 2010000, 2000000), "", false},
+		{testAsset(standard, 60000, 50000), "less than the minimum", false},
+		{bigData, "target data size", false},
+		{tooBigData, "target data size", true},
+	}
+	for i, c := range cases {
+		err := rules.CheckAsset(sideNode, c.asset)
+		if c.reason == "" && err != nil || c.reason != "" && (err == nil ||
+			!strings.Contains(err.Error(), c.reason)) {
+			t.Errorf("case %d: expect %q, got %v", i, c.reason, err)
+		}
+		if IsWithdrawInvalid(err) != c.invalid {
+			t.Errorf("case %d: expect invalid %v, got %v", i, c.invalid, err)
+		}
+	}
+	policyFee := NewWithdrawRules(&config.Configuration{
+		WithdrawRules: &config.WithdrawRulesConfig{MinFee: 20000, MaxTargetDataSize: 4},
+	})
+	if err := policyFee.CheckAsset(nil, testAsset(standard, 215000, 200000)); err == nil || IsWithdrawInvalid(err) {
+		t.Errorf("fee under MinFee of the policy should be held, got %v", err)
+	}
+
+	tx := &base.WithdrawTx{
+		Txid: &common.Uint256{1},
+		WithdrawInfo: &base.WithdrawInfo{WithdrawAssets: []*base.WithdrawAsset{
+			testAsset(standard, 210000, 200000),
+			testAsset(standard, 60000, 50000),
+		}},
+	}
+	if err := rules.Check(sideNode, tx); err == nil || IsWithdrawInvalid(err) ||
+		!strings.HasPrefix(err.Error(), "asset 1:") {
+		t.Errorf("withdraw breaking the policy should be held, got %v", err)
+	}
+	tx.WithdrawInfo.WithdrawAssets[1] = testAsset(standard, 55000, 50000)
+	if err := rules.Check(sideNode, tx); !IsWithdrawInvalid(err) || !strings.HasPrefix(err.Error(), "asset 1:") {
+		t.Errorf("withdraw breaking the main chain rules should be invalid, got %v", err)
+	}
+	tx.WithdrawInfo.WithdrawAssets = tx.WithdrawInfo.WithdrawAssets[:1]
+	if err := rules.Check(sideNode, tx); err != nil {
+		t.Errorf("valid withdraw rejected: %v", err)
+	}
+	tx.WithdrawInfo.WithdrawAssets = nil
+	if err := rules.Check(sideNode, tx); !IsWithdrawInvalid(err) {
+		t.Errorf("withdraw without assets should be invalid, got %v", err)
+	}
+
+	legacy := NewWithdrawRules(&config.Configuration{})
+	if err := legacy.CheckAsset(nil, testAsset(testAddress(contract.PrefixDPoSV2, 1), 20000, 10000)); err != nil {
+		t.Errorf("address prefix types should not be limited without WithdrawRules, got %v", err)
+	}
+}
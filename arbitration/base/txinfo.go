@@ -30,6 +30,10 @@ type NFTDestroyFromSideChainTx struct {
 type WithdrawTx struct {
 	Txid         *common.Uint256
 	WithdrawInfo *WithdrawInfo
+
+	// RejectReason is the reason the withdraw is rejected by the withdraw
+	// rules, it is stored aside and not serialized.
+	RejectReason string
 }
 
 type DepositInfo struct {
@@ -64,6 +68,7 @@ type SideChainTransaction struct {
 	TransactionHash string
 	Transaction     []byte
 	BlockHeight     uint32
+	RejectReason    string
 }
 
 type NFTDestroyTransaction struct {
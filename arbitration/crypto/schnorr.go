@@ -107,6 +107,25 @@ func GetEMulPrivateKey(privateKeys *big.Int, e *big.Int) *big.Int {
 	return new(big.Int).Mul(e, privateKeys)
 }
 
+// AggregatePoints returns the sum of the points.
+func AggregatePoints(xs []*big.Int, ys []*big.Int) (x *big.Int, y *big.Int) {
+	x, y = new(big.Int), new(big.Int)
+	for i := range xs {
+		x, y = Curve.Add(x, y, xs[i], ys[i])
+	}
+	return
+}
+
+// GetPartialS returns the partial signature s = k + e*x mod N of a signer
+// with the nonce k0 and the private key x, e is computed from the aggregated
+// nonce point R, the aggregated public key P and the message.
+func GetPartialS(privateKey, k0, Rx, Ry, Px, Py *big.Int, message []byte) *big.Int {
+	e := getE(Px, Py, IntToByte(Rx), message)
+	s := GetK(Ry, new(big.Int).Set(k0))
+	s.Add(s, new(big.Int).Mul(e, privateKey))
+	return s.Mod(s, N)
+}
+
 func GetK(Ry, k0 *big.Int) *big.Int {
 	if big.Jacobi(Ry, P) == 1 {
 		return k0
@@ -33,11 +33,17 @@ const (
 	IllegalContent          DistributeContentType = 0x01
 	SchnorrMultisigContent2 DistributeContentType = 0x02
 	SchnorrMultisigContent3 DistributeContentType = 0x03
+	// the schnorr proposals exchanging partial signatures instead of nonces,
+	// sent since SchnorrPartialSignatureHeight
+	SchnorrPartialContent2 DistributeContentType = 0x04
+	SchnorrPartialContent3 DistributeContentType = 0x05
 
 	AnswerMultisigContent         DistributeContentType = 0x10
 	AnswerIllegalContent          DistributeContentType = 0x11
 	AnswerSchnorrMultisigContent2 DistributeContentType = 0x12
 	AnswerSchnorrMultisigContent3 DistributeContentType = 0x13
+	AnswerSchnorrPartialContent2  DistributeContentType = 0x14
+	AnswerSchnorrPartialContent3  DistributeContentType = 0x15
 )
 
 const MaxRedeemScriptDataSize = 10000
@@ -295,19 +301,19 @@ func (item *DistributedItem) SerializeUnsigned(w io.Writer) error {
 		if err := common.WriteVarBytes(w, item.redeemScript); err != nil {
 			return errors.New("redeemScript serialization failed.")
 		}
-	case SchnorrMultisigContent2:
+	case SchnorrMultisigContent2, SchnorrPartialContent2:
 		if err := item.SchnorrRequestRProposalContent.Serialize(w, false); err != nil {
 			return err
 		}
-	case AnswerSchnorrMultisigContent2:
+	case AnswerSchnorrMultisigContent2, AnswerSchnorrPartialContent2:
 		if err := item.SchnorrRequestRProposalContent.Serialize(w, true); err != nil {
 			return err
 		}
-	case SchnorrMultisigContent3:
+	case SchnorrMultisigContent3, SchnorrPartialContent3:
 		if err := item.SchnorrRequestSProposalContent.Serialize(w, false); err != nil {
 			return err
 		}
-	case AnswerSchnorrMultisigContent3:
+	case AnswerSchnorrMultisigContent3, AnswerSchnorrPartialContent3:
 		if err := item.SchnorrRequestSProposalContent.Serialize(w, true); err != nil {
 			return err
 		}
@@ -348,22 +354,22 @@ func (item *DistributedItem) Serialize(w io.Writer) error {
 		if err := common.WriteVarBytes(w, item.signedData); err != nil {
 			return errors.New("signedData serialization failed.")
 		}
-	case SchnorrMultisigContent2:
+	case SchnorrMultisigContent2, SchnorrPartialContent2:
 		if err := item.SchnorrRequestRProposalContent.Serialize(w, false); err != nil {
 			return err
 		}
-	case AnswerSchnorrMultisigContent2:
+	case AnswerSchnorrMultisigContent2, AnswerSchnorrPartialContent2:
 		if err := item.SchnorrRequestRProposalContent.Serialize(w, true); err != nil {
 			return err
 		}
 		if err := common.WriteVarBytes(w, item.signedData); err != nil {
 			return errors.New("signedData serialization failed.")
 		}
-	case SchnorrMultisigContent3:
+	case SchnorrMultisigContent3, SchnorrPartialContent3:
 		if err := item.SchnorrRequestSProposalContent.Serialize(w, false); err != nil {
 			return err
 		}
-	case AnswerSchnorrMultisigContent3:
+	case AnswerSchnorrMultisigContent3, AnswerSchnorrPartialContent3:
 		if err := item.SchnorrRequestSProposalContent.Serialize(w, true); err != nil {
 			return err
 		}
@@ -437,11 +443,11 @@ func (item *DistributedItem) Deserialize(r io.Reader) error {
 		}
 		item.signedData = signedData
 
-	case SchnorrMultisigContent2:
+	case SchnorrMultisigContent2, SchnorrPartialContent2:
 		if err = item.SchnorrRequestRProposalContent.Deserialize(r, false); err != nil {
 			return errors.New("SchnorrRequestRProposalContent deserialization failed." + err.Error())
 		}
-	case AnswerSchnorrMultisigContent2:
+	case AnswerSchnorrMultisigContent2, AnswerSchnorrPartialContent2:
 		if err = item.SchnorrRequestRProposalContent.Deserialize(r, true); err != nil {
 			return errors.New("Answer SchnorrRequestRProposalContent deserialization failed." + err.Error())
 		}
@@ -450,11 +456,13 @@ func (item *DistributedItem) Deserialize(r io.Reader) error {
 			return errors.New("signedData deserialization failed.")
 		}
 		item.signedData = signedData
-	case SchnorrMultisigContent3:
+	case SchnorrMultisigContent3, SchnorrPartialContent3:
+		item.SchnorrRequestSProposalContent.Partial = item.Type == SchnorrPartialContent3
 		if err = item.SchnorrRequestSProposalContent.Deserialize(r, false); err != nil {
 			return errors.New("Answer SchnorrRequestSProposalContent deserialization failed." + err.Error())
 		}
-	case AnswerSchnorrMultisigContent3:
+	case AnswerSchnorrMultisigContent3, AnswerSchnorrPartialContent3:
+		item.SchnorrRequestSProposalContent.Partial = item.Type == AnswerSchnorrPartialContent3
 		if err = item.SchnorrRequestSProposalContent.Deserialize(r, true); err != nil {
 			return errors.New("Answer SchnorrRequestSProposalContent deserialization failed." + err.Error())
 		}
@@ -3,9 +3,15 @@ package cs
 import (
 	"bytes"
 	"errors"
+	"math/big"
+
 	"github.com/elastos/Elastos.ELA.Arbiter/arbitration/arbitrator"
+	crypto2 "github.com/elastos/Elastos.ELA.Arbiter/arbitration/crypto"
+	"github.com/elastos/Elastos.ELA.Arbiter/log"
 	"github.com/elastos/Elastos.ELA/common"
 	"github.com/elastos/Elastos.ELA/core/contract"
+	it "github.com/elastos/Elastos.ELA/core/types/interfaces"
+	"github.com/elastos/Elastos.ELA/crypto"
 	"github.com/elastos/Elastos.ELA/dpos/p2p/peer"
 )
 
@@ -55,9 +61,9 @@ func (client *DistributedNodeClient) OnReceivedProposal(id peer.PID, content []b
 	switch transactionItem.Type {
 	case MultisigContent:
 		return client.onReceivedProposal(id, transactionItem)
-	case SchnorrMultisigContent2:
+	case SchnorrMultisigContent2, SchnorrPartialContent2:
 		return client.onReceivedSchnorrProposal2(id, transactionItem)
-	case SchnorrMultisigContent3:
+	case SchnorrMultisigContent3, SchnorrPartialContent3:
 		return client.onReceivedSchnorrProposal3(id, transactionItem)
 	}
 	return nil
@@ -68,9 +74,19 @@ func (client *DistributedNodeClient) onReceivedProposal(id peer.PID, transaction
 		return err
 	}
 
+	content, ok := transactionItem.ItemContent.(*TxDistributedContent)
+	if ok {
+		if err := client.checkPolicy(content.Tx); err != nil {
+			return err
+		}
+	}
+
 	if err := client.SignProposal(transactionItem); err != nil {
 		return err
 	}
+	if ok {
+		client.recordPolicy(content.Tx)
+	}
 
 	if err := client.Feedback(id, transactionItem); err != nil {
 		return err
@@ -81,7 +97,16 @@ func (client *DistributedNodeClient) onReceivedProposal(id peer.PID, transaction
 
 func (client *DistributedNodeClient) onReceivedSchnorrProposal2(id peer.PID, transactionItem *DistributedItem) error {
 	currentAccount := arbitrator.ArbitratorGroupSingleton.GetCurrentArbitrator()
-	k0, rx, ry, px, py, err := currentAccount.GetSchnorrR()
+	var k0, rx, ry, px, py *big.Int
+	var err error
+	if transactionItem.Type == SchnorrPartialContent2 {
+		rx, ry, px, py, err = currentAccount.GetSchnorrR(transactionItem.SchnorrRequestRProposalContent.Hash())
+	} else {
+		if isSchnorrPartialSignature() {
+			return errors.New("legacy schnorr proposal after SchnorrPartialSignatureHeight")
+		}
+		k0, rx, ry, px, py, err = currentAccount.GetSchnorrLegacyR()
+	}
 	if err != nil {
 		return err
 	}
@@ -92,6 +117,8 @@ func (client *DistributedNodeClient) onReceivedSchnorrProposal2(id peer.PID, tra
 		Px: px,
 		Py: py,
 	}
+	// the R proposal carries only a nonce and moves no coin, the policy is
+	// consulted by the S proposal with the transaction.
 	if err := client.SignSchnorrProposal2(transactionItem); err != nil {
 		return err
 	}
@@ -104,6 +131,11 @@ func (client *DistributedNodeClient) onReceivedSchnorrProposal2(id peer.PID, tra
 }
 
 func (client *DistributedNodeClient) onReceivedSchnorrProposal3(id peer.PID, transactionItem *DistributedItem) error {
+	content := transactionItem.SchnorrRequestSProposalContent
+	if !content.Partial && isSchnorrPartialSignature() {
+		return errors.New("legacy schnorr proposal after SchnorrPartialSignatureHeight")
+	}
+
 	// check if I am in public keys.
 	currentAccount := arbitrator.ArbitratorGroupSingleton.GetCurrentArbitrator()
 	myself, err := currentAccount.GetPublicKey().EncodePoint(true)
@@ -129,14 +161,41 @@ func (client *DistributedNodeClient) onReceivedSchnorrProposal3(id peer.PID, tra
 		}
 		client.CheckedTransactions[hash] = struct{}{}
 	}
+	if err := client.checkPolicy(transactionItem.SchnorrRequestSProposalContent.Tx); err != nil {
+		return err
+	}
 
-	s := currentAccount.GetSchnorrS(transactionItem.SchnorrRequestSProposalContent.E)
+	var s *big.Int
+	if content.Partial {
+		// the signer computes e from the aggregated points itself, E is
+		// checked only to refuse an inconsistent proposal early.
+		Px, Py, err := aggregatePublicKeys(content.Publickeys)
+		if err != nil {
+			return err
+		}
+		message := content.Tx.Hash()
+		e := crypto2.GetE([]*big.Int{content.Rx}, []*big.Int{content.Ry}, []*big.Int{Px}, []*big.Int{Py}, message[:])
+		if e.Cmp(content.E) != 0 {
+			return errors.New("invalid e of the schnorr proposal 3")
+		}
+		s, err = currentAccount.GetSchnorrS(content.NonceHash, content.Tx, content.Rx, content.Ry, Px, Py)
+		if err != nil {
+			return err
+		}
+		transactionItem.Type = AnswerSchnorrPartialContent3
+	} else {
+		s, err = currentAccount.GetSchnorrLegacyS(content.E, content.Tx)
+		if err != nil {
+			return err
+		}
+		transactionItem.Type = AnswerSchnorrMultisigContent3
+	}
 	transactionItem.SchnorrRequestSProposalContent.S = s
-	transactionItem.Type = AnswerSchnorrMultisigContent3
 
 	if err := client.SignSchnorrProposal3(transactionItem); err != nil {
 		return err
 	}
+	client.recordPolicy(transactionItem.SchnorrRequestSProposalContent.Tx)
 
 	if err := client.Feedback(id, transactionItem); err != nil {
 		return err
@@ -145,6 +204,24 @@ func (client *DistributedNodeClient) onReceivedSchnorrProposal3(id peer.PID, tra
 	return nil
 }
 
+// checkPolicy consults the proposal policy before signing the transaction.
+func (client *DistributedNodeClient) checkPolicy(txn it.Transaction) error {
+	if ProposalPolicySingleton == nil {
+		return nil
+	}
+	return ProposalPolicySingleton.Check(txn)
+}
+
+// recordPolicy counts the signed transaction in the daily withdraw limits.
+func (client *DistributedNodeClient) recordPolicy(txn it.Transaction) {
+	if ProposalPolicySingleton == nil {
+		return
+	}
+	if err := ProposalPolicySingleton.Record(txn); err != nil {
+		log.Error("[ProposalPolicy] record signed proposal failed:", err)
+	}
+}
+
 func (client *DistributedNodeClient) Feedback(id peer.PID, item *DistributedItem) error {
 	ar := arbitrator.ArbitratorGroupSingleton.GetCurrentArbitrator()
 	item.TargetArbitratorPublicKey = ar.GetPublicKey()
@@ -162,6 +239,12 @@ func (client *DistributedNodeClient) Feedback(id peer.PID, item *DistributedItem
 	case SchnorrMultisigContent3:
 		item.Type = AnswerSchnorrMultisigContent3
 
+	case SchnorrPartialContent2:
+		item.Type = AnswerSchnorrPartialContent2
+
+	case SchnorrPartialContent3:
+		item.Type = AnswerSchnorrPartialContent3
+
 	}
 
 	pkBuf, err := item.TargetArbitratorPublicKey.EncodePoint(true)
@@ -184,3 +267,20 @@ func (client *DistributedNodeClient) Feedback(id peer.PID, item *DistributedItem
 		Content: messageReader.Bytes(),
 	})
 }
+
+// aggregatePublicKeys returns the sum of the public keys of the schnorr
+// signers.
+func aggregatePublicKeys(publicKeys [][]byte) (*big.Int, *big.Int, error) {
+	xs := make([]*big.Int, 0, len(publicKeys))
+	ys := make([]*big.Int, 0, len(publicKeys))
+	for _, pk := range publicKeys {
+		publicKey, err := crypto.DecodePoint(pk)
+		if err != nil {
+			return nil, nil, err
+		}
+		xs = append(xs, publicKey.X)
+		ys = append(ys, publicKey.Y)
+	}
+	x, y := crypto2.AggregatePoints(xs, ys)
+	return x, y, nil
+}
@@ -44,6 +44,8 @@ type DistributedNodeServer struct {
 	schnorrWithdrawContentsTransaction     map[common.Uint256]it.Transaction // key: nonce hash
 	schnorrWithdrawRequestRContentsSigners map[common.Uint256]map[string]KRP
 	schnorrWithdrawRequestSContentsSigners map[common.Uint256]map[string]*big.Int
+	// key: nonce hash, true if the signers return partial signatures
+	schnorrWithdrawPartialSessions map[common.Uint256]bool
 
 	// no need to reset, just record unsigned count
 	UnsignedSigners map[string]uint64
@@ -55,6 +57,7 @@ func (dns *DistributedNodeServer) Reset() {
 	dns.schnorrWithdrawContentsTransaction = make(map[common.Uint256]it.Transaction)
 	dns.schnorrWithdrawRequestRContentsSigners = make(map[common.Uint256]map[string]KRP)
 	dns.schnorrWithdrawRequestSContentsSigners = make(map[common.Uint256]map[string]*big.Int)
+	dns.schnorrWithdrawPartialSessions = make(map[common.Uint256]bool)
 }
 
 func (dns *DistributedNodeServer) tryInit() {
@@ -79,6 +82,9 @@ func (dns *DistributedNodeServer) tryInit() {
 	if dns.schnorrWithdrawRequestSContentsSigners == nil {
 		dns.schnorrWithdrawRequestSContentsSigners = make(map[common.Uint256]map[string]*big.Int)
 	}
+	if dns.schnorrWithdrawPartialSessions == nil {
+		dns.schnorrWithdrawPartialSessions = make(map[common.Uint256]bool)
+	}
 	if dns.UnsignedSigners == nil {
 		dns.UnsignedSigners = make(map[string]uint64)
 	}
@@ -148,11 +154,17 @@ func (dns *DistributedNodeServer) sendSchnorrItemMsgToSelf(nonceHash common.Uint
 	}()
 }
 
-func (dns *DistributedNodeServer) recordKRPOfMyself(nonceHash common.Uint256) error {
+func (dns *DistributedNodeServer) recordKRPOfMyself(nonceHash common.Uint256, partial bool) error {
 	// record KRP of myself
 	currentAccount := arbitrator.ArbitratorGroupSingleton.GetCurrentArbitrator()
 	strPK := arbitrator.ArbitratorGroupSingleton.GetCurrentArbitratorPublicKey()
-	k0, rx, ry, px, py, err := currentAccount.GetSchnorrR()
+	var k0, rx, ry, px, py *big.Int
+	var err error
+	if partial {
+		rx, ry, px, py, err = currentAccount.GetSchnorrR(nonceHash)
+	} else {
+		k0, rx, ry, px, py, err = currentAccount.GetSchnorrLegacyR()
+	}
 	if err != nil {
 		return err
 	}
@@ -175,17 +187,24 @@ func (dns *DistributedNodeServer) BroadcastSchnorrWithdrawProposal2(txn it.Trans
 		txType = ReturnDepositTransaction
 	}
 
+	// the legacy proposals are sent until all arbiters are upgraded to
+	// return partial signatures
+	partial := isSchnorrPartialSignature()
+	cType := SchnorrMultisigContent2
+	if partial {
+		cType = SchnorrPartialContent2
+	}
 	content := SchnorrWithdrawRequestRProposalContent{
 		Nonce: txn.Hash().Bytes()}
 	proposal, err := dns.generateDistributedSchnorrProposal2(
-		txn, txType, SchnorrMultisigContent2,
+		txn, txType, cType,
 		content)
 	if err != nil {
 		return err
 	}
 
 	nonceHash := content.Hash()
-	if err := dns.recordKRPOfMyself(nonceHash); err != nil {
+	if err := dns.recordKRPOfMyself(nonceHash, partial); err != nil {
 		return err
 	}
 	dns.sendToArbitrator(proposal)
@@ -195,14 +214,14 @@ func (dns *DistributedNodeServer) BroadcastSchnorrWithdrawProposal2(txn it.Trans
 }
 
 func (dns *DistributedNodeServer) BroadcastSchnorrWithdrawProposal3(
-	nonceHash common.Uint256, txn it.Transaction, pks [][]byte, e *big.Int) error {
+	nonceHash common.Uint256, txn it.Transaction, pks [][]byte, e, rx, ry *big.Int) error {
 	dns.mux.Lock()
 	defer dns.mux.Unlock()
-	return dns.broadcastSchnorrWithdrawProposal3(nonceHash, txn, pks, e)
+	return dns.broadcastSchnorrWithdrawProposal3(nonceHash, txn, pks, e, rx, ry)
 }
 
 func (dns *DistributedNodeServer) broadcastSchnorrWithdrawProposal3(
-	nonce common.Uint256, txn it.Transaction, pks [][]byte, e *big.Int) error {
+	nonce common.Uint256, txn it.Transaction, pks [][]byte, e, rx, ry *big.Int) error {
 	var txType TransactionType
 	switch txn.TxType() {
 	case elacommon.WithdrawFromSideChain:
@@ -211,13 +230,21 @@ func (dns *DistributedNodeServer) broadcastSchnorrWithdrawProposal3(
 		txType = ReturnDepositTransaction
 	}
 
+	partial := dns.schnorrWithdrawPartialSessions[nonce]
+	cType := SchnorrMultisigContent3
+	if partial {
+		cType = SchnorrPartialContent3
+	}
 	proposal, err := dns.generateDistributedSchnorrProposal3(
-		txn, txType, SchnorrMultisigContent3,
+		txn, txType, cType,
 		SchnorrWithdrawRequestSProposalContent{
 			NonceHash:  nonce,
 			Tx:         txn,
 			Publickeys: pks,
-			E:          e})
+			E:          e,
+			Rx:         rx,
+			Ry:         ry,
+			Partial:    partial})
 	if err != nil {
 		return err
 	}
@@ -297,6 +324,7 @@ func (dns *DistributedNodeServer) generateDistributedSchnorrProposal2(
 	}
 	dns.schnorrWithdrawContentsTransaction[content.Hash()] = txn
 	dns.schnorrWithdrawRequestRContentsSigners[content.Hash()] = make(map[string]KRP)
+	dns.schnorrWithdrawPartialSessions[content.Hash()] = cType == SchnorrPartialContent2
 	return buf.Bytes(), nil
 }
 
@@ -405,9 +433,9 @@ func (dns *DistributedNodeServer) ReceiveProposalFeedback(content []byte) error
 	case AnswerMultisigContent:
 		return dns.receiveWithdrawProposalFeedback(transactionItem)
 	case AnswerIllegalContent:
-	case AnswerSchnorrMultisigContent2:
+	case AnswerSchnorrMultisigContent2, AnswerSchnorrPartialContent2:
 		return dns.receiveSchnorrWithdrawProposal2Feedback(transactionItem)
-	case AnswerSchnorrMultisigContent3:
+	case AnswerSchnorrMultisigContent3, AnswerSchnorrPartialContent3:
 		return dns.receiveSchnorrWithdrawProposal3Feedback(transactionItem)
 	}
 
@@ -490,6 +518,15 @@ func (dns *DistributedNodeServer) receiveSchnorrWithdrawProposal2Feedback(transa
 		dns.mux.Unlock()
 		return errors.New("can not find RequestR signer")
 	}
+	partial := transactionItem.Type == AnswerSchnorrPartialContent2
+	if partial != dns.schnorrWithdrawPartialSessions[hash] {
+		dns.mux.Unlock()
+		return errors.New("schnorr feedback type mismatch with the proposal")
+	}
+	if !partial && transactionItem.SchnorrRequestRProposalContent.R.K0 == nil {
+		dns.mux.Unlock()
+		return errors.New("k0 not found in legacy schnorr feedback")
+	}
 	pkBuf, err := transactionItem.TargetArbitratorPublicKey.EncodePoint(true)
 	if err != nil {
 		dns.mux.Unlock()
@@ -614,13 +651,24 @@ func (dns *DistributedNodeServer) ReceiveSendSchnorrWithdrawProposal3(nonceHash
 		// get E
 		message := newTx.Hash()
 		e := crypto2.GetE(rxs, rys, pxs, pys, message[:])
-		if err := dns.broadcastSchnorrWithdrawProposal3(nonceHash, newTx, pks, e); err != nil {
+		Rx, Ry := crypto2.AggregatePoints(rxs, rys)
+		Px, Py := crypto2.AggregatePoints(pxs, pys)
+		if err := dns.broadcastSchnorrWithdrawProposal3(nonceHash, newTx, pks, e, Rx, Ry); err != nil {
 			return errors.New("failed to BroadcastSchnorrWithdrawProposal2, err:" + err.Error())
 		}
 
 		// record signature of myself
 		currentAccount := arbitrator.ArbitratorGroupSingleton.GetCurrentArbitrator()
-		dns.schnorrWithdrawRequestSContentsSigners[newTx.Hash()][myPK] = currentAccount.GetSchnorrS(e)
+		var s *big.Int
+		if dns.schnorrWithdrawPartialSessions[nonceHash] {
+			s, err = currentAccount.GetSchnorrS(nonceHash, newTx, Rx, Ry, Px, Py)
+		} else {
+			s, err = currentAccount.GetSchnorrLegacyS(e, newTx)
+		}
+		if err != nil {
+			return errors.New("failed to get schnorr S, err:" + err.Error())
+		}
+		dns.schnorrWithdrawRequestSContentsSigners[newTx.Hash()][myPK] = s
 	} else {
 		log.Errorf("[ReceiveSendSchnorrWithdrawProposal3] not enought "+
 			"signers for transaction %s, need %d, current %d",
@@ -689,6 +737,11 @@ func (dns *DistributedNodeServer) receiveSchnorrWithdrawProposal3Feedback(transa
 		log.Warn("arbiter already recorded the schnorr signer")
 		return nil
 	}
+	nonceHash := transactionItem.SchnorrRequestSProposalContent.NonceHash
+	if transactionItem.SchnorrRequestSProposalContent.Partial != dns.schnorrWithdrawPartialSessions[nonceHash] {
+		dns.mux.Unlock()
+		return errors.New("schnorr feedback type mismatch with the proposal")
+	}
 
 	if count, ok := dns.UnsignedSigners[strPK]; !ok || count < 1 {
 		return errors.New("not found in UnsignedSigners")
@@ -702,7 +755,6 @@ func (dns *DistributedNodeServer) receiveSchnorrWithdrawProposal3Feedback(transa
 		// aggregate signatures
 		Px, Py := new(big.Int), new(big.Int)
 		Rx, Ry := new(big.Int), new(big.Int)
-		nonceHash := transactionItem.SchnorrRequestSProposalContent.NonceHash
 		for pk, _ := range dns.schnorrWithdrawRequestSContentsSigners[hash] {
 			if r, ok := dns.schnorrWithdrawRequestRContentsSigners[nonceHash][pk]; ok {
 				Rx, Ry = crypto2.Curve.Add(Rx, Ry, r.Rx, r.Ry)
@@ -710,7 +762,10 @@ func (dns *DistributedNodeServer) receiveSchnorrWithdrawProposal3Feedback(transa
 			}
 		}
 
+		// the partial signatures carry the nonces, which never leave the
+		// signers, the legacy signatures are added by the nonces k0
 		s := new(big.Int).SetInt64(0)
+		partial := dns.schnorrWithdrawPartialSessions[nonceHash]
 
 		for pk, signature := range dns.schnorrWithdrawRequestSContentsSigners[hash] {
 			r, ok := dns.schnorrWithdrawRequestRContentsSigners[nonceHash][pk]
@@ -718,11 +773,13 @@ func (dns *DistributedNodeServer) receiveSchnorrWithdrawProposal3Feedback(transa
 				dns.mux.Unlock()
 				return errors.New("invalid schnorrWithdrawRequestSContentsSigners, not found signature of " + pk)
 			}
-
-			k := crypto2.GetK(Ry, r.K0)
-			k.Add(k, signature)
-			s.Add(s, k)
+			if !partial {
+				k := crypto2.GetK(Ry, new(big.Int).Set(r.K0))
+				s.Add(s, k)
+			}
+			s.Add(s, signature)
 		}
+		s.Mod(s, crypto2.N)
 		dns.mux.Unlock()
 
 		signature := crypto2.GetS(Rx, s)
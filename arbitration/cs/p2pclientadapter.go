@@ -3,15 +3,18 @@ package cs
 import (
 	"encoding/hex"
 	"errors"
+	"fmt"
 	"math/rand"
 	"net"
 	"os"
 	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/elastos/Elastos.ELA.Arbiter/arbitration/arbitrator"
 	"github.com/elastos/Elastos.ELA.Arbiter/arbitration/base"
 	"github.com/elastos/Elastos.ELA.Arbiter/config"
+	"github.com/elastos/Elastos.ELA.Arbiter/events"
 	"github.com/elastos/Elastos.ELA.Arbiter/log"
 	"github.com/elastos/Elastos.ELA.Arbiter/rpc"
 	"github.com/elastos/Elastos.ELA.Arbiter/store"
@@ -29,6 +32,8 @@ const (
 	//len of message need to less than 12
 	DistributeItemCommand = "disitem"
 	SendSchnorrItemommand = "senditem"
+
+	peersQuorumCheckInterval = time.Minute
 )
 
 type messageItem struct {
@@ -62,6 +67,8 @@ func (n *arbitratorsNetwork) Start() {
 	}
 	n.UpdatePeers(peers)
 
+	go n.monitorPeersQuorum()
+
 	go func() {
 	out:
 		for {
@@ -103,6 +110,43 @@ func (n *arbitratorsNetwork) UpdatePeers(connectedPeers []peer.PID) {
 	n.p2pServer.ConnectPeers(n.connectedPeers, nil)
 }
 
+func (n *arbitratorsNetwork) monitorPeersQuorum() {
+	for {
+		select {
+		case <-time.After(peersQuorumCheckInterval):
+			n.checkPeersQuorum()
+		}
+	}
+}
+
+// checkPeersQuorum announces when the connected peers are not enough to
+// collect signatures from two thirds of current arbiters.
+func (n *arbitratorsNetwork) checkPeersQuorum() {
+	n.peersLock.Lock()
+	arbiters := len(n.connectedPeers)
+	n.peersLock.Unlock()
+	if arbiters == 0 {
+		return
+	}
+
+	var connected int
+	for _, p := range n.DumpArbiterPeersInfo() {
+		if p.State != p2p.CSNoneConnection {
+			connected++
+		}
+	}
+	quorum := arbiters * 2 / 3
+	if connected >= quorum {
+		return
+	}
+	log.Warn("[checkPeersQuorum] connected peers:", connected, "quorum:", quorum)
+	events.Notify(events.ETArbiterPeersBelowQuorum, &events.Alert{
+		Subject: "peers",
+		Message: fmt.Sprintf("connected %d of %d arbiter peers, need %d",
+			connected, arbiters, quorum),
+	})
+}
+
 func (n *arbitratorsNetwork) notifyFlag(flag p2p.NotifyFlag) {
 }
 
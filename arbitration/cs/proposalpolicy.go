@@ -0,0 +1,310 @@
+package cs
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/elastos/Elastos.ELA.Arbiter/config"
+	"github.com/elastos/Elastos.ELA.Arbiter/events"
+	"github.com/elastos/Elastos.ELA.Arbiter/log"
+	"github.com/elastos/Elastos.ELA.Arbiter/store"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/contract"
+	elacommon "github.com/elastos/Elastos.ELA/core/types/common"
+	it "github.com/elastos/Elastos.ELA/core/types/interfaces"
+	"github.com/elastos/Elastos.ELA/core/types/outputpayload"
+	"github.com/elastos/Elastos.ELA/core/types/payload"
+)
+
+// DailyLimitWindow is the period counted by the daily withdraw limits.
+const DailyLimitWindow = 24 * time.Hour
+
+// ProposalPolicySingleton is consulted before signing proposals of other
+// arbiters, no business limit is applied if it is nil.
+var ProposalPolicySingleton *ProposalPolicy
+
+type approvalRule struct {
+	name           string
+	genesisAddress string
+	minAmount      common.Fixed64
+	minDailyAmount common.Fixed64
+}
+
+// ProposalPolicy applies the limits of withdraw amounts and destinations to
+// the withdraw and return deposit proposals, the proposals rejected are
+// recorded with reasons.
+type ProposalPolicy struct {
+	maxWithdrawAmount common.Fixed64
+	dailyAddressLimit common.Fixed64
+	dailyLimits       map[string]common.Fixed64
+	deniedAddresses   map[string]struct{}
+	rules             []*approvalRule
+
+	db store.ProposalDataStore
+}
+
+// withdrawal is an amount paid by the proposal, reference is the side chain
+// transaction or deposit transaction paid.
+type withdrawal struct {
+	reference string
+	address   string
+	amount    common.Fixed64
+}
+
+// NewProposalPolicy returns the policy of configuration, the frozen addresses
+// are always denied.
+func NewProposalPolicy(cfg *config.Configuration, db store.ProposalDataStore) *ProposalPolicy {
+	p := &ProposalPolicy{
+		dailyLimits:     make(map[string]common.Fixed64),
+		deniedAddresses: make(map[string]struct{}),
+		db:              db,
+	}
+	for _, addr := range cfg.FrozenAddresses {
+		p.deniedAddresses[addr] = struct{}{}
+	}
+
+	pc := cfg.ProposalPolicy
+	if pc == nil {
+		pc = &config.ProposalPolicyConfig{}
+	}
+	p.maxWithdrawAmount = pc.MaxWithdrawAmount
+	p.dailyAddressLimit = pc.DailyAddressLimit
+	for _, addr := range pc.DeniedAddresses {
+		p.deniedAddresses[addr] = struct{}{}
+	}
+
+	chains := make(map[string]string)
+	for _, node := range cfg.SideNodeList {
+		chains[node.Name] = node.GenesisBlockAddress
+		if node.DailyWithdrawLimit > 0 {
+			p.dailyLimits[node.GenesisBlockAddress] = node.DailyWithdrawLimit
+		} else if pc.DailyWithdrawLimit > 0 {
+			p.dailyLimits[node.GenesisBlockAddress] = pc.DailyWithdrawLimit
+		}
+	}
+	for i, rule := range pc.ApprovalRules {
+		name := rule.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i)
+		}
+		p.rules = append(p.rules, &approvalRule{
+			name:           name,
+			genesisAddress: chains[rule.SideChain],
+			minAmount:      rule.MinAmount,
+			minDailyAmount: rule.MinDailyAmount,
+		})
+	}
+	return p
+}
+
+// Check returns error if the transaction should not be signed, the rejected
+// transaction is recorded with the reason.
+func (p *ProposalPolicy) Check(txn it.Transaction) error {
+	genesisAddress, withdrawals, err := proposalWithdrawals(txn)
+	if err != nil {
+		return err
+	}
+	if len(withdrawals) == 0 {
+		return nil
+	}
+
+	status, reason, err := p.check(txn.Hash().String(), genesisAddress, withdrawals)
+	if err != nil {
+		return err
+	}
+	if reason == "" {
+		return nil
+	}
+
+	var amount common.Fixed64
+	for _, w := range withdrawals {
+		amount += w.amount
+	}
+	log.Warnf("[ProposalPolicy] %s proposal %s of %s: %s", status,
+		txn.Hash(), genesisAddress, reason)
+	err = p.db.AddRejectedProposal(&store.RejectedProposal{
+		TransactionHash:     txn.Hash().String(),
+		GenesisBlockAddress: genesisAddress,
+		Amount:              amount,
+		Status:              status,
+		Reason:              reason,
+	})
+	if err != nil {
+		log.Error("[ProposalPolicy] add rejected proposal failed:", err)
+	}
+	events.Notify(events.ETProposalRejected, &events.Alert{
+		GenesisBlockAddress: genesisAddress,
+		Subject:             txn.Hash().String(),
+		Message:             status + ": " + reason,
+	})
+	return errors.New("proposal " + status + ": " + reason)
+}
+
+func (p *ProposalPolicy) check(txHash, genesisAddress string,
+	withdrawals []*withdrawal) (status string, reason string, err error) {
+	for _, w := range withdrawals {
+		if _, ok := p.deniedAddresses[w.address]; ok {
+			return store.ProposalRejected, "address " + w.address + " is denied", nil
+		}
+		if p.maxWithdrawAmount > 0 && w.amount > p.maxWithdrawAmount {
+			return store.ProposalRejected, fmt.Sprintf("withdraw %s to %s exceeds limit %s",
+				w.amount, w.address, p.maxWithdrawAmount), nil
+		}
+	}
+
+	// the withdraws signed before are already counted
+	usages, err := p.db.GetWithdrawUsages(time.Now().Add(-DailyLimitWindow).Unix())
+	if err != nil {
+		return "", "", err
+	}
+	signed := make(map[string]struct{})
+	var chainTotal common.Fixed64
+	addressTotals := make(map[string]common.Fixed64)
+	for _, u := range usages {
+		if u.GenesisBlockAddress == genesisAddress {
+			signed[u.Reference+u.Address] = struct{}{}
+			chainTotal += u.Amount
+		}
+		addressTotals[u.Address] += u.Amount
+	}
+	for _, w := range withdrawals {
+		if _, ok := signed[w.reference+w.address]; ok {
+			continue
+		}
+		chainTotal += w.amount
+		addressTotals[w.address] += w.amount
+	}
+
+	if limit := p.dailyLimits[genesisAddress]; limit > 0 && chainTotal > limit {
+		return store.ProposalRejected, fmt.Sprintf("daily withdraw %s exceeds "+
+			"side chain limit %s", chainTotal, limit), nil
+	}
+	if p.dailyAddressLimit > 0 {
+		for _, w := range withdrawals {
+			if total := addressTotals[w.address]; total > p.dailyAddressLimit {
+				return store.ProposalRejected, fmt.Sprintf("daily withdraw %s to %s "+
+					"exceeds address limit %s", total, w.address, p.dailyAddressLimit), nil
+			}
+		}
+	}
+
+	for _, rule := range p.rules {
+		if !rule.match(genesisAddress, withdrawals, chainTotal) {
+			continue
+		}
+		approved, err := p.db.IsProposalApproved(txHash)
+		if err != nil {
+			return "", "", err
+		}
+		if approved {
+			return "", "", nil
+		}
+		return store.ProposalNeedApproval, "manual approval required by rule " + rule.name, nil
+	}
+	return "", "", nil
+}
+
+func (r *approvalRule) match(genesisAddress string, withdrawals []*withdrawal,
+	chainTotal common.Fixed64) bool {
+	if r.genesisAddress != "" && r.genesisAddress != genesisAddress {
+		return false
+	}
+	if r.minDailyAmount > 0 && chainTotal < r.minDailyAmount {
+		return false
+	}
+	if r.minAmount > 0 {
+		for _, w := range withdrawals {
+			if w.amount >= r.minAmount {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// Record counts the withdraws of the signed transaction in the daily limits,
+// and removes the withdraws out of the window.
+func (p *ProposalPolicy) Record(txn it.Transaction) error {
+	genesisAddress, withdrawals, err := proposalWithdrawals(txn)
+	if err != nil || len(withdrawals) == 0 {
+		return err
+	}
+	usages := make([]*store.WithdrawUsage, 0, len(withdrawals))
+	for _, w := range withdrawals {
+		usages = append(usages, &store.WithdrawUsage{
+			GenesisBlockAddress: genesisAddress,
+			Reference:           w.reference,
+			Address:             w.address,
+			Amount:              w.amount,
+		})
+	}
+	if err := p.db.AddWithdrawUsages(usages); err != nil {
+		return err
+	}
+	return p.db.RemoveWithdrawUsages(time.Now().Add(-DailyLimitWindow).Unix())
+}
+
+// proposalWithdrawals returns the side chain and the amounts paid by the
+// withdraw or return deposit transaction, the change back to the cross chain
+// address is ignored.
+func proposalWithdrawals(txn it.Transaction) (string, []*withdrawal, error) {
+	var genesisAddress string
+	var withdrawals []*withdrawal
+	setGenesisAddress := func(addr string) error {
+		if genesisAddress != "" && genesisAddress != addr {
+			return errors.New("outputs of different side chains")
+		}
+		genesisAddress = addr
+		return nil
+	}
+
+	switch txn.TxType() {
+	case elacommon.WithdrawFromSideChain, elacommon.ReturnSideChainDepositCoin:
+	default:
+		return "", nil, nil
+	}
+	for i, output := range txn.Outputs() {
+		if contract.PrefixType(output.ProgramHash[0]) == contract.PrefixCrossChain {
+			continue
+		}
+		addr, err := output.ProgramHash.ToAddress()
+		if err != nil {
+			return "", nil, err
+		}
+		w := &withdrawal{address: addr, amount: output.Value}
+		switch p := output.Payload.(type) {
+		case *outputpayload.Withdraw:
+			if output.Type != elacommon.OTWithdrawFromSideChain {
+				continue
+			}
+			if err := setGenesisAddress(p.GenesisBlockAddress); err != nil {
+				return "", nil, err
+			}
+			w.reference = p.SideChainTransactionHash.String()
+		case *outputpayload.ReturnSideChainDeposit:
+			if output.Type != elacommon.OTReturnSideChainDepositCoin {
+				continue
+			}
+			if err := setGenesisAddress(p.GenesisBlockAddress); err != nil {
+				return "", nil, err
+			}
+			w.reference = p.DepositTransactionHash.String()
+		default:
+			// outputs of the withdraw payload version 0 are not bound to
+			// the side chain transactions
+			pl, ok := txn.Payload().(*payload.WithdrawFromSideChain)
+			if !ok || txn.PayloadVersion() != payload.WithdrawFromSideChainVersion {
+				continue
+			}
+			if err := setGenesisAddress(pl.GenesisBlockAddress); err != nil {
+				return "", nil, err
+			}
+			w.reference = fmt.Sprintf("%s:%d", txn.Hash(), i)
+		}
+		withdrawals = append(withdrawals, w)
+	}
+	return genesisAddress, withdrawals, nil
+}
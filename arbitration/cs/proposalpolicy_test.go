@@ -0,0 +1,251 @@
+package cs
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA.Arbiter/config"
+	"github.com/elastos/Elastos.ELA.Arbiter/log"
+	"github.com/elastos/Elastos.ELA.Arbiter/store"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/contract"
+	pg "github.com/elastos/Elastos.ELA/core/contract/program"
+	elatx "github.com/elastos/Elastos.ELA/core/transaction"
+	elacommon "github.com/elastos/Elastos.ELA/core/types/common"
+	it "github.com/elastos/Elastos.ELA/core/types/interfaces"
+	"github.com/elastos/Elastos.ELA/core/types/outputpayload"
+	"github.com/elastos/Elastos.ELA/core/types/payload"
+)
+
+const (
+	testGenesisAddress  = "XKUh4GLhFJiqAMTF6HyWQrV9pK9HcGUdfJ"
+	testGenesisAddress2 = "XVfmhjxGxBKgzYxyXCJTb6YmaRfWPVunj4"
+)
+
+func TestMain(m *testing.M) {
+	dir, err := ioutil.TempDir("", "arbiter-cs")
+	if err != nil {
+		panic(err)
+	}
+	log.Init(filepath.Join(dir, "logs"), 1, 0, 0)
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// memoryProposalStore keeps the proposal records in memory.
+type memoryProposalStore struct {
+	usages   []*store.WithdrawUsage
+	rejected map[string]*store.RejectedProposal
+}
+
+func newMemoryProposalStore() *memoryProposalStore {
+	return &memoryProposalStore{rejected: make(map[string]*store.RejectedProposal)}
+}
+
+func (s *memoryProposalStore) AddWithdrawUsages(usages []*store.WithdrawUsage) error {
+	for _, u := range usages {
+		exist := false
+		for _, e := range s.usages {
+			if e.GenesisBlockAddress == u.GenesisBlockAddress &&
+				e.Reference == u.Reference && e.Address == u.Address {
+				exist = true
+			}
+		}
+		if !exist {
+			s.usages = append(s.usages, u)
+		}
+	}
+	return nil
+}
+
+func (s *memoryProposalStore) GetWithdrawUsages(since int64) ([]*store.WithdrawUsage, error) {
+	return s.usages, nil
+}
+
+func (s *memoryProposalStore) RemoveWithdrawUsages(before int64) error {
+	return nil
+}
+
+func (s *memoryProposalStore) AddRejectedProposal(proposal *store.RejectedProposal) error {
+	if p, ok := s.rejected[proposal.TransactionHash]; ok {
+		p.Status, p.Reason = proposal.Status, proposal.Reason
+		return nil
+	}
+	s.rejected[proposal.TransactionHash] = proposal
+	return nil
+}
+
+func (s *memoryProposalStore) GetRejectedProposals(limit int) ([]*store.RejectedProposal, error) {
+	var proposals []*store.RejectedProposal
+	for _, p := range s.rejected {
+		proposals = append(proposals, p)
+	}
+	return proposals, nil
+}
+
+func (s *memoryProposalStore) ApproveProposal(transactionHash string) (*store.RejectedProposal, error) {
+	p, ok := s.rejected[transactionHash]
+	if !ok || p.Status != store.ProposalNeedApproval {
+		return nil, errors.New("proposal is not waiting for approval")
+	}
+	p.Approved = true
+	return p, nil
+}
+
+func (s *memoryProposalStore) IsProposalApproved(transactionHash string) (bool, error) {
+	p, ok := s.rejected[transactionHash]
+	return ok && p.Approved, nil
+}
+
+func (s *memoryProposalStore) ResetDataStore(dbName string) error {
+	return nil
+}
+
+func testAddress(i byte) (common.Uint168, string) {
+	programHash := common.Uint168{byte(contract.PrefixStandard), i}
+	addr, _ := programHash.ToAddress()
+	return programHash, addr
+}
+
+// newTestWithdrawTx returns a withdraw transaction paying amounts to the test
+// addresses from 1.
+func newTestWithdrawTx(genesisAddress string, sideChainTx byte, amounts ...common.Fixed64) it.Transaction {
+	var outputs []*elacommon.Output
+	for i, amount := range amounts {
+		programHash, _ := testAddress(byte(i + 1))
+		outputs = append(outputs, &elacommon.Output{
+			ProgramHash: programHash,
+			Value:       amount,
+			Type:        elacommon.OTWithdrawFromSideChain,
+			Payload: &outputpayload.Withdraw{
+				GenesisBlockAddress:      genesisAddress,
+				SideChainTransactionHash: common.Uint256{sideChainTx, byte(i)},
+			},
+		})
+	}
+	outputs = append(outputs, &elacommon.Output{
+		ProgramHash: common.Uint168{byte(contract.PrefixCrossChain)},
+		Value:       1000000,
+		Type:        elacommon.OTNone,
+		Payload:     &outputpayload.DefaultOutput{},
+	})
+	return elatx.CreateTransaction(
+		elacommon.TxVersion09,
+		elacommon.WithdrawFromSideChain,
+		payload.WithdrawFromSideChainVersionV1,
+		&payload.WithdrawFromSideChain{},
+		[]*elacommon.Attribute{},
+		[]*elacommon.Input{{Previous: elacommon.OutPoint{TxID: common.Uint256{sideChainTx}}}},
+		outputs,
+		0,
+		[]*pg.Program{},
+	)
+}
+
+func newTestPolicy(policy *config.ProposalPolicyConfig) (*ProposalPolicy, *memoryProposalStore) {
+	db := newMemoryProposalStore()
+	_, frozen := testAddress(9)
+	cfg := &config.Configuration{
+		FrozenAddresses: []string{frozen},
+		SideNodeList: []*config.SideNodeConfig{
+			{Name: "ESC", GenesisBlockAddress: testGenesisAddress, DailyWithdrawLimit: 500},
+			{Name: "EID", GenesisBlockAddress: testGenesisAddress2},
+		},
+		ProposalPolicy: policy,
+	}
+	return NewProposalPolicy(cfg, db), db
+}
+
+func TestProposalPolicy_Limits(t *testing.T) {
+	_, denied := testAddress(8)
+	policy, db := newTestPolicy(&config.ProposalPolicyConfig{
+		MaxWithdrawAmount:  300,
+		DailyWithdrawLimit: 1000,
+		DailyAddressLimit:  400,
+		DeniedAddresses:    []string{denied},
+	})
+
+	if err := policy.Check(newTestWithdrawTx(testGenesisAddress, 1, 301)); err == nil {
+		t.Error("withdraw over max amount should be rejected")
+	}
+	if err := policy.Check(newTestWithdrawTx(testGenesisAddress, 1, 1, 1, 1, 1, 1, 1, 1, 1)); err == nil {
+		t.Error("withdraw to denied address should be rejected")
+	}
+	if err := policy.Check(newTestWithdrawTx(testGenesisAddress, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1)); err == nil {
+		t.Error("withdraw to frozen address should be rejected")
+	}
+	if len(db.rejected) != 3 {
+		t.Fatalf("rejected proposals should be recorded, got %d", len(db.rejected))
+	}
+	for _, p := range db.rejected {
+		if p.Status != store.ProposalRejected || p.Reason == "" ||
+			p.GenesisBlockAddress != testGenesisAddress {
+			t.Errorf("invalid rejected proposal %+v", p)
+		}
+	}
+
+	// side chain limit of ESC is 500 and address limit is 400
+	txn := newTestWithdrawTx(testGenesisAddress, 2, 300)
+	if err := policy.Check(txn); err != nil {
+		t.Fatal(err)
+	}
+	policy.Record(txn)
+	if err := policy.Check(txn); err != nil {
+		t.Errorf("signed proposal should not be counted twice, %v", err)
+	}
+	if err := policy.Check(newTestWithdrawTx(testGenesisAddress, 3, 150)); err == nil {
+		t.Error("withdraw over daily address limit should be rejected")
+	}
+	if err := policy.Check(newTestWithdrawTx(testGenesisAddress2, 3, 150)); err == nil {
+		t.Error("daily address limit should count all side chains")
+	}
+
+	txn = newTestWithdrawTx(testGenesisAddress, 4, 0, 0, 100)
+	if err := policy.Check(txn); err != nil {
+		t.Fatal(err)
+	}
+	policy.Record(txn)
+	if err := policy.Check(newTestWithdrawTx(testGenesisAddress, 5, 0, 0, 0, 200)); err == nil {
+		t.Error("withdraw over daily side chain limit should be rejected")
+	}
+	if err := policy.Check(newTestWithdrawTx(testGenesisAddress2, 5, 0, 0, 0, 200)); err != nil {
+		t.Errorf("daily side chain limit should be separated, %v", err)
+	}
+}
+
+func TestProposalPolicy_Approval(t *testing.T) {
+	policy, db := newTestPolicy(&config.ProposalPolicyConfig{
+		ApprovalRules: []*config.ApprovalRule{
+			{Name: "large", SideChain: "EID", MinAmount: 200},
+		},
+	})
+
+	if err := policy.Check(newTestWithdrawTx(testGenesisAddress, 1, 300)); err != nil {
+		t.Errorf("rule of other side chain should not match, %v", err)
+	}
+	if err := policy.Check(newTestWithdrawTx(testGenesisAddress2, 1, 100)); err != nil {
+		t.Errorf("withdraw under rule amount should not match, %v", err)
+	}
+
+	txn := newTestWithdrawTx(testGenesisAddress2, 2, 100, 200)
+	if err := policy.Check(txn); err == nil {
+		t.Fatal("outlier should require manual approval")
+	}
+	p := db.rejected[txn.Hash().String()]
+	if p == nil || p.Status != store.ProposalNeedApproval || p.Amount != 300 {
+		t.Fatalf("proposal waiting for approval should be recorded, %+v", p)
+	}
+
+	if _, err := db.ApproveProposal(txn.Hash().String()); err != nil {
+		t.Fatal(err)
+	}
+	if err := policy.Check(txn); err != nil {
+		t.Errorf("approved proposal should be signed, %v", err)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/elastos/Elastos.ELA.Arbiter/arbitration/arbitrator"
 	"github.com/elastos/Elastos.ELA.Arbiter/arbitration/base"
 	"github.com/elastos/Elastos.ELA.Arbiter/config"
+	"github.com/elastos/Elastos.ELA.Arbiter/events"
 	"github.com/elastos/Elastos.ELA.Arbiter/log"
 	"github.com/elastos/Elastos.ELA.Arbiter/rpc"
 	"github.com/elastos/Elastos.ELA.Arbiter/store"
@@ -60,6 +61,7 @@ func (d *TxDistributedContent) SubmitWithdrawTransaction() error {
 		transactionHashes = append(transactionHashes, hash.String())
 	}
 	var dbStore store.DataStoreSideChain
+	var genesisAddress string
 	if d.Tx.PayloadVersion() == payload.WithdrawFromSideChainVersionV1 || d.Tx.PayloadVersion() == payload.WithdrawFromSideChainVersionV2 {
 		var sideChain arbitrator.SideChain
 		for _, output := range d.Tx.Outputs() {
@@ -82,19 +84,29 @@ func (d *TxDistributedContent) SubmitWithdrawTransaction() error {
 				}
 			}
 		}
-		dbStore = store.DbCache.GetDataStoreGenesisBlocAddress(sideChain.GetKey())
+		genesisAddress = sideChain.GetKey()
+		dbStore = store.DbCache.GetDataStoreGenesisBlocAddress(genesisAddress)
 		if dbStore == nil {
 			return errors.New("can't find db by genesis block hash ")
 		}
 	} else {
-		dbStore = store.DbCache.GetDataStoreGenesisBlocAddress(pl.GenesisBlockAddress)
+		genesisAddress = pl.GenesisBlockAddress
+		dbStore = store.DbCache.GetDataStoreGenesisBlocAddress(genesisAddress)
 	}
 	if dbStore == nil {
 		return errors.New("can't find db by genesis block hash ")
 	}
 
+	withdrawTx := &events.CrossChainTx{
+		GenesisBlockAddress:        genesisAddress,
+		MainChainTransactionHash:   d.Tx.Hash().String(),
+		SideChainTransactionHashes: transactionHashes,
+		Addresses:                  withdrawTargetAddresses(d.Tx, genesisAddress),
+	}
+
 	if err != nil || resp.Error != nil && resp.Code != MCErrDoubleSpend {
 		log.Warn("send withdraw transaction failed, move to finished db, txHash:", d.Tx.Hash().String(), ", code: ", resp.Code, ", result:", resp.Result)
+		notifySubmitFailed(genesisAddress, d.Tx, resp, err)
 
 		buf := new(bytes.Buffer)
 		err := d.Tx.Serialize(buf)
@@ -129,6 +141,8 @@ func (d *TxDistributedContent) SubmitWithdrawTransaction() error {
 		if err != nil {
 			return errors.New("add succeed withdraw transaction into finished db failed")
 		}
+		events.Notify(events.ETWithdrawProposalSigned, withdrawTx)
+		events.Notify(events.ETWithdrawConfirmed, withdrawTx)
 	} else {
 		log.Warn("send withdraw transaction failed, need to resend")
 	}
@@ -200,6 +214,7 @@ func (d *TxDistributedContent) SubmitReturnSideChainDepositCoin() error {
 	log.Info("Submit return side chain deposit coin transaction")
 	var transactionHashes []string
 	var genesisAddresses []string
+	var returnedTxs []*events.CrossChainTx
 	for _, o := range d.Tx.Outputs() {
 		if o.Type != elacommon.OTReturnSideChainDepositCoin {
 			continue
@@ -210,6 +225,16 @@ func (d *TxDistributedContent) SubmitReturnSideChainDepositCoin() error {
 		}
 		transactionHashes = append(transactionHashes, opl.DepositTransactionHash.String())
 		genesisAddresses = append(genesisAddresses, opl.GenesisBlockAddress)
+
+		returnedTx := &events.CrossChainTx{
+			GenesisBlockAddress:      opl.GenesisBlockAddress,
+			MainChainTransactionHash: opl.DepositTransactionHash.String(),
+			ReturnTransactionHash:    d.Tx.Hash().String(),
+		}
+		if addr, err := o.ProgramHash.ToAddress(); err == nil {
+			returnedTx.Addresses = []string{addr}
+		}
+		returnedTxs = append(returnedTxs, returnedTx)
 	}
 
 	if err != nil {
@@ -220,6 +245,9 @@ func (d *TxDistributedContent) SubmitReturnSideChainDepositCoin() error {
 	}
 	if err != nil || resp.Error != nil && resp.Code != MCErrDoubleSpend {
 		log.Warn("failed to send return side chain deposit coin transaction, move to finished db, txHash:", d.Tx.Hash().String(), ", code: ", resp.Code, ", result:", resp.Result)
+		for _, returnedTx := range returnedTxs {
+			notifySubmitFailed(returnedTx.GenesisBlockAddress, d.Tx, resp, err)
+		}
 
 		buf := new(bytes.Buffer)
 		err := d.Tx.Serialize(buf)
@@ -245,6 +273,9 @@ func (d *TxDistributedContent) SubmitReturnSideChainDepositCoin() error {
 		if err != nil {
 			return errors.New("failed to remove succeed send return side chain deposit coin transaction from db")
 		}
+		for _, returnedTx := range returnedTxs {
+			events.Notify(events.ETFailedDepositReturned, returnedTx)
+		}
 		// todo add to succeed db
 	} else {
 		log.Warn("failed to  send return side chain deposit coin transaction, need to resend")
@@ -308,6 +339,35 @@ func (d *TxDistributedContent) Hash() common.Uint256 {
 	return d.Tx.Hash()
 }
 
+// notifySubmitFailed announces the transaction rejected by the main chain.
+func notifySubmitFailed(genesisAddress string, txn it.Transaction, resp rpc.Response, err error) {
+	reason := ""
+	if err != nil {
+		reason = err.Error()
+	} else if resp.Error != nil {
+		reason = fmt.Sprintf("code %d, %s", resp.Code, resp.Message)
+	}
+	events.Notify(events.ETSubmitFailed, &events.Alert{
+		GenesisBlockAddress: genesisAddress,
+		Subject:             txn.Hash().String(),
+		Message:             "send " + txn.TxType().Name() + " transaction " + txn.Hash().String() + " failed: " + reason,
+	})
+}
+
+// withdrawTargetAddresses returns the main chain addresses the withdraw
+// transaction pays to, the change back to the side chain is ignored.
+func withdrawTargetAddresses(txn it.Transaction, genesisAddress string) []string {
+	var addresses []string
+	for _, output := range txn.Outputs() {
+		addr, err := output.ProgramHash.ToAddress()
+		if err != nil || addr == genesisAddress {
+			continue
+		}
+		addresses = append(addresses, addr)
+	}
+	return addresses
+}
+
 func checkWithdrawFromSidechainPayload(txn it.Transaction,
 	clientFunc DistributedNodeClientFunc, mainFunc *arbitrator.MainChainFuncImpl, payloadWithdraw *payload.WithdrawFromSideChain) error {
 	// check if side chain exist.
@@ -324,11 +384,6 @@ func checkWithdrawFromSidechainPayload(txn it.Transaction,
 	// check if withdraw transactions exist in db, if not found then will check
 	// by the rpc interface of the side chain.
 	var txs []*base.WithdrawTx
-
-	frozenAddressMap := make(map[string]bool)
-	for _, frozenAddress := range config.Parameters.FrozenAddresses {
-		frozenAddressMap[frozenAddress] = true
-	}
 	dbStore := store.DbCache.GetDataStoreGenesisBlocAddress(payloadWithdraw.GenesisBlockAddress)
 	if dbStore == nil {
 		return errors.New(fmt.Sprintf("can't find db store by genesis block address:%s", payloadWithdraw.GenesisBlockAddress))
@@ -357,9 +412,6 @@ func checkWithdrawFromSidechainPayload(txn it.Transaction,
 				if err != nil {
 					return errors.New("[checkWithdrawTransaction] invalid output amount in tx")
 				}
-				if _, ok := frozenAddressMap[cs.CrossChainAddress]; ok {
-					return errors.New("[checkWithdrawTransaction] invalid cross chain address in tx: frozen address, " + cs.CrossChainAddress)
-				}
 				withdrawAssets = append(withdrawAssets, &base.WithdrawAsset{
 					TargetAddress:    cs.CrossChainAddress,
 					Amount:           opAmount,
@@ -409,13 +461,13 @@ func checkWithdrawFromSidechainPayload(txn it.Transaction,
 	var totalCrossChainAmount int
 	crossChainOutputsMap := make(map[string]common.Fixed64)
 	for _, tx := range txs {
+		if tx.RejectReason != "" {
+			return fmt.Errorf("[checkWithdrawTransaction] withdraw transaction %s invalid: %s", tx.Txid, tx.RejectReason)
+		}
+		if err := arbitrator.CheckWithdrawTx(payloadWithdraw.GenesisBlockAddress, tx); err != nil {
+			return fmt.Errorf("[checkWithdrawTransaction] withdraw transaction %s rejected: %v", tx.Txid, err)
+		}
 		for _, w := range tx.WithdrawInfo.WithdrawAssets {
-			if *w.CrossChainAmount < 0 || *w.Amount <= 0 ||
-				*w.Amount-*w.CrossChainAmount <= 0 ||
-				*w.CrossChainAmount >= *w.Amount {
-				return errors.New("check withdraw transaction " +
-					"failed, cross chain amount less than 0")
-			}
 			oriOutputAmount += common.Fixed64(float64(*w.CrossChainAmount) / exchangeRate)
 			totalFee += common.Fixed64(float64(*w.Amount-*w.CrossChainAmount) / exchangeRate)
 
@@ -527,6 +579,29 @@ func checkNFTDestroyFromSideChainTxPayload(txn it.Transaction, clientFunc Distri
 	return checkNFTDestroyFromSideChainPayload(txn, clientFunc, nftDestroyPayload)
 }
 
+// checkDecommissionedReturnDeposit returns if the deposit to the
+// decommissioned side chain is returned by this arbiter, the deposit should
+// be seen since the side chain is decommissioned and not be recharged on it.
+func checkDecommissionedReturnDeposit(sideChain arbitrator.SideChain, genesisAddress,
+	depositHash string) (bool, error) {
+	dbStore := store.DbCache.GetDataStoreGenesisBlocAddress(genesisAddress)
+	if dbStore == nil {
+		return false, errors.New("can't find db store by genesis block address:" + genesisAddress)
+	}
+	recordTime, err := dbStore.GetReturnDepositTxRecordTime(depositHash)
+	if err != nil {
+		return false, err
+	}
+	if recordTime == 0 || recordTime < arbitrator.DecommissionSingleton.StartTime(genesisAddress) {
+		return false, nil
+	}
+	recharged, err := sideChain.GetExistDepositTransactions([]string{depositHash})
+	if err != nil {
+		return false, err
+	}
+	return len(recharged) == 0, nil
+}
+
 func checkReturnDepositTxPayload(txn it.Transaction, clientFunc DistributedNodeClientFunc) error {
 	// check if withdraw transactions exist in db, if not found then will check
 	// by the rpc interface of the side chain.
@@ -548,6 +623,12 @@ func checkReturnDepositTxPayload(txn it.Transaction, clientFunc DistributedNodeC
 		}
 
 		exist, err := sideChain.GetFailedDepositTransaction(opl.DepositTransactionHash.String())
+		if err == nil && !exist && arbitrator.IsSideChainDecommissioned(opl.GenesisBlockAddress) {
+			// the deposits to a decommissioned side chain are returned
+			// without being sent to it
+			exist, err = checkDecommissionedReturnDeposit(sideChain, opl.GenesisBlockAddress,
+				opl.DepositTransactionHash.String())
+		}
 		if err != nil || !exist {
 			return errors.New("[checkReturnDepositTxPayload] failed, unknown side chain transactions")
 		}
@@ -652,6 +733,9 @@ func checkWithdrawFromSideChainPayload(txn it.Transaction,
 	}
 
 	genesisAddress := sideChain.GetKey()
+	if arbitrator.IsWithdrawPaused(genesisAddress) {
+		return errors.New("withdraws of side chain are paused")
+	}
 	// check if withdraw transactions exist in db, if not found then will check
 	// by the rpc interface of the side chain.
 
@@ -732,22 +816,14 @@ func checkWithdrawFromSideChainPayload(txn it.Transaction,
 	var oriOutputAmount common.Fixed64
 	var totalCrossChainAmount int
 	crossChainOutputsMap := make(map[string]common.Fixed64)
-			
-	frozenAddressMap := make(map[string]bool)
-	for _, frozenAddress := range config.Parameters.FrozenAddresses {
-		frozenAddressMap[frozenAddress] = true
-	}
 	for _, tx := range txs {
+		if tx.RejectReason != "" {
+			return fmt.Errorf("[checkWithdrawTransaction] withdraw transaction %s invalid: %s", tx.Txid, tx.RejectReason)
+		}
+		if err := arbitrator.CheckWithdrawTx(genesisAddress, tx); err != nil {
+			return fmt.Errorf("[checkWithdrawTransaction] withdraw transaction %s rejected: %v", tx.Txid, err)
+		}
 		for _, w := range tx.WithdrawInfo.WithdrawAssets {
-			if _, ok := frozenAddressMap[w.TargetAddress]; ok {
-				return errors.New("[checkWithdrawTransaction] invalid cross chain address in tx: frozen address, " + w.TargetAddress)
-			}
-			if *w.CrossChainAmount < 0 || *w.Amount <= 0 ||
-				*w.Amount-*w.CrossChainAmount <= 0 ||
-				*w.CrossChainAmount >= *w.Amount {
-				return errors.New("check withdraw transaction " +
-					"failed, cross chain amount less than 0")
-			}
 			oriOutputAmount += common.Fixed64(float64(*w.CrossChainAmount) / exchangeRate)
 			totalFee += common.Fixed64(float64(*w.Amount-*w.CrossChainAmount) / exchangeRate)
 
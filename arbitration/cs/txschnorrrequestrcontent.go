@@ -12,6 +12,9 @@ type SchnorrWithdrawRequestRProposalContent struct {
 	R     KRP
 }
 
+// KRP is the nonce point R and the public key P of a schnorr signer. The nonce
+// k0 is sent only by the legacy schnorr proposals, it is kept by the signer and
+// left empty on the wire if the signers return partial signatures.
 type KRP struct {
 	K0 *big.Int
 	Rx *big.Int
@@ -21,7 +24,11 @@ type KRP struct {
 }
 
 func (r *KRP) Serialize(w io.Writer) error {
-	if err := common.WriteVarBytes(w, r.K0.Bytes()); err != nil {
+	var k0 []byte
+	if r.K0 != nil {
+		k0 = r.K0.Bytes()
+	}
+	if err := common.WriteVarBytes(w, k0); err != nil {
 		return err
 	}
 	if err := common.WriteVarBytes(w, r.Rx.Bytes()); err != nil {
@@ -44,7 +51,10 @@ func (c *KRP) Deserialize(r io.Reader) error {
 	if err != nil {
 		return err
 	}
-	c.K0 = new(big.Int).SetBytes(k0)
+	c.K0 = nil
+	if len(k0) > 0 {
+		c.K0 = new(big.Int).SetBytes(k0)
+	}
 
 	rx, err := common.ReadVarBytes(r, 64, "rx")
 	if err != nil {
@@ -22,7 +22,14 @@ type SchnorrWithdrawRequestSProposalContent struct {
 	Tx         it.Transaction
 	Publickeys [][]byte
 	E          *big.Int
-	S          *big.Int
+	// Rx, Ry is the aggregated nonce point of the signers, carried only if
+	// the signers return partial signatures.
+	Rx *big.Int
+	Ry *big.Int
+	S  *big.Int
+
+	// Partial is not serialized, it is set by the type of the proposal.
+	Partial bool
 }
 
 func (c *SchnorrWithdrawRequestSProposalContent) SerializeUnsigned(w io.Writer, feedback bool) error {
@@ -46,6 +53,14 @@ func (c *SchnorrWithdrawRequestSProposalContent) SerializeUnsigned(w io.Writer,
 	if err := common.WriteVarBytes(w, c.E.Bytes()); err != nil {
 		return err
 	}
+	if c.Partial {
+		if err := common.WriteVarBytes(w, c.Rx.Bytes()); err != nil {
+			return err
+		}
+		if err := common.WriteVarBytes(w, c.Ry.Bytes()); err != nil {
+			return err
+		}
+	}
 	if feedback {
 		if err := common.WriteVarBytes(w, c.S.Bytes()); err != nil {
 			return err
@@ -93,6 +108,20 @@ func (c *SchnorrWithdrawRequestSProposalContent) Deserialize(r io.Reader, feedba
 	}
 	c.E = new(big.Int).SetBytes(e)
 
+	if c.Partial {
+		rx, err := common.ReadVarBytes(r, 64, "rx")
+		if err != nil {
+			return err
+		}
+		c.Rx = new(big.Int).SetBytes(rx)
+
+		ry, err := common.ReadVarBytes(r, 64, "ry")
+		if err != nil {
+			return err
+		}
+		c.Ry = new(big.Int).SetBytes(ry)
+	}
+
 	if feedback {
 		s, err := common.ReadVarBytes(r, 65, "s")
 		if err != nil {
@@ -108,6 +137,13 @@ func (d *SchnorrWithdrawRequestSProposalContent) Hash() common.Uint256 {
 	return d.Tx.Hash()
 }
 
+// isSchnorrPartialSignature returns if the schnorr proposals exchange partial
+// signatures instead of nonces at the current main chain height.
+func isSchnorrPartialSignature() bool {
+	height := store.DbCache.MainChainStore.CurrentHeight(store.QueryHeightCode)
+	return height >= config.Parameters.SchnorrPartialSignatureHeight
+}
+
 func (d *SchnorrWithdrawRequestSProposalContent) Check(client interface{}) error {
 	clientFunc, ok := client.(DistributedNodeClientFunc)
 	if !ok {
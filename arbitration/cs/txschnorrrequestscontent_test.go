@@ -0,0 +1,94 @@
+package cs
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA.Arbiter/signer"
+
+	"github.com/elastos/Elastos.ELA/common"
+)
+
+func TestSchnorrRequestSProposalContent_Serialize(t *testing.T) {
+	txn := newTestWithdrawTx(testGenesisAddress, 1, 100)
+	policy, err := signer.NewPolicy(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, partial := range []bool{false, true} {
+		content := SchnorrWithdrawRequestSProposalContent{
+			NonceHash:  common.Uint256{1},
+			Tx:         txn,
+			Publickeys: [][]byte{bytes.Repeat([]byte{2}, 33)},
+			E:          big.NewInt(3),
+			Rx:         big.NewInt(4),
+			Ry:         big.NewInt(5),
+			S:          big.NewInt(6),
+			Partial:    partial,
+		}
+		buf := new(bytes.Buffer)
+		if err := content.Serialize(buf, true); err != nil {
+			t.Fatal(err)
+		}
+
+		// the feedback signed by a remote signer is accepted by its policy
+		signed := new(bytes.Buffer)
+		if err := content.SerializeUnsigned(signed, true); err != nil {
+			t.Fatal(err)
+		}
+		if err := policy.CheckContent(signed.Bytes()); err != nil {
+			t.Error("schnorr S feedback should be allowed by signer policy,", err)
+		}
+
+		decoded := SchnorrWithdrawRequestSProposalContent{Partial: partial}
+		if err := decoded.Deserialize(bytes.NewReader(buf.Bytes()), true); err != nil {
+			t.Fatal(err)
+		}
+		if decoded.NonceHash != content.NonceHash || decoded.Tx.Hash() != txn.Hash() ||
+			decoded.E.Cmp(content.E) != 0 || decoded.S.Cmp(content.S) != 0 {
+			t.Error("schnorr S proposal mismatch, partial:", partial)
+		}
+		if partial {
+			if decoded.Rx == nil || decoded.Rx.Cmp(content.Rx) != 0 || decoded.Ry.Cmp(content.Ry) != 0 {
+				t.Error("aggregated R should be carried by the partial proposal")
+			}
+		} else if decoded.Rx != nil || decoded.Ry != nil {
+			t.Error("aggregated R should not be carried by the legacy proposal")
+		}
+	}
+}
+
+func TestKRP_Serialize(t *testing.T) {
+	policy, err := signer.NewPolicy(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, k0 := range []*big.Int{big.NewInt(7), nil} {
+		krp := KRP{K0: k0, Rx: big.NewInt(1), Ry: big.NewInt(2), Px: big.NewInt(3), Py: big.NewInt(4)}
+		buf := new(bytes.Buffer)
+		if err := krp.Serialize(buf); err != nil {
+			t.Fatal(err)
+		}
+
+		signed := new(bytes.Buffer)
+		content := SchnorrWithdrawRequestRProposalContent{Nonce: make([]byte, 32), R: krp}
+		if err := content.SerializeUnsigned(signed, true); err != nil {
+			t.Fatal(err)
+		}
+		if err := policy.CheckContent(signed.Bytes()); err != nil {
+			t.Error("schnorr R feedback should be allowed by signer policy,", err)
+		}
+
+		var decoded KRP
+		if err := decoded.Deserialize(bytes.NewReader(buf.Bytes())); err != nil {
+			t.Fatal(err)
+		}
+		if k0 == nil && decoded.K0 != nil || k0 != nil && (decoded.K0 == nil || decoded.K0.Cmp(k0) != 0) {
+			t.Error("k0 mismatch, expected:", k0, "got:", decoded.K0)
+		}
+		if decoded.Rx.Int64() != 1 || decoded.Ry.Int64() != 2 || decoded.Px.Int64() != 3 || decoded.Py.Int64() != 4 {
+			t.Error("R and P mismatch")
+		}
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/elastos/Elastos.ELA.Arbiter/arbitration/base"
 	"github.com/elastos/Elastos.ELA.Arbiter/arbitration/cs"
 	"github.com/elastos/Elastos.ELA.Arbiter/config"
+	"github.com/elastos/Elastos.ELA.Arbiter/events"
 	"github.com/elastos/Elastos.ELA.Arbiter/log"
 	"github.com/elastos/Elastos.ELA.Arbiter/rpc"
 	"github.com/elastos/Elastos.ELA.Arbiter/store"
@@ -582,14 +583,29 @@ func (mc *MainChainImpl) SyncChainData() uint32 {
 		return currentHeight
 	}
 	for _, v := range transactions {
+		// The registrations approved or rejected are not reviewed again.
+		if reviewed, err := store.FinishedTxsDbCache.HasRegisterTx(v.TransactionHash, v.GenesisBlockAddress); err != nil {
+			log.Error("HasRegisterTx failed ", err.Error())
+			return currentHeight
+		} else if reviewed {
+			continue
+		}
 		if exist, err := store.DbCache.RegisteredSideChainStore.HasRegisteredSideChainTx(v.TransactionHash, v.GenesisBlockAddress); err != nil {
 			log.Error("HasRegisteredSideChainTx failed ", err.Error())
 			return currentHeight
 		} else if !exist {
-			_, err := store.DbCache.RegisteredSideChainStore.AddRegisteredSideChainTxs(transactions)
+			_, err := store.DbCache.RegisteredSideChainStore.AddRegisteredSideChainTxs(
+				[]*base.RegisteredSideChainTransaction{v})
 			if err != nil {
 				log.Error("AddRegisteredSideChainTxs failed", err.Error())
+				continue
 			}
+			log.Info("Register sidechain ", v.RegisteredSideChain.SideChainName, " waiting for review")
+			events.Notify(events.ETSideChainRegistered, &events.Alert{
+				GenesisBlockAddress: v.GenesisBlockAddress,
+				Subject:             v.TransactionHash,
+				Message:             "side chain " + v.RegisteredSideChain.SideChainName + " registered, waiting for review",
+			})
 		} else {
 			log.Warn("Sidechain with genesisblockaddress ", v.GenesisBlockAddress, " already exists")
 		}
@@ -627,7 +643,7 @@ func (mc *MainChainImpl) needSyncBlocks() (uint32, uint32, bool) {
 }
 
 func (mc *MainChainImpl) containGenesisBlockAddress(address string) bool {
-	for _, node := range config.Parameters.SideNodeList {
+	for _, node := range config.SideNodes() {
 		if node.GenesisBlockAddress == address {
 			return true
 		}
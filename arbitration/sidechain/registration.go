@@ -0,0 +1,299 @@
+package sidechain
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/elastos/Elastos.ELA.Arbiter/arbitration/arbitrator"
+	"github.com/elastos/Elastos.ELA.Arbiter/arbitration/base"
+	"github.com/elastos/Elastos.ELA.Arbiter/config"
+	"github.com/elastos/Elastos.ELA.Arbiter/log"
+	"github.com/elastos/Elastos.ELA.Arbiter/rpc"
+	"github.com/elastos/Elastos.ELA.Arbiter/store"
+
+	"github.com/elastos/Elastos.ELA/common"
+)
+
+var (
+	// registrationLock serializes the review of registered side chains.
+	registrationLock sync.Mutex
+
+	// registrationPasses keeps the rpc password of the rpc information
+	// received by transaction hash, it is never written to the store.
+	registrationPasses = make(map[string]string)
+)
+
+// OnReceivedRegisteredSideChain records the rpc information of the
+// registered side chain, it is activated once approved by the operator.
+func (sideManager *SideChainManagerImpl) OnReceivedRegisteredSideChain(info base.RegisterSidechainRpcInfo) error {
+	log.Info("Receive register sidechain rpc ", info.IpAddr, info.Httpjsonport, info.GenesisBlockHash)
+	txs, err := store.DbCache.RegisteredSideChainStore.GetAllRegisteredSideChainTxs()
+	if err != nil {
+		return errors.New("[OnReceivedRegisteredSideChain] " + err.Error())
+	}
+
+	pass := info.Pass
+	info.Pass = ""
+	data, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+	registrationLock.Lock()
+	defer registrationLock.Unlock()
+	for _, transaction := range txs {
+		if transaction.RegisteredSideChain.GenesisHash.String() != info.GenesisBlockHash {
+			continue
+		}
+		if err := store.RegistrationDbCache.SetRegistrationRpcInfo(transaction.TransactionHash,
+			transaction.GenesisBlockAddress, string(data)); err != nil {
+			return errors.New("[OnReceivedRegisteredSideChain] " + err.Error())
+		}
+		registrationPasses[transaction.TransactionHash] = pass
+		log.Info("Register sidechain ", transaction.RegisteredSideChain.SideChainName, " waiting for review")
+		return nil
+	}
+	return errors.New("[OnReceivedRegisteredSideChain] no pending registration of genesis block " +
+		info.GenesisBlockHash)
+}
+
+// ApproveRegisteredSideChain activates the registered side chain with the rpc
+// endpoint and feature flags of node, the rpc information received is used
+// if node.Rpc is nil. The side node is probed first, and the registration is
+// rejected if it does not match.
+func (sideManager *SideChainManagerImpl) ApproveRegisteredSideChain(txHash string, node *config.SideNodeConfig) error {
+	registrationLock.Lock()
+	defer registrationLock.Unlock()
+
+	transaction, err := getRegisteredSideChainTx(txHash)
+	if err != nil {
+		return err
+	}
+	if _, ok := sideManager.GetChain(transaction.GenesisBlockAddress); ok {
+		return errors.New("side chain already activated")
+	}
+	rsc := transaction.RegisteredSideChain
+
+	if node.Rpc == nil {
+		review, err := store.RegistrationDbCache.GetRegistrationReview(txHash, transaction.GenesisBlockAddress)
+		if err != nil {
+			return err
+		}
+		if review == nil || review.RpcInfo == "" {
+			return errors.New("rpc endpoint not set")
+		}
+		var info base.RegisterSidechainRpcInfo
+		if err := json.Unmarshal([]byte(review.RpcInfo), &info); err != nil {
+			return err
+		}
+		node.Rpc = &config.RpcConfig{
+			IpAddress:    info.IpAddr,
+			HttpJsonPort: info.Httpjsonport,
+			User:         info.User,
+			Pass:         registrationPasses[txHash],
+		}
+	}
+
+	reason, err := probeSideNode(rsc, node.Rpc)
+	if err != nil {
+		return fmt.Errorf("probe side node failed: %v", err)
+	}
+	if reason != "" {
+		if err := rejectRegisteredSideChain(transaction, reason); err != nil {
+			return err
+		}
+		return errors.New("rejected: " + reason)
+	}
+
+	exchangeRate, err := strconv.ParseFloat(rsc.ExchangeRate.String(), 64)
+	if err != nil {
+		return errors.New("exchangeRate convert error:" + err.Error())
+	}
+	node.Name = rsc.SideChainName
+	node.ExchangeRate = exchangeRate
+	node.EffectiveHeight = rsc.EffectiveHeight
+	node.GenesisBlockAddress = transaction.GenesisBlockAddress
+	node.GenesisBlock = common.ToReversedString(rsc.GenesisHash)
+	side := &SideChainImpl{
+		Key:           transaction.GenesisBlockAddress,
+		CurrentConfig: node,
+	}
+
+	// try create side chain db
+	db, err := store.CreateSideChainDBByConfig(side.CurrentConfig)
+	if err != nil {
+		return errors.New("CreateSideChainDBByConfig err:" + err.Error())
+	}
+	store.DbCache.SideChainStore = append(store.DbCache.SideChainStore, db)
+	sideManager.AddChain(transaction.GenesisBlockAddress, side)
+	SideChainAccountMonitor.AddListener(side)
+	go SideChainAccountMonitor.SyncChainData(side.CurrentConfig, side, rsc.EffectiveHeight)
+	err = store.DbCache.RegisteredSideChainStore.RemoveRegisteredSideChainTx(transaction.TransactionHash, transaction.GenesisBlockAddress)
+	if err != nil {
+		return errors.New("RemoveRegisteredSideChainTx " + err.Error())
+	}
+	writer := new(bytes.Buffer)
+	rsc.Serialize(writer)
+	err = store.FinishedTxsDbCache.AddSucceedRegisterTx(transaction.TransactionHash, transaction.GenesisBlockAddress, writer.Bytes())
+	if err != nil {
+		return errors.New("AddSucceedRegisterTxs " + err.Error())
+	}
+	nodeData, _ := json.Marshal(withoutPass(node))
+	if err := store.RegistrationDbCache.SetRegistrationReview(&store.RegistrationReview{
+		TransactionHash:     transaction.TransactionHash,
+		GenesisBlockAddress: transaction.GenesisBlockAddress,
+		Status:              store.RegistrationApproved,
+		SideNodeConfig:      string(nodeData),
+	}); err != nil {
+		log.Warn("[ApproveRegisteredSideChain] record review failed:", err)
+	}
+
+	delete(registrationPasses, txHash)
+
+	config.AddSideNode(side.CurrentConfig)
+	if arbitrator.CircuitBreakerSingleton != nil {
+		arbitrator.CircuitBreakerSingleton.AddSideNode(side.CurrentConfig)
+	}
+	rpc.RegisterEndpoints(node.Name, node.Endpoints())
+	return nil
+}
+
+// LoadApprovedSideChains activates the side chains approved before restart
+// from the reviews recorded, side chains in SideNodeList are skipped. The rpc
+// password is not recorded, so a side node requiring one should be added to
+// SideNodeList instead.
+func LoadApprovedSideChains(current arbitrator.Arbitrator) error {
+	reviews, err := store.RegistrationDbCache.GetRegistrationReviews()
+	if err != nil {
+		return err
+	}
+	for _, review := range reviews {
+		if review.Status != store.RegistrationApproved || review.SideNodeConfig == "" {
+			continue
+		}
+		if _, ok := current.GetSideChainManager().GetChain(review.GenesisBlockAddress); ok {
+			continue
+		}
+		node := &config.SideNodeConfig{}
+		if err := json.Unmarshal([]byte(review.SideNodeConfig), node); err != nil {
+			return fmt.Errorf("side node config of %s: %v", review.GenesisBlockAddress, err)
+		}
+		if node.Rpc == nil {
+			return fmt.Errorf("side node config of %s: rpc not set", review.GenesisBlockAddress)
+		}
+		if node.Rpc.User != "" {
+			log.Warn("Approved sidechain ", node.Name, " requires rpc password, add it to SideNodeList")
+		}
+
+		db, err := store.CreateSideChainDBByConfig(node)
+		if err != nil {
+			return errors.New("CreateSideChainDBByConfig err:" + err.Error())
+		}
+		store.DbCache.SideChainStore = append(store.DbCache.SideChainStore, db)
+		current.GetSideChainManager().AddChain(node.GenesisBlockAddress, &SideChainImpl{
+			Key:           node.GenesisBlockAddress,
+			CurrentConfig: node,
+		})
+		config.AddSideNode(node)
+		if arbitrator.CircuitBreakerSingleton != nil {
+			arbitrator.CircuitBreakerSingleton.AddSideNode(node)
+		}
+		rpc.RegisterEndpoints(node.Name, node.Endpoints())
+		log.Info("Load approved sidechain ", node.Name, " ", node.GenesisBlockAddress)
+	}
+	return nil
+}
+
+// withoutPass returns a copy of node without the rpc passwords.
+func withoutPass(node *config.SideNodeConfig) *config.SideNodeConfig {
+	n := *node
+	n.Rpc, n.RpcList = nil, nil
+	for i, rpcConfig := range node.Endpoints() {
+		if rpcConfig == nil {
+			continue
+		}
+		c := *rpcConfig
+		c.Pass = ""
+		if i == 0 {
+			n.Rpc = &c
+		} else {
+			n.RpcList = append(n.RpcList, &c)
+		}
+	}
+	return &n
+}
+
+// RejectRegisteredSideChain rejects the registered side chain with the
+// reason, it is never activated.
+func (sideManager *SideChainManagerImpl) RejectRegisteredSideChain(txHash, reason string) error {
+	registrationLock.Lock()
+	defer registrationLock.Unlock()
+
+	transaction, err := getRegisteredSideChainTx(txHash)
+	if err != nil {
+		return err
+	}
+	delete(registrationPasses, txHash)
+	return rejectRegisteredSideChain(transaction, reason)
+}
+
+func getRegisteredSideChainTx(txHash string) (*base.RegisteredSideChainTransaction, error) {
+	txs, err := store.DbCache.RegisteredSideChainStore.GetAllRegisteredSideChainTxs()
+	if err != nil {
+		return nil, err
+	}
+	for _, transaction := range txs {
+		if transaction.TransactionHash == txHash {
+			return transaction, nil
+		}
+	}
+	return nil, errors.New("not found in pending registrations")
+}
+
+func rejectRegisteredSideChain(transaction *base.RegisteredSideChainTransaction, reason string) error {
+	log.Warn("Register sidechain ", transaction.RegisteredSideChain.SideChainName, " rejected: ", reason)
+	err := store.DbCache.RegisteredSideChainStore.RemoveRegisteredSideChainTx(transaction.TransactionHash, transaction.GenesisBlockAddress)
+	if err != nil {
+		return errors.New("RemoveRegisteredSideChainTx " + err.Error())
+	}
+	err = store.FinishedTxsDbCache.AddFailedRegisterTxs([]string{transaction.TransactionHash},
+		[]string{transaction.GenesisBlockAddress})
+	if err != nil {
+		return errors.New("AddFailedRegisterTxs " + err.Error())
+	}
+	return store.RegistrationDbCache.SetRegistrationReview(&store.RegistrationReview{
+		TransactionHash:     transaction.TransactionHash,
+		GenesisBlockAddress: transaction.GenesisBlockAddress,
+		Status:              store.RegistrationRejected,
+		Reason:              reason,
+	})
+}
+
+// probeSideNode returns the reason if the side node does not serve the
+// registered side chain, the magic number is only compared if the side node
+// reports it.
+func probeSideNode(rsc *base.RegisteredSideChain, rpcConfig *config.RpcConfig) (string, error) {
+	block, err := rpc.GetBlockByHeight(0, rpcConfig)
+	if err != nil {
+		return "", err
+	}
+	// the block hash is returned in reversed hex by side nodes
+	genesisHash := common.ToReversedString(rsc.GenesisHash)
+	if !strings.EqualFold(block.Hash, genesisHash) {
+		return fmt.Sprintf("genesis block %s of side node does not match %s",
+			block.Hash, genesisHash), nil
+	}
+	magic, reported, err := rpc.GetNodeMagic(rpcConfig)
+	if err != nil {
+		return "", err
+	}
+	if reported && magic != rsc.MagicNumber {
+		return fmt.Sprintf("magic number %d of side node does not match %d",
+			magic, rsc.MagicNumber), nil
+	}
+	return "", nil
+}
@@ -0,0 +1,105 @@
+package sidechain
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA.Arbiter/arbitration/base"
+	"github.com/elastos/Elastos.ELA.Arbiter/config"
+	"github.com/elastos/Elastos.ELA.Arbiter/log"
+
+	"github.com/elastos/Elastos.ELA/common"
+)
+
+func TestMain(m *testing.M) {
+	dir, err := ioutil.TempDir("", "arbiter-sidechain")
+	if err != nil {
+		panic(err)
+	}
+	log.Init(filepath.Join(dir, "logs"), 1, 0, 0)
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// newTestSideNode returns a side node responding the results of methods.
+func newTestSideNode(t *testing.T, results map[string]string) *config.RpcConfig {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string `json:"method"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		result, ok := results[req.Method]
+		if !ok {
+			fmt.Fprint(w, `{"id":0,"jsonrpc":"2.0","result":null,"error":{"code":-32601,"message":"method not found"}}`)
+			return
+		}
+		fmt.Fprintf(w, `{"id":0,"jsonrpc":"2.0","result":%s,"error":null}`, result)
+	}))
+	t.Cleanup(server.Close)
+	host, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	httpJsonPort, _ := strconv.Atoi(port)
+	return &config.RpcConfig{IpAddress: host, HttpJsonPort: httpJsonPort}
+}
+
+func TestProbeSideNode(t *testing.T) {
+	rsc := &base.RegisteredSideChain{
+		SideChainName: "ESC",
+		MagicNumber:   2019000,
+		GenesisHash:   common.Uint256{1, 2, 3},
+	}
+	// side nodes return the block hash in reversed hex
+	genesis := fmt.Sprintf(`{"hash":"%s","height":0}`, strings.ToUpper(common.ToReversedString(rsc.GenesisHash)))
+	unreversed := fmt.Sprintf(`{"hash":"%s","height":0}`, rsc.GenesisHash.String())
+	cases := []struct {
+		results map[string]string
+		reason  string
+	}{
+		{map[string]string{"getblockbyheight": genesis, "getnodestate": `{"magic":2019000}`}, ""},
+		{map[string]string{"getblockbyheight": genesis, "getnodestate": `{"height":10}`}, ""},
+		{map[string]string{"getblockbyheight": `{"hash":"00","height":0}`, "getnodestate": `{}`}, "genesis block"},
+		{map[string]string{"getblockbyheight": unreversed, "getnodestate": `{}`}, "genesis block"},
+		{map[string]string{"getblockbyheight": genesis, "getnodestate": `{"magic":2019001}`}, "magic number"},
+	}
+	for i, c := range cases {
+		reason, err := probeSideNode(rsc, newTestSideNode(t, c.results))
+		if err != nil || c.reason == "" && reason != "" || !strings.Contains(reason, c.reason) {
+			t.Errorf("case %d: expect %q, got %q, %v", i, c.reason, reason, err)
+		}
+	}
+
+	unreachable := newTestSideNode(t, nil)
+	if _, err := probeSideNode(rsc, unreachable); err == nil {
+		t.Error("side node failed to respond should not be regarded as a mismatch")
+	}
+}
+
+func TestWithoutPass(t *testing.T) {
+	node := &config.SideNodeConfig{
+		Rpc:     &config.RpcConfig{IpAddress: "127.0.0.1", HttpJsonPort: 20606, User: "user", Pass: "pass"},
+		RpcList: []*config.RpcConfig{{IpAddress: "127.0.0.2", HttpJsonPort: 20606, User: "user", Pass: "pass"}},
+		Name:    "ID",
+	}
+	stored := withoutPass(node)
+
+	if stored.Name != "ID" || stored.Rpc.User != "user" || len(stored.RpcList) != 1 ||
+		stored.RpcList[0].IpAddress != "127.0.0.2" {
+		t.Errorf("side node config should be kept, got %+v", stored)
+	}
+	if stored.Rpc.Pass != "" || stored.RpcList[0].Pass != "" {
+		t.Error("rpc password should not be stored")
+	}
+	if node.Rpc.Pass != "pass" || node.RpcList[0].Pass != "pass" {
+		t.Error("rpc password of side node in use should be kept")
+	}
+}
@@ -3,20 +3,20 @@ package sidechain
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"sync"
 	"time"
 
 	"github.com/elastos/Elastos.ELA.Arbiter/arbitration/arbitrator"
 	"github.com/elastos/Elastos.ELA.Arbiter/arbitration/base"
 	"github.com/elastos/Elastos.ELA.Arbiter/config"
+	"github.com/elastos/Elastos.ELA.Arbiter/events"
 	"github.com/elastos/Elastos.ELA.Arbiter/log"
 	"github.com/elastos/Elastos.ELA.Arbiter/rpc"
 	"github.com/elastos/Elastos.ELA.Arbiter/store"
-	"github.com/elastos/Elastos.ELA/core/types/outputpayload"
 
 	"github.com/elastos/Elastos.ELA/common"
 	"github.com/elastos/Elastos.ELA/core/contract"
-	elacommon "github.com/elastos/Elastos.ELA/core/types/common"
 	"github.com/elastos/Elastos.ELA/core/types/payload"
 )
 
@@ -103,6 +103,8 @@ func (monitor *SideChainAccountMonitorImpl) SyncChainData(sideNode *config.SideN
 		return
 	}
 
+	var lastSyncHeight uint32
+	lastSyncTime := time.Now()
 	for {
 		time.Sleep(time.Millisecond * config.Parameters.SideChainMonitorScanInterval)
 		if effectiveHeight != 0 {
@@ -115,9 +117,26 @@ func (monitor *SideChainAccountMonitorImpl) SyncChainData(sideNode *config.SideN
 			log.Info("Not initialized yet")
 			continue
 		}
+		if arbitrator.IsSideChainRetired(sideNode.GenesisBlockAddress) {
+			log.Info("[SyncSideChain] Side chain [", sideNode.GenesisBlockAddress, "] retired, stop syncing")
+			return
+		}
 		log.Info("side chain SyncChainData ,", sideNode.SupportQuickRecharge, sideNode.Rpc.IpAddress, sideNode.Rpc.HttpJsonPort)
 		chainHeight, currentHeight, needSync := monitor.needSyncBlocks(sideNode.GenesisBlockAddress, sideNode.Rpc)
 		log.Info("chainheight , currentHeight ", chainHeight, currentHeight)
+		if !needSync && chainHeight != 0 && arbitrator.IsSideChainDecommissioned(sideNode.GenesisBlockAddress) &&
+			monitor.drained(dbStore, sideNode.GenesisBlockAddress) {
+			if err := monitor.retire(sideNode.GenesisBlockAddress); err != nil {
+				log.Error("[SyncSideChain] retire side chain [", sideNode.GenesisBlockAddress, "] failed:", err)
+				continue
+			}
+			return
+		}
+		if currentHeight > lastSyncHeight {
+			lastSyncHeight, lastSyncTime = currentHeight, time.Now()
+		} else {
+			monitor.checkSyncStalled(sideNode.GenesisBlockAddress, lastSyncHeight, lastSyncTime)
+		}
 		if needSync {
 			if currentHeight < sideNode.SyncStartHeight {
 				currentHeight = sideNode.SyncStartHeight
@@ -176,6 +195,17 @@ func (monitor *SideChainAccountMonitorImpl) SyncChainData(sideNode *config.SideN
 							payload.SidechainIllegalEvidence{*se}
 					}
 
+					message := fmt.Sprintf("illegal evidence of type %d at height %d, signer %s",
+						evidence.IllegalType, evidence.Height, e.IllegalSigner)
+					events.Notify(events.ETIllegalEvidenceFound, &events.Alert{
+						GenesisBlockAddress: sideNode.GenesisBlockAddress,
+						Subject:             evidence.Evidence.DataHash.String(),
+						Message:             message,
+					})
+					if arbitrator.CircuitBreakerSingleton != nil {
+						arbitrator.CircuitBreakerSingleton.TripIllegalEvidence(sideNode.GenesisBlockAddress,
+							message, time.Now())
+					}
 					if err := monitor.fireIllegalEvidenceFound(
 						evidence); err != nil {
 						log.Error("fire illegal evidence found error:",
@@ -223,74 +253,11 @@ func (monitor *SideChainAccountMonitorImpl) SyncChainData(sideNode *config.SideN
 							log.Errorf(err.Error())
 							continue
 						}
-						referTxid := originTx.Inputs()[0].Previous.TxID
-						referIndex := originTx.Inputs()[0].Previous.Index
-						referReversedTx := common.BytesToHexString(common.BytesReverse(referTxid.Bytes()))
-						referTxn, err := rpc.GetTransaction(referReversedTx, config.Parameters.MainNode.Rpc)
+						failedTx, err := arbitrator.NewFailedDepositTx(originTx, sideNode.GenesisBlockAddress)
 						if err != nil {
-							log.Errorf(err.Error())
-							continue
-						}
-						address, err := referTxn.Outputs()[referIndex].ProgramHash.ToAddress()
-						if err != nil {
-							log.Error("program hash to address error", err.Error())
+							log.Error("[MoniterFailedDepositTransfer] ", err.Error())
 							continue
 						}
-						crossChainHash, err := common.Uint168FromAddress(sideNode.GenesisBlockAddress)
-						if err != nil {
-							log.Error("GenesisBlockAddress to hash error", err.Error())
-							continue
-						}
-						originHash := originTx.Hash()
-						var depositAmount common.Fixed64
-						var crossChainAmount common.Fixed64
-						switch originTx.PayloadVersion() {
-						case payload.TransferCrossChainVersion:
-							p, ok := originTx.Payload().(*payload.TransferCrossChainAsset)
-							if !ok {
-								log.Error("Invalid payload type need TransferCrossChainAsset")
-								continue
-							}
-
-							for i, cca := range p.CrossChainAmounts {
-								idx := p.OutputIndexes[i]
-								// output to current side chain
-								if !crossChainHash.IsEqual(originTx.Outputs()[idx].ProgramHash) {
-									continue
-								}
-								amount := originTx.Outputs()[idx].Value
-								depositAmount += amount
-								crossChainAmount += cca
-							}
-						case payload.TransferCrossChainVersionV1:
-							_, ok := originTx.Payload().(*payload.TransferCrossChainAsset)
-							if !ok {
-								log.Error("Invalid payload type need TransferCrossChainAsset")
-								continue
-							}
-							for _, o := range originTx.Outputs() {
-								if o.Type != elacommon.OTCrossChain {
-									continue
-								}
-								// output to current side chain
-								if !crossChainHash.IsEqual(o.ProgramHash) {
-									continue
-								}
-								p, ok := o.Payload.(*outputpayload.CrossChainOutput)
-								if !ok {
-									continue
-								}
-								depositAmount += o.Value
-								crossChainAmount += p.TargetAmount
-							}
-						}
-						failedTx := &base.FailedDepositTx{
-							Txid: &originHash,
-							DepositInfo: &base.DepositInfo{
-								TargetAddress:    address,
-								Amount:           &depositAmount,
-								CrossChainAmount: &crossChainAmount,
-							}}
 						failedTxs = append(failedTxs, failedTx)
 						buf := new(bytes.Buffer)
 						err = failedTx.Serialize(buf)
@@ -352,6 +319,63 @@ func (monitor *SideChainAccountMonitorImpl) SyncChainData(sideNode *config.SideN
 	}
 }
 
+// drained returns if the decommissioned side chain has no withdraw, return
+// deposit or deposit transaction waiting to be processed.
+func (monitor *SideChainAccountMonitorImpl) drained(dbStore store.DataStoreSideChain, genesisBlockAddress string) bool {
+	withdraws, err := dbStore.GetAllSideChainTxHashes()
+	if err != nil || len(withdraws) != 0 {
+		return false
+	}
+	_, returnDeposits, err := dbStore.GetAllReturnDepositTx(genesisBlockAddress)
+	if err != nil || len(returnDeposits) != 0 {
+		return false
+	}
+	_, addresses, err := store.DbCache.MainChainStore.GetAllMainChainTxHashes()
+	if err != nil {
+		return false
+	}
+	for _, addr := range addresses {
+		if addr == genesisBlockAddress {
+			return false
+		}
+	}
+	return true
+}
+
+// retire stops processing the drained side chain, the SPV listeners of it
+// ignore the transactions notified since they can not be unregistered.
+func (monitor *SideChainAccountMonitorImpl) retire(genesisBlockAddress string) error {
+	if err := arbitrator.DecommissionSingleton.Retire(genesisBlockAddress); err != nil {
+		return err
+	}
+	monitor.RemoveListener(genesisBlockAddress)
+	arbitrator.ArbitratorGroupSingleton.GetCurrentArbitrator().GetSideChainManager().RemoveChain(genesisBlockAddress)
+	log.Info("[SyncSideChain] Side chain [", genesisBlockAddress, "] drained and retired")
+	events.Notify(events.ETSideChainRetired, &events.Alert{
+		GenesisBlockAddress: genesisBlockAddress,
+		Subject:             genesisBlockAddress,
+		Message:             "side chain drained and retired",
+	})
+	return nil
+}
+
+func (monitor *SideChainAccountMonitorImpl) checkSyncStalled(genesisBlockAddress string, height uint32, since time.Time) {
+	cfg := config.Parameters.Notifier
+	if cfg == nil || cfg.SyncStallTimeout == 0 {
+		return
+	}
+	stalled := time.Since(since)
+	if stalled < time.Millisecond*cfg.SyncStallTimeout {
+		return
+	}
+	events.Notify(events.ETSideChainSyncStalled, &events.Alert{
+		GenesisBlockAddress: genesisBlockAddress,
+		Subject:             "sync",
+		Message: fmt.Sprintf("side chain synced height stays at %d for %s",
+			height, stalled.Truncate(time.Second)),
+	})
+}
+
 func (monitor *SideChainAccountMonitorImpl) needSyncBlocks(genesisBlockAddress string, config *config.RpcConfig) (uint32, uint32, bool) {
 
 	chainHeight, err := rpc.GetCurrentHeight(config)
@@ -389,11 +413,6 @@ func (monitor *SideChainAccountMonitorImpl) processTransactions(transactions []*
 			continue
 		}
 
-		frozenAddressMap := make(map[string]bool)
-		for _, frozenAddress := range config.Parameters.FrozenAddresses {
-			frozenAddressMap[frozenAddress] = true
-		}
-
 		var withdrawAssets []*base.WithdrawAsset
 		for _, withdraw := range txn.CrossChainAssets {
 			opAmount, err := common.StringToFixed64(withdraw.OutputAmount)
@@ -406,25 +425,6 @@ func (monitor *SideChainAccountMonitorImpl) processTransactions(transactions []*
 				log.Warn("Find output to destroy address, but have invalid cross chain amount")
 				continue
 			}
-			programHash, err := common.Uint168FromAddress(withdraw.CrossChainAddress)
-			if err != nil {
-				log.Warn("invalid withdraw cross chain address:", withdraw.CrossChainAddress)
-				continue
-			}
-			if _, ok := frozenAddressMap[withdraw.CrossChainAddress]; ok {
-				log.Warn("invalid withdraw cross chain address in frozen address list:", withdraw.CrossChainAddress)
-				continue
-			}
-			addr, err := programHash.ToAddress()
-			if err != nil || addr != withdraw.CrossChainAddress {
-				log.Warn("invalid withdraw cross chain address:", withdraw.CrossChainAddress)
-				continue
-			}
-			// if contract.PrefixType(programHash[0]) != contract.PrefixStandard &&
-			// 	contract.PrefixType(programHash[0]) != contract.PrefixMultiSig {
-			// 	log.Warn("invalid withdraw cross chain address:", withdraw.CrossChainAddress)
-			// 	continue
-			// }
 
 			withdrawAssets = append(withdrawAssets, &base.WithdrawAsset{
 				TargetAddress:    withdraw.CrossChainAddress,
@@ -444,6 +444,18 @@ func (monitor *SideChainAccountMonitorImpl) processTransactions(transactions []*
 				WithdrawAssets: withdrawAssets,
 			},
 		}
+		// The invalid withdraw is kept with the reason to be reported to the
+		// side chain, the one held by the policy or blocked by a frozen
+		// address is kept as usual and checked again before proposed.
+		if err := arbitrator.CheckWithdrawTx(genesisAddress, withdrawTx); arbitrator.IsWithdrawInvalid(err) {
+			log.Warn("withdraw transaction ", hash, " invalid: ", err)
+			withdrawTx.RejectReason = err.Error()
+			for _, asset := range withdrawAssets {
+				if len(asset.TargetData) > int(base.MaxTargetDataSize) {
+					asset.TargetData = nil
+				}
+			}
+		}
 
 		reversedTxnHash := common.BytesToHexString(reversedTxnBytes)
 		dbStore := store.DbCache.GetDataStoreGenesisBlocAddress(genesisAddress)
@@ -459,6 +471,21 @@ func (monitor *SideChainAccountMonitorImpl) processTransactions(transactions []*
 		err := monitor.fireUTXOChanged(withdrawTxs, genesisAddress, blockHeight)
 		if err != nil {
 			log.Error("[fireUTXOChanged] err:", err.Error())
+			return
+		}
+		if arbitrator.CircuitBreakerSingleton != nil {
+			arbitrator.CircuitBreakerSingleton.RecordWithdraws(genesisAddress, withdrawTxs, time.Now())
+		}
+		for _, withdrawTx := range withdrawTxs {
+			var addresses []string
+			for _, asset := range withdrawTx.WithdrawInfo.WithdrawAssets {
+				addresses = append(addresses, asset.TargetAddress)
+			}
+			events.Notify(events.ETWithdrawFound, &events.CrossChainTx{
+				GenesisBlockAddress:        genesisAddress,
+				SideChainTransactionHashes: []string{withdrawTx.Txid.String()},
+				Addresses:                  addresses,
+			})
 		}
 	}
 }
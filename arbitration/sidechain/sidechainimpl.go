@@ -21,19 +21,18 @@ import (
 	"github.com/elastos/Elastos.ELA/elanet/pact"
 )
 
-const MinCrossChainTxFee common.Fixed64 = 10000
-
 type SideChainImpl struct {
 	mux sync.Mutex
 
 	Key           string
 	CurrentConfig *config.SideNodeConfig
-	DoneSmallCrs  map[string]bool
 }
 
+// IsSendSmallCrxTx returns if the small cross transfer has been accepted by
+// or appeared in the side chain.
 func (sc *SideChainImpl) IsSendSmallCrxTx(tx string) bool {
-	_, ok := sc.DoneSmallCrs[tx]
-	return ok
+	known, err := store.SmallCrossTxsDbCache.GetSmallCrossTx(tx, sc.GetKey())
+	return err == nil && (known.State == store.SmallCrossTxSent || known.State == store.SmallCrossTxFinished)
 }
 
 func (sc *SideChainImpl) GetKey() string {
@@ -48,7 +47,7 @@ func (sc *SideChainImpl) getCurrentConfig() *config.SideNodeConfig {
 	sc.mux.Lock()
 	defer sc.mux.Unlock()
 	if sc.CurrentConfig == nil {
-		for _, sideConfig := range config.Parameters.SideNodeList {
+		for _, sideConfig := range config.SideNodes() {
 			if sc.GetKey() == sideConfig.GenesisBlockAddress {
 				sc.CurrentConfig = sideConfig
 				break
@@ -74,6 +73,10 @@ func (sc *SideChainImpl) GetCurrentHeight() (uint32, error) {
 	return rpc.GetCurrentHeight(sc.getCurrentConfig().Rpc)
 }
 
+func (sc *SideChainImpl) GetAuxBlockHash() (common.Uint256, error) {
+	return sideauxpow.GetAuxBlockHash(sc.getCurrentConfig())
+}
+
 func (sc *SideChainImpl) GetBlockByHeight(height uint32) (*base.BlockInfo, error) {
 	return rpc.GetBlockByHeight(height, sc.getCurrentConfig().Rpc)
 }
@@ -107,8 +110,7 @@ func (sc *SideChainImpl) SendSmallCrossTransaction(tx string, signature []byte,
 
 	if response.Error != nil {
 		log.Info("response: ", response.Error.Message)
-	} else if r, ok := response.Result.(bool); ok && r {
-		sc.DoneSmallCrs[hash] = true
+	} else {
 		log.Info("response:", response)
 	}
 
@@ -130,10 +132,16 @@ func (sc *SideChainImpl) GetProcessedInvalidWithdrawTransactions(txs []string) (
 	return removeTxs, nil
 }
 
-func (sc *SideChainImpl) SendInvalidWithdrawTransaction(signature []byte, hash string) (rpc.Response, error) {
+// SendInvalidWithdrawTransaction reports the invalid withdraw transaction to
+// the side chain, reason is why it is rejected by the withdraw rules.
+func (sc *SideChainImpl) SendInvalidWithdrawTransaction(signature []byte, hash, reason string) (rpc.Response, error) {
+	if arbitrator.IsWithdrawPaused(sc.GetKey()) {
+		return rpc.Response{}, errors.New("withdraws of side chain are paused")
+	}
 	log.Info("[Rpc-SendInvalidWithdrawTransaction] Send to side chain：", sc.CurrentConfig.Rpc.IpAddress, ":", sc.CurrentConfig.Rpc.HttpJsonPort)
 	response, err := rpc.CallAndUnmarshalResponse("sendinvalidwithdrawtransaction",
-		rpc.Param("signature", hex.EncodeToString(signature)).Add("txHash", hash), sc.CurrentConfig.Rpc)
+		rpc.Param("signature", hex.EncodeToString(signature)).Add("txHash", hash).Add("reason", reason),
+		sc.CurrentConfig.Rpc)
 	if err != nil {
 		return rpc.Response{}, err
 	}
@@ -169,6 +177,7 @@ func (sc *SideChainImpl) OnUTXOChanged(withdrawTxs []*base.WithdrawTx, blockHeig
 			TransactionHash: withdrawTx.Txid.String(),
 			Transaction:     buf.Bytes(),
 			BlockHeight:     blockHeight,
+			RejectReason:    withdrawTx.RejectReason,
 		})
 	}
 
@@ -309,7 +318,9 @@ func (sc *SideChainImpl) SendCachedWithdrawTxs(currentHeight uint32) {
 	}
 
 	unsolvedTxs, _ := base.SubstractTransactionHashesAndBlockHeights(txHashes, blockHeights, receivedTxs)
-	if len(unsolvedTxs) != 0 {
+	if arbitrator.IsWithdrawPaused(sc.GetKey()) {
+		log.Info("[SendCachedWithdrawTxs] withdraws of side chain ", sc.GetKey(), " are paused")
+	} else if len(unsolvedTxs) != 0 {
 		err := sc.CreateAndBroadcastWithdrawProposal(unsolvedTxs)
 		if err != nil {
 			log.Error("[SendCachedWithdrawTxs] CreateAndBroadcastWithdrawProposal failed" + err.Error())
@@ -408,7 +419,9 @@ func (sc *SideChainImpl) SendCachedReturnDepositTxs() {
 	}
 
 	unsolvedTxs, indexes := base.SubstractReturnDepositTransactionHashes(txHashes, receivedTxs)
-	if len(unsolvedTxs) != 0 {
+	if arbitrator.IsDepositPaused(sc.GetKey()) {
+		log.Info("[SendCachedReturnDepositTxs] deposits of side chain ", sc.GetKey(), " are paused")
+	} else if len(unsolvedTxs) != 0 {
 		var failedTxs []*base.FailedDepositTx
 		for _, index := range indexes {
 			if len(txBytes) <= index {
@@ -442,6 +455,9 @@ func (sc *SideChainImpl) SendCachedReturnDepositTxs() {
 }
 
 func (sc *SideChainImpl) CreateAndBroadcastWithdrawProposal(txnHashes []string) error {
+	if arbitrator.IsWithdrawPaused(sc.GetKey()) {
+		return errors.New("withdraws of side chain are paused")
+	}
 
 	dbStore := store.DbCache.GetDataStoreByDBName(sc.CurrentConfig.Name)
 	if dbStore == nil {
@@ -456,36 +472,30 @@ func (sc *SideChainImpl) CreateAndBroadcastWithdrawProposal(txnHashes []string)
 		return nil
 	}
 
-	frozenAddressMap := make(map[string]bool)
-	for _, frozenAddress := range config.Parameters.FrozenAddresses {
-		frozenAddressMap[frozenAddress] = true
+	if err := store.PendingTxsDbCache.AddPendingTxsAttempt(store.PendingWithdraw,
+		txnHashes, dbStore.GenesisBlockAddress()); err != nil {
+		log.Warn("[CreateAndBroadcastWithdrawProposal] record withdraw attempts failed:", err)
 	}
 
 	targetTransactions := make([]*base.WithdrawTx, 0)
 	for _, tx := range unsolvedTransactions {
-		ignore := false
-		for _, w := range tx.WithdrawInfo.WithdrawAssets {
-			if *w.CrossChainAmount <= 0 ||
-				*w.Amount-*w.CrossChainAmount < MinCrossChainTxFee {
-				ignore = true
-				break
-			}
-			_, err := common.Uint168FromAddress(w.TargetAddress)
-			if err != nil {
-				ignore = true
-				break
-			}
-			if _, ok := frozenAddressMap[w.TargetAddress]; ok {
-				ignore = true
-				break
-			}
-		}
-		if ignore {
+		if tx.RejectReason != "" {
 			continue
 		}
-		if len(tx.WithdrawInfo.WithdrawAssets) != 0 {
-			targetTransactions = append(targetTransactions, tx)
+		if err := arbitrator.CheckWithdrawTx(sc.GetKey(), tx); err != nil {
+			if arbitrator.IsWithdrawBlocked(err) {
+				log.Info("[CreateAndBroadcastWithdrawProposal] withdraw transaction ", tx.Txid, " blocked: ", err)
+			} else if arbitrator.IsWithdrawInvalid(err) {
+				log.Warn("[CreateAndBroadcastWithdrawProposal] withdraw transaction ", tx.Txid, " invalid: ", err)
+				if err := dbStore.SetSideChainTxRejectReason(tx.Txid.String(), err.Error()); err != nil {
+					log.Warn("[CreateAndBroadcastWithdrawProposal] record reject reason failed:", err)
+				}
+			} else {
+				log.Info("[CreateAndBroadcastWithdrawProposal] withdraw transaction ", tx.Txid, " held: ", err)
+			}
+			continue
 		}
+		targetTransactions = append(targetTransactions, tx)
 	}
 
 	currentArbitrator := arbitrator.ArbitratorGroupSingleton.GetCurrentArbitrator()
@@ -7,6 +7,7 @@ import (
 	"io/ioutil"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/elastos/Elastos.ELA.Arbiter/arbitration/base"
@@ -14,6 +15,7 @@ import (
 	"github.com/elastos/Elastos.ELA/common"
 	elacfg "github.com/elastos/Elastos.ELA/common/config"
 	elacore "github.com/elastos/Elastos.ELA/core"
+	"github.com/elastos/Elastos.ELA/core/contract"
 )
 
 const (
@@ -33,6 +35,7 @@ var (
 	SpvDir     = "spv"
 	LogDir     = "logs"
 	ArbiterDir = "arbiter"
+	SignerDir  = "signer"
 )
 
 type RpcConfiguration struct {
@@ -64,29 +67,191 @@ type Configuration struct {
 	MaxConnections               int           `json:"MaxConnections"`
 	//defines max nodes that one host can establish
 	MaxNodePerHost                  uint32
-	SideAuxPowFee                   int              `json:"SideAuxPowFee"`
-	MinThreshold                    int              `json:"MinThreshold"`
-	SmallCrossTransferThreshold     common.Fixed64   `json:"SmallCrossTransferThreshold"`
-	DepositAmount                   int              `json:"DepositAmount"`
-	CRCOnlyDPOSHeight               uint32           `json:"CRCOnlyDPOSHeight"`
-	CRClaimDPOSNodeStartHeight      uint32           `json:"CRClaimDPOSNodeStartHeight"`
-	NewP2PProtocolVersionHeight     uint64           `json:"NewP2PProtocolVersionHeight"`
-	DPOSNodeCrossChainHeight        uint32           `json:"DPOSNodeCrossChainHeight"`
-	MaxTxsPerWithdrawTx             int              `json:"MaxTxsPerWithdrawTx"`
-	OriginCrossChainArbiters        []string         `json:"OriginCrossChainArbiters"`
-	CRCCrossChainArbiters           []string         `json:"CRCCrossChainArbiters"`
-	RpcConfiguration                RpcConfiguration `json:"RpcConfiguration"`
-	DPoSNetAddress                  string           `json:"DPoSNetAddress"`
-	ReturnDepositTransactionFee     common.Fixed64   `json:"ReturnDepositTransactionFee"`
-	NewCrossChainTransactionHeight  uint32           `json:"NewCrossChainTransactionHeight"`
-	ProcessInvalidWithdrawHeight    uint32           `json:"ProcessInvalidWithdrawHeight"`
-	WalletPath                      string           `json:"WalletPath"`
-	ReturnCrossChainCoinStartHeight uint32           `json:"ReturnCrossChainCoinStartHeight"`
-	SchnorrStartHeight              uint32           `json:"SchnorrStartHeight"`
-	NFTStartHeight                  uint32           `json:"NFTStartHeight"`
-	DPoSV2StartHeight               uint32           `json:"DPoSV2StartHeight"`
-	ShowPeersIp                     bool             `json:"ShowPeersIp"`
-	FrozenAddresses                 []string         `json:"FrozenAddresses"`
+	SideAuxPowFee                   int                   `json:"SideAuxPowFee"`
+	MinThreshold                    int                   `json:"MinThreshold"`
+	SmallCrossTransferThreshold     common.Fixed64        `json:"SmallCrossTransferThreshold"`
+	DepositAmount                   int                   `json:"DepositAmount"`
+	CRCOnlyDPOSHeight               uint32                `json:"CRCOnlyDPOSHeight"`
+	CRClaimDPOSNodeStartHeight      uint32                `json:"CRClaimDPOSNodeStartHeight"`
+	NewP2PProtocolVersionHeight     uint64                `json:"NewP2PProtocolVersionHeight"`
+	DPOSNodeCrossChainHeight        uint32                `json:"DPOSNodeCrossChainHeight"`
+	MaxTxsPerWithdrawTx             int                   `json:"MaxTxsPerWithdrawTx"`
+	OriginCrossChainArbiters        []string              `json:"OriginCrossChainArbiters"`
+	CRCCrossChainArbiters           []string              `json:"CRCCrossChainArbiters"`
+	RpcConfiguration                RpcConfiguration      `json:"RpcConfiguration"`
+	DPoSNetAddress                  string                `json:"DPoSNetAddress"`
+	ReturnDepositTransactionFee     common.Fixed64        `json:"ReturnDepositTransactionFee"`
+	NewCrossChainTransactionHeight  uint32                `json:"NewCrossChainTransactionHeight"`
+	ProcessInvalidWithdrawHeight    uint32                `json:"ProcessInvalidWithdrawHeight"`
+	WalletPath                      string                `json:"WalletPath"`
+	ReturnCrossChainCoinStartHeight uint32                `json:"ReturnCrossChainCoinStartHeight"`
+	SchnorrStartHeight              uint32                `json:"SchnorrStartHeight"`
+	SchnorrPartialSignatureHeight   uint32                `json:"SchnorrPartialSignatureHeight"`
+	NFTStartHeight                  uint32                `json:"NFTStartHeight"`
+	DPoSV2StartHeight               uint32                `json:"DPoSV2StartHeight"`
+	ShowPeersIp                     bool                  `json:"ShowPeersIp"`
+	FrozenAddresses                 []string              `json:"FrozenAddresses"`
+	Notifier                        *NotifierConfig       `json:"Notifier"`
+	Signer                          *SignerConfig         `json:"Signer"`
+	ProposalPolicy                  *ProposalPolicyConfig `json:"ProposalPolicy"`
+	DepositRetry                    *DepositRetryConfig   `json:"DepositRetry"`
+	HealthCheck                     *HealthCheckConfig    `json:"HealthCheck"`
+	DepositConfirmationDepth        uint32                `json:"DepositConfirmationDepth"`
+	Treasury                        *TreasuryConfig       `json:"Treasury"`
+	AuxpowRetry                     *AuxpowRetryConfig    `json:"AuxpowRetry"`
+	SideMiningFee                   *SideMiningFeeConfig  `json:"SideMiningFee"`
+	WithdrawRules                   *WithdrawRulesConfig  `json:"WithdrawRules"`
+	Reconciliation                  *ReconciliationConfig `json:"Reconciliation"`
+}
+
+type WebhookConfig struct {
+	Url    string `json:"Url"`
+	Secret string `json:"Secret"`
+}
+
+type NotifierConfig struct {
+	Webhooks         []*WebhookConfig `json:"Webhooks"`
+	MaxRetries       int              `json:"MaxRetries"`
+	RetryInterval    time.Duration    `json:"RetryInterval"`
+	DedupInterval    time.Duration    `json:"DedupInterval"`
+	SyncStallTimeout time.Duration    `json:"SyncStallTimeout"`
+}
+
+// DepositRetryConfig is the budget of resending a deposit transaction to the
+// side chain after transient failures, the deposit is declared failed once
+// MaxAttempts or MaxDuration is exceeded.
+type DepositRetryConfig struct {
+	MaxAttempts     int           `json:"MaxAttempts"`
+	InitialInterval time.Duration `json:"InitialInterval"`
+	MaxInterval     time.Duration `json:"MaxInterval"`
+	MaxDuration     time.Duration `json:"MaxDuration"`
+}
+
+// HealthCheckConfig is the health checking of side chain rpc endpoints, an
+// endpoint is healthy if it responds in Timeout and its height is not lower
+// than the highest one by more than MaxHeightLag.
+type HealthCheckConfig struct {
+	Interval     time.Duration `json:"Interval"`
+	Timeout      time.Duration `json:"Timeout"`
+	MaxHeightLag uint32        `json:"MaxHeightLag"`
+}
+
+// WithdrawRulesConfig is the rules every withdraw asset from side chains
+// should meet before it is proposed or signed, the fee of an asset is the
+// amount minus the cross chain amount.
+type WithdrawRulesConfig struct {
+	AddressPrefixes   []string       `json:"AddressPrefixes"`
+	MinFee            common.Fixed64 `json:"MinFee"`
+	MaxTargetDataSize int            `json:"MaxTargetDataSize"`
+}
+
+// ReconciliationConfig is the periodic reconciliation of the ELA locked at
+// the genesis address of every side chain against the side chain supply
+// returned by the SupplyMethod of the side node, or SupplyMethod if it is not
+// set. A discrepancy beyond Tolerance is flagged, and the reports older than
+// Retention are pruned.
+type ReconciliationConfig struct {
+	Interval     time.Duration  `json:"Interval"`
+	Tolerance    common.Fixed64 `json:"Tolerance"`
+	SupplyMethod string         `json:"SupplyMethod"`
+	Retention    time.Duration  `json:"Retention"`
+}
+
+// DefaultReconciliationRetention is the time the reconciliation reports are
+// kept if Retention is not set, in milliseconds.
+const DefaultReconciliationRetention = 30 * 24 * 3600000
+
+// GetRetention returns the time the reconciliation reports are kept,
+// DefaultReconciliationRetention if Retention is not set.
+func (r *ReconciliationConfig) GetRetention() time.Duration {
+	if r.Retention > 0 {
+		return time.Millisecond * r.Retention
+	}
+	return time.Millisecond * DefaultReconciliationRetention
+}
+
+// GetSupplyMethod returns the rpc method of the side node returning the ELA
+// circulating on the side chain, SupplyMethod if the side node does not set
+// it.
+func (r *ReconciliationConfig) GetSupplyMethod(node *SideNodeConfig) string {
+	if node.SupplyMethod != "" {
+		return node.SupplyMethod
+	}
+	return r.SupplyMethod
+}
+
+// MaxTargetDataSize is the max size of the target data of a withdraw asset
+// accepted by the main chain.
+const MaxTargetDataSize = int(base.MaxTargetDataSize)
+
+// AddressPrefixes are the address prefix types allowed to be set in
+// WithdrawRules.AddressPrefixes.
+var AddressPrefixes = map[string]contract.PrefixType{
+	"standard":   contract.PrefixStandard,
+	"multisig":   contract.PrefixMultiSig,
+	"crosschain": contract.PrefixCrossChain,
+	"deposit":    contract.PrefixDeposit,
+	"crdid":      contract.PrefixCRDID,
+	"dposv2":     contract.PrefixDPoSV2,
+}
+
+// SideMiningFeeConfig is the strategy deciding the fee of SideChainPow
+// transactions: fixed pays SideAuxPowFee, bumponmiss multiplies the last fee
+// by BumpRatio if the last transaction missed its side block and falls back
+// to SideAuxPowFee once it lands, adaptive raises the fee towards MaxFee with
+// the miss rate of the last Window transactions.
+type SideMiningFeeConfig struct {
+	Strategy  string         `json:"Strategy"`
+	MaxFee    common.Fixed64 `json:"MaxFee"`
+	BumpRatio float64        `json:"BumpRatio"`
+	Window    int            `json:"Window"`
+}
+
+// AuxpowRetryConfig is the budget of submitting an auxpow to the side chain
+// again after it is rejected or the side chain is unreachable, the auxpow is
+// given up once MaxAttempts is exceeded or the side chain has moved on.
+type AuxpowRetryConfig struct {
+	MaxAttempts     int           `json:"MaxAttempts"`
+	InitialInterval time.Duration `json:"InitialInterval"`
+	MaxInterval     time.Duration `json:"MaxInterval"`
+}
+
+// TreasuryConfig is the refilling of side chain mining accounts from the main
+// account, a refill keeps the mining account running for RunwayTarget at the
+// observed spend rate and is split into RefillOutputs utxos.
+type TreasuryConfig struct {
+	DailyBudget    common.Fixed64 `json:"DailyBudget"`
+	RefillFee      common.Fixed64 `json:"RefillFee"`
+	RefillOutputs  int            `json:"RefillOutputs"`
+	RunwayTarget   time.Duration  `json:"RunwayTarget"`
+	PendingTimeout time.Duration  `json:"PendingTimeout"`
+}
+
+type SignerConfig struct {
+	Socket                  string         `json:"Socket"`
+	AllowedTxTypes          []string       `json:"AllowedTxTypes"`
+	MaxWithdrawAmount       common.Fixed64 `json:"MaxWithdrawAmount"`
+	MaxWithdrawOutputAmount common.Fixed64 `json:"MaxWithdrawOutputAmount"`
+}
+
+// ProposalPolicyConfig is the limits applied before signing the withdraw
+// proposals of other arbiters, a limit of 0 means no limit.
+type ProposalPolicyConfig struct {
+	MaxWithdrawAmount  common.Fixed64  `json:"MaxWithdrawAmount"`
+	DailyWithdrawLimit common.Fixed64  `json:"DailyWithdrawLimit"`
+	DailyAddressLimit  common.Fixed64  `json:"DailyAddressLimit"`
+	DeniedAddresses    []string        `json:"DeniedAddresses"`
+	ApprovalRules      []*ApprovalRule `json:"ApprovalRules"`
+}
+
+// ApprovalRule matches the withdraw proposals which should be approved by
+// the operator before signing, a rule matches if all its conditions set are
+// met.
+type ApprovalRule struct {
+	Name           string         `json:"Name"`
+	SideChain      string         `json:"SideChain"`
+	MinAmount      common.Fixed64 `json:"MinAmount"`
+	MinDailyAmount common.Fixed64 `json:"MinDailyAmount"`
 }
 
 type RpcConfig struct {
@@ -96,16 +261,26 @@ type RpcConfig struct {
 	Pass         string `json:"Pass"`
 }
 
+// MainNodeConfig is the main node config, the safety-critical lookups must
+// be agreed by Quorum endpoints of Rpc and RpcList if Quorum is greater than 1.
 type MainNodeConfig struct {
-	Rpc               *RpcConfig `json:"Rpc"`
-	SpvSeedList       []string   `json:"SpvSeedList"`
-	DefaultPort       uint16     `json:"DefaultPort"`
-	Magic             uint32     `json:"Magic"`
-	FoundationAddress string     `json:"FoundationAddress"`
+	Rpc               *RpcConfig   `json:"Rpc"`
+	RpcList           []*RpcConfig `json:"RpcList,omitempty"`
+	Quorum            int          `json:"Quorum,omitempty"`
+	SpvSeedList       []string     `json:"SpvSeedList"`
+	DefaultPort       uint16       `json:"DefaultPort"`
+	Magic             uint32       `json:"Magic"`
+	FoundationAddress string       `json:"FoundationAddress"`
+}
+
+// Endpoints returns the rpc endpoints of the main node, Rpc is the first.
+func (m *MainNodeConfig) Endpoints() []*RpcConfig {
+	return append([]*RpcConfig{m.Rpc}, m.RpcList...)
 }
 
 type SideNodeConfig struct {
-	Rpc *RpcConfig `json:"Rpc"`
+	Rpc     *RpcConfig   `json:"Rpc"`
+	RpcList []*RpcConfig `json:"RpcList,omitempty"`
 
 	Name                   string  `json:"Name"`
 	ExchangeRate           float64 `json:"ExchangeRate"`
@@ -121,6 +296,55 @@ type SideNodeConfig struct {
 	SupportInvalidDeposit  bool    `json:"SupportInvalidDeposit"`
 	SupportInvalidWithdraw bool    `json:"SupportInvalidWithdraw"`
 	SupportNFT             bool    `json:"SupportNFT"`
+
+	DailyWithdrawLimit common.Fixed64 `json:"DailyWithdrawLimit,omitempty"`
+	ConfirmationDepth  uint32         `json:"ConfirmationDepth,omitempty"`
+	MinWithdrawAmount  common.Fixed64 `json:"MinWithdrawAmount,omitempty"`
+
+	WithdrawBreakerLimit  common.Fixed64 `json:"WithdrawBreakerLimit,omitempty"`
+	WithdrawBreakerWindow time.Duration  `json:"WithdrawBreakerWindow,omitempty"`
+
+	SupplyMethod string `json:"SupplyMethod,omitempty"`
+}
+
+// DefaultWithdrawBreakerWindow is the window of the withdraw volume breaker
+// if WithdrawBreakerWindow is not set, in milliseconds.
+const DefaultWithdrawBreakerWindow = 3600000
+
+// GetWithdrawBreakerWindow returns the window the withdraw volume breaker
+// counts, DefaultWithdrawBreakerWindow if WithdrawBreakerWindow is not set.
+func (s *SideNodeConfig) GetWithdrawBreakerWindow() time.Duration {
+	if s.WithdrawBreakerWindow > 0 {
+		return time.Millisecond * s.WithdrawBreakerWindow
+	}
+	return time.Millisecond * DefaultWithdrawBreakerWindow
+}
+
+// GetConfirmationDepth returns the main chain blocks needed to confirm a
+// deposit to the side chain, DepositConfirmationDepth if ConfirmationDepth is
+// not set.
+func (s *SideNodeConfig) GetConfirmationDepth() uint32 {
+	if s.ConfirmationDepth > 0 {
+		return s.ConfirmationDepth
+	}
+	return Parameters.DepositConfirmationDepth
+}
+
+// UnmarshalJSON unmarshals the side node config, PowChain is true if it is
+// not set.
+func (s *SideNodeConfig) UnmarshalJSON(data []byte) error {
+	type sideNodeConfig SideNodeConfig
+	node := sideNodeConfig{PowChain: true}
+	if err := json.Unmarshal(data, &node); err != nil {
+		return err
+	}
+	*s = SideNodeConfig(node)
+	return nil
+}
+
+// Endpoints returns the rpc endpoints of the side node, Rpc is the first.
+func (s *SideNodeConfig) Endpoints() []*RpcConfig {
+	return append([]*RpcConfig{s.Rpc}, s.RpcList...)
 }
 
 type ConfigFile struct {
@@ -131,8 +355,42 @@ type ConfigParams struct {
 	*Configuration
 }
 
-func GetRpcConfig(genesisBlockHash string) (*RpcConfig, bool) {
+// sideNodeListLock guards Parameters.SideNodeList, which is changed at
+// runtime once a registered side chain is activated.
+var sideNodeListLock sync.RWMutex
+
+// SideNodes returns a copy of Parameters.SideNodeList, it is used instead of
+// the list once the arbiter is started.
+func SideNodes() []*SideNodeConfig {
+	sideNodeListLock.RLock()
+	defer sideNodeListLock.RUnlock()
+	return append([]*SideNodeConfig(nil), Parameters.SideNodeList...)
+}
+
+// AddSideNode appends the side node activated at runtime to
+// Parameters.SideNodeList.
+func AddSideNode(node *SideNodeConfig) {
+	sideNodeListLock.Lock()
+	defer sideNodeListLock.Unlock()
+	Parameters.SideNodeList = append(Parameters.SideNodeList, node)
+}
+
+// RemoveSideNode removes the side node of the genesis block address from
+// Parameters.SideNodeList once its side chain is retired.
+func RemoveSideNode(genesisBlockAddress string) {
+	sideNodeListLock.Lock()
+	defer sideNodeListLock.Unlock()
+	nodes := make([]*SideNodeConfig, 0, len(Parameters.SideNodeList))
 	for _, node := range Parameters.SideNodeList {
+		if node.GenesisBlockAddress != genesisBlockAddress {
+			nodes = append(nodes, node)
+		}
+	}
+	Parameters.SideNodeList = nodes
+}
+
+func GetRpcConfig(genesisBlockHash string) (*RpcConfig, bool) {
+	for _, node := range SideNodes() {
 		if node.GetGenesisBlock() == genesisBlockHash {
 			return node.Rpc, true
 		}
@@ -188,82 +446,148 @@ func GetSpvChainParams() *elacfg.Configuration {
 	return params
 }
 
-func Initialize() {
-	file, e := ioutil.ReadFile(DefaultConfigFilename)
-	if e != nil {
-		fmt.Printf("File error: %v\n", e)
-		return
+// Initialize loads the configuration into Parameters from the built-in
+// parameters of ActiveNet, the config file on path and the overrides in
+// order. The config file is skipped if it is the default one and not exists.
+// An error is returned if the configuration can not be loaded or is invalid.
+func Initialize(path string, overrides ...*Override) error {
+	if path == DefaultConfigFilename {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			path = ""
+		}
 	}
-	i := ConfigFile{}
-	// Remove the UTF-8 Byte Order Mark
-	file = bytes.TrimPrefix(file, []byte("\xef\xbb\xbf"))
-
-	e = json.Unmarshal(file, &i)
-	var config ConfigFile
-	switch strings.ToLower(i.ConfigFile.ActiveNet) {
-	case "testnet", "test":
-		config = testnet
-	case "regnet", "reg":
-		config = regnet
-	default:
-		config = mainnet
+	cfg, err := Load(path, overrides...)
+	if err != nil {
+		return err
 	}
+	Parameters.Configuration = cfg
+	return nil
+}
 
-	Parameters.Configuration = &(config.ConfigFile)
-
-	e = json.Unmarshal(file, &config)
-	if e != nil {
-		fmt.Printf("Unmarshal json file erro %v", e)
-		os.Exit(1)
+// Load reads the config file on the given path over the default parameters
+// of the network it is active on, applies the overrides, validates the
+// result and fills the genesis block addresses of side nodes. The config
+// file is not read if path is empty.
+func Load(path string, overrides ...*Override) (*Configuration, error) {
+	var file []byte
+	var activeNet struct {
+		ConfigFile struct {
+			ActiveNet string `json:"ActiveNet"`
+		} `json:"Configuration"`
 	}
-
-	for _, side := range config.ConfigFile.SideNodeList {
-		side.PowChain = true
+	if path != "" {
+		var err error
+		file, err = ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		// Remove the UTF-8 Byte Order Mark
+		file = bytes.TrimPrefix(file, []byte("\xef\xbb\xbf"))
+		if err := json.Unmarshal(file, &activeNet); err != nil {
+			return nil, fmt.Errorf("unmarshal config file %s error: %v", path, err)
+		}
 	}
-
-	e = json.Unmarshal(file, &config)
-	if e != nil {
-		fmt.Printf("Unmarshal json file erro %v", e)
-		os.Exit(1)
+	for _, o := range overrides {
+		if o.isPath("ActiveNet") {
+			activeNet.ConfigFile.ActiveNet = o.Value
+		}
 	}
 
-	//Parameters.Configuration = &(config.ConfigFile)
-
-	var out bytes.Buffer
-	err := json.Indent(&out, file, "", "")
-	if err != nil {
-		fmt.Printf("Config file error: %v\n", e)
-		os.Exit(1)
+	config := defaultConfig(activeNet.ConfigFile.ActiveNet)
+	if file != nil {
+		if err := json.Unmarshal(file, &config); err != nil {
+			return nil, fmt.Errorf("unmarshal config file %s error: %v", path, err)
+		}
 	}
 
-	if Parameters.Configuration.MainNode == nil {
-		fmt.Printf("Need to set main node in config file\n")
-		return
+	var errs ValidationErrors
+	for _, o := range overrides {
+		if err := o.Apply(&config.ConfigFile); err != nil {
+			errs = append(errs, err)
+		}
 	}
-
-	if Parameters.Configuration.SideNodeList == nil {
-		fmt.Printf("Need to set side node list in config file\n")
-		return
+	if err := config.ConfigFile.Validate(); err != nil {
+		errs = append(errs, err.(ValidationErrors)...)
+	}
+	if len(errs) > 0 {
+		return nil, errs
 	}
 
-	for _, node := range Parameters.SideNodeList {
-		genesisBytes, err := common.HexStringToBytes(node.GenesisBlock)
+	cfg := &config.ConfigFile
+	for _, node := range cfg.SideNodeList {
+		genesisBlockHash, err := common.Uint256FromHexString(node.GetGenesisBlock())
 		if err != nil {
-			fmt.Printf("Side node genesis block hash error: %v\n", e)
-			return
-		}
-		reversedGenesisBytes := common.BytesReverse(genesisBytes)
-		reversedGenesisStr := common.BytesToHexString(reversedGenesisBytes)
-		genesisBlockHash, err := common.Uint256FromHexString(reversedGenesisStr)
-		if err != nil {
-			fmt.Printf("Side node genesis block hash reverse error: %v\n", e)
-			return
+			return nil, fmt.Errorf("side node %s genesis block hash error: %v", node.Name, err)
 		}
 		address, err := base.GetGenesisAddress(*genesisBlockHash)
 		if err != nil {
-			fmt.Printf("Side node genesis block hash to address error: %v\n", e)
-			return
+			return nil, fmt.Errorf("side node %s genesis block hash to address error: %v", node.Name, err)
 		}
 		node.GenesisBlockAddress = address
 	}
+	return cfg, nil
+}
+
+// defaultConfig returns a copy of the default parameters of the network, so
+// the parameters can be overwritten without changing the defaults.
+func defaultConfig(activeNet string) ConfigFile {
+	var config ConfigFile
+	switch strings.ToLower(activeNet) {
+	case "testnet", "test":
+		config = testnet
+	case "regnet", "reg":
+		config = regnet
+	default:
+		config = mainnet
+	}
+
+	c := &config.ConfigFile
+	if c.MainNode != nil {
+		mainNode := *c.MainNode
+		if mainNode.Rpc != nil {
+			rpc := *mainNode.Rpc
+			mainNode.Rpc = &rpc
+		}
+		mainNode.RpcList = append([]*RpcConfig(nil), mainNode.RpcList...)
+		mainNode.SpvSeedList = append([]string(nil), mainNode.SpvSeedList...)
+		c.MainNode = &mainNode
+	}
+	c.RpcConfiguration.WhiteIPList = append([]string(nil), c.RpcConfiguration.WhiteIPList...)
+	c.CRCCrossChainArbiters = append([]string(nil), c.CRCCrossChainArbiters...)
+	c.OriginCrossChainArbiters = append([]string(nil), c.OriginCrossChainArbiters...)
+	c.FrozenAddresses = append([]string(nil), c.FrozenAddresses...)
+	if c.Notifier != nil {
+		notifier := *c.Notifier
+		c.Notifier = &notifier
+	}
+	if c.DepositRetry != nil {
+		depositRetry := *c.DepositRetry
+		c.DepositRetry = &depositRetry
+	}
+	if c.HealthCheck != nil {
+		healthCheck := *c.HealthCheck
+		c.HealthCheck = &healthCheck
+	}
+	if c.Treasury != nil {
+		treasury := *c.Treasury
+		c.Treasury = &treasury
+	}
+	if c.AuxpowRetry != nil {
+		auxpowRetry := *c.AuxpowRetry
+		c.AuxpowRetry = &auxpowRetry
+	}
+	if c.SideMiningFee != nil {
+		sideMiningFee := *c.SideMiningFee
+		c.SideMiningFee = &sideMiningFee
+	}
+	if c.WithdrawRules != nil {
+		withdrawRules := *c.WithdrawRules
+		withdrawRules.AddressPrefixes = append([]string(nil), withdrawRules.AddressPrefixes...)
+		c.WithdrawRules = &withdrawRules
+	}
+	if c.Reconciliation != nil {
+		reconciliation := *c.Reconciliation
+		c.Reconciliation = &reconciliation
+	}
+	return config
 }
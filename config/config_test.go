@@ -41,6 +41,25 @@ func TestGetRpcConfig(t *testing.T) {
 	}
 }
 
+func TestAddRemoveSideNode(t *testing.T) {
+	nodes := Parameters.SideNodeList
+	defer func() { Parameters.SideNodeList = nodes }()
+
+	AddSideNode(&SideNodeConfig{Name: "ESC", GenesisBlockAddress: "XVbCTM7vqM1qHKsABSFH4xKN1qbp7ijpWf"})
+	if len(SideNodes()) != len(nodes)+1 {
+		t.Error("side node should be added")
+	}
+	RemoveSideNode("XVbCTM7vqM1qHKsABSFH4xKN1qbp7ijpWf")
+	if len(SideNodes()) != len(nodes) {
+		t.Error("side node should be removed")
+	}
+	for _, node := range SideNodes() {
+		if node.GenesisBlockAddress == "XVbCTM7vqM1qHKsABSFH4xKN1qbp7ijpWf" {
+			t.Error("retired side node should not be listed")
+		}
+	}
+}
+
 type Salary struct {
 	Basic float64 `json:"basic"`
 	HRA   float64 `json:"hra"`
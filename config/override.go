@@ -0,0 +1,222 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	// EnvPrefix is the prefix of environment variables overriding config
+	// values, e.g. ARBITER_MAINNODE_RPC_PASS overrides MainNode.Rpc.Pass.
+	EnvPrefix = "ARBITER_"
+
+	// EnvFileSuffix is the suffix of environment variables whose value is
+	// the path of a file containing the config value, used for secrets,
+	// e.g. ARBITER_MAINNODE_RPC_PASS_FILE=/run/secrets/rpc_pass.
+	EnvFileSuffix = "_FILE"
+
+	// RedactedValue replaces the secret values in the effective config.
+	RedactedValue = "******"
+)
+
+// secretFields are the names of config fields holding secret values.
+var secretFields = map[string]struct{}{
+	"Pass":   {},
+	"Secret": {},
+}
+
+// Override is a config value overriding the value in the config file.
+type Override struct {
+	// Path is the field names from Configuration to the overridden field,
+	// slice elements are indexed by number, e.g. [SideNodeList 0 Rpc Pass].
+	// Names are case insensitive.
+	Path []string
+
+	// Value is the string of the value, it is decoded as json unless the
+	// field is a string.
+	Value string
+
+	// Source describes where the override comes from, used in errors.
+	Source string
+}
+
+// EnvOverrides returns the overrides of environment variables in "KEY=VALUE"
+// format with EnvPrefix, the values of variables with EnvFileSuffix are read
+// from the files they point to.
+func EnvOverrides(environ []string) ([]*Override, error) {
+	var overrides []*Override
+	for _, env := range environ {
+		kv := strings.SplitN(env, "=", 2)
+		if len(kv) != 2 || !strings.HasPrefix(kv[0], EnvPrefix) {
+			continue
+		}
+		key, value := strings.TrimPrefix(kv[0], EnvPrefix), kv[1]
+		if strings.HasSuffix(key, EnvFileSuffix) {
+			secret, err := readSecretFile(value)
+			if err != nil {
+				return nil, fmt.Errorf("env %s: %v", kv[0], err)
+			}
+			key, value = strings.TrimSuffix(key, EnvFileSuffix), secret
+		}
+		overrides = append(overrides, &Override{
+			Path:   strings.Split(key, "_"),
+			Value:  value,
+			Source: "env " + kv[0],
+		})
+	}
+	return overrides, nil
+}
+
+// FlagOverride returns the override of a command line flag in "Key.Path=value"
+// format, the value is read from the file it points to if fromFile is true.
+func FlagOverride(flag string, fromFile bool) (*Override, error) {
+	kv := strings.SplitN(flag, "=", 2)
+	if len(kv) != 2 || kv[0] == "" {
+		return nil, fmt.Errorf("invalid override %q, should be Key.Path=value", flag)
+	}
+	value := kv[1]
+	if fromFile {
+		secret, err := readSecretFile(value)
+		if err != nil {
+			return nil, fmt.Errorf("flag %s: %v", kv[0], err)
+		}
+		value = secret
+	}
+	return &Override{
+		Path:   strings.Split(kv[0], "."),
+		Value:  value,
+		Source: "flag " + kv[0],
+	}, nil
+}
+
+func readSecretFile(path string) (string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// Apply sets the overridden value into the configuration.
+func (o *Override) Apply(c *Configuration) error {
+	if err := setField(reflect.ValueOf(c).Elem(), o.Path, o.Value); err != nil {
+		return fmt.Errorf("%s: %v", o.Source, err)
+	}
+	return nil
+}
+
+// isPath checks if the override is on the given field path.
+func (o *Override) isPath(path ...string) bool {
+	if len(o.Path) != len(path) {
+		return false
+	}
+	for i := range path {
+		if !strings.EqualFold(o.Path[i], path[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func setField(v reflect.Value, path []string, value string) error {
+	if len(path) == 0 {
+		return setValue(v, value)
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+			if side, ok := v.Interface().(*SideNodeConfig); ok {
+				side.PowChain = true
+			}
+		}
+		return setField(v.Elem(), path, value)
+
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if strings.EqualFold(fieldName(v.Type().Field(i)), path[0]) {
+				return setField(v.Field(i), path[1:], value)
+			}
+		}
+		return fmt.Errorf("unknown config field %s", path[0])
+
+	case reflect.Slice:
+		index, err := strconv.Atoi(path[0])
+		if err != nil || index < 0 || index > v.Len() {
+			return fmt.Errorf("invalid index %s of %d elements", path[0], v.Len())
+		}
+		if index == v.Len() {
+			v.Set(reflect.Append(v, reflect.Zero(v.Type().Elem())))
+		}
+		return setField(v.Index(index), path[1:], value)
+
+	default:
+		return fmt.Errorf("config field %s has no field %s", v.Type(), path[0])
+	}
+}
+
+func setValue(v reflect.Value, value string) error {
+	if v.Kind() == reflect.String {
+		v.SetString(value)
+		return nil
+	}
+	if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.String &&
+		!strings.HasPrefix(strings.TrimSpace(value), "[") {
+		values := strings.Split(value, ",")
+		for i := range values {
+			values[i] = strings.TrimSpace(values[i])
+		}
+		v.Set(reflect.ValueOf(values))
+		return nil
+	}
+	if err := json.Unmarshal([]byte(value), v.Addr().Interface()); err != nil {
+		return fmt.Errorf("invalid value %q, %v", value, err)
+	}
+	return nil
+}
+
+func fieldName(field reflect.StructField) string {
+	if name := strings.Split(field.Tag.Get("json"), ",")[0]; name != "" {
+		return name
+	}
+	return field.Name
+}
+
+// Redacted returns the configuration as a json object with the secret values
+// replaced by RedactedValue.
+func (c *Configuration) Redacted() (map[string]interface{}, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	var result map[string]interface{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	redact(result)
+	return result, nil
+}
+
+func redact(value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if _, ok := secretFields[key]; ok {
+				if s, ok := field.(string); ok && s != "" {
+					v[key] = RedactedValue
+				}
+				continue
+			}
+			redact(field)
+		}
+	case []interface{}:
+		for _, e := range v {
+			redact(e)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package config
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestEnvOverrides(t *testing.T) {
+	secret := filepath.Join(filepath.Dir(writeConfig(t, validConfig)), "pass")
+	if err := ioutil.WriteFile(secret, []byte("secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	overrides, err := EnvOverrides([]string{
+		"HOME=/root",
+		"ARBITER_HTTPJSONPORT=30000",
+		"ARBITER_MAINNODE_RPC_PASS_FILE=" + secret,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(overrides) != 2 {
+		t.Fatalf("expect 2 overrides, got %d", len(overrides))
+	}
+	if !overrides[1].isPath("MainNode", "Rpc", "Pass") || overrides[1].Value != "secret" {
+		t.Errorf("wrong secret override %v", overrides[1])
+	}
+
+	_, err = EnvOverrides([]string{"ARBITER_MAINNODE_RPC_PASS_FILE=/not/exist"})
+	if err == nil {
+		t.Error("expect error of not existing secret file")
+	}
+}
+
+func TestOverride_Apply(t *testing.T) {
+	cfg := &Configuration{SideNodeList: []*SideNodeConfig{{Name: "ESC"}}}
+	overrides := []*Override{
+		{Path: []string{"HTTPJSONPORT"}, Value: "30000"},
+		{Path: []string{"MainNode", "Rpc", "Pass"}, Value: "pass"},
+		{Path: []string{"SideNodeList", "0", "Rpc", "HttpJsonPort"}, Value: "20632"},
+		{Path: []string{"SideNodeList", "1", "Name"}, Value: "EID"},
+		{Path: []string{"CRCCrossChainArbiters"}, Value: "a, b"},
+		{Path: []string{"RpcConfiguration", "WhiteIPList"}, Value: `["127.0.0.1"]`},
+		{Path: []string{"SmallCrossTransferThreshold"}, Value: "1000"},
+	}
+	for _, o := range overrides {
+		if err := o.Apply(cfg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if cfg.HttpJsonPort != 30000 || cfg.MainNode.Rpc.Pass != "pass" ||
+		cfg.SideNodeList[0].Rpc.HttpJsonPort != 20632 ||
+		cfg.SmallCrossTransferThreshold != 1000 {
+		t.Error("override not applied")
+	}
+	if len(cfg.SideNodeList) != 2 || cfg.SideNodeList[1].Name != "EID" ||
+		!cfg.SideNodeList[1].PowChain {
+		t.Error("side node not appended")
+	}
+	if len(cfg.CRCCrossChainArbiters) != 2 || cfg.CRCCrossChainArbiters[1] != "b" ||
+		len(cfg.RpcConfiguration.WhiteIPList) != 1 {
+		t.Error("string list override not applied")
+	}
+
+	invalid := []*Override{
+		{Path: []string{"NotExist"}, Value: "1"},
+		{Path: []string{"HttpJsonPort"}, Value: "abc"},
+		{Path: []string{"SideNodeList", "5", "Name"}, Value: "ESC"},
+		{Path: []string{"HttpJsonPort", "Port"}, Value: "1"},
+	}
+	for _, o := range invalid {
+		if err := o.Apply(cfg); err == nil {
+			t.Errorf("expect error of override %v", o.Path)
+		}
+	}
+}
+
+func TestLoad_Overrides(t *testing.T) {
+	env, err := EnvOverrides([]string{
+		"ARBITER_ACTIVENET=regnet",
+		"ARBITER_HTTPJSONPORT=30000",
+		"ARBITER_SIDENODELIST_1_RPC_PASS=pass",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	flag, err := FlagOverride("HttpJsonPort=30001", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(writeConfig(t, validConfig), append(env, flag)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.ActiveNet != "regnet" || cfg.NodePort != regnet.ConfigFile.NodePort {
+		t.Error("default parameters of overridden ActiveNet not loaded")
+	}
+	if cfg.HttpJsonPort != 30001 || cfg.SideNodeList[1].Rpc.Pass != "pass" {
+		t.Error("overrides not applied in order")
+	}
+
+	redacted, err := cfg.Redacted()
+	if err != nil {
+		t.Fatal(err)
+	}
+	side := redacted["SideNodeList"].([]interface{})[1].(map[string]interface{})
+	if side["Rpc"].(map[string]interface{})["Pass"] != RedactedValue {
+		t.Error("secret not redacted")
+	}
+	if cfg.SideNodeList[1].Rpc.Pass != "pass" {
+		t.Error("configuration changed by redaction")
+	}
+
+	_, err = Load(writeConfig(t, validConfig), &Override{
+		Path: []string{"NotExist"}, Value: "1", Source: "env ARBITER_NOTEXIST"})
+	if _, ok := err.(ValidationErrors); !ok {
+		t.Errorf("expect validation errors, got %v", err)
+	}
+}
@@ -77,9 +77,38 @@ var (
 			ProcessInvalidWithdrawHeight:    730000,
 			ReturnCrossChainCoinStartHeight: 730000,
 			SchnorrStartHeight:              875544 + 720*5,
+			SchnorrPartialSignatureHeight:   math.MaxUint32,
 			DPoSV2StartHeight:               875544 + 720*2,
 			NFTStartHeight:                  100, // todo fix me
 			FrozenAddresses:                 []string{},
+			Notifier: &NotifierConfig{
+				MaxRetries:       5,
+				RetryInterval:    1000,
+				DedupInterval:    600000,
+				SyncStallTimeout: 600000,
+			},
+			HealthCheck: &HealthCheckConfig{
+				Interval:     10000,
+				Timeout:      5000,
+				MaxHeightLag: 6,
+			},
+			Treasury: &TreasuryConfig{
+				RefillFee:      100000,
+				RefillOutputs:  4,
+				RunwayTarget:   604800000,
+				PendingTimeout: 3600000,
+			},
+			AuxpowRetry: &AuxpowRetryConfig{
+				MaxAttempts:     5,
+				InitialInterval: 2000,
+				MaxInterval:     30000,
+			},
+			SideMiningFee: &SideMiningFeeConfig{
+				Strategy:  "fixed",
+				MaxFee:    500000,
+				BumpRatio: 1.5,
+				Window:    30,
+			},
 		},
 	}
 
@@ -157,9 +186,38 @@ var (
 			ProcessInvalidWithdrawHeight:    807000,
 			ReturnCrossChainCoinStartHeight: 807000,
 			SchnorrStartHeight:              965800 + 720*10,
+			SchnorrPartialSignatureHeight:   math.MaxUint32,
 			DPoSV2StartHeight:               965800 + 720*3,
 			NFTStartHeight:                  100, // todo fix me
 			FrozenAddresses:                 []string{},
+			Notifier: &NotifierConfig{
+				MaxRetries:       5,
+				RetryInterval:    1000,
+				DedupInterval:    600000,
+				SyncStallTimeout: 600000,
+			},
+			HealthCheck: &HealthCheckConfig{
+				Interval:     10000,
+				Timeout:      5000,
+				MaxHeightLag: 6,
+			},
+			Treasury: &TreasuryConfig{
+				RefillFee:      100000,
+				RefillOutputs:  4,
+				RunwayTarget:   604800000,
+				PendingTimeout: 3600000,
+			},
+			AuxpowRetry: &AuxpowRetryConfig{
+				MaxAttempts:     5,
+				InitialInterval: 2000,
+				MaxInterval:     30000,
+			},
+			SideMiningFee: &SideMiningFeeConfig{
+				Strategy:  "fixed",
+				MaxFee:    500000,
+				BumpRatio: 1.5,
+				Window:    30,
+			},
 		},
 	}
 
@@ -239,12 +297,41 @@ var (
 			ProcessInvalidWithdrawHeight:    1032840,
 			ReturnCrossChainCoinStartHeight: 1032840,
 			SchnorrStartHeight:              math.MaxUint32,
+			SchnorrPartialSignatureHeight:   math.MaxUint32,
 			DPoSV2StartHeight:               1405000,
 			NFTStartHeight:                  1405000,
 			FrozenAddresses:                 []string{
 				"Ef9kN3KvTLeGKLKwVCv9BsmJRp8gLx1p2s",
 				"EbMowFp6TsoE9sLjvn3zTjVchExPj1SCrt",
 			},
+			Notifier: &NotifierConfig{
+				MaxRetries:       5,
+				RetryInterval:    1000,
+				DedupInterval:    600000,
+				SyncStallTimeout: 600000,
+			},
+			HealthCheck: &HealthCheckConfig{
+				Interval:     10000,
+				Timeout:      5000,
+				MaxHeightLag: 6,
+			},
+			Treasury: &TreasuryConfig{
+				RefillFee:      100000,
+				RefillOutputs:  4,
+				RunwayTarget:   604800000,
+				PendingTimeout: 3600000,
+			},
+			AuxpowRetry: &AuxpowRetryConfig{
+				MaxAttempts:     5,
+				InitialInterval: 2000,
+				MaxInterval:     30000,
+			},
+			SideMiningFee: &SideMiningFeeConfig{
+				Strategy:  "fixed",
+				MaxFee:    500000,
+				BumpRatio: 1.5,
+				Window:    30,
+			},
 		},
 	}
 )
@@ -0,0 +1,377 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/crypto"
+)
+
+// ValidationErrors contains all the problems found in a configuration.
+type ValidationErrors []error
+
+func (e ValidationErrors) Error() string {
+	problems := make([]string, 0, len(e))
+	for _, err := range e {
+		problems = append(problems, err.Error())
+	}
+	return fmt.Sprintf("%d problem(s) found in configuration:\n  %s",
+		len(e), strings.Join(problems, "\n  "))
+}
+
+// heightOrder requires the activation height Before not to be higher than
+// the activation height After.
+type heightOrder struct {
+	Before string
+	After  string
+	height func(c *Configuration) (uint32, uint32)
+}
+
+var heightOrders = []heightOrder{
+	{"CRCOnlyDPOSHeight", "CRClaimDPOSNodeStartHeight",
+		func(c *Configuration) (uint32, uint32) {
+			return c.CRCOnlyDPOSHeight, c.CRClaimDPOSNodeStartHeight
+		}},
+	{"CRClaimDPOSNodeStartHeight", "DPOSNodeCrossChainHeight",
+		func(c *Configuration) (uint32, uint32) {
+			return c.CRClaimDPOSNodeStartHeight, c.DPOSNodeCrossChainHeight
+		}},
+	{"DPOSNodeCrossChainHeight", "SchnorrStartHeight",
+		func(c *Configuration) (uint32, uint32) {
+			return c.DPOSNodeCrossChainHeight, c.SchnorrStartHeight
+		}},
+	{"SchnorrStartHeight", "SchnorrPartialSignatureHeight",
+		func(c *Configuration) (uint32, uint32) {
+			return c.SchnorrStartHeight, c.SchnorrPartialSignatureHeight
+		}},
+	{"NewCrossChainTransactionHeight", "ProcessInvalidWithdrawHeight",
+		func(c *Configuration) (uint32, uint32) {
+			return c.NewCrossChainTransactionHeight, c.ProcessInvalidWithdrawHeight
+		}},
+	{"NewCrossChainTransactionHeight", "ReturnCrossChainCoinStartHeight",
+		func(c *Configuration) (uint32, uint32) {
+			return c.NewCrossChainTransactionHeight, c.ReturnCrossChainCoinStartHeight
+		}},
+}
+
+// Validate checks the whole configuration and returns all the problems found
+// as ValidationErrors, or nil if the configuration is valid.
+func (c *Configuration) Validate() error {
+	var errs ValidationErrors
+	addErr := func(format string, a ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, a...))
+	}
+
+	switch strings.ToLower(c.ActiveNet) {
+	case "", "mainnet", "main", "testnet", "test", "regnet", "reg":
+	default:
+		addErr("ActiveNet: unknown network %q", c.ActiveNet)
+	}
+
+	if c.MainNode == nil {
+		addErr("MainNode: not set")
+	} else {
+		if err := checkRpc(c.MainNode.Rpc); err != nil {
+			addErr("MainNode.Rpc: %v", err)
+		}
+		for i, rpc := range c.MainNode.RpcList {
+			if err := checkRpc(rpc); err != nil {
+				addErr("MainNode.RpcList[%d]: %v", i, err)
+			}
+		}
+		endpoints := len(c.MainNode.RpcList) + 1
+		if c.MainNode.Quorum < 0 || c.MainNode.Quorum > endpoints {
+			addErr("MainNode.Quorum: should be between 0 and the number of "+
+				"endpoints %d", endpoints)
+		} else if c.MainNode.Quorum > 1 && c.MainNode.Quorum*2 <= endpoints {
+			addErr("MainNode.Quorum: should be more than half of the "+
+				"endpoints %d", endpoints)
+		}
+		if c.MainNode.FoundationAddress != "" {
+			if _, err := common.Uint168FromAddress(c.MainNode.FoundationAddress); err != nil {
+				addErr("MainNode.FoundationAddress: invalid address %q",
+					c.MainNode.FoundationAddress)
+			}
+		}
+	}
+
+	if len(c.SideNodeList) == 0 {
+		addErr("SideNodeList: not set")
+	}
+	names := make(map[string]int)
+	genesisHashes := make(map[string]int)
+	for i, node := range c.SideNodeList {
+		if node == nil {
+			addErr("SideNodeList[%d]: empty side node", i)
+			continue
+		}
+		if node.Name == "" {
+			addErr("SideNodeList[%d].Name: not set", i)
+		} else if j, ok := names[node.Name]; ok {
+			addErr("SideNodeList[%d].Name: duplicate side chain name %q, "+
+				"already used by SideNodeList[%d]", i, node.Name, j)
+		} else {
+			names[node.Name] = i
+		}
+		if err := checkRpc(node.Rpc); err != nil {
+			addErr("SideNodeList[%d].Rpc: %v", i, err)
+		}
+		for j, rpc := range node.RpcList {
+			if err := checkRpc(rpc); err != nil {
+				addErr("SideNodeList[%d].RpcList[%d]: %v", i, j, err)
+			}
+		}
+		if err := checkGenesisBlock(node.GenesisBlock); err != nil {
+			addErr("SideNodeList[%d].GenesisBlock: %v", i, err)
+		} else {
+			genesis := strings.ToLower(node.GenesisBlock)
+			if j, ok := genesisHashes[genesis]; ok {
+				addErr("SideNodeList[%d].GenesisBlock: duplicate genesis block "+
+					"hash, already used by SideNodeList[%d]", i, j)
+			} else {
+				genesisHashes[genesis] = i
+			}
+		}
+		if node.ExchangeRate <= 0 {
+			addErr("SideNodeList[%d].ExchangeRate: should be greater than 0", i)
+		}
+	}
+
+	for _, err := range checkPublicKeys("CRCCrossChainArbiters", c.CRCCrossChainArbiters) {
+		errs = append(errs, err)
+	}
+	for _, err := range checkPublicKeys("OriginCrossChainArbiters", c.OriginCrossChainArbiters) {
+		errs = append(errs, err)
+	}
+
+	for i, node := range c.SideNodeList {
+		if node == nil {
+			continue
+		}
+		if node.DailyWithdrawLimit < 0 {
+			addErr("SideNodeList[%d].DailyWithdrawLimit: should not be negative", i)
+		}
+		if node.MinWithdrawAmount < 0 {
+			addErr("SideNodeList[%d].MinWithdrawAmount: should not be negative", i)
+		}
+		if node.WithdrawBreakerLimit < 0 {
+			addErr("SideNodeList[%d].WithdrawBreakerLimit: should not be negative", i)
+		}
+		if node.WithdrawBreakerWindow < 0 {
+			addErr("SideNodeList[%d].WithdrawBreakerWindow: should not be negative", i)
+		}
+	}
+	if w := c.WithdrawRules; w != nil {
+		for i, prefix := range w.AddressPrefixes {
+			if _, ok := AddressPrefixes[prefix]; !ok {
+				addErr("WithdrawRules.AddressPrefixes[%d]: unknown address prefix type %q", i, prefix)
+			}
+		}
+		if w.MinFee < 0 {
+			addErr("WithdrawRules.MinFee: should not be negative")
+		}
+		if w.MaxTargetDataSize < 0 || w.MaxTargetDataSize > MaxTargetDataSize {
+			addErr("WithdrawRules.MaxTargetDataSize: should be between 0 and %d",
+				MaxTargetDataSize)
+		}
+	}
+	if p := c.ProposalPolicy; p != nil {
+		if p.MaxWithdrawAmount < 0 {
+			addErr("ProposalPolicy.MaxWithdrawAmount: should not be negative")
+		}
+		if p.DailyWithdrawLimit < 0 {
+			addErr("ProposalPolicy.DailyWithdrawLimit: should not be negative")
+		}
+		if p.DailyAddressLimit < 0 {
+			addErr("ProposalPolicy.DailyAddressLimit: should not be negative")
+		}
+		for i, addr := range p.DeniedAddresses {
+			if _, err := common.Uint168FromAddress(addr); err != nil {
+				addErr("ProposalPolicy.DeniedAddresses[%d]: invalid address %q", i, addr)
+			}
+		}
+		for i, rule := range p.ApprovalRules {
+			if rule == nil {
+				addErr("ProposalPolicy.ApprovalRules[%d]: empty rule", i)
+				continue
+			}
+			if rule.MinAmount <= 0 && rule.MinDailyAmount <= 0 {
+				addErr("ProposalPolicy.ApprovalRules[%d]: MinAmount or "+
+					"MinDailyAmount should be greater than 0", i)
+			}
+			if _, ok := names[rule.SideChain]; rule.SideChain != "" && !ok {
+				addErr("ProposalPolicy.ApprovalRules[%d].SideChain: unknown "+
+					"side chain %q", i, rule.SideChain)
+			}
+		}
+	}
+
+	if r := c.DepositRetry; r != nil {
+		if r.MaxAttempts < 0 {
+			addErr("DepositRetry.MaxAttempts: should not be negative")
+		}
+		if r.InitialInterval <= 0 {
+			addErr("DepositRetry.InitialInterval: should be greater than 0")
+		} else if r.MaxInterval < r.InitialInterval {
+			addErr("DepositRetry.MaxInterval: %d is less than InitialInterval %d",
+				r.MaxInterval, r.InitialInterval)
+		}
+		if r.MaxDuration < 0 {
+			addErr("DepositRetry.MaxDuration: should not be negative")
+		}
+	}
+
+	if h := c.HealthCheck; h != nil {
+		if h.Interval <= 0 {
+			addErr("HealthCheck.Interval: should be greater than 0")
+		}
+		if h.Timeout <= 0 {
+			addErr("HealthCheck.Timeout: should be greater than 0")
+		}
+	}
+
+	if t := c.Treasury; t != nil {
+		if t.DailyBudget < 0 {
+			addErr("Treasury.DailyBudget: should not be negative")
+		}
+		if t.RefillFee <= 0 {
+			addErr("Treasury.RefillFee: should be greater than 0")
+		}
+		if t.RefillOutputs <= 0 {
+			addErr("Treasury.RefillOutputs: should be greater than 0")
+		}
+		if t.RunwayTarget < 0 {
+			addErr("Treasury.RunwayTarget: should not be negative")
+		}
+		if t.PendingTimeout <= 0 {
+			addErr("Treasury.PendingTimeout: should be greater than 0")
+		}
+	}
+
+	if r := c.AuxpowRetry; r != nil {
+		if r.MaxAttempts < 0 {
+			addErr("AuxpowRetry.MaxAttempts: should not be negative")
+		}
+		if r.InitialInterval <= 0 {
+			addErr("AuxpowRetry.InitialInterval: should be greater than 0")
+		} else if r.MaxInterval < r.InitialInterval {
+			addErr("AuxpowRetry.MaxInterval: %d is less than InitialInterval %d",
+				r.MaxInterval, r.InitialInterval)
+		}
+	}
+
+	if f := c.SideMiningFee; f != nil {
+		switch f.Strategy {
+		case "", "fixed":
+		case "bumponmiss", "adaptive":
+			if f.MaxFee < common.Fixed64(c.SideAuxPowFee) {
+				addErr("SideMiningFee.MaxFee: %d is less than SideAuxPowFee %d",
+					f.MaxFee, c.SideAuxPowFee)
+			}
+			if f.Strategy == "bumponmiss" && f.BumpRatio <= 1 {
+				addErr("SideMiningFee.BumpRatio: should be greater than 1")
+			}
+			if f.Strategy == "adaptive" && f.Window <= 0 {
+				addErr("SideMiningFee.Window: should be greater than 0")
+			}
+		default:
+			addErr("SideMiningFee.Strategy: unknown strategy %q, should be fixed, "+
+				"bumponmiss or adaptive", f.Strategy)
+		}
+	}
+
+	if r := c.Reconciliation; r != nil {
+		if r.Interval <= 0 {
+			addErr("Reconciliation.Interval: should be greater than 0")
+		}
+		if r.Tolerance < 0 {
+			addErr("Reconciliation.Tolerance: should not be negative")
+		}
+		if r.Retention < 0 {
+			addErr("Reconciliation.Retention: should not be negative")
+		}
+		for i, node := range c.SideNodeList {
+			if node != nil && r.GetSupplyMethod(node) == "" {
+				addErr("SideNodeList[%d].SupplyMethod: should be set if Reconciliation.SupplyMethod is empty", i)
+			}
+		}
+	}
+
+	if c.HttpRestPort != 0 && int(c.HttpRestPort) == c.HttpJsonPort {
+		addErr("HttpRestPort: same as HttpJsonPort %d", c.HttpJsonPort)
+	}
+
+	// The built-in parameters of regnet and testnet keep a placeholder for
+	// DPOSNodeCrossChainHeight, so only orders changed by the config file are
+	// checked.
+	defaults := defaultConfig(c.ActiveNet).ConfigFile
+	for _, o := range heightOrders {
+		before, after := o.height(c)
+		if before <= after {
+			continue
+		}
+		defaultBefore, defaultAfter := o.height(&defaults)
+		if before == defaultBefore && after == defaultAfter {
+			continue
+		}
+		addErr("%s: %d is higher than %s %d", o.Before, before, o.After, after)
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func checkRpc(rpc *RpcConfig) error {
+	if rpc == nil {
+		return errors.New("not set")
+	}
+	if rpc.IpAddress == "" {
+		return errors.New("IpAddress not set")
+	}
+	if rpc.HttpJsonPort <= 0 || rpc.HttpJsonPort > 65535 {
+		return fmt.Errorf("invalid HttpJsonPort %d", rpc.HttpJsonPort)
+	}
+	return nil
+}
+
+func checkGenesisBlock(genesisBlock string) error {
+	if genesisBlock == "" {
+		return errors.New("not set")
+	}
+	genesisBytes, err := common.HexStringToBytes(genesisBlock)
+	if err != nil {
+		return fmt.Errorf("invalid hash %q, %v", genesisBlock, err)
+	}
+	if len(genesisBytes) != common.UINT256SIZE {
+		return fmt.Errorf("invalid hash %q, length should be %d bytes",
+			genesisBlock, common.UINT256SIZE)
+	}
+	return nil
+}
+
+func checkPublicKeys(field string, publicKeys []string) []error {
+	var errs []error
+	keys := make(map[string]int)
+	for i, key := range publicKeys {
+		pk, err := common.HexStringToBytes(key)
+		if err == nil {
+			_, err = crypto.DecodePoint(pk)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s[%d]: invalid public key %q, %v",
+				field, i, key, err))
+			continue
+		}
+		if j, ok := keys[strings.ToLower(key)]; ok {
+			errs = append(errs, fmt.Errorf("%s[%d]: duplicate public key, "+
+				"already used by %s[%d]", field, i, field, j))
+			continue
+		}
+		keys[strings.ToLower(key)] = i
+	}
+	return errs
+}
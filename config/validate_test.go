@@ -0,0 +1,225 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const validConfig = `{
+  "Configuration": {
+    "ActiveNet": "testnet",
+    "MainNode": {
+      "Rpc": {
+        "IpAddress": "127.0.0.1",
+        "HttpJsonPort": 21999
+      }
+    },
+    "SideNodeList": [
+      {
+        "Name": "ESC",
+        "Rpc": {
+          "IpAddress": "127.0.0.1",
+          "HttpJsonPort": 20632
+        },
+        "ExchangeRate": 1,
+        "GenesisBlock": "698e5ec133064dabb7c42eb4b2bdfa21e7b7c2326b0b719d5ab7f452ae8f5ee4",
+        "PowChain": false
+      },
+      {
+        "Name": "EID",
+        "Rpc": {
+          "IpAddress": "127.0.0.1",
+          "HttpJsonPort": 20642
+        },
+        "ExchangeRate": 1,
+        "GenesisBlock": "3d0f9da9320556f6d58129419e041de28cf515eedc6b59f8dae49df98e3f943c"
+      }
+    ],
+    "CRCCrossChainArbiters": [
+      "0306e3deefee78e0e25f88e98f1f3290ccea98f08dd3a890616755f1a066c4b9b8"
+    ]
+  }
+}`
+
+const invalidConfig = `{
+  "Configuration": {
+    "ActiveNet": "testnet",
+    "SchnorrStartHeight": 900000,
+    "DPOSNodeCrossChainHeight": 910000,
+    "MainNode": {
+      "Quorum": 3
+    },
+    "SideNodeList": [
+      {
+        "Name": "ESC",
+        "Rpc": {
+          "IpAddress": "127.0.0.1",
+          "HttpJsonPort": 20632
+        },
+        "RpcList": [
+          {
+            "IpAddress": "127.0.0.2"
+          }
+        ],
+        "ExchangeRate": 1,
+        "GenesisBlock": "698e5ec133064dabb7c42eb4b2bdfa21e7b7c2326b0b719d5ab7f452ae8f5e",
+        "SupplyMethod": "getcirculatingsupply"
+      },
+      {
+        "Name": "ESC",
+        "Rpc": {
+          "IpAddress": "127.0.0.1",
+          "HttpJsonPort": 20642
+        },
+        "ExchangeRate": 1,
+        "GenesisBlock": "3d0f9da9320556f6d58129419e041de28cf515eedc6b59f8dae49df98e3f943c",
+        "WithdrawBreakerLimit": -1
+      }
+    ],
+    "CRCCrossChainArbiters": [
+      "0306e3deefee78e0e25f88e98f1f3290ccea98f08dd3a890616755f1a066c4b9",
+      "0306e3deefee78e0e25f88e98f1f3290ccea98f08dd3a890616755f1a066c4b9b8",
+      "0306e3deefee78e0e25f88e98f1f3290ccea98f08dd3a890616755f1a066c4b9b8"
+    ],
+    "ProposalPolicy": {
+      "DeniedAddresses": ["EXXX"],
+      "ApprovalRules": [{"SideChain": "EID", "MinAmount": 100}]
+    },
+    "DepositRetry": {
+      "InitialInterval": 60000,
+      "MaxInterval": 10000
+    },
+    "Treasury": {
+      "RefillOutputs": 0
+    },
+    "AuxpowRetry": {
+      "InitialInterval": 0
+    },
+    "SideMiningFee": {
+      "Strategy": "bumponmiss",
+      "MaxFee": 10000
+    },
+    "WithdrawRules": {
+      "AddressPrefixes": ["standard", "schnorr"]
+    },
+    "Reconciliation": {
+      "Interval": 3600000,
+      "Tolerance": -1,
+      "Retention": -1
+    }
+  }
+}`
+
+func writeConfig(t *testing.T, content string) string {
+	dir, err := ioutil.TempDir("", "arbiter-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	path := filepath.Join(dir, "config.json")
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	cfg, err := Load(writeConfig(t, validConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.SideNodeList[0].PowChain || !cfg.SideNodeList[1].PowChain {
+		t.Error("PowChain should be true only if it is not set")
+	}
+	for _, node := range cfg.SideNodeList {
+		if node.GenesisBlockAddress == "" {
+			t.Errorf("genesis block address of %s not set", node.Name)
+		}
+	}
+	if len(cfg.CRCCrossChainArbiters) != 1 || cfg.MainNode.Rpc.HttpJsonPort != 21999 {
+		t.Error("config file not loaded")
+	}
+	if cfg.NodePort != testnet.ConfigFile.NodePort {
+		t.Error("default parameters not loaded")
+	}
+	if len(testnet.ConfigFile.CRCCrossChainArbiters) == 1 ||
+		testnet.ConfigFile.MainNode.Rpc.HttpJsonPort == 21999 {
+		t.Error("default parameters changed by config file")
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	_, err := Load(writeConfig(t, invalidConfig))
+	errs, ok := err.(ValidationErrors)
+	if !ok {
+		t.Fatalf("expect validation errors, got %v", err)
+	}
+
+	expected := []string{
+		"MainNode.Quorum: should be between 0 and the number of endpoints 1",
+		"SideNodeList[0].GenesisBlock",
+		"SideNodeList[0].RpcList[0]: invalid HttpJsonPort 0",
+		"SideNodeList[1].Name: duplicate side chain name",
+		"SideNodeList[1].WithdrawBreakerLimit: should not be negative",
+		"CRCCrossChainArbiters[0]: invalid public key",
+		"CRCCrossChainArbiters[2]: duplicate public key",
+		"DPOSNodeCrossChainHeight: 910000 is higher than SchnorrStartHeight 900000",
+		"ProposalPolicy.DeniedAddresses[0]: invalid address",
+		"ProposalPolicy.ApprovalRules[0].SideChain: unknown side chain",
+		"DepositRetry.MaxInterval: 10000 is less than InitialInterval 60000",
+		"Treasury.RefillOutputs: should be greater than 0",
+		"AuxpowRetry.InitialInterval: should be greater than 0",
+		"SideMiningFee.MaxFee: 10000 is less than SideAuxPowFee 50000",
+		"Reconciliation.Tolerance: should not be negative",
+		"Reconciliation.Retention: should not be negative",
+		"SideNodeList[1].SupplyMethod: should be set if Reconciliation.SupplyMethod is empty",
+		"WithdrawRules.AddressPrefixes[1]: unknown address prefix type",
+	}
+	if len(errs) != len(expected) {
+		t.Errorf("expect %d problems, got %d: %v", len(expected), len(errs), errs)
+	}
+	for _, e := range expected {
+		if !strings.Contains(errs.Error(), e) {
+			t.Errorf("problem %q not reported", e)
+		}
+	}
+}
+
+func TestConfiguration_Validate(t *testing.T) {
+	if err := Parameters.Configuration.Validate(); err != nil {
+		t.Errorf("mock config should be valid, got %v", err)
+	}
+
+	for _, net := range []string{"mainnet", "testnet", "regnet"} {
+		cfg := defaultConfig(net).ConfigFile
+		cfg.ActiveNet = net
+		cfg.SideNodeList = Parameters.SideNodeList
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("default parameters of %s should be valid, got %v",
+				cfg.ActiveNet, err)
+		}
+	}
+}
+
+func TestConfiguration_ValidateQuorum(t *testing.T) {
+	cfg := defaultConfig("regnet").ConfigFile
+	cfg.ActiveNet = "regnet"
+	cfg.SideNodeList = Parameters.SideNodeList
+	mainNode := *cfg.MainNode
+	mainNode.RpcList = []*RpcConfig{
+		{IpAddress: "127.0.0.2", HttpJsonPort: 20336},
+		{IpAddress: "127.0.0.3", HttpJsonPort: 20336},
+		{IpAddress: "127.0.0.4", HttpJsonPort: 20336},
+	}
+	cfg.MainNode = &mainNode
+
+	for quorum, valid := range map[int]bool{0: true, 1: true, 2: false, 3: true, 4: true, 5: false} {
+		mainNode.Quorum = quorum
+		if err := cfg.Validate(); (err == nil) != valid {
+			t.Errorf("quorum %d of 4 endpoints, expect valid %v, got %v", quorum, valid, err)
+		}
+	}
+}
@@ -6,6 +6,9 @@ const (
 	Error                   ErrCode = -1
 	Success                 ErrCode = 0
 
+	SessionExpired          ErrCode = 41001
+	IllegalDataFormat       ErrCode = 41003
+
 	InvalidMethod           ErrCode = 42001
 	InvalidParams           ErrCode = 42002
 	InvalidToken            ErrCode = 42003
@@ -18,6 +21,8 @@ const (
 var ErrMap = map[ErrCode]string{
 	Error:                   "Unclassified error",
 	Success:                 "Success",
+	SessionExpired:          "Session expired",
+	IllegalDataFormat:       "Illegal Dataformat",
 	InvalidMethod:           "Invalid method",
 	InvalidParams:           "Invalid Params",
 	InvalidToken:            "Verify token error",
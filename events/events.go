@@ -0,0 +1,189 @@
+package events
+
+import (
+	"fmt"
+	"sync"
+)
+
+// EventType represents the type of a event message.
+type EventType int
+
+// EventCallback is used for a caller to provide a callback for
+// notifications about cross chain events.
+type EventCallback func(*Event)
+
+// Constants for the type of a notification message.
+const (
+	// ETDepositSeen indicates a deposit transaction was found on the main
+	// chain by the SPV module.
+	ETDepositSeen EventType = iota
+
+	// ETDepositRecharged indicates a deposit transaction was recharged to
+	// the side chain.
+	ETDepositRecharged
+
+	// ETWithdrawFound indicates a withdraw transaction was picked up from
+	// the side chain.
+	ETWithdrawFound
+
+	// ETWithdrawProposalSigned indicates a withdraw proposal collected
+	// enough signatures from arbiters and was sent to the main chain.
+	ETWithdrawProposalSigned
+
+	// ETWithdrawConfirmed indicates a withdraw transaction was accepted by
+	// the main chain.
+	ETWithdrawConfirmed
+
+	// ETFailedDepositReturned indicates a failed deposit was returned to the
+	// main chain.
+	ETFailedDepositReturned
+
+	// ETSideChainPowAccountLow indicates the available balance of a side
+	// chain mining account is under MinThreshold.
+	ETSideChainPowAccountLow
+
+	// ETSideChainSyncStalled indicates the side chain data has not been
+	// synced for a while.
+	ETSideChainSyncStalled
+
+	// ETArbiterPeersBelowQuorum indicates the connected arbiter peers are
+	// not enough to collect signatures of proposals.
+	ETArbiterPeersBelowQuorum
+
+	// ETSubmitFailed indicates a transaction or auxpow failed to be
+	// submitted to the main chain or side chain.
+	ETSubmitFailed
+
+	// ETIllegalEvidenceFound indicates an illegal evidence was found on the
+	// side chain.
+	ETIllegalEvidenceFound
+
+	// ETProposalRejected indicates a withdraw proposal was rejected by the
+	// proposal policy or is waiting for manual approval.
+	ETProposalRejected
+
+	// ETMainNodeDisagreement indicates the main node rpc endpoints returned
+	// different results of a quorum read.
+	ETMainNodeDisagreement
+
+	// ETWithdrawBlocked indicates a withdraw transaction is held since it
+	// pays to a frozen address.
+	ETWithdrawBlocked
+
+	// ETSideChainRegistered indicates a side chain was registered on the
+	// main chain and is waiting for review.
+	ETSideChainRegistered
+
+	// ETSideChainRetired indicates a decommissioned side chain was drained
+	// and is no longer synced.
+	ETSideChainRetired
+
+	// ETCircuitBreakerTripped indicates the deposits or withdraws of a side
+	// chain were paused by a circuit breaker.
+	ETCircuitBreakerTripped
+
+	// ETReconciliationDiscrepancy indicates the ELA locked for a side chain
+	// on the main chain does not match its supply beyond the tolerance.
+	ETReconciliationDiscrepancy
+)
+
+// notificationTypeStrings is a map of notification types back to their constant
+// names for pretty printing.
+var notificationTypeStrings = map[EventType]string{
+	ETDepositSeen:               "ETDepositSeen",
+	ETDepositRecharged:          "ETDepositRecharged",
+	ETWithdrawFound:             "ETWithdrawFound",
+	ETWithdrawProposalSigned:    "ETWithdrawProposalSigned",
+	ETWithdrawConfirmed:         "ETWithdrawConfirmed",
+	ETFailedDepositReturned:     "ETFailedDepositReturned",
+	ETSideChainPowAccountLow:    "ETSideChainPowAccountLow",
+	ETSideChainSyncStalled:      "ETSideChainSyncStalled",
+	ETArbiterPeersBelowQuorum:   "ETArbiterPeersBelowQuorum",
+	ETSubmitFailed:              "ETSubmitFailed",
+	ETIllegalEvidenceFound:      "ETIllegalEvidenceFound",
+	ETProposalRejected:          "ETProposalRejected",
+	ETMainNodeDisagreement:      "ETMainNodeDisagreement",
+	ETWithdrawBlocked:           "ETWithdrawBlocked",
+	ETSideChainRegistered:       "ETSideChainRegistered",
+	ETSideChainRetired:          "ETSideChainRetired",
+	ETCircuitBreakerTripped:     "ETCircuitBreakerTripped",
+	ETReconciliationDiscrepancy: "ETReconciliationDiscrepancy",
+}
+
+// String returns the EventType in human-readable form.
+func (n EventType) String() string {
+	if s, ok := notificationTypeStrings[n]; ok {
+		return s
+	}
+	return fmt.Sprintf("Unknown Event Type (%d)", int(n))
+}
+
+// CrossChainTx is the data of all cross chain events, Addresses are the
+// main chain or side chain addresses the transaction pays to.
+type CrossChainTx struct {
+	GenesisBlockAddress        string   `json:"genesisblockaddress"`
+	MainChainTransactionHash   string   `json:"mainchaintransactionhash,omitempty"`
+	SideChainTransactionHashes []string `json:"sidechaintransactionhashes,omitempty"`
+	ReturnTransactionHash      string   `json:"returntransactionhash,omitempty"`
+	Addresses                  []string `json:"addresses,omitempty"`
+}
+
+// Alert is the data of all operational events, alerts with the same type and
+// Subject are regarded as duplicated.
+type Alert struct {
+	GenesisBlockAddress string `json:"genesisblockaddress,omitempty"`
+	Subject             string `json:"subject"`
+	Message             string `json:"message"`
+}
+
+// Event defines notification that is sent to the caller via the callback
+// function provided during the call to Subscribe and consists of a
+// notification type as well as associated data that depends on the type as
+// follows:
+//   - ETDepositSeen:               *CrossChainTx
+//   - ETDepositRecharged:          *CrossChainTx
+//   - ETWithdrawFound:             *CrossChainTx
+//   - ETWithdrawProposalSigned:    *CrossChainTx
+//   - ETWithdrawConfirmed:         *CrossChainTx
+//   - ETFailedDepositReturned:     *CrossChainTx
+//   - ETSideChainPowAccountLow:    *Alert
+//   - ETSideChainSyncStalled:      *Alert
+//   - ETArbiterPeersBelowQuorum:   *Alert
+//   - ETSubmitFailed:              *Alert
+//   - ETIllegalEvidenceFound:      *Alert
+//   - ETProposalRejected:          *Alert
+//   - ETMainNodeDisagreement:      *Alert
+//   - ETWithdrawBlocked:           *Alert
+//   - ETSideChainRegistered:       *Alert
+//   - ETSideChainRetired:          *Alert
+//   - ETCircuitBreakerTripped:     *Alert
+//   - ETReconciliationDiscrepancy: *Alert
+type Event struct {
+	Type EventType
+	Data interface{}
+}
+
+var events struct {
+	mtx       sync.Mutex
+	callbacks []EventCallback
+}
+
+// Subscribe to cross chain notifications. Registers a callback to be executed
+// when various events take place. See the documentation on Event and EventType
+// for details on the types and contents of notifications.
+func Subscribe(callback EventCallback) {
+	events.mtx.Lock()
+	events.callbacks = append(events.callbacks, callback)
+	events.mtx.Unlock()
+}
+
+// Notify sends a notification with the passed type and data to all
+// subscribers, callbacks must not call Notify again.
+func Notify(typ EventType, data interface{}) {
+	events.mtx.Lock()
+	n := Event{Type: typ, Data: data}
+	for _, callback := range events.callbacks {
+		callback(&n)
+	}
+	events.mtx.Unlock()
+}
@@ -5,6 +5,7 @@ go 1.20
 require (
 	github.com/elastos/Elastos.ELA v0.9.7
 	github.com/elastos/Elastos.ELA.SPV v0.1.0
+	github.com/gorilla/websocket v1.4.2
 	github.com/howeyc/gopass v0.0.0-20190910152052-7cb4b85ec19c
 	github.com/mattn/go-sqlite3 v2.0.3+incompatible
 )
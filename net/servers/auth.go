@@ -0,0 +1,76 @@
+package servers
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"net"
+	"net/http"
+
+	"github.com/elastos/Elastos.ELA.Arbiter/config"
+	"github.com/elastos/Elastos.ELA.Arbiter/log"
+)
+
+// CheckAuth checks the basic auth of request with the user and password of
+// RpcConfiguration, it always passes if both of them are empty.
+func CheckAuth(r *http.Request) bool {
+	tempRpcConf := config.Parameters.RpcConfiguration
+	if (tempRpcConf.User == tempRpcConf.Pass) && (len(tempRpcConf.User) == 0) {
+		return true
+	}
+	authHeader := r.Header["Authorization"]
+	if len(authHeader) <= 0 {
+		return false
+	}
+
+	authSha256 := sha256.Sum256([]byte(authHeader[0]))
+
+	login := tempRpcConf.User + ":" + tempRpcConf.Pass
+	auth := "Basic " + base64.StdEncoding.EncodeToString([]byte(login))
+	cfgAuthSha256 := sha256.Sum256([]byte(auth))
+
+	resultCmp := subtle.ConstantTimeCompare(authSha256[:], cfgAuthSha256[:])
+	if resultCmp == 1 {
+		return true
+	}
+
+	// Request's auth doesn't match  user
+	return false
+}
+
+// ClientAllowed checks if the remote address of request is loopback or in
+// the WhiteIPList of RpcConfiguration.
+func ClientAllowed(r *http.Request) bool {
+	log.Debugf("clientAllowed RpcConfiguration %v", config.Parameters.RpcConfiguration)
+	//this ipAbbr  may be  ::1 when request is localhost
+	ipAbbr, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		log.Errorf("RemoteAddr clientAllowed SplitHostPort failure %s \n", r.RemoteAddr)
+		return false
+
+	}
+	//after ParseIP ::1 chg to 0:0:0:0:0:0:0:1 the true ip
+	remoteIp := net.ParseIP(ipAbbr)
+
+	if remoteIp == nil {
+		log.Errorf("clientAllowed ParseIP ipAbbr %s failure  \n", ipAbbr)
+		return false
+	}
+
+	if remoteIp.IsLoopback() {
+		//log.Debugf("remoteIp %s IsLoopback\n", remoteIp)
+		return true
+	}
+
+	for _, cfgIp := range config.Parameters.RpcConfiguration.WhiteIPList {
+		//WhiteIPList have 0.0.0.0  allow all ip in
+		if cfgIp == "0.0.0.0" {
+			return true
+		}
+		if cfgIp == remoteIp.String() {
+			return true
+		}
+
+	}
+	return false
+}
@@ -2,9 +2,6 @@ package httpjsonrpc
 
 import (
 	"context"
-	"crypto/sha256"
-	"crypto/subtle"
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -17,29 +14,378 @@ import (
 	"github.com/elastos/Elastos.ELA.Arbiter/errors"
 	"github.com/elastos/Elastos.ELA.Arbiter/log"
 	"github.com/elastos/Elastos.ELA.Arbiter/net/servers"
+	"github.com/elastos/Elastos.ELA.Arbiter/net/servers/httpwebsocket"
 )
 
-//an instance of the multiplexer
-var mainMux map[string]func(servers.Params) map[string]interface{}
+//the registry of rpc methods
+var methods *servers.MethodSet
 
 func StartRPCServer(pServer *http.Server) {
-	mainMux = make(map[string]func(servers.Params) map[string]interface{})
-
-	mainMux["submitcomplain"] = servers.SubmitComplain
-	mainMux["getcomplainstatus"] = servers.GetComplainStatus
-	mainMux["getinfo"] = servers.GetInfo
-	mainMux["getsidemininginfo"] = servers.GetSideMiningInfo
-	mainMux["getmainchainblockheight"] = servers.GetMainChainBlockHeight
-	mainMux["getsidechainblockheight"] = servers.GetSideChainBlockHeight
-	mainMux["getfinisheddeposittxs"] = servers.GetFinishedDepositTxs
-	mainMux["getfinishedwithdrawtxs"] = servers.GetFinishedWithdrawTxs
-	mainMux["getgitversion"] = servers.GetGitVersion
-	mainMux["getspvheight"] = servers.GetSPVHeight
-	mainMux["getarbiterpeersinfo"] = servers.GetArbiterPeersInfo
-	mainMux["setregistersidechainrpcinfo"] = servers.SetRegisterSideChainRPCInfo
+	methods = servers.NewMethodSet()
+
+	hash := &servers.Param{Name: "hash", Type: servers.TypeString, Required: true,
+		Description: "the genesis block hash of side chain"}
+	succeed := &servers.Param{Name: "succeed", Type: servers.TypeBoolean, Required: true,
+		Description: "get succeed or failed transactions"}
+	transactionHash := &servers.Param{Name: "transactionhash", Type: servers.TypeString, Required: true,
+		Description: "the hash of complained transaction"}
+	genesisAddress := &servers.Param{Name: "genesisblockaddress", Type: servers.TypeString, Required: true,
+		Description: "the genesis block address of side chain"}
+	genesisAddressFilter := &servers.Param{Name: "genesisblockaddress", Type: servers.TypeString,
+		Description: "only return transactions of the side chain"}
+	kind := &servers.Param{Name: "kind", Type: servers.TypeString, Required: true,
+		Description: "deposit or withdraw"}
+	hashes := &servers.Param{Name: "hashes", Type: servers.TypeArray, Items: servers.TypeString, Required: true,
+		Description: "the hashes of pending transactions"}
+
+	methods.Register(&servers.Method{
+		Name:    "submitcomplain",
+		Summary: "submit a complain of cross chain transaction",
+		Params: []*servers.Param{
+			{Name: "fromaddress", Type: servers.TypeString, Required: true, Description: "the address of complainer"},
+			transactionHash,
+			{Name: "chaingenesisblockhash", Type: servers.TypeString, Description: "the genesis block hash of side chain"},
+		},
+		Handler: servers.SubmitComplain,
+	})
+	methods.Register(&servers.Method{
+		Name:    "getcomplainstatus",
+		Summary: "return the status of a complain",
+		Params:  []*servers.Param{transactionHash},
+		Result:  &servers.Result{Name: "status", Type: servers.TypeInteger},
+		Handler: servers.GetComplainStatus,
+	})
+	methods.Register(&servers.Method{
+		Name:    "getinfo",
+		Summary: "return part of parameters of current arbiter",
+		Result:  &servers.Result{Name: "info", Type: servers.TypeObject},
+		Handler: servers.GetInfo,
+	})
+	methods.Register(&servers.Method{
+		Name:    "getsidemininginfo",
+		Summary: "return last side mining heights of a side chain",
+		Params:  []*servers.Param{hash},
+		Result:  &servers.Result{Name: "mininginfo", Type: servers.TypeObject},
+		Handler: servers.GetSideMiningInfo,
+	})
+	methods.Register(&servers.Method{
+		Name:    "gettreasury",
+		Summary: "return side chain mining accounts with their pending top-ups and projected runway",
+		Result:  &servers.Result{Name: "treasury", Type: servers.TypeObject},
+		Handler: servers.GetTreasury,
+	})
+	methods.Register(&servers.Method{
+		Name:    "getsidemininghistory",
+		Summary: "return side chain pow transactions sent with their submit results, miss rate and fees spent",
+		Params: []*servers.Param{genesisAddressFilter,
+			{Name: "from", Type: servers.TypeInteger, Description: "the unix time the range starts, 24 hours before to by default"},
+			{Name: "to", Type: servers.TypeInteger, Description: "the unix time the range ends, now by default"},
+			{Name: "limit", Type: servers.TypeInteger, Description: "the max count of latest transactions returned per side chain, 100 by default"}},
+		Result:  &servers.Result{Name: "sidechains", Type: servers.TypeArray},
+		Handler: servers.GetSideMiningHistory,
+	})
+	methods.Register(&servers.Method{
+		Name:    "getmainchainblockheight",
+		Summary: "return main chain height synced by arbiter",
+		Result:  &servers.Result{Name: "height", Type: servers.TypeInteger},
+		Handler: servers.GetMainChainBlockHeight,
+	})
+	methods.Register(&servers.Method{
+		Name:    "getsidechainblockheight",
+		Summary: "return side chain height synced by arbiter",
+		Params:  []*servers.Param{hash},
+		Result:  &servers.Result{Name: "height", Type: servers.TypeInteger},
+		Handler: servers.GetSideChainBlockHeight,
+	})
+	methods.Register(&servers.Method{
+		Name:    "getfinisheddeposittxs",
+		Summary: "return finished deposit transactions",
+		Params:  []*servers.Param{succeed},
+		Result:  &servers.Result{Name: "transactions", Type: servers.TypeObject},
+		Handler: servers.GetFinishedDepositTxs,
+	})
+	methods.Register(&servers.Method{
+		Name:    "getfinishedwithdrawtxs",
+		Summary: "return finished withdraw transactions",
+		Params:  []*servers.Param{succeed},
+		Result:  &servers.Result{Name: "transactions", Type: servers.TypeObject},
+		Handler: servers.GetFinishedWithdrawTxs,
+	})
+	methods.Register(&servers.Method{
+		Name:    "getgitversion",
+		Summary: "return git version of arbiter",
+		Result:  &servers.Result{Name: "version", Type: servers.TypeString},
+		Handler: servers.GetGitVersion,
+	})
+	methods.Register(&servers.Method{
+		Name:    "geteffectiveconfig",
+		Summary: "return the effective configuration merged from defaults, config file, environment variables and flags, with secrets redacted",
+		Result:  &servers.Result{Name: "config", Type: servers.TypeObject},
+		Handler: servers.GetEffectiveConfig,
+	})
+	methods.Register(&servers.Method{
+		Name:    "getspvheight",
+		Summary: "return main chain height synced by spv module",
+		Result:  &servers.Result{Name: "height", Type: servers.TypeInteger},
+		Handler: servers.GetSPVHeight,
+	})
+	methods.Register(&servers.Method{
+		Name:    "getarbiterpeersinfo",
+		Summary: "return connection states of arbiter peers",
+		Result:  &servers.Result{Name: "peers", Type: servers.TypeArray},
+		Handler: servers.GetArbiterPeersInfo,
+	})
+	methods.Register(&servers.Method{
+		Name:    "getsidechains",
+		Summary: "return side chains configured in arbiter and their synced heights",
+		Result:  &servers.Result{Name: "sidechains", Type: servers.TypeArray},
+		Handler: servers.GetSideChains,
+	})
+	methods.Register(&servers.Method{
+		Name:    "getsidechainendpoints",
+		Summary: "return the health of rpc endpoints of side chains",
+		Params:  []*servers.Param{genesisAddressFilter},
+		Result:  &servers.Result{Name: "sidechains", Type: servers.TypeArray},
+		Handler: servers.GetSideChainEndpoints,
+	})
+	methods.Register(&servers.Method{
+		Name:    "getpendingdeposittxs",
+		Summary: "return deposit transactions waiting to be sent to side chains",
+		Params:  []*servers.Param{genesisAddressFilter},
+		Result:  &servers.Result{Name: "transactions", Type: servers.TypeArray},
+		Handler: servers.GetPendingDepositTxs,
+	})
+	methods.Register(&servers.Method{
+		Name:    "getpendingwithdrawtxs",
+		Summary: "return withdraw transactions waiting to be sent to main chain",
+		Params:  []*servers.Param{genesisAddressFilter},
+		Result:  &servers.Result{Name: "transactions", Type: servers.TypeArray},
+		Handler: servers.GetPendingWithdrawTxs,
+	})
+	methods.Register(&servers.Method{
+		Name:    "getpendingreturndeposittxs",
+		Summary: "return failed deposit transactions waiting to be returned",
+		Result:  &servers.Result{Name: "transactions", Type: servers.TypeArray},
+		Handler: servers.GetPendingReturnDepositTxs,
+	})
+	methods.Register(&servers.Method{
+		Name:    "getsmallcrosstxs",
+		Summary: "return small cross chain transfers sent to side chains before they appear in side chains",
+		Params: []*servers.Param{genesisAddressFilter,
+			{Name: "state", Type: servers.TypeString, Description: "pending, sent or failed, all states if absent"}},
+		Result:  &servers.Result{Name: "transactions", Type: servers.TypeArray},
+		Handler: servers.GetSmallCrossTxs,
+	})
+	methods.Register(&servers.Method{
+		Name:    "reproposewithdrawtxs",
+		Summary: "create and broadcast a withdraw proposal of pending withdraw transactions",
+		Params:  []*servers.Param{genesisAddress, hashes},
+		Result:  &servers.Result{Name: "result", Type: servers.TypeObject},
+		Handler: servers.ReproposeWithdrawTxs,
+	})
+	methods.Register(&servers.Method{
+		Name:    "quarantinetxs",
+		Summary: "move pending transactions to quarantine so they are no longer processed",
+		Params: []*servers.Param{kind, genesisAddress, hashes,
+			{Name: "reason", Type: servers.TypeString, Description: "the reason recorded with the quarantine"}},
+		Result:  &servers.Result{Name: "result", Type: servers.TypeObject},
+		Handler: servers.QuarantineTxs,
+	})
+	methods.Register(&servers.Method{
+		Name:    "restoretxs",
+		Summary: "move quarantined transactions back to pending",
+		Params:  []*servers.Param{kind, genesisAddress, hashes},
+		Result:  &servers.Result{Name: "result", Type: servers.TypeObject},
+		Handler: servers.RestoreTxs,
+	})
+	methods.Register(&servers.Method{
+		Name:    "getquarantinedtxs",
+		Summary: "return quarantined transactions",
+		Result:  &servers.Result{Name: "transactions", Type: servers.TypeArray},
+		Handler: servers.GetQuarantinedTxs,
+	})
+	methods.Register(&servers.Method{
+		Name:    "getaudittrail",
+		Summary: "return the latest manual interventions, newest first",
+		Params: []*servers.Param{
+			{Name: "limit", Type: servers.TypeInteger, Description: "the max count of records, 100 by default"},
+		},
+		Result:  &servers.Result{Name: "records", Type: servers.TypeArray},
+		Handler: servers.GetAuditTrail,
+	})
+	methods.Register(&servers.Method{
+		Name:    "getrejectedproposals",
+		Summary: "return the latest proposals rejected by the proposal policy, newest first",
+		Params: []*servers.Param{
+			{Name: "limit", Type: servers.TypeInteger, Description: "the max count of proposals, 100 by default"},
+		},
+		Result:  &servers.Result{Name: "proposals", Type: servers.TypeArray},
+		Handler: servers.GetRejectedProposals,
+	})
+	methods.Register(&servers.Method{
+		Name:    "approveproposal",
+		Summary: "approve a proposal waiting for manual approval, it is signed when proposed again",
+		Params: []*servers.Param{
+			{Name: "hash", Type: servers.TypeString, Required: true, Description: "hash of the main chain transaction proposed"},
+		},
+		Result:  &servers.Result{Name: "result", Type: servers.TypeBoolean},
+		Handler: servers.ApproveProposal,
+	})
+	methods.Register(&servers.Method{
+		Name:    "freezeaddress",
+		Summary: "freeze an address at once, withdraw transactions paying to it are blocked",
+		Params: []*servers.Param{
+			{Name: "address", Type: servers.TypeString, Required: true, Description: "the address to freeze"},
+			{Name: "reason", Type: servers.TypeString, Description: "the reason recorded with the address"},
+			{Name: "expiry", Type: servers.TypeInteger, Description: "the unix time the address is unfrozen, never if absent or 0"},
+		},
+		Result:  &servers.Result{Name: "result", Type: servers.TypeBoolean},
+		Handler: servers.FreezeAddress,
+	})
+	methods.Register(&servers.Method{
+		Name:    "unfreezeaddress",
+		Summary: "unfreeze an address frozen by freezeaddress",
+		Params: []*servers.Param{
+			{Name: "address", Type: servers.TypeString, Required: true, Description: "the address to unfreeze"},
+		},
+		Result:  &servers.Result{Name: "result", Type: servers.TypeBoolean},
+		Handler: servers.UnfreezeAddress,
+	})
+	methods.Register(&servers.Method{
+		Name:    "getfrozenaddresses",
+		Summary: "return the addresses frozen by config and freezeaddress",
+		Result:  &servers.Result{Name: "addresses", Type: servers.TypeArray},
+		Handler: servers.GetFrozenAddresses,
+	})
+	methods.Register(&servers.Method{
+		Name:    "getblockedwithdrawtxs",
+		Summary: "return withdraw transactions blocked by frozen addresses",
+		Params: []*servers.Param{genesisAddressFilter,
+			{Name: "status", Type: servers.TypeString, Description: "blocked, released or returned, all statuses if absent"}},
+		Result:  &servers.Result{Name: "transactions", Type: servers.TypeArray},
+		Handler: servers.GetBlockedWithdrawTxs,
+	})
+	methods.Register(&servers.Method{
+		Name:    "releasewithdrawtxs",
+		Summary: "let blocked withdraw transactions be proposed though the address is frozen",
+		Params:  []*servers.Param{genesisAddress, hashes},
+		Result:  &servers.Result{Name: "result", Type: servers.TypeObject},
+		Handler: servers.ReleaseWithdrawTxs,
+	})
+	methods.Register(&servers.Method{
+		Name:    "returnwithdrawtxs",
+		Summary: "report blocked withdraw transactions to the side chain as invalid withdraws",
+		Params:  []*servers.Param{genesisAddress, hashes},
+		Result:  &servers.Result{Name: "result", Type: servers.TypeObject},
+		Handler: servers.ReturnWithdrawTxs,
+	})
+	methods.Register(&servers.Method{
+		Name:    "setregistersidechainrpcinfo",
+		Summary: "set rpc information of a registered side chain waiting for review",
+		Params: []*servers.Param{
+			{Name: "data", Type: servers.TypeString, Required: true,
+				Description: "hex string of json encoded side chain rpc information"},
+		},
+		Handler: servers.SetRegisterSideChainRPCInfo,
+	})
+	methods.Register(&servers.Method{
+		Name:    "getpendingsidechainregistrations",
+		Summary: "return side chains registered on the main chain and waiting for review",
+		Result:  &servers.Result{Name: "registrations", Type: servers.TypeArray},
+		Handler: servers.GetPendingSideChainRegistrations,
+	})
+	methods.Register(&servers.Method{
+		Name:    "approvesidechainregistration",
+		Summary: "probe the side node and activate a registered side chain with the chosen rpc endpoint and features",
+		Params: []*servers.Param{
+			{Name: "hash", Type: servers.TypeString, Required: true, Description: "hash of the register side chain transaction"},
+			{Name: "ipaddress", Type: servers.TypeString, Description: "ip address of side node, the rpc information set if absent"},
+			{Name: "httpjsonport", Type: servers.TypeInteger, Description: "json rpc port of side node, required with ipaddress"},
+			{Name: "user", Type: servers.TypeString, Description: "rpc user of side node"},
+			{Name: "pass", Type: servers.TypeString, Description: "rpc password of side node"},
+			{Name: "powchain", Type: servers.TypeBoolean, Description: "if the side chain is merged mined"},
+			{Name: "supportquickrecharge", Type: servers.TypeBoolean, Description: "if the side chain supports quick recharge"},
+			{Name: "supportinvaliddeposit", Type: servers.TypeBoolean, Description: "if the side chain supports invalid deposit"},
+			{Name: "supportinvalidwithdraw", Type: servers.TypeBoolean, Description: "if the side chain supports invalid withdraw"},
+			{Name: "supportnft", Type: servers.TypeBoolean, Description: "if the side chain supports nft"},
+		},
+		Result:  &servers.Result{Name: "result", Type: servers.TypeBoolean},
+		Handler: servers.ApproveSideChainRegistration,
+	})
+	methods.Register(&servers.Method{
+		Name:    "rejectsidechainregistration",
+		Summary: "reject a registered side chain so it is never activated",
+		Params: []*servers.Param{
+			{Name: "hash", Type: servers.TypeString, Required: true, Description: "hash of the register side chain transaction"},
+			{Name: "reason", Type: servers.TypeString, Description: "the reason recorded with the rejection"},
+		},
+		Result:  &servers.Result{Name: "result", Type: servers.TypeBoolean},
+		Handler: servers.RejectSideChainRegistration,
+	})
+	methods.Register(&servers.Method{
+		Name:    "decommissionsidechain",
+		Summary: "stop accepting deposits to a side chain and retire it once the queued transactions are processed",
+		Params: []*servers.Param{genesisAddress,
+			{Name: "reason", Type: servers.TypeString, Description: "the reason recorded with the decommission"}},
+		Result:  &servers.Result{Name: "result", Type: servers.TypeBoolean},
+		Handler: servers.DecommissionSideChain,
+	})
+	methods.Register(&servers.Method{
+		Name:    "getsidechaindecommissions",
+		Summary: "return the progress of side chains decommissioned",
+		Params:  []*servers.Param{genesisAddressFilter},
+		Result:  &servers.Result{Name: "decommissions", Type: servers.TypeArray},
+		Handler: servers.GetSideChainDecommissions,
+	})
+	methods.Register(&servers.Method{
+		Name:    "pausesidechain",
+		Summary: "pause the deposits to or withdraws from a side chain until resumed",
+		Params: []*servers.Param{genesisAddress, kind,
+			{Name: "reason", Type: servers.TypeString, Description: "the reason recorded with the pause"}},
+		Result:  &servers.Result{Name: "result", Type: servers.TypeBoolean},
+		Handler: servers.PauseSideChain,
+	})
+	methods.Register(&servers.Method{
+		Name:    "resumesidechain",
+		Summary: "resume the deposits or withdraws of a side chain paused by the operator or a circuit breaker",
+		Params:  []*servers.Param{genesisAddress, kind},
+		Result:  &servers.Result{Name: "result", Type: servers.TypeBoolean},
+		Handler: servers.ResumeSideChain,
+	})
+	methods.Register(&servers.Method{
+		Name:    "getsidechainpauses",
+		Summary: "return the deposits and withdraws of side chains paused",
+		Params:  []*servers.Param{genesisAddressFilter},
+		Result:  &servers.Result{Name: "pauses", Type: servers.TypeArray},
+		Handler: servers.GetSideChainPauses,
+	})
+	methods.Register(&servers.Method{
+		Name:    "getcircuitbreakertrips",
+		Summary: "return the trips of side chain circuit breakers",
+		Params:  []*servers.Param{genesisAddressFilter},
+		Result:  &servers.Result{Name: "trips", Type: servers.TypeArray},
+		Handler: servers.GetCircuitBreakerTrips,
+	})
+	methods.Register(&servers.Method{
+		Name:    "getreconciliationreports",
+		Summary: "return the dated reports of reconciling the ELA locked for side chains with their supply",
+		Params: []*servers.Param{genesisAddressFilter,
+			{Name: "from", Type: servers.TypeInteger, Description: "the unix time the range starts, 24 hours before to by default"},
+			{Name: "to", Type: servers.TypeInteger, Description: "the unix time the range ends, now by default"},
+			{Name: "limit", Type: servers.TypeInteger, Description: "the max count of latest reports returned, 100 by default"}},
+		Result:  &servers.Result{Name: "reports", Type: servers.TypeArray},
+		Handler: servers.GetReconciliationReports,
+	})
+	methods.Register(&servers.Method{
+		Name:    "rpc.discover",
+		Summary: "return the OpenRPC document of arbiter",
+		Result:  &servers.Result{Name: "document", Type: servers.TypeObject},
+		Handler: discover,
+	})
 
 	rpcServeMux := http.NewServeMux()
 	rpcServeMux.HandleFunc("/", Handle)
+	rpcServeMux.HandleFunc("/ws", HandleWebSocket)
 	if pServer == nil {
 		pServer = &http.Server{}
 	}
@@ -71,7 +417,7 @@ func Stop(s *http.Server) error {
 //this is the funciton that should be called in order to answer an rpc call
 //should be registered like "http.AddMethod("/", httpjsonrpc.Handle)"
 func Handle(w http.ResponseWriter, r *http.Request) {
-	isClientAllowed := clientAllowed(r)
+	isClientAllowed := servers.ClientAllowed(r)
 	if !isClientAllowed {
 		log.Warn("HTTP Client ip is not allowd")
 		http.Error(w, "Client ip is not allowd", http.StatusForbidden)
@@ -90,7 +436,7 @@ func Handle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	isCheckAuthOk := checkAuth(r)
+	isCheckAuthOk := servers.CheckAuth(r)
 	if !isCheckAuthOk {
 		//log.Warn("client authenticate failed")
 		http.Error(w, "client authenticate failed", http.StatusUnauthorized)
@@ -110,21 +456,28 @@ func Handle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	//get the corresponding function
-	function, method, ok := checkMethod(request)
+	//get the corresponding method
+	method, name, ok := checkMethod(request)
 
 	if !ok {
-		Error(w, errors.InvalidMethod, method)
+		Error(w, errors.InvalidMethod, name)
 		return
 	}
 
-	params, ok := checkParams(request)
+	params, ok := checkParams(method, request)
 	if !ok {
-		Error(w, errors.InvalidParams, method)
+		writeResponse(w, request, servers.ResponsePack(errors.InvalidParams, "params should be an object or an array"))
+		return
+	}
+	if reason, ok := method.CheckParams(params); !ok {
+		writeResponse(w, request, servers.ResponsePack(errors.InvalidParams, reason))
 		return
 	}
 
-	response := function(params)
+	writeResponse(w, request, method.Handler(params))
+}
+
+func writeResponse(w http.ResponseWriter, request map[string]interface{}, response map[string]interface{}) {
 	var data []byte
 	if response["Error"] != errors.ErrCode(0) {
 		data, _ = json.Marshal(map[string]interface{}{
@@ -149,95 +502,55 @@ func Handle(w http.ResponseWriter, r *http.Request) {
 	w.Write(data)
 }
 
-func checkAuth(r *http.Request) bool {
-	tempRpcConf := config.Parameters.RpcConfiguration
-	if (tempRpcConf.User == tempRpcConf.Pass) && (len(tempRpcConf.User) == 0) {
-		return true
-	}
-	authHeader := r.Header["Authorization"]
-	if len(authHeader) <= 0 {
-		return false
-	}
-
-	authSha256 := sha256.Sum256([]byte(authHeader[0]))
-
-	login := tempRpcConf.User + ":" + tempRpcConf.Pass
-	auth := "Basic " + base64.StdEncoding.EncodeToString([]byte(login))
-	cfgAuthSha256 := sha256.Sum256([]byte(auth))
-
-	resultCmp := subtle.ConstantTimeCompare(authSha256[:], cfgAuthSha256[:])
-	if resultCmp == 1 {
-		return true
-	}
-
-	// Request's auth doesn't match  user
-	return false
-}
-
-func clientAllowed(r *http.Request) bool {
-	log.Debugf("clientAllowed RpcConfiguration %v", config.Parameters.RpcConfiguration)
-	//this ipAbbr  may be  ::1 when request is localhost
-	ipAbbr, _, err := net.SplitHostPort(r.RemoteAddr)
-	if err != nil {
-		log.Errorf("RemoteAddr clientAllowed SplitHostPort failure %s \n", r.RemoteAddr)
-		return false
-
-	}
-	//after ParseIP ::1 chg to 0:0:0:0:0:0:0:1 the true ip
-	remoteIp := net.ParseIP(ipAbbr)
-
-	if remoteIp == nil {
-		log.Errorf("clientAllowed ParseIP ipAbbr %s failure  \n", ipAbbr)
-		return false
-	}
-
-	if remoteIp.IsLoopback() {
-		//log.Debugf("remoteIp %s IsLoopback\n", remoteIp)
-		return true
+//HandleWebSocket upgrades the request to a websocket session of cross chain
+//events, it is protected by the same white list and auth as json rpc.
+func HandleWebSocket(w http.ResponseWriter, r *http.Request) {
+	if !servers.ClientAllowed(r) {
+		log.Warn("HTTP Client ip is not allowd")
+		http.Error(w, "Client ip is not allowd", http.StatusForbidden)
+		return
 	}
-
-	for _, cfgIp := range config.Parameters.RpcConfiguration.WhiteIPList {
-		//WhiteIPList have 0.0.0.0  allow all ip in
-		if cfgIp == "0.0.0.0" {
-			return true
-		}
-		if cfgIp == remoteIp.String() {
-			return true
-		}
-
+	if !servers.CheckAuth(r) {
+		http.Error(w, "client authenticate failed", http.StatusUnauthorized)
+		return
 	}
-	return false
+	httpwebsocket.Handle(w, r)
 }
 
-func checkMethod(request map[string]interface{}) (func(servers.Params) map[string]interface{}, interface{}, bool) {
-	method := request["method"]
-	if method == nil {
-		return nil, method, false
+func checkMethod(request map[string]interface{}) (*servers.Method, interface{}, bool) {
+	name := request["method"]
+	if name == nil {
+		return nil, name, false
 	}
-	switch method.(type) {
+	switch name.(type) {
 	case string:
 	default:
-		return nil, method, false
+		return nil, name, false
 	}
-	function, ok := mainMux[request["method"].(string)]
+	method, ok := methods.Get(name.(string))
 	if !ok {
-		return nil, method, false
+		return nil, name, false
 	}
-	return function, nil, true
+	return method, name, true
 }
 
-func checkParams(request map[string]interface{}) (map[string]interface{}, bool) {
+func checkParams(method *servers.Method, request map[string]interface{}) (servers.Params, bool) {
 	params := request["params"]
 	if params == nil {
-		return map[string]interface{}{}, true
+		return servers.Params{}, true
 	}
-	switch params.(type) {
+	switch p := params.(type) {
 	case map[string]interface{}:
-		return params.(map[string]interface{}), true
+		return p, true
+	case []interface{}:
+		return method.FromArray(p)
 	default:
 		return nil, false
 	}
-	return nil, false
+}
+
+func discover(param servers.Params) map[string]interface{} {
+	return servers.ResponsePack(errors.Success, methods.Discover())
 }
 
 func Error(w http.ResponseWriter, code errors.ErrCode, method interface{}) {
@@ -0,0 +1,309 @@
+package httprestful
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/elastos/Elastos.ELA.Arbiter/config"
+	"github.com/elastos/Elastos.ELA.Arbiter/errors"
+	"github.com/elastos/Elastos.ELA.Arbiter/log"
+	"github.com/elastos/Elastos.ELA.Arbiter/net/servers"
+)
+
+const (
+	ApiGetInfo                    = "/api/v1/info"
+	ApiGetVersion                 = "/api/v1/version"
+	ApiGetMainChainHeight         = "/api/v1/mainchain/height"
+	ApiGetSPVHeight               = "/api/v1/spv/height"
+	ApiGetSideChains              = "/api/v1/sidechains"
+	ApiGetSideChainHeight         = "/api/v1/sidechains/:hash/height"
+	ApiGetSideMiningInfo          = "/api/v1/sidechains/:hash/mininginfo"
+	ApiGetArbiterPeers            = "/api/v1/peers"
+	ApiGetFinishedDepositTxs      = "/api/v1/transactions/deposit/finished"
+	ApiGetFinishedWithdrawTxs     = "/api/v1/transactions/withdraw/finished"
+	ApiGetPendingDepositTxs       = "/api/v1/transactions/deposit/pending"
+	ApiGetPendingWithdrawTxs      = "/api/v1/transactions/withdraw/pending"
+	ApiGetPendingReturnDepositTxs = "/api/v1/transactions/returndeposit/pending"
+	ApiGetQuarantinedTxs          = "/api/v1/transactions/quarantined"
+	ApiGetAuditTrail              = "/api/v1/audittrail"
+)
+
+const (
+	// DefaultLimit is the page size of list resources if limit is not given.
+	DefaultLimit = 100
+
+	// MaxLimit is the max page size of list resources.
+	MaxLimit = 1000
+)
+
+type route struct {
+	segments []string
+	method   *servers.Method
+	paged    bool
+}
+
+// match checks the path against the route, and puts the values of path
+// parameters like ":hash" into params.
+func (r *route) match(path string, params servers.Params) bool {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(segments) != len(r.segments) {
+		return false
+	}
+	for i, s := range r.segments {
+		if !strings.HasPrefix(s, ":") && s != segments[i] {
+			return false
+		}
+	}
+	for i, s := range r.segments {
+		if strings.HasPrefix(s, ":") {
+			params[s[1:]] = segments[i]
+		}
+	}
+	return true
+}
+
+type restServer struct {
+	routes []*route
+}
+
+// StartServer starts the REST server on HttpRestPort, it does nothing if the
+// port is not configured.
+func StartServer() {
+	if config.Parameters.HttpRestPort == 0 {
+		log.Info("[RESTful] HttpRestPort not configured, REST server disabled")
+		return
+	}
+	rt := newRestServer()
+	listener, err := net.Listen("tcp4", ":"+strconv.Itoa(int(config.Parameters.HttpRestPort)))
+	if err != nil {
+		log.Fatal("[RESTful] listen error: ", err.Error())
+		return
+	}
+	server := &http.Server{
+		Handler:      rt,
+		ReadTimeout:  15 * time.Second,
+		WriteTimeout: 15 * time.Second,
+	}
+	if err := server.Serve(listener); err != nil {
+		log.Warnf("[RESTful] StartServer : %v", err.Error())
+	}
+}
+
+func newRestServer() *restServer {
+	rt := &restServer{}
+
+	hash := &servers.Param{Name: "hash", Type: servers.TypeString, Required: true}
+	succeed := &servers.Param{Name: "succeed", Type: servers.TypeBoolean, Required: true}
+	genesisAddress := &servers.Param{Name: "genesisblockaddress", Type: servers.TypeString}
+
+	rt.get(ApiGetInfo, false, nil, servers.GetInfo)
+	rt.get(ApiGetVersion, false, nil, servers.GetGitVersion)
+	rt.get(ApiGetMainChainHeight, false, nil, servers.GetMainChainBlockHeight)
+	rt.get(ApiGetSPVHeight, false, nil, servers.GetSPVHeight)
+	rt.get(ApiGetSideChains, true, nil, servers.GetSideChains)
+	rt.get(ApiGetSideChainHeight, false, []*servers.Param{hash}, servers.GetSideChainBlockHeight)
+	rt.get(ApiGetSideMiningInfo, false, []*servers.Param{hash}, servers.GetSideMiningInfo)
+	rt.get(ApiGetArbiterPeers, true, nil, servers.GetArbiterPeersInfo)
+	rt.get(ApiGetFinishedDepositTxs, true, []*servers.Param{succeed}, servers.GetFinishedDepositTxs)
+	rt.get(ApiGetFinishedWithdrawTxs, true, []*servers.Param{succeed}, servers.GetFinishedWithdrawTxs)
+	rt.get(ApiGetPendingDepositTxs, true, []*servers.Param{genesisAddress}, servers.GetPendingDepositTxs)
+	rt.get(ApiGetPendingWithdrawTxs, true, []*servers.Param{genesisAddress}, servers.GetPendingWithdrawTxs)
+	rt.get(ApiGetPendingReturnDepositTxs, true, nil, servers.GetPendingReturnDepositTxs)
+	rt.get(ApiGetQuarantinedTxs, true, nil, servers.GetQuarantinedTxs)
+	rt.get(ApiGetAuditTrail, true, nil, servers.GetAuditTrail)
+	return rt
+}
+
+func (rt *restServer) get(path string, paged bool, params []*servers.Param,
+	handler func(servers.Params) map[string]interface{}) {
+	rt.routes = append(rt.routes, &route{
+		segments: strings.Split(strings.Trim(path, "/"), "/"),
+		method:   &servers.Method{Name: path, Params: params, Handler: handler},
+		paged:    paged,
+	})
+}
+
+func (rt *restServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !servers.ClientAllowed(r) {
+		log.Warn("HTTP Client ip is not allowd")
+		http.Error(w, "Client ip is not allowd", http.StatusForbidden)
+		return
+	}
+	if !servers.CheckAuth(r) {
+		http.Error(w, "client authenticate failed", http.StatusUnauthorized)
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "REST API only allows GET method", http.StatusMethodNotAllowed)
+		return
+	}
+
+	params := make(servers.Params)
+	var rte *route
+	for _, candidate := range rt.routes {
+		if candidate.match(r.URL.Path, params) {
+			rte = candidate
+			break
+		}
+	}
+	if rte == nil {
+		rt.response(w, r, servers.ResponsePack(errors.InvalidMethod, ""), http.StatusNotFound)
+		return
+	}
+
+	query := r.URL.Query()
+	for _, p := range rte.method.Params {
+		value := query.Get(p.Name)
+		if value == "" {
+			continue
+		}
+		params[p.Name] = value
+		if p.Type == servers.TypeBoolean {
+			if b, err := strconv.ParseBool(value); err == nil {
+				params[p.Name] = b
+			}
+		}
+	}
+	if reason, ok := rte.method.CheckParams(params); !ok {
+		rt.response(w, r, servers.ResponsePack(errors.InvalidParams, reason), http.StatusBadRequest)
+		return
+	}
+
+	var offset, limit int
+	if rte.paged {
+		var reason string
+		var ok bool
+		offset, limit, reason, ok = pageParams(query.Get("offset"), query.Get("limit"))
+		if !ok {
+			rt.response(w, r, servers.ResponsePack(errors.InvalidParams, reason), http.StatusBadRequest)
+			return
+		}
+	}
+
+	resp := rte.method.Handler(params)
+	if resp["Error"] != errors.Success {
+		status := http.StatusInternalServerError
+		if resp["Error"] == errors.InvalidParams {
+			status = http.StatusBadRequest
+		}
+		rt.response(w, r, resp, status)
+		return
+	}
+	if rte.paged {
+		if page, total, ok := paginate(resp["Result"], offset, limit); ok {
+			resp["Result"] = page
+			resp["Total"] = total
+			resp["Offset"] = offset
+			resp["Limit"] = limit
+		}
+	}
+	rt.response(w, r, resp, http.StatusOK)
+}
+
+func (rt *restServer) response(w http.ResponseWriter, r *http.Request,
+	resp map[string]interface{}, status int) {
+	resp["Desc"] = errors.ErrMap[resp["Error"].(errors.ErrCode)]
+	data, err := json.Marshal(resp)
+	if err != nil {
+		log.Error("[RESTful] json.Marshal: ", err)
+		http.Error(w, "marshal response failed", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json;charset=utf-8")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	if status == http.StatusOK {
+		etag := ETag(data)
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Cache-Control", "no-cache")
+		if etagMatch(r.Header.Get("If-None-Match"), etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+	w.WriteHeader(status)
+	if r.Method != http.MethodHead {
+		w.Write(data)
+	}
+}
+
+// pageParams parses the offset and limit of a list resource.
+func pageParams(offsetStr, limitStr string) (int, int, string, bool) {
+	offset, limit := 0, DefaultLimit
+	if offsetStr != "" {
+		v, err := strconv.Atoi(offsetStr)
+		if err != nil || v < 0 {
+			return 0, 0, "offset should be a non-negative integer", false
+		}
+		offset = v
+	}
+	if limitStr != "" {
+		v, err := strconv.Atoi(limitStr)
+		if err != nil || v <= 0 || v > MaxLimit {
+			return 0, 0, fmt.Sprintf("limit should be an integer between 1 and %d", MaxLimit), false
+		}
+		limit = v
+	}
+	return offset, limit, "", true
+}
+
+// paginate returns the page of result in [offset, offset+limit) and the total
+// count of items. The result should be a slice, or a pointer to a struct
+// wrapping a single slice like the finished transactions results.
+func paginate(result interface{}, offset, limit int) (interface{}, int, bool) {
+	value := reflect.ValueOf(result)
+	if value.Kind() == reflect.Slice {
+		page, total := slice(value, offset, limit)
+		return page.Interface(), total, true
+	}
+	if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Struct ||
+		value.Elem().NumField() != 1 || value.Elem().Field(0).Kind() != reflect.Slice {
+		return nil, 0, false
+	}
+	wrapper := reflect.New(value.Elem().Type())
+	page, total := slice(value.Elem().Field(0), offset, limit)
+	wrapper.Elem().Field(0).Set(page)
+	return wrapper.Interface(), total, true
+}
+
+func slice(value reflect.Value, offset, limit int) (reflect.Value, int) {
+	total := value.Len()
+	start := offset
+	if start > total {
+		start = total
+	}
+	// limit is compared with the items left instead of adding it to offset,
+	// which may overflow.
+	end := total
+	if limit < total-start {
+		end = start + limit
+	}
+	return value.Slice(start, end), total
+}
+
+// ETag returns the strong entity tag of response body.
+func ETag(data []byte) string {
+	hash := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(hash[:16]) + `"`
+}
+
+func etagMatch(ifNoneMatch string, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	for _, tag := range strings.Split(ifNoneMatch, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
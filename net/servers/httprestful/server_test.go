@@ -0,0 +1,79 @@
+package httprestful
+
+import (
+	"testing"
+
+	"github.com/elastos/Elastos.ELA.Arbiter/net/servers"
+)
+
+func TestRoute_Match(t *testing.T) {
+	rt := newRestServer()
+
+	params := make(servers.Params)
+	var matched *route
+	for _, r := range rt.routes {
+		if r.match("/api/v1/sidechains/abcd/height", params) {
+			matched = r
+			break
+		}
+	}
+	if matched == nil || matched.method.Name != ApiGetSideChainHeight {
+		t.Fatal("path with parameter should be matched")
+	}
+	if params["hash"] != "abcd" {
+		t.Error("path parameter should be parsed")
+	}
+
+	for _, r := range rt.routes {
+		if r.match("/api/v1/sidechains/abcd", make(servers.Params)) {
+			t.Error("unknown path should not be matched")
+		}
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	page, total, ok := paginate([]int{1, 2, 3, 4, 5}, 1, 2)
+	if !ok || total != 5 || len(page.([]int)) != 2 || page.([]int)[0] != 2 {
+		t.Error("slice result should be paginated")
+	}
+	page, total, ok = paginate([]int{1, 2, 3}, 5, 2)
+	if !ok || total != 3 || len(page.([]int)) != 0 {
+		t.Error("offset beyond total should return empty page")
+	}
+
+	wrapped := &struct{ Transactions []string }{[]string{"a", "b", "c"}}
+	page, total, ok = paginate(wrapped, 2, 10)
+	if !ok || total != 3 {
+		t.Fatal("wrapped result should be paginated")
+	}
+	txs := page.(*struct{ Transactions []string }).Transactions
+	if len(txs) != 1 || txs[0] != "c" || len(wrapped.Transactions) != 3 {
+		t.Error("wrapped result should be paginated without changing origin")
+	}
+
+	if _, _, ok := paginate("height", 0, 10); ok {
+		t.Error("non list result should not be paginated")
+	}
+
+	offset, limit, _, ok := pageParams("9223372036854775807", "50")
+	if !ok {
+		t.Fatal("max offset should be accepted")
+	}
+	page, total, ok = paginate([]int{1, 2, 3}, offset, limit)
+	if !ok || total != 3 || len(page.([]int)) != 0 {
+		t.Error("max offset should return empty page")
+	}
+}
+
+func TestETag(t *testing.T) {
+	etag := ETag([]byte(`{"Result":1}`))
+	if etag == ETag([]byte(`{"Result":2}`)) {
+		t.Error("different body should have different etag")
+	}
+	if !etagMatch(etag, etag) || !etagMatch(`"x", W/`+etag, etag) || !etagMatch("*", etag) {
+		t.Error("etag should be matched")
+	}
+	if etagMatch("", etag) || etagMatch(`"x"`, etag) {
+		t.Error("etag should not be matched")
+	}
+}
@@ -0,0 +1,266 @@
+package httpwebsocket
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/elastos/Elastos.ELA.Arbiter/errors"
+	"github.com/elastos/Elastos.ELA.Arbiter/events"
+	"github.com/elastos/Elastos.ELA.Arbiter/log"
+	"github.com/elastos/Elastos.ELA.Arbiter/net/servers"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// sessionTimeout is the duration of inactivity before we time out a session.
+	sessionTimeout = time.Minute
+)
+
+var instance *Server
+
+// actions is the map of event types to the actions pushed to clients.
+var actions = map[events.EventType]string{
+	events.ETDepositSeen:            "depositseen",
+	events.ETDepositRecharged:       "depositrecharged",
+	events.ETWithdrawFound:          "withdrawfound",
+	events.ETWithdrawProposalSigned: "withdrawproposalsigned",
+	events.ETWithdrawConfirmed:      "withdrawconfirmed",
+	events.ETFailedDepositReturned:  "faileddepositreturned",
+}
+
+type Handler func(*session, servers.Params) map[string]interface{}
+
+type Server struct {
+	websocket.Upgrader
+
+	connCount int64
+	sessions  *sessions
+	handlers  map[string]Handler
+}
+
+// Start creates the websocket server and subscribes the cross chain events,
+// connections are accepted by Handle which is served by the json rpc server.
+func Start() {
+	instance = &Server{
+		Upgrader: websocket.Upgrader{},
+		sessions: &sessions{},
+	}
+	instance.initMethods()
+	instance.Upgrader.CheckOrigin = func(r *http.Request) bool { return true }
+
+	events.Subscribe(func(e *events.Event) {
+		tx, ok := e.Data.(*events.CrossChainTx)
+		if !ok {
+			return
+		}
+		go instance.PushResult(e.Type, tx)
+	})
+
+	go instance.sessionHandler()
+}
+
+// Handle upgrades the http request to a websocket connection.
+func Handle(w http.ResponseWriter, r *http.Request) {
+	if instance == nil {
+		http.Error(w, "websocket server not started", http.StatusServiceUnavailable)
+		return
+	}
+	instance.Handler(w, r)
+}
+
+func (s *Server) initMethods() {
+	s.handlers = map[string]Handler{
+		"subscribe":       s.subscribe,
+		"unsubscribe":     s.unsubscribe,
+		"heartbeat":       s.heartBeat,
+		"getsessioncount": s.getSessionCount,
+	}
+}
+
+func (s *Server) subscribe(ss *session, param servers.Params) map[string]interface{} {
+	sub := &subscription{
+		events:     make(map[events.EventType]struct{}),
+		sideChains: make(map[string]struct{}),
+		addresses:  make(map[string]struct{}),
+	}
+	if _, ok := param["events"]; ok {
+		names, ok := param.ArrayString("events")
+		if !ok {
+			return servers.ResponsePack(errors.InvalidParams, "events should be an array of string")
+		}
+		for _, name := range names {
+			typ, ok := eventType(name)
+			if !ok {
+				return servers.ResponsePack(errors.InvalidParams, "unknown event "+name)
+			}
+			sub.events[typ] = struct{}{}
+		}
+	}
+	if _, ok := param["sidechains"]; ok {
+		sideChains, ok := param.ArrayString("sidechains")
+		if !ok {
+			return servers.ResponsePack(errors.InvalidParams, "sidechains should be an array of string")
+		}
+		for _, sideChain := range sideChains {
+			sub.sideChains[sideChain] = struct{}{}
+		}
+	}
+	if _, ok := param["addresses"]; ok {
+		addresses, ok := param.ArrayString("addresses")
+		if !ok {
+			return servers.ResponsePack(errors.InvalidParams, "addresses should be an array of string")
+		}
+		for _, address := range addresses {
+			sub.addresses[address] = struct{}{}
+		}
+	}
+	ss.Subscribe(sub)
+	return servers.ResponsePack(errors.Success, true)
+}
+
+func (s *Server) unsubscribe(ss *session, param servers.Params) map[string]interface{} {
+	ss.Subscribe(nil)
+	return servers.ResponsePack(errors.Success, true)
+}
+
+func (s *Server) heartBeat(ss *session, param servers.Params) map[string]interface{} {
+	return servers.ResponsePack(errors.Success, "")
+}
+
+func (s *Server) getSessionCount(ss *session, param servers.Params) map[string]interface{} {
+	return servers.ResponsePack(errors.Success, s.sessions.Count())
+}
+
+func (s *Server) sessionHandler() {
+	ticker := time.NewTicker(sessionTimeout)
+	defer ticker.Stop()
+	for range ticker.C {
+		now := time.Now()
+		s.sessions.Foreach(func(v *session) {
+			if !v.Expired(now, sessionTimeout) {
+				return
+			}
+
+			resp := servers.ResponsePack(errors.SessionExpired, "")
+			s.response(v, resp)
+			s.sessions.Delete(v)
+		})
+	}
+}
+
+func (s *Server) Handler(w http.ResponseWriter, r *http.Request) {
+	conn, err := s.Upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Error("websocket Upgrader: ", err)
+		return
+	}
+	defer conn.Close()
+
+	// clear the deadlines set by the json rpc server, sessions are expired
+	// by sessionHandler instead.
+	conn.UnderlyingConn().SetDeadline(time.Time{})
+
+	ss := &session{
+		id:         atomic.AddInt64(&s.connCount, 1),
+		conn:       conn,
+		lastActive: time.Now().UnixNano(),
+	}
+	s.sessions.Store(ss.id, ss)
+
+	defer func() {
+		s.sessions.Delete(ss)
+	}()
+
+	for {
+		_, bysMsg, err := conn.ReadMessage()
+		if err == nil {
+			if s.handle(ss, bysMsg) {
+				ss.Touch()
+			}
+			continue
+		}
+		e, ok := err.(net.Error)
+		if !ok || !e.Timeout() {
+			log.Debug("websocket conn:", err)
+			return
+		}
+	}
+}
+
+func (s *Server) handle(ss *session, bysMsg []byte) bool {
+	var req = make(map[string]interface{})
+
+	if err := json.Unmarshal(bysMsg, &req); err != nil {
+		resp := servers.ResponsePack(errors.IllegalDataFormat, "")
+		s.response(ss, resp)
+		log.Error("websocket OnDataHandle:", err)
+		return false
+	}
+	action, ok := req["action"].(string)
+	if !ok {
+		resp := servers.ResponsePack(errors.InvalidMethod, "")
+		s.response(ss, resp)
+		return false
+	}
+	handler, ok := s.handlers[action]
+	if !ok {
+		resp := servers.ResponsePack(errors.InvalidMethod, "")
+		s.response(ss, resp)
+		return false
+	}
+
+	resp := handler(ss, req)
+	resp["Action"] = action
+
+	s.response(ss, resp)
+
+	return true
+}
+
+func (s *Server) response(ss *session, resp map[string]interface{}) {
+	resp["Desc"] = errors.ErrMap[resp["Error"].(errors.ErrCode)]
+	data, err := json.Marshal(resp)
+	if err != nil {
+		log.Error("Websocket response:", err)
+		return
+	}
+	ss.Send(data)
+}
+
+// PushResult sends the cross chain event to all sessions subscribed to it.
+func (s *Server) PushResult(typ events.EventType, tx *events.CrossChainTx) {
+	action, ok := actions[typ]
+	if !ok {
+		log.Error("httpwebsocket/server.go in pushresult function: unknown event ", typ)
+		return
+	}
+
+	resp := servers.ResponsePack(errors.Success, tx)
+	resp["Action"] = action
+	resp["Desc"] = errors.ErrMap[errors.Success]
+
+	data, err := json.Marshal(resp)
+	if err != nil {
+		log.Error("Websocket PushResult:", err)
+		return
+	}
+
+	s.sessions.Foreach(func(v *session) {
+		if v.Subscribed(typ, tx) {
+			v.Send(data)
+		}
+	})
+}
+
+func eventType(action string) (events.EventType, bool) {
+	for typ, a := range actions {
+		if a == action {
+			return typ, true
+		}
+	}
+	return 0, false
+}
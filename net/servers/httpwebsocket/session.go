@@ -0,0 +1,113 @@
+package httpwebsocket
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/elastos/Elastos.ELA.Arbiter/events"
+
+	"github.com/gorilla/websocket"
+)
+
+// subscription filters the cross chain events pushed to a session, an empty
+// set means no filter on that dimension.
+type subscription struct {
+	events     map[events.EventType]struct{}
+	sideChains map[string]struct{}
+	addresses  map[string]struct{}
+}
+
+func (s *subscription) match(typ events.EventType, tx *events.CrossChainTx) bool {
+	if len(s.events) != 0 {
+		if _, ok := s.events[typ]; !ok {
+			return false
+		}
+	}
+	if len(s.sideChains) != 0 {
+		if _, ok := s.sideChains[tx.GenesisBlockAddress]; !ok {
+			return false
+		}
+	}
+	if len(s.addresses) == 0 {
+		return true
+	}
+	for _, addr := range tx.Addresses {
+		if _, ok := s.addresses[addr]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+type session struct {
+	mtx          sync.Mutex
+	id           int64
+	conn         *websocket.Conn
+	lastActive   int64 // unix nanoseconds, accessed atomically
+	subscription *subscription
+}
+
+func (s *session) Send(data []byte) error {
+	if s.conn == nil {
+		return errors.New("WebSocket is null")
+	}
+
+	s.mtx.Lock()
+	err := s.conn.WriteMessage(websocket.TextMessage, data)
+	s.mtx.Unlock()
+	return err
+}
+
+// Touch marks the session active now.
+func (s *session) Touch() {
+	atomic.StoreInt64(&s.lastActive, time.Now().UnixNano())
+}
+
+// Expired returns if the session is inactive for the timeout.
+func (s *session) Expired(now time.Time, timeout time.Duration) bool {
+	lastActive := time.Unix(0, atomic.LoadInt64(&s.lastActive))
+	return !lastActive.Add(timeout).After(now)
+}
+
+func (s *session) Subscribe(sub *subscription) {
+	s.mtx.Lock()
+	s.subscription = sub
+	s.mtx.Unlock()
+}
+
+// Subscribed returns if the event should be pushed to the session.
+func (s *session) Subscribed(typ events.EventType, tx *events.CrossChainTx) bool {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.subscription == nil {
+		return false
+	}
+	return s.subscription.match(typ, tx)
+}
+
+type sessions struct {
+	sync.Map
+}
+
+func (ss *sessions) Delete(s *session) {
+	s.conn.Close()
+	ss.Map.Delete(s.id)
+}
+
+func (ss *sessions) Count() int {
+	count := 0
+	ss.Map.Range(func(k, v interface{}) bool {
+		count++
+		return true
+	})
+	return count
+}
+
+func (ss *sessions) Foreach(f func(*session)) {
+	ss.Map.Range(func(k, v interface{}) bool {
+		f(v.(*session))
+		return true
+	})
+}
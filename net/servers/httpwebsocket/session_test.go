@@ -0,0 +1,46 @@
+package httpwebsocket
+
+import (
+	"testing"
+
+	"github.com/elastos/Elastos.ELA.Arbiter/events"
+)
+
+func TestSubscription_Match(t *testing.T) {
+	tx := &events.CrossChainTx{
+		GenesisBlockAddress: "XKUh4GLhFJiqAMTF6HyWQrV9pK9HcGUdfJ",
+		Addresses:           []string{"EXe1z3KYtsCdnGUMGo4mA8DJbhWwDo3pzM"},
+	}
+
+	sub := &subscription{}
+	if !sub.match(events.ETDepositSeen, tx) {
+		t.Error("empty subscription should match all events")
+	}
+
+	sub = &subscription{
+		events: map[events.EventType]struct{}{events.ETDepositRecharged: {}},
+	}
+	if sub.match(events.ETDepositSeen, tx) {
+		t.Error("subscription should not match unsubscribed event")
+	}
+	if !sub.match(events.ETDepositRecharged, tx) {
+		t.Error("subscription should match subscribed event")
+	}
+
+	sub = &subscription{
+		sideChains: map[string]struct{}{"XVfmhjxGxBKgzYxyXCJTb6YmaRfWPVunj4": {}},
+	}
+	if sub.match(events.ETDepositSeen, tx) {
+		t.Error("subscription should not match other side chain")
+	}
+
+	sub = &subscription{
+		addresses: map[string]struct{}{"EXe1z3KYtsCdnGUMGo4mA8DJbhWwDo3pzM": {}},
+	}
+	if !sub.match(events.ETWithdrawFound, tx) {
+		t.Error("subscription should match subscribed address")
+	}
+	if sub.match(events.ETWithdrawFound, &events.CrossChainTx{}) {
+		t.Error("subscription should not match transaction without address")
+	}
+}
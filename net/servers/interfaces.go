@@ -12,6 +12,8 @@ import (
 	"github.com/elastos/Elastos.ELA.Arbiter/arbitration/cs"
 	"github.com/elastos/Elastos.ELA.Arbiter/config"
 	"github.com/elastos/Elastos.ELA.Arbiter/errors"
+	"github.com/elastos/Elastos.ELA.Arbiter/log"
+	"github.com/elastos/Elastos.ELA.Arbiter/rpc"
 	"github.com/elastos/Elastos.ELA.Arbiter/sideauxpow"
 	"github.com/elastos/Elastos.ELA.Arbiter/store"
 
@@ -33,10 +35,322 @@ func SetRegisterSideChainRPCInfo(param Params) map[string]interface{} {
 	if err != nil {
 		return ResponsePack(errors.InvalidParams, "can not unmarshal bytes")
 	}
-	arbitrator.ArbitratorGroupSingleton.GetCurrentArbitrator().GetSideChainManager().OnReceivedRegisteredSideChain(*rpcDetails)
+	err = arbitrator.ArbitratorGroupSingleton.GetCurrentArbitrator().GetSideChainManager().OnReceivedRegisteredSideChain(*rpcDetails)
+	if err != nil {
+		return ResponsePack(errors.InvalidParams, err.Error())
+	}
 	return ResponsePack(errors.Success, fmt.Sprint(""))
 }
 
+func GetPendingSideChainRegistrations(param Params) map[string]interface{} {
+	txs, err := store.DbCache.RegisteredSideChainStore.GetAllRegisteredSideChainTxs()
+	if err != nil {
+		return ResponsePack(errors.InternalError, "get registered side chains failed")
+	}
+	type registration struct {
+		Hash                string `json:"hash"`
+		GenesisBlockAddress string `json:"genesisblockaddress"`
+		GenesisBlockHash    string `json:"genesisblockhash"`
+		Name                string `json:"name"`
+		MagicNumber         uint32 `json:"magicnumber"`
+		ExchangeRate        string `json:"exchangerate"`
+		EffectiveHeight     uint32 `json:"effectiveheight"`
+		ResourcePath        string `json:"resourcepath,omitempty"`
+		Rpc                 string `json:"rpc,omitempty"`
+	}
+	result := make([]registration, 0, len(txs))
+	for _, tx := range txs {
+		r := registration{
+			Hash:                tx.TransactionHash,
+			GenesisBlockAddress: tx.GenesisBlockAddress,
+			GenesisBlockHash:    tx.RegisteredSideChain.GenesisHash.String(),
+			Name:                tx.RegisteredSideChain.SideChainName,
+			MagicNumber:         tx.RegisteredSideChain.MagicNumber,
+			ExchangeRate:        tx.RegisteredSideChain.ExchangeRate.String(),
+			EffectiveHeight:     tx.RegisteredSideChain.EffectiveHeight,
+			ResourcePath:        tx.RegisteredSideChain.ResourcePath,
+		}
+		review, err := store.RegistrationDbCache.GetRegistrationReview(tx.TransactionHash, tx.GenesisBlockAddress)
+		if err == nil && review != nil && review.RpcInfo != "" {
+			var info base.RegisterSidechainRpcInfo
+			if json.Unmarshal([]byte(review.RpcInfo), &info) == nil {
+				r.Rpc = fmt.Sprintf("%s:%d", info.IpAddr, info.Httpjsonport)
+			}
+		}
+		result = append(result, r)
+	}
+	return ResponsePack(errors.Success, result)
+}
+
+func ApproveSideChainRegistration(param Params) map[string]interface{} {
+	txHash, ok := param.String("hash")
+	if !ok {
+		return ResponsePack(errors.InvalidParams, "need a string parameter named hash")
+	}
+	node := &config.SideNodeConfig{}
+	if ip, ok := param.String("ipaddress"); ok {
+		port, ok := param.Int("httpjsonport")
+		if !ok || port <= 0 || port > 65535 {
+			return ResponsePack(errors.InvalidParams, "need a valid integer parameter named httpjsonport")
+		}
+		node.Rpc = &config.RpcConfig{IpAddress: ip, HttpJsonPort: int(port)}
+		node.Rpc.User, _ = param.String("user")
+		node.Rpc.Pass, _ = param.String("pass")
+	}
+	node.PowChain, _ = param.Bool("powchain")
+	node.SupportQuickRecharge, _ = param.Bool("supportquickrecharge")
+	node.SupportInvalidDeposit, _ = param.Bool("supportinvaliddeposit")
+	node.SupportInvalidWithdraw, _ = param.Bool("supportinvalidwithdraw")
+	node.SupportNFT, _ = param.Bool("supportnft")
+
+	err := arbitrator.ArbitratorGroupSingleton.GetCurrentArbitrator().GetSideChainManager().
+		ApproveRegisteredSideChain(txHash, node)
+	if err != nil {
+		audit("approve", "sidechain", txHash, node.GenesisBlockAddress, "failed: "+err.Error())
+		return ResponsePack(errors.InvalidParams, err.Error())
+	}
+	audit("approve", "sidechain", txHash, node.GenesisBlockAddress, fmt.Sprintf(
+		"%s, rpc %s:%d, powchain %t, quickrecharge %t, invaliddeposit %t, invalidwithdraw %t, nft %t",
+		node.Name, node.Rpc.IpAddress, node.Rpc.HttpJsonPort, node.PowChain, node.SupportQuickRecharge,
+		node.SupportInvalidDeposit, node.SupportInvalidWithdraw, node.SupportNFT))
+	return ResponsePack(errors.Success, true)
+}
+
+func RejectSideChainRegistration(param Params) map[string]interface{} {
+	txHash, ok := param.String("hash")
+	if !ok {
+		return ResponsePack(errors.InvalidParams, "need a string parameter named hash")
+	}
+	reason, _ := param.String("reason")
+	err := arbitrator.ArbitratorGroupSingleton.GetCurrentArbitrator().GetSideChainManager().
+		RejectRegisteredSideChain(txHash, reason)
+	if err != nil {
+		return ResponsePack(errors.InvalidParams, err.Error())
+	}
+	audit("reject", "sidechain", txHash, "", reason)
+	return ResponsePack(errors.Success, true)
+}
+
+func DecommissionSideChain(param Params) map[string]interface{} {
+	genesisAddress, ok := param.String("genesisblockaddress")
+	if !ok {
+		return ResponsePack(errors.InvalidParams, "need a string parameter named genesisblockaddress")
+	}
+	reason, _ := param.String("reason")
+	if _, ok := arbitrator.ArbitratorGroupSingleton.GetCurrentArbitrator().GetSideChainManager().
+		GetChain(genesisAddress); !ok {
+		return ResponsePack(errors.InvalidParams, "unknown side chain")
+	}
+	if err := arbitrator.DecommissionSingleton.Decommission(genesisAddress, reason); err != nil {
+		return ResponsePack(errors.InvalidParams, err.Error())
+	}
+	audit("decommission", "sidechain", "", genesisAddress, reason)
+	return ResponsePack(errors.Success, true)
+}
+
+func PauseSideChain(param Params) map[string]interface{} {
+	genesisAddress, ok := param.String("genesisblockaddress")
+	if !ok {
+		return ResponsePack(errors.InvalidParams, "need a string parameter named genesisblockaddress")
+	}
+	kind, ok := param.String("kind")
+	if !ok || kind != store.PendingDeposit && kind != store.PendingWithdraw {
+		return ResponsePack(errors.InvalidParams, "kind should be deposit or withdraw")
+	}
+	reason, _ := param.String("reason")
+	if _, ok := arbitrator.ArbitratorGroupSingleton.GetCurrentArbitrator().GetSideChainManager().
+		GetChain(genesisAddress); !ok {
+		return ResponsePack(errors.InvalidParams, "unknown side chain")
+	}
+	if err := arbitrator.CircuitBreakerSingleton.Pause(genesisAddress, kind, reason); err != nil {
+		return ResponsePack(errors.InvalidParams, err.Error())
+	}
+	audit("pause", kind, "", genesisAddress, reason)
+	return ResponsePack(errors.Success, true)
+}
+
+func ResumeSideChain(param Params) map[string]interface{} {
+	genesisAddress, ok := param.String("genesisblockaddress")
+	if !ok {
+		return ResponsePack(errors.InvalidParams, "need a string parameter named genesisblockaddress")
+	}
+	kind, ok := param.String("kind")
+	if !ok || kind != store.PendingDeposit && kind != store.PendingWithdraw {
+		return ResponsePack(errors.InvalidParams, "kind should be deposit or withdraw")
+	}
+	pause, ok := arbitrator.CircuitBreakerSingleton.Paused(genesisAddress, kind)
+	if !ok {
+		return ResponsePack(errors.InvalidParams, kind+"s are not paused")
+	}
+	if err := arbitrator.CircuitBreakerSingleton.Resume(genesisAddress, kind); err != nil {
+		return ResponsePack(errors.InvalidParams, err.Error())
+	}
+	audit("resume", kind, "", genesisAddress, "paused by "+pause.PausedBy)
+	go arbitrator.SendResumedTransactions(genesisAddress, kind)
+	return ResponsePack(errors.Success, true)
+}
+
+func GetSideChainPauses(param Params) map[string]interface{} {
+	genesisAddress, _ := param.String("genesisblockaddress")
+	type sideChainPause struct {
+		GenesisBlockAddress string `json:"genesisblockaddress"`
+		Kind                string `json:"kind"`
+		PausedBy            string `json:"pausedby"`
+		Reason              string `json:"reason"`
+		Time                int64  `json:"time"`
+	}
+	result := make([]sideChainPause, 0)
+	for _, p := range arbitrator.CircuitBreakerSingleton.Pauses() {
+		if genesisAddress != "" && p.GenesisBlockAddress != genesisAddress {
+			continue
+		}
+		result = append(result, sideChainPause{
+			GenesisBlockAddress: p.GenesisBlockAddress,
+			Kind:                p.Kind,
+			PausedBy:            p.PausedBy,
+			Reason:              p.Reason,
+			Time:                p.PauseTime,
+		})
+	}
+	return ResponsePack(errors.Success, result)
+}
+
+func GetCircuitBreakerTrips(param Params) map[string]interface{} {
+	genesisAddress, _ := param.String("genesisblockaddress")
+	trips, err := store.PauseDbCache.GetBreakerTrips(genesisAddress)
+	if err != nil {
+		return ResponsePack(errors.InternalError, "get breaker trips from dbcache failed")
+	}
+	type breakerTrip struct {
+		GenesisBlockAddress string `json:"genesisblockaddress"`
+		Breaker             string `json:"breaker"`
+		Detail              string `json:"detail"`
+		Time                int64  `json:"time"`
+	}
+	result := make([]breakerTrip, 0, len(trips))
+	for _, t := range trips {
+		result = append(result, breakerTrip{
+			GenesisBlockAddress: t.GenesisBlockAddress,
+			Breaker:             t.Breaker,
+			Detail:              t.Detail,
+			Time:                t.TripTime,
+		})
+	}
+	return ResponsePack(errors.Success, result)
+}
+
+func GetReconciliationReports(param Params) map[string]interface{} {
+	genesisAddress, _ := param.String("genesisblockaddress")
+	to := time.Now().Unix() + 1
+	if _, ok := param["to"]; ok {
+		if to, ok = param.Int("to"); !ok {
+			return ResponsePack(errors.InvalidParams, "to should be an integer")
+		}
+	}
+	from := to - 24*60*60
+	if _, ok := param["from"]; ok {
+		if from, ok = param.Int("from"); !ok || from > to {
+			return ResponsePack(errors.InvalidParams, "from should be an integer not greater than to")
+		}
+	}
+	limit := int64(100)
+	if _, ok := param["limit"]; ok {
+		l, ok := param.Int("limit")
+		if !ok || l < 0 {
+			return ResponsePack(errors.InvalidParams, "limit should be a non-negative integer")
+		}
+		limit = l
+	}
+
+	reports, err := store.ReconciliationDbCache.GetReconciliationReports(genesisAddress, from, to)
+	if err != nil {
+		return ResponsePack(errors.InternalError, "get reconciliation reports from dbcache failed")
+	}
+	if int64(len(reports)) > limit {
+		reports = reports[int64(len(reports))-limit:]
+	}
+	type reconciliationReport struct {
+		GenesisBlockAddress string `json:"genesisblockaddress"`
+		Locked              string `json:"locked,omitempty"`
+		Supply              string `json:"supply,omitempty"`
+		PendingWithdraws    string `json:"pendingwithdraws,omitempty"`
+		PendingDeposits     string `json:"pendingdeposits,omitempty"`
+		Discrepancy         string `json:"discrepancy,omitempty"`
+		Flagged             bool   `json:"flagged"`
+		Error               string `json:"error,omitempty"`
+		Time                int64  `json:"time"`
+	}
+	result := make([]reconciliationReport, 0, len(reports))
+	for _, r := range reports {
+		report := reconciliationReport{
+			GenesisBlockAddress: r.GenesisBlockAddress,
+			Flagged:             r.Flagged,
+			Error:               r.Error,
+			Time:                r.ReportTime,
+		}
+		if r.Error == "" {
+			report.Locked = r.Locked.String()
+			report.Supply = r.Supply.String()
+			report.PendingWithdraws = r.PendingWithdraws.String()
+			report.PendingDeposits = r.PendingDeposits.String()
+			report.Discrepancy = r.Discrepancy.String()
+		}
+		result = append(result, report)
+	}
+	return ResponsePack(errors.Success, result)
+}
+
+func GetSideChainDecommissions(param Params) map[string]interface{} {
+	genesisAddress, _ := param.String("genesisblockaddress")
+	type decommission struct {
+		GenesisBlockAddress   string `json:"genesisblockaddress"`
+		Stage                 string `json:"stage"`
+		Reason                string `json:"reason"`
+		StartTime             int64  `json:"starttime"`
+		RetireTime            int64  `json:"retiretime,omitempty"`
+		ReturnedDeposits      int    `json:"returneddeposits"`
+		PendingDeposits       int    `json:"pendingdeposits"`
+		PendingWithdraws      int    `json:"pendingwithdraws"`
+		PendingReturnDeposits int    `json:"pendingreturndeposits"`
+	}
+	_, depositAddresses, err := store.DbCache.MainChainStore.GetAllMainChainTxHashes()
+	if err != nil {
+		return ResponsePack(errors.InternalError, "get deposit transactions from dbcache failed")
+	}
+	result := make([]decommission, 0)
+	for _, d := range arbitrator.DecommissionSingleton.Decommissions() {
+		if genesisAddress != "" && d.GenesisBlockAddress != genesisAddress {
+			continue
+		}
+		r := decommission{
+			GenesisBlockAddress: d.GenesisBlockAddress,
+			Stage:               d.Stage,
+			Reason:              d.Reason,
+			StartTime:           d.StartTime,
+			RetireTime:          d.RetireTime,
+			ReturnedDeposits:    d.ReturnedDeposits,
+		}
+		for _, addr := range depositAddresses {
+			if addr == d.GenesisBlockAddress {
+				r.PendingDeposits++
+			}
+		}
+		if dbStore := store.DbCache.GetDataStoreGenesisBlocAddress(d.GenesisBlockAddress); dbStore != nil {
+			withdraws, err := dbStore.GetAllSideChainTxHashes()
+			if err != nil {
+				return ResponsePack(errors.InternalError, "get withdraw transactions from dbcache failed")
+			}
+			_, returnDeposits, err := dbStore.GetAllReturnDepositTx(d.GenesisBlockAddress)
+			if err != nil {
+				return ResponsePack(errors.InternalError, "get return deposit transactions from dbcache failed")
+			}
+			r.PendingWithdraws, r.PendingReturnDeposits = len(withdraws), len(returnDeposits)
+		}
+		result = append(result, r)
+	}
+	return ResponsePack(errors.Success, result)
+}
+
 func SubmitComplain(param Params) map[string]interface{} {
 	if !checkParam(param, "fromaddress", "transactionhash") {
 		return ResponsePack(errors.InvalidParams, "")
@@ -167,6 +481,153 @@ func GetSideMiningInfo(param Params) map[string]interface{} {
 	return ResponsePack(errors.Success, &Info)
 }
 
+func GetTreasury(param Params) map[string]interface{} {
+	type topUp struct {
+		TxHash string `json:"txhash"`
+		Amount string `json:"amount"`
+		Time   int64  `json:"time"`
+	}
+	type miningAccount struct {
+		SideChain string  `json:"sidechain"`
+		Address   string  `json:"address"`
+		Available string  `json:"available"`
+		Locked    string  `json:"locked"`
+		Pending   string  `json:"pending"`
+		SpendRate string  `json:"spendrate"`
+		Runway    int64   `json:"runway,omitempty"`
+		TopUps    []topUp `json:"topups"`
+	}
+	accounts, err := sideauxpow.GetMiningAccounts()
+	if err != nil {
+		return ResponsePack(errors.InternalError, "get mining accounts failed: "+err.Error())
+	}
+	result := struct {
+		DailyBudget string          `json:"dailybudget"`
+		Refilled    string          `json:"refilled"`
+		Accounts    []miningAccount `json:"accounts"`
+	}{
+		Refilled: sideauxpow.GetRefilledAmount().String(),
+		Accounts: make([]miningAccount, 0, len(accounts)),
+	}
+	if treasury := config.Parameters.Treasury; treasury != nil {
+		result.DailyBudget = treasury.DailyBudget.String()
+	}
+	for _, a := range accounts {
+		account := miningAccount{
+			SideChain: a.SideChain,
+			Address:   a.Address,
+			Available: a.Available.String(),
+			Locked:    a.Locked.String(),
+			Pending:   a.Pending.String(),
+			SpendRate: a.SpendRate.String(),
+			Runway:    int64(a.Runway / time.Second),
+			TopUps:    make([]topUp, 0, len(a.TopUps)),
+		}
+		for _, t := range a.TopUps {
+			account.TopUps = append(account.TopUps, topUp{
+				TxHash: common.ToReversedString(t.TxHash),
+				Amount: t.Amount.String(),
+				Time:   t.Time.Unix(),
+			})
+		}
+		result.Accounts = append(result.Accounts, account)
+	}
+	return ResponsePack(errors.Success, result)
+}
+
+func GetSideMiningHistory(param Params) map[string]interface{} {
+	filter, _ := param.String("genesisblockaddress")
+	to := time.Now().Unix() + 1
+	if _, ok := param["to"]; ok {
+		if to, ok = param.Int("to"); !ok {
+			return ResponsePack(errors.InvalidParams, "to should be an integer")
+		}
+	}
+	from := to - 24*60*60
+	if _, ok := param["from"]; ok {
+		if from, ok = param.Int("from"); !ok || from > to {
+			return ResponsePack(errors.InvalidParams, "from should be an integer not greater than to")
+		}
+	}
+	limit := int64(100)
+	if _, ok := param["limit"]; ok {
+		l, ok := param.Int("limit")
+		if !ok || l < 0 {
+			return ResponsePack(errors.InvalidParams, "limit should be a non-negative integer")
+		}
+		limit = l
+	}
+
+	type sideMiningTx struct {
+		Hash            string `json:"hash"`
+		SideBlockHash   string `json:"sideblockhash"`
+		SideBlockHeight uint32 `json:"sideblockheight"`
+		Fee             string `json:"fee"`
+		SendHeight      uint32 `json:"sendheight"`
+		SendTime        int64  `json:"sendtime"`
+		InclusionHeight uint32 `json:"inclusionheight,omitempty"`
+		Result          string `json:"result"`
+		Error           string `json:"error,omitempty"`
+		SubmitTime      int64  `json:"submittime,omitempty"`
+		FeeStrategy     string `json:"feestrategy,omitempty"`
+		FeeReason       string `json:"feereason,omitempty"`
+	}
+	type sideMiningHistory struct {
+		Name                string         `json:"name"`
+		GenesisBlockAddress string         `json:"genesisblockaddress"`
+		Blocks              int            `json:"blocks"`
+		Submitted           int            `json:"submitted"`
+		Missed              int            `json:"missed"`
+		Pending             int            `json:"pending"`
+		MissRate            float64        `json:"missrate"`
+		Fees                string         `json:"fees"`
+		Txs                 []sideMiningTx `json:"txs"`
+	}
+	result := make([]sideMiningHistory, 0)
+	for _, node := range config.SideNodes() {
+		if !node.PowChain || filter != "" && filter != node.GenesisBlockAddress {
+			continue
+		}
+		txs, err := store.SideMiningDbCache.GetSideMiningTxs(node.GenesisBlockAddress, from, to)
+		if err != nil {
+			return ResponsePack(errors.InternalError, "get side mining transactions failed")
+		}
+		summary := sideauxpow.SummarizeSideMining(txs)
+		history := sideMiningHistory{
+			Name:                node.Name,
+			GenesisBlockAddress: node.GenesisBlockAddress,
+			Blocks:              summary.Blocks,
+			Submitted:           summary.Submitted,
+			Missed:              summary.Missed,
+			Pending:             summary.Pending,
+			MissRate:            summary.MissRate(),
+			Fees:                summary.Fees.String(),
+			Txs:                 make([]sideMiningTx, 0),
+		}
+		if int64(len(txs)) > limit {
+			txs = txs[int64(len(txs))-limit:]
+		}
+		for _, tx := range txs {
+			history.Txs = append(history.Txs, sideMiningTx{
+				Hash:            tx.TransactionHash,
+				SideBlockHash:   tx.SideBlockHash,
+				SideBlockHeight: tx.SideBlockHeight,
+				Fee:             tx.Fee.String(),
+				SendHeight:      tx.SendHeight,
+				SendTime:        tx.SendTime,
+				InclusionHeight: tx.InclusionHeight,
+				Result:          tx.Result,
+				Error:           tx.Error,
+				SubmitTime:      tx.SubmitTime,
+				FeeStrategy:     tx.FeeStrategy,
+				FeeReason:       tx.FeeReason,
+			})
+		}
+		result = append(result, history)
+	}
+	return ResponsePack(errors.Success, result)
+}
+
 func GetMainChainBlockHeight(param Params) map[string]interface{} {
 	return ResponsePack(errors.Success, store.DbCache.MainChainStore.CurrentHeight(0))
 }
@@ -249,6 +710,14 @@ func GetGitVersion(param Params) map[string]interface{} {
 	return ResponsePack(errors.Success, config.Version)
 }
 
+func GetEffectiveConfig(param Params) map[string]interface{} {
+	result, err := config.Parameters.Redacted()
+	if err != nil {
+		return ResponsePack(errors.InternalError, "marshal config failed")
+	}
+	return ResponsePack(errors.Success, result)
+}
+
 func GetSPVHeight(param Params) map[string]interface{} {
 	bestHeader, err := arbitrator.SpvService.HeaderStore().GetBest()
 	if err != nil {
@@ -279,3 +748,676 @@ func GetArbiterPeersInfo(params Params) map[string]interface{} {
 	}
 	return ResponsePack(errors.Success, result)
 }
+
+func GetSideChains(param Params) map[string]interface{} {
+	type sideChainInfo struct {
+		Name                string `json:"name"`
+		GenesisBlockAddress string `json:"genesisblockaddress"`
+		GenesisBlock        string `json:"genesisblock"`
+		PowChain            bool   `json:"powchain"`
+		Height              uint32 `json:"height"`
+		ConfirmationDepth   uint32 `json:"confirmationdepth"`
+	}
+	result := make([]sideChainInfo, 0)
+	for _, node := range config.SideNodes() {
+		info := sideChainInfo{
+			Name:                node.Name,
+			GenesisBlockAddress: node.GenesisBlockAddress,
+			GenesisBlock:        node.GenesisBlock,
+			PowChain:            node.PowChain,
+			ConfirmationDepth:   node.GetConfirmationDepth(),
+		}
+		if dbStore := store.DbCache.GetDataStoreGenesisBlocAddress(node.GenesisBlockAddress); dbStore != nil {
+			info.Height = dbStore.CurrentSideHeight(store.QueryHeightCode)
+		}
+		result = append(result, info)
+	}
+	return ResponsePack(errors.Success, result)
+}
+
+func GetSideChainEndpoints(param Params) map[string]interface{} {
+	type endpointInfo struct {
+		IpAddress    string `json:"ipaddress"`
+		HttpJsonPort int    `json:"httpjsonport"`
+		Height       uint32 `json:"height"`
+		Latency      int64  `json:"latency"`
+		LastCheck    int64  `json:"lastcheck,omitempty"`
+		Error        string `json:"error,omitempty"`
+		Healthy      bool   `json:"healthy"`
+		Active       bool   `json:"active"`
+	}
+	type sideChainEndpoints struct {
+		Name                string         `json:"name"`
+		GenesisBlockAddress string         `json:"genesisblockaddress"`
+		Endpoints           []endpointInfo `json:"endpoints"`
+	}
+	filter, _ := param.String("genesisblockaddress")
+	status := rpc.GetEndpointsStatus()
+	result := make([]sideChainEndpoints, 0)
+	for _, node := range config.SideNodes() {
+		if filter != "" && filter != node.GenesisBlockAddress {
+			continue
+		}
+		info := sideChainEndpoints{
+			Name:                node.Name,
+			GenesisBlockAddress: node.GenesisBlockAddress,
+			Endpoints:           make([]endpointInfo, 0),
+		}
+		for _, s := range status[node.Name] {
+			info.Endpoints = append(info.Endpoints, endpointInfo{
+				IpAddress:    s.IpAddress,
+				HttpJsonPort: s.HttpJsonPort,
+				Height:       s.Height,
+				Latency:      s.Latency.Milliseconds(),
+				LastCheck:    s.LastCheck,
+				Error:        s.Error,
+				Healthy:      s.Healthy,
+				Active:       s.Active,
+			})
+		}
+		result = append(result, info)
+	}
+	return ResponsePack(errors.Success, result)
+}
+
+type pendingTx struct {
+	Hash                string `json:"hash"`
+	GenesisBlockAddress string `json:"genesisblockaddress"`
+	BlockHeight         uint32 `json:"blockheight,omitempty"`
+	FirstSeen           int64  `json:"firstseen,omitempty"`
+	Age                 int64  `json:"age"`
+	RetryCount          int    `json:"retrycount"`
+	LastRetry           int64  `json:"lastretry,omitempty"`
+	NextRetry           int64  `json:"nextretry,omitempty"`
+	LastError           string `json:"lasterror,omitempty"`
+	Confirmations       uint32 `json:"confirmations,omitempty"`
+	Verification        string `json:"verification,omitempty"`
+	VerifyError         string `json:"verifyerror,omitempty"`
+	RejectReason        string `json:"rejectreason,omitempty"`
+}
+
+// newPendingTx fills the attempts info of a pending transaction, the age is
+// counted from the first attempt of sending it.
+func newPendingTx(hash, genesisAddress string, infos map[string]*store.PendingTxInfo, now int64) pendingTx {
+	tx := pendingTx{Hash: hash, GenesisBlockAddress: genesisAddress}
+	if info, ok := infos[hash+genesisAddress]; ok {
+		tx.FirstSeen = info.FirstSeen
+		tx.Age = now - info.FirstSeen
+		tx.RetryCount = info.RetryCount
+		tx.LastRetry = info.LastRetry
+		tx.NextRetry = info.NextRetry
+		tx.LastError = info.LastError
+	}
+	return tx
+}
+
+func getPendingTxsInfo(kind string) (map[string]*store.PendingTxInfo, error) {
+	infos, err := store.PendingTxsDbCache.GetPendingTxsInfo(kind)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]*store.PendingTxInfo, len(infos))
+	for _, info := range infos {
+		result[info.TransactionHash+info.GenesisBlockAddress] = info
+	}
+	return result, nil
+}
+
+func GetPendingDepositTxs(param Params) map[string]interface{} {
+	filter, _ := param.String("genesisblockaddress")
+	txHashes, genesisAddresses, err := store.DbCache.MainChainStore.GetAllMainChainTxHashes()
+	if err != nil {
+		return ResponsePack(errors.InternalError, "get deposit transactions from dbcache failed")
+	}
+	infos, err := getPendingTxsInfo(store.PendingDeposit)
+	if err != nil {
+		return ResponsePack(errors.InternalError, "get deposit transactions info failed")
+	}
+	verifications, err := store.PendingTxsDbCache.GetDepositVerifications()
+	if err != nil {
+		return ResponsePack(errors.InternalError, "get deposit verifications failed")
+	}
+	verified := make(map[string]*store.DepositVerification, len(verifications))
+	for _, v := range verifications {
+		verified[v.TransactionHash+v.GenesisBlockAddress] = v
+	}
+	now := time.Now().Unix()
+	result := make([]pendingTx, 0, len(txHashes))
+	for i := 0; i < len(txHashes); i++ {
+		if filter != "" && filter != genesisAddresses[i] {
+			continue
+		}
+		tx := newPendingTx(txHashes[i], genesisAddresses[i], infos, now)
+		if v, ok := verified[txHashes[i]+genesisAddresses[i]]; ok {
+			tx.BlockHeight = v.BlockHeight
+			tx.Confirmations = v.Confirmations
+			tx.Verification = v.Status
+			tx.VerifyError = v.Error
+		}
+		result = append(result, tx)
+	}
+	return ResponsePack(errors.Success, result)
+}
+
+func GetPendingWithdrawTxs(param Params) map[string]interface{} {
+	filter, _ := param.String("genesisblockaddress")
+	infos, err := getPendingTxsInfo(store.PendingWithdraw)
+	if err != nil {
+		return ResponsePack(errors.InternalError, "get withdraw transactions info failed")
+	}
+	now := time.Now().Unix()
+	result := make([]pendingTx, 0)
+	for _, dbStore := range store.DbCache.SideChainStore {
+		if filter != "" && filter != dbStore.GenesisBlockAddress() {
+			continue
+		}
+		txHashes, blockHeights, err := dbStore.GetAllSideChainTxHashesAndHeights()
+		if err != nil {
+			return ResponsePack(errors.InternalError, "get withdraw transactions from dbcache failed")
+		}
+		rejectReasons := make(map[string]string)
+		if len(txHashes) != 0 {
+			withdrawTxs, err := dbStore.GetSideChainTxsFromHashes(txHashes)
+			if err != nil {
+				return ResponsePack(errors.InternalError, "get withdraw transactions from dbcache failed")
+			}
+			for _, w := range withdrawTxs {
+				if w.Txid != nil {
+					rejectReasons[w.Txid.String()] = w.RejectReason
+				}
+			}
+		}
+		for i := 0; i < len(txHashes); i++ {
+			tx := newPendingTx(txHashes[i], dbStore.GenesisBlockAddress(), infos, now)
+			tx.BlockHeight = blockHeights[i]
+			tx.RejectReason = rejectReasons[txHashes[i]]
+			result = append(result, tx)
+		}
+	}
+	return ResponsePack(errors.Success, result)
+}
+
+func GetPendingReturnDepositTxs(param Params) map[string]interface{} {
+	type returnDepositTx struct {
+		Hash                string `json:"hash"`
+		GenesisBlockAddress string `json:"genesisblockaddress"`
+	}
+	result := make([]returnDepositTx, 0)
+	for _, dbStore := range store.DbCache.SideChainStore {
+		txHashes, err := dbStore.GetAllReturnDepositTxs()
+		if err != nil {
+			return ResponsePack(errors.InternalError, "get return deposit transactions from dbcache failed")
+		}
+		for _, hash := range txHashes {
+			result = append(result, returnDepositTx{
+				Hash:                hash,
+				GenesisBlockAddress: dbStore.GenesisBlockAddress(),
+			})
+		}
+	}
+	return ResponsePack(errors.Success, result)
+}
+
+// pendingTxsParams returns the kind, genesis block address and hashes of
+// the pending transactions to intervene.
+func pendingTxsParams(param Params) (string, string, []string, string, bool) {
+	kind, ok := param.String("kind")
+	if !ok || kind != store.PendingDeposit && kind != store.PendingWithdraw {
+		return "", "", nil, "kind should be deposit or withdraw", false
+	}
+	genesisAddress, ok := param.String("genesisblockaddress")
+	if !ok {
+		return "", "", nil, "need a string parameter named genesisblockaddress", false
+	}
+	txHashes, ok := param.ArrayString("hashes")
+	if !ok || len(txHashes) == 0 {
+		return "", "", nil, "need a non-empty array parameter named hashes", false
+	}
+	return kind, genesisAddress, txHashes, "", true
+}
+
+func audit(action, kind, txHash, genesisAddress, detail string) {
+	log.Info("[Audit]", action, kind, txHash, genesisAddress, detail)
+	err := store.PendingTxsDbCache.AddAuditRecord(&store.AuditRecord{
+		Action:              action,
+		Kind:                kind,
+		TransactionHash:     txHash,
+		GenesisBlockAddress: genesisAddress,
+		Detail:              detail,
+	})
+	if err != nil {
+		log.Error("[Audit] add audit record failed:", err)
+	}
+}
+
+type interventionResult struct {
+	Succeeded []string          `json:"succeeded"`
+	Failed    map[string]string `json:"failed"`
+}
+
+func ReproposeWithdrawTxs(param Params) map[string]interface{} {
+	genesisAddress, ok := param.String("genesisblockaddress")
+	if !ok {
+		return ResponsePack(errors.InvalidParams, "need a string parameter named genesisblockaddress")
+	}
+	txHashes, ok := param.ArrayString("hashes")
+	if !ok || len(txHashes) == 0 {
+		return ResponsePack(errors.InvalidParams, "need a non-empty array parameter named hashes")
+	}
+	currentArbitrator := arbitrator.ArbitratorGroupSingleton.GetCurrentArbitrator()
+	if !currentArbitrator.IsOnDutyOfMain() {
+		return ResponsePack(errors.Error, "current arbiter is not on duty")
+	}
+	sideChain, ok := currentArbitrator.GetSideChainManager().GetChain(genesisAddress)
+	if !ok {
+		return ResponsePack(errors.InvalidParams, "unknown genesis block address")
+	}
+	dbStore := store.DbCache.GetDataStoreGenesisBlocAddress(genesisAddress)
+	if dbStore == nil {
+		return ResponsePack(errors.InvalidParams, "unknown genesis block address")
+	}
+
+	result := interventionResult{Succeeded: make([]string, 0), Failed: make(map[string]string)}
+	var pendingHashes []string
+	for _, txHash := range txHashes {
+		if exist, err := dbStore.HasSideChainTx(txHash); err != nil || !exist {
+			result.Failed[txHash] = "not found in pending withdraw transactions"
+			continue
+		}
+		pendingHashes = append(pendingHashes, txHash)
+	}
+	if len(pendingHashes) != 0 {
+		if err := sideChain.CreateAndBroadcastWithdrawProposal(pendingHashes); err != nil {
+			for _, txHash := range pendingHashes {
+				result.Failed[txHash] = err.Error()
+			}
+		} else {
+			result.Succeeded = pendingHashes
+		}
+	}
+
+	for _, txHash := range result.Succeeded {
+		audit("repropose", store.PendingWithdraw, txHash, genesisAddress, "proposal broadcast")
+	}
+	for txHash, reason := range result.Failed {
+		audit("repropose", store.PendingWithdraw, txHash, genesisAddress, "failed: "+reason)
+	}
+	return ResponsePack(errors.Success, result)
+}
+
+func QuarantineTxs(param Params) map[string]interface{} {
+	kind, genesisAddress, txHashes, reason, ok := pendingTxsParams(param)
+	if !ok {
+		return ResponsePack(errors.InvalidParams, reason)
+	}
+	note, _ := param.String("reason")
+
+	result := interventionResult{Succeeded: make([]string, 0), Failed: make(map[string]string)}
+	for _, txHash := range txHashes {
+		if err := quarantineTx(kind, genesisAddress, txHash, note); err != nil {
+			result.Failed[txHash] = err.Error()
+			audit("quarantine", kind, txHash, genesisAddress, "failed: "+err.Error())
+			continue
+		}
+		result.Succeeded = append(result.Succeeded, txHash)
+		audit("quarantine", kind, txHash, genesisAddress, note)
+	}
+	return ResponsePack(errors.Success, result)
+}
+
+func quarantineTx(kind, genesisAddress, txHash, reason string) error {
+	tx := &store.QuarantinedTx{
+		Kind:                kind,
+		TransactionHash:     txHash,
+		GenesisBlockAddress: genesisAddress,
+		Reason:              reason,
+	}
+	var err error
+	var sideStore store.DataStoreSideChain
+	if kind == store.PendingDeposit {
+		tx.TransactionData, tx.MerkleProof, err = store.DbCache.MainChainStore.GetMainChainTxData(txHash, genesisAddress)
+	} else {
+		sideStore = store.DbCache.GetDataStoreGenesisBlocAddress(genesisAddress)
+		if sideStore == nil {
+			return fmt.Errorf("unknown genesis block address")
+		}
+		tx.TransactionData, tx.BlockHeight, err = sideStore.GetSideChainTxData(txHash)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := store.PendingTxsDbCache.AddQuarantinedTx(tx); err != nil {
+		return err
+	}
+	if kind == store.PendingDeposit {
+		err = store.DbCache.MainChainStore.RemoveMainChainTx(txHash, genesisAddress)
+	} else {
+		err = sideStore.RemoveSideChainTxs([]string{txHash})
+	}
+	if err != nil {
+		store.PendingTxsDbCache.RemoveQuarantinedTx(kind, txHash, genesisAddress)
+		return err
+	}
+	return store.PendingTxsDbCache.RemovePendingTxsInfo(kind, []string{txHash}, []string{genesisAddress})
+}
+
+func RestoreTxs(param Params) map[string]interface{} {
+	kind, genesisAddress, txHashes, reason, ok := pendingTxsParams(param)
+	if !ok {
+		return ResponsePack(errors.InvalidParams, reason)
+	}
+
+	result := interventionResult{Succeeded: make([]string, 0), Failed: make(map[string]string)}
+	for _, txHash := range txHashes {
+		if err := restoreTx(kind, genesisAddress, txHash); err != nil {
+			result.Failed[txHash] = err.Error()
+			audit("restore", kind, txHash, genesisAddress, "failed: "+err.Error())
+			continue
+		}
+		result.Succeeded = append(result.Succeeded, txHash)
+		audit("restore", kind, txHash, genesisAddress, "")
+	}
+	return ResponsePack(errors.Success, result)
+}
+
+func restoreTx(kind, genesisAddress, txHash string) error {
+	tx, err := store.PendingTxsDbCache.GetQuarantinedTx(kind, txHash, genesisAddress)
+	if err != nil {
+		return err
+	}
+	if kind == store.PendingDeposit {
+		err = store.DbCache.MainChainStore.AddMainChainTxData(txHash, genesisAddress, tx.TransactionData, tx.MerkleProof)
+	} else {
+		sideStore := store.DbCache.GetDataStoreGenesisBlocAddress(genesisAddress)
+		if sideStore == nil {
+			return fmt.Errorf("unknown genesis block address")
+		}
+		err = sideStore.AddSideChainTx(&base.SideChainTransaction{
+			TransactionHash: txHash,
+			Transaction:     tx.TransactionData,
+			BlockHeight:     tx.BlockHeight,
+		})
+	}
+	if err != nil {
+		return err
+	}
+	return store.PendingTxsDbCache.RemoveQuarantinedTx(kind, txHash, genesisAddress)
+}
+
+func GetQuarantinedTxs(param Params) map[string]interface{} {
+	txs, err := store.PendingTxsDbCache.GetQuarantinedTxs()
+	if err != nil {
+		return ResponsePack(errors.InternalError, "get quarantined transactions failed")
+	}
+	type quarantinedTx struct {
+		Kind                string `json:"kind"`
+		Hash                string `json:"hash"`
+		GenesisBlockAddress string `json:"genesisblockaddress"`
+		BlockHeight         uint32 `json:"blockheight,omitempty"`
+		Reason              string `json:"reason"`
+		Time                int64  `json:"time"`
+	}
+	result := make([]quarantinedTx, 0, len(txs))
+	for _, tx := range txs {
+		result = append(result, quarantinedTx{
+			Kind:                tx.Kind,
+			Hash:                tx.TransactionHash,
+			GenesisBlockAddress: tx.GenesisBlockAddress,
+			BlockHeight:         tx.BlockHeight,
+			Reason:              tx.Reason,
+			Time:                tx.RecordTime,
+		})
+	}
+	return ResponsePack(errors.Success, result)
+}
+
+func GetAuditTrail(param Params) map[string]interface{} {
+	limit := int64(100)
+	if _, ok := param["limit"]; ok {
+		l, ok := param.Int("limit")
+		if !ok || l <= 0 {
+			return ResponsePack(errors.InvalidParams, "limit should be a positive integer")
+		}
+		limit = l
+	}
+	records, err := store.PendingTxsDbCache.GetAuditRecords(int(limit))
+	if err != nil {
+		return ResponsePack(errors.InternalError, "get audit records failed")
+	}
+	type auditRecord struct {
+		Action              string `json:"action"`
+		Kind                string `json:"kind"`
+		Hash                string `json:"hash"`
+		GenesisBlockAddress string `json:"genesisblockaddress"`
+		Detail              string `json:"detail,omitempty"`
+		Time                int64  `json:"time"`
+	}
+	result := make([]auditRecord, 0, len(records))
+	for _, r := range records {
+		result = append(result, auditRecord{
+			Action:              r.Action,
+			Kind:                r.Kind,
+			Hash:                r.TransactionHash,
+			GenesisBlockAddress: r.GenesisBlockAddress,
+			Detail:              r.Detail,
+			Time:                r.RecordTime,
+		})
+	}
+	return ResponsePack(errors.Success, result)
+}
+
+func GetRejectedProposals(param Params) map[string]interface{} {
+	limit := int64(100)
+	if _, ok := param["limit"]; ok {
+		l, ok := param.Int("limit")
+		if !ok || l <= 0 {
+			return ResponsePack(errors.InvalidParams, "limit should be a positive integer")
+		}
+		limit = l
+	}
+	proposals, err := store.ProposalDbCache.GetRejectedProposals(int(limit))
+	if err != nil {
+		return ResponsePack(errors.InternalError, "get rejected proposals failed")
+	}
+	type rejectedProposal struct {
+		Hash                string `json:"hash"`
+		GenesisBlockAddress string `json:"genesisblockaddress"`
+		Amount              string `json:"amount"`
+		Status              string `json:"status"`
+		Reason              string `json:"reason"`
+		Approved            bool   `json:"approved"`
+		Time                int64  `json:"time"`
+	}
+	result := make([]rejectedProposal, 0, len(proposals))
+	for _, p := range proposals {
+		result = append(result, rejectedProposal{
+			Hash:                p.TransactionHash,
+			GenesisBlockAddress: p.GenesisBlockAddress,
+			Amount:              p.Amount.String(),
+			Status:              p.Status,
+			Reason:              p.Reason,
+			Approved:            p.Approved,
+			Time:                p.RecordTime,
+		})
+	}
+	return ResponsePack(errors.Success, result)
+}
+
+func ApproveProposal(param Params) map[string]interface{} {
+	txHash, ok := param.String("hash")
+	if !ok {
+		return ResponsePack(errors.InvalidParams, "need a string parameter named hash")
+	}
+	p, err := store.ProposalDbCache.ApproveProposal(txHash)
+	if err != nil {
+		return ResponsePack(errors.InvalidParams, err.Error())
+	}
+	audit("approve", "proposal", txHash, p.GenesisBlockAddress, p.Reason)
+	return ResponsePack(errors.Success, true)
+}
+
+func GetSmallCrossTxs(param Params) map[string]interface{} {
+	genesisAddress, _ := param.String("genesisblockaddress")
+	state, _ := param.String("state")
+	switch state {
+	case "", store.SmallCrossTxPending, store.SmallCrossTxSent, store.SmallCrossTxFailed,
+		store.SmallCrossTxFinished:
+	default:
+		return ResponsePack(errors.InvalidParams, "state should be pending, sent, failed or finished")
+	}
+	txs, err := store.SmallCrossTxsDbCache.GetSmallCrossTxs(genesisAddress, state)
+	if err != nil {
+		return ResponsePack(errors.InternalError, "get small cross transfers failed")
+	}
+	type smallCrossTx struct {
+		Hash                string `json:"hash"`
+		GenesisBlockAddress string `json:"genesisblockaddress"`
+		State               string `json:"state"`
+		Response            string `json:"response,omitempty"`
+		Attempts            int    `json:"attempts"`
+		FirstSeen           int64  `json:"firstseen"`
+		LastAttempt         int64  `json:"lastattempt,omitempty"`
+	}
+	result := make([]smallCrossTx, 0, len(txs))
+	for _, tx := range txs {
+		result = append(result, smallCrossTx{
+			Hash:                tx.TransactionHash,
+			GenesisBlockAddress: tx.GenesisBlockAddress,
+			State:               tx.State,
+			Response:            tx.Response,
+			Attempts:            tx.Attempts,
+			FirstSeen:           tx.FirstSeen,
+			LastAttempt:         tx.LastAttempt,
+		})
+	}
+	return ResponsePack(errors.Success, result)
+}
+
+func FreezeAddress(param Params) map[string]interface{} {
+	address, ok := param.String("address")
+	if !ok {
+		return ResponsePack(errors.InvalidParams, "need a string parameter named address")
+	}
+	reason, _ := param.String("reason")
+	var expiry int64
+	if _, ok := param["expiry"]; ok {
+		if expiry, ok = param.Int("expiry"); !ok || expiry < 0 {
+			return ResponsePack(errors.InvalidParams, "expiry should be a unix time or 0")
+		}
+	}
+	if err := arbitrator.FrozenRegistrySingleton.Freeze(address, reason, expiry); err != nil {
+		return ResponsePack(errors.InvalidParams, err.Error())
+	}
+	audit("freeze", "address", address, "", fmt.Sprintf("%s, expiry %d", reason, expiry))
+	return ResponsePack(errors.Success, true)
+}
+
+func UnfreezeAddress(param Params) map[string]interface{} {
+	address, ok := param.String("address")
+	if !ok {
+		return ResponsePack(errors.InvalidParams, "need a string parameter named address")
+	}
+	if err := arbitrator.FrozenRegistrySingleton.Unfreeze(address); err != nil {
+		return ResponsePack(errors.InvalidParams, err.Error())
+	}
+	audit("unfreeze", "address", address, "", "")
+	return ResponsePack(errors.Success, true)
+}
+
+func GetFrozenAddresses(param Params) map[string]interface{} {
+	type frozenAddress struct {
+		Address string `json:"address"`
+		Reason  string `json:"reason"`
+		Expiry  int64  `json:"expiry"`
+		Expired bool   `json:"expired"`
+		Time    int64  `json:"time,omitempty"`
+	}
+	now := time.Now().Unix()
+	addrs := arbitrator.FrozenRegistrySingleton.FrozenAddresses()
+	result := make([]frozenAddress, 0, len(addrs))
+	for _, addr := range addrs {
+		result = append(result, frozenAddress{
+			Address: addr.Address,
+			Reason:  addr.Reason,
+			Expiry:  addr.Expiry,
+			Expired: addr.Expiry != 0 && now >= addr.Expiry,
+			Time:    addr.RecordTime,
+		})
+	}
+	return ResponsePack(errors.Success, result)
+}
+
+func GetBlockedWithdrawTxs(param Params) map[string]interface{} {
+	genesisAddress, _ := param.String("genesisblockaddress")
+	status, _ := param.String("status")
+	switch status {
+	case "", store.WithdrawBlocked, store.WithdrawReleased, store.WithdrawReturned:
+	default:
+		return ResponsePack(errors.InvalidParams, "status should be blocked, released or returned")
+	}
+	type blockedWithdrawTx struct {
+		Hash                string `json:"hash"`
+		GenesisBlockAddress string `json:"genesisblockaddress"`
+		Address             string `json:"address"`
+		Reason              string `json:"reason"`
+		Status              string `json:"status"`
+		BlockTime           int64  `json:"blocktime"`
+		UpdateTime          int64  `json:"updatetime"`
+	}
+	result := make([]blockedWithdrawTx, 0)
+	for _, w := range arbitrator.FrozenRegistrySingleton.BlockedWithdraws() {
+		if genesisAddress != "" && w.GenesisBlockAddress != genesisAddress ||
+			status != "" && w.Status != status {
+			continue
+		}
+		result = append(result, blockedWithdrawTx{
+			Hash:                w.TransactionHash,
+			GenesisBlockAddress: w.GenesisBlockAddress,
+			Address:             w.Address,
+			Reason:              w.Reason,
+			Status:              w.Status,
+			BlockTime:           w.BlockTime,
+			UpdateTime:          w.UpdateTime,
+		})
+	}
+	return ResponsePack(errors.Success, result)
+}
+
+func ReleaseWithdrawTxs(param Params) map[string]interface{} {
+	return resolveBlockedWithdrawTxs(param, "release", arbitrator.FrozenRegistrySingleton.Release)
+}
+
+func ReturnWithdrawTxs(param Params) map[string]interface{} {
+	genesisAddress, _ := param.String("genesisblockaddress")
+	for _, node := range config.SideNodes() {
+		if node.GenesisBlockAddress == genesisAddress && !node.SupportInvalidWithdraw {
+			return ResponsePack(errors.InvalidParams, "side chain does not support invalid withdraw")
+		}
+	}
+	return resolveBlockedWithdrawTxs(param, "return", arbitrator.FrozenRegistrySingleton.Return)
+}
+
+func resolveBlockedWithdrawTxs(param Params, action string,
+	resolve func(genesisAddress, txHash string) error) map[string]interface{} {
+	genesisAddress, ok := param.String("genesisblockaddress")
+	if !ok {
+		return ResponsePack(errors.InvalidParams, "need a string parameter named genesisblockaddress")
+	}
+	txHashes, ok := param.ArrayString("hashes")
+	if !ok || len(txHashes) == 0 {
+		return ResponsePack(errors.InvalidParams, "need a non-empty array parameter named hashes")
+	}
+
+	result := interventionResult{Succeeded: make([]string, 0), Failed: make(map[string]string)}
+	for _, txHash := range txHashes {
+		if err := resolve(genesisAddress, txHash); err != nil {
+			result.Failed[txHash] = err.Error()
+			audit(action, store.PendingWithdraw, txHash, genesisAddress, "failed: "+err.Error())
+			continue
+		}
+		result.Succeeded = append(result.Succeeded, txHash)
+		audit(action, store.PendingWithdraw, txHash, genesisAddress, "blocked by frozen address")
+	}
+	return ResponsePack(errors.Success, result)
+}
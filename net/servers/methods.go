@@ -0,0 +1,215 @@
+package servers
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/elastos/Elastos.ELA.Arbiter/config"
+)
+
+// OpenRPCVersion is the version of OpenRPC specification the discover
+// document follows.
+const OpenRPCVersion = "1.2.6"
+
+// ParamType is the json schema type of a rpc parameter or result.
+type ParamType string
+
+const (
+	TypeString  ParamType = "string"
+	TypeInteger ParamType = "integer"
+	TypeNumber  ParamType = "number"
+	TypeBoolean ParamType = "boolean"
+	TypeArray   ParamType = "array"
+	TypeObject  ParamType = "object"
+)
+
+// Param describes a named parameter of a rpc method, Items is the type of
+// elements when Type is TypeArray.
+type Param struct {
+	Name        string
+	Type        ParamType
+	Items       ParamType
+	Required    bool
+	Description string
+}
+
+// Result describes the result of a rpc method.
+type Result struct {
+	Name        string
+	Type        ParamType
+	Description string
+}
+
+// Method is a rpc method with the description of its request and response,
+// the parameters are validated by the description before calling Handler.
+type Method struct {
+	Name    string
+	Summary string
+	Params  []*Param
+	Result  *Result
+	Handler func(Params) map[string]interface{}
+}
+
+// CheckParams validates the parameters by the method description, and
+// returns the reason if they are invalid.
+func (m *Method) CheckParams(params Params) (string, bool) {
+	for _, p := range m.Params {
+		if _, ok := params[p.Name]; !ok {
+			if p.Required {
+				return fmt.Sprintf("need a %s parameter named %s", p.Type, p.Name), false
+			}
+			continue
+		}
+		if !p.check(params) {
+			return fmt.Sprintf("parameter %s should be %s", p.Name, p.typeName()), false
+		}
+	}
+	return "", true
+}
+
+// FromArray converts positional parameters to named parameters in the order
+// of the method description.
+func (m *Method) FromArray(array []interface{}) (Params, bool) {
+	if len(array) > len(m.Params) {
+		return nil, false
+	}
+	names := make([]string, 0, len(m.Params))
+	for _, p := range m.Params {
+		names = append(names, p.Name)
+	}
+	return FromArray(array, names...), true
+}
+
+func (p *Param) check(params Params) bool {
+	var ok bool
+	switch p.Type {
+	case TypeString:
+		_, ok = params.String(p.Name)
+	case TypeInteger:
+		_, ok = params.Int(p.Name)
+	case TypeNumber:
+		_, ok = params.Float(p.Name)
+	case TypeBoolean:
+		_, ok = params.Bool(p.Name)
+	case TypeArray:
+		if p.Items == TypeString {
+			_, ok = params.ArrayString(p.Name)
+		} else {
+			_, ok = params[p.Name].([]interface{})
+		}
+	case TypeObject:
+		_, ok = params[p.Name].(map[string]interface{})
+	default:
+		ok = true
+	}
+	return ok
+}
+
+func (p *Param) typeName() string {
+	if p.Type == TypeArray && p.Items != "" {
+		return string(p.Type) + " of " + string(p.Items)
+	}
+	return string(p.Type)
+}
+
+// MethodSet is the registry of rpc methods.
+type MethodSet struct {
+	methods map[string]*Method
+}
+
+func NewMethodSet() *MethodSet {
+	return &MethodSet{methods: make(map[string]*Method)}
+}
+
+func (s *MethodSet) Register(m *Method) {
+	s.methods[m.Name] = m
+}
+
+func (s *MethodSet) Get(name string) (*Method, bool) {
+	m, ok := s.methods[name]
+	return m, ok
+}
+
+// Discover returns the OpenRPC document generated from the registered
+// methods.
+func (s *MethodSet) Discover() *OpenRPCDocument {
+	names := make([]string, 0, len(s.methods))
+	for name := range s.methods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	doc := &OpenRPCDocument{
+		OpenRPC: OpenRPCVersion,
+		Info: OpenRPCInfo{
+			Title:   "Elastos ELA Arbiter JSON-RPC",
+			Version: config.Version,
+		},
+		Methods: make([]*OpenRPCMethod, 0, len(names)),
+	}
+	for _, name := range names {
+		m := s.methods[name]
+		method := &OpenRPCMethod{
+			Name:           m.Name,
+			Summary:        m.Summary,
+			ParamStructure: "either",
+			Params:         make([]*ContentDescriptor, 0, len(m.Params)),
+		}
+		for _, p := range m.Params {
+			method.Params = append(method.Params, &ContentDescriptor{
+				Name:        p.Name,
+				Description: p.Description,
+				Required:    p.Required,
+				Schema:      newSchema(p.Type, p.Items),
+			})
+		}
+		if m.Result != nil {
+			method.Result = &ContentDescriptor{
+				Name:        m.Result.Name,
+				Description: m.Result.Description,
+				Schema:      newSchema(m.Result.Type, ""),
+			}
+		}
+		doc.Methods = append(doc.Methods, method)
+	}
+	return doc
+}
+
+type OpenRPCDocument struct {
+	OpenRPC string           `json:"openrpc"`
+	Info    OpenRPCInfo      `json:"info"`
+	Methods []*OpenRPCMethod `json:"methods"`
+}
+
+type OpenRPCInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type OpenRPCMethod struct {
+	Name           string               `json:"name"`
+	Summary        string               `json:"summary,omitempty"`
+	ParamStructure string               `json:"paramStructure"`
+	Params         []*ContentDescriptor `json:"params"`
+	Result         *ContentDescriptor   `json:"result,omitempty"`
+}
+
+type ContentDescriptor struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type Schema struct {
+	Type  ParamType `json:"type,omitempty"`
+	Items *Schema   `json:"items,omitempty"`
+}
+
+func newSchema(typ ParamType, items ParamType) *Schema {
+	schema := &Schema{Type: typ}
+	if typ == TypeArray && items != "" {
+		schema.Items = &Schema{Type: items}
+	}
+	return schema
+}
@@ -0,0 +1,77 @@
+package servers
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA.Arbiter/log"
+)
+
+func TestMain(m *testing.M) {
+	dir, _ := ioutil.TempDir("", "servers")
+	log.Init(dir, 1, 0, 0)
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func TestMethod_CheckParams(t *testing.T) {
+	method := &Method{
+		Name: "test",
+		Params: []*Param{
+			{Name: "hash", Type: TypeString, Required: true},
+			{Name: "succeed", Type: TypeBoolean},
+			{Name: "addresses", Type: TypeArray, Items: TypeString},
+		},
+	}
+
+	if _, ok := method.CheckParams(Params{"hash": "abc"}); !ok {
+		t.Error("optional params should not be required")
+	}
+	if _, ok := method.CheckParams(Params{}); ok {
+		t.Error("required param should be checked")
+	}
+	if _, ok := method.CheckParams(Params{"hash": 1.0}); ok {
+		t.Error("param type should be checked")
+	}
+	if _, ok := method.CheckParams(Params{"hash": "abc", "succeed": "true"}); ok {
+		t.Error("optional param type should be checked")
+	}
+	if _, ok := method.CheckParams(Params{"hash": "abc",
+		"addresses": []interface{}{"a", 1.0}}); ok {
+		t.Error("array items type should be checked")
+	}
+
+	params, ok := method.FromArray([]interface{}{"abc", true})
+	if !ok || params["hash"] != "abc" || params["succeed"] != true {
+		t.Error("positional params should be mapped by order")
+	}
+	if _, ok := method.FromArray([]interface{}{"abc", true, nil, nil}); ok {
+		t.Error("too many positional params should be rejected")
+	}
+}
+
+func TestMethodSet_Discover(t *testing.T) {
+	set := NewMethodSet()
+	set.Register(&Method{Name: "b"})
+	set.Register(&Method{
+		Name:   "a",
+		Params: []*Param{{Name: "addresses", Type: TypeArray, Items: TypeString}},
+		Result: &Result{Name: "height", Type: TypeInteger},
+	})
+
+	doc := set.Discover()
+	if doc.OpenRPC != OpenRPCVersion || len(doc.Methods) != 2 {
+		t.Fatal("invalid OpenRPC document")
+	}
+	if doc.Methods[0].Name != "a" || doc.Methods[1].Name != "b" {
+		t.Error("methods should be sorted by name")
+	}
+	if doc.Methods[0].Params[0].Schema.Items.Type != TypeString {
+		t.Error("array items should be described")
+	}
+	if doc.Methods[0].Result.Schema.Type != TypeInteger {
+		t.Error("result should be described")
+	}
+}
@@ -0,0 +1,185 @@
+package notifier
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/elastos/Elastos.ELA.Arbiter/config"
+	"github.com/elastos/Elastos.ELA.Arbiter/events"
+	"github.com/elastos/Elastos.ELA.Arbiter/log"
+)
+
+const (
+	// SignatureHeader is the header carrying the hex encoded HMAC-SHA256 of
+	// the request body, keyed by the secret of the webhook.
+	SignatureHeader = "X-Arbiter-Signature"
+
+	// EventHeader is the header carrying the event name of the payload.
+	EventHeader = "X-Arbiter-Event"
+
+	postTimeout = 10 * time.Second
+)
+
+// alertNames is the map of alert events to the event names posted to
+// webhooks.
+var alertNames = map[events.EventType]string{
+	events.ETSideChainPowAccountLow:    "sidechainpowaccountlow",
+	events.ETSideChainSyncStalled:      "sidechainsyncstalled",
+	events.ETArbiterPeersBelowQuorum:   "arbiterpeersbelowquorum",
+	events.ETSubmitFailed:              "submitfailed",
+	events.ETIllegalEvidenceFound:      "illegalevidencefound",
+	events.ETProposalRejected:          "proposalrejected",
+	events.ETMainNodeDisagreement:      "mainnodedisagreement",
+	events.ETWithdrawBlocked:           "withdrawblocked",
+	events.ETSideChainRegistered:       "sidechainregistered",
+	events.ETSideChainRetired:          "sidechainretired",
+	events.ETCircuitBreakerTripped:     "circuitbreakertripped",
+	events.ETReconciliationDiscrepancy: "reconciliationdiscrepancy",
+}
+
+type payload struct {
+	Id        string `json:"id"`
+	Event     string `json:"event"`
+	Timestamp int64  `json:"timestamp"`
+	*events.Alert
+}
+
+type Notifier struct {
+	mtx      sync.Mutex
+	cfg      *config.NotifierConfig
+	client   *http.Client
+	lastSent map[string]time.Time
+}
+
+// Start subscribes the alert events and posts them to the configured
+// webhooks.
+func Start() {
+	cfg := config.Parameters.Notifier
+	if cfg == nil || len(cfg.Webhooks) == 0 {
+		log.Info("[Notifier] no webhook configured")
+		return
+	}
+
+	n := New(cfg)
+	events.Subscribe(func(e *events.Event) {
+		alert, ok := e.Data.(*events.Alert)
+		if !ok {
+			return
+		}
+		go n.Notify(e.Type, alert)
+	})
+}
+
+func New(cfg *config.NotifierConfig) *Notifier {
+	return &Notifier{
+		cfg:      cfg,
+		client:   &http.Client{Timeout: postTimeout},
+		lastSent: make(map[string]time.Time),
+	}
+}
+
+// Notify posts the alert to all webhooks unless the same alert has been
+// posted within DedupInterval.
+func (n *Notifier) Notify(typ events.EventType, alert *events.Alert) {
+	name, ok := alertNames[typ]
+	if !ok {
+		log.Warn("[Notifier] unknown alert event:", typ)
+		return
+	}
+	if n.isDuplicated(name + ":" + alert.GenesisBlockAddress + ":" + alert.Subject) {
+		log.Debug("[Notifier] ignore duplicated alert", name, alert.Subject)
+		return
+	}
+
+	now := time.Now().Unix()
+	id := sha256.Sum256([]byte(fmt.Sprint(name, alert.GenesisBlockAddress, alert.Subject, now)))
+	body, err := json.Marshal(&payload{
+		Id:        hex.EncodeToString(id[:]),
+		Event:     name,
+		Timestamp: now,
+		Alert:     alert,
+	})
+	if err != nil {
+		log.Error("[Notifier] marshal alert failed:", err)
+		return
+	}
+
+	for _, hook := range n.cfg.Webhooks {
+		go n.deliver(hook, name, body)
+	}
+}
+
+func (n *Notifier) isDuplicated(key string) bool {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+
+	now := time.Now()
+	interval := time.Millisecond * n.cfg.DedupInterval
+	for k, t := range n.lastSent {
+		if now.Sub(t) >= interval {
+			delete(n.lastSent, k)
+		}
+	}
+	if _, ok := n.lastSent[key]; ok {
+		return true
+	}
+	n.lastSent[key] = now
+	return false
+}
+
+func (n *Notifier) deliver(hook *config.WebhookConfig, event string, body []byte) {
+	interval := time.Millisecond * n.cfg.RetryInterval
+	for i := 0; i <= n.cfg.MaxRetries; i++ {
+		if i > 0 {
+			time.Sleep(interval)
+			interval *= 2
+		}
+		err := n.post(hook, event, body)
+		if err == nil {
+			return
+		}
+		log.Warn("[Notifier] post", event, "to", hook.Url, "failed, attempt", i+1, "error:", err)
+	}
+	log.Error("[Notifier] give up posting", event, "to", hook.Url)
+}
+
+func (n *Notifier) post(hook *config.WebhookConfig, event string, body []byte) error {
+	req, err := http.NewRequest("POST", hook.Url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, event)
+	if hook.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(hook.Secret, body))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.New("unexpected status " + resp.Status)
+	}
+	return nil
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of body keyed by secret.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
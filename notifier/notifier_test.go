@@ -0,0 +1,74 @@
+package notifier
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/elastos/Elastos.ELA.Arbiter/config"
+	"github.com/elastos/Elastos.ELA.Arbiter/events"
+	"github.com/elastos/Elastos.ELA.Arbiter/log"
+)
+
+func TestMain(m *testing.M) {
+	dir, _ := ioutil.TempDir("", "notifier")
+	log.Init(dir, 1, 0, 0)
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func TestNotifier_Notify(t *testing.T) {
+	var attempts int32
+	received := make(chan *payload, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// fail the first post to test retry
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		if r.Header.Get(SignatureHeader) != Sign("secret", body) {
+			t.Error("invalid signature")
+		}
+		if r.Header.Get(EventHeader) != "submitfailed" {
+			t.Error("invalid event header")
+		}
+		p := &payload{}
+		if err := json.Unmarshal(body, p); err != nil {
+			t.Error("invalid payload")
+		}
+		received <- p
+	}))
+	defer server.Close()
+
+	n := New(&config.NotifierConfig{
+		Webhooks:      []*config.WebhookConfig{{Url: server.URL, Secret: "secret"}},
+		MaxRetries:    2,
+		RetryInterval: 10,
+		DedupInterval: 60000,
+	})
+	alert := &events.Alert{Subject: "hash", Message: "failed"}
+	n.Notify(events.ETSubmitFailed, alert)
+	n.Notify(events.ETSubmitFailed, alert)
+
+	select {
+	case p := <-received:
+		if p.Event != "submitfailed" || p.Subject != "hash" {
+			t.Error("received wrong alert")
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("alert not received")
+	}
+
+	// the duplicated alert should not be posted
+	time.Sleep(time.Millisecond * 100)
+	if atomic.LoadInt32(&attempts) != 2 {
+		t.Error("duplicated alert should be ignored")
+	}
+}
@@ -0,0 +1,139 @@
+package password
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// CommandTimeout is the max time to wait for the secret provider command.
+const CommandTimeout = 30 * time.Second
+
+// Source indicates where to get the wallet password, at most one of the
+// sources can be set, and the password is read from user input if none of
+// them is set.
+type Source struct {
+	// Password is the plain password given by the -p flag.
+	Password string
+
+	// File is the path of a file containing the password, it should not be
+	// accessible by group or others.
+	File string
+
+	// Fd is an inherited file descriptor to read the password from, it is
+	// not set if negative.
+	Fd int
+
+	// Command is a local secret provider command printing the password to
+	// stdout, it is run directly without shell.
+	Command string
+}
+
+// GetAccountPasswordFrom gets node's wallet password from the source, the
+// caller should Zero the password once it is not used.
+func GetAccountPasswordFrom(s *Source) ([]byte, error) {
+	count := 0
+	for _, set := range []bool{s.Password != "", s.File != "", s.Fd >= 0, s.Command != ""} {
+		if set {
+			count++
+		}
+	}
+	if count > 1 {
+		return nil, errors.New("more than one wallet password sources are given")
+	}
+
+	switch {
+	case s.File != "":
+		return FromFile(s.File)
+	case s.Fd >= 0:
+		return FromFd(s.Fd)
+	case s.Command != "":
+		return FromCommand(s.Command)
+	default:
+		return GetAccountPassword(s.Password)
+	}
+}
+
+// FromFile reads password from the file, the file should be a regular file
+// not accessible by group or others.
+func FromFile(path string) ([]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("password file %s is not a regular file", path)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0077 != 0 {
+		return nil, fmt.Errorf("password file %s is accessible by group or "+
+			"others, its permission %04o should be 0600 or 0400", path,
+			info.Mode().Perm())
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return trimPassword(data, "password file "+path)
+}
+
+// FromFd reads password from the inherited file descriptor until EOF, the
+// file descriptor is closed after reading.
+func FromFd(fd int) ([]byte, error) {
+	file := os.NewFile(uintptr(fd), fmt.Sprintf("fd%d", fd))
+	if file == nil {
+		return nil, fmt.Errorf("invalid password file descriptor %d", fd)
+	}
+	defer file.Close()
+	data, err := ioutil.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("read password file descriptor %d error: %v", fd, err)
+	}
+	return trimPassword(data, fmt.Sprintf("password file descriptor %d", fd))
+}
+
+// FromCommand runs the secret provider command and takes its stdout as
+// password, the command is split by spaces and run without shell.
+func FromCommand(command string) ([]byte, error) {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return nil, errors.New("empty password command")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), CommandTimeout)
+	defer cancel()
+	var stdout bytes.Buffer
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		Zero(stdout.Bytes())
+		return nil, fmt.Errorf("password command %s error: %v", args[0], err)
+	}
+	data := stdout.Bytes()
+	return trimPassword(data, "password command "+args[0])
+}
+
+// trimPassword removes the trailing line break of data, data is zeroed if the
+// password is empty.
+func trimPassword(data []byte, source string) ([]byte, error) {
+	passwd := bytes.TrimRight(data, "\r\n")
+	if len(passwd) == 0 {
+		Zero(data)
+		return nil, fmt.Errorf("empty password from %s", source)
+	}
+	return passwd, nil
+}
+
+// Zero overwrites the password in memory.
+func Zero(passwd []byte) {
+	for i := range passwd {
+		passwd[i] = 0
+	}
+}
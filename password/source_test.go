@@ -0,0 +1,98 @@
+package password
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestFromFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "arbiter-password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "password")
+	if err := ioutil.WriteFile(path, []byte("123\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	passwd, err := FromFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(passwd) != "123" {
+		t.Errorf("expect password 123, got %s", passwd)
+	}
+
+	if runtime.GOOS != "windows" {
+		if err := os.Chmod(path, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := FromFile(path); err == nil {
+			t.Error("expect error of password file readable by others")
+		}
+	}
+
+	empty := filepath.Join(dir, "empty")
+	if err := ioutil.WriteFile(empty, []byte("\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := FromFile(empty); err == nil {
+		t.Error("expect error of empty password")
+	}
+}
+
+func TestFromFd(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("123\r\n"))
+	w.Close()
+
+	passwd, err := FromFd(int(r.Fd()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(passwd) != "123" {
+		t.Errorf("expect password 123, got %s", passwd)
+	}
+}
+
+func TestFromCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("echo is not a command on windows")
+	}
+	passwd, err := FromCommand("echo 123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(passwd) != "123" {
+		t.Errorf("expect password 123, got %s", passwd)
+	}
+
+	if _, err := FromCommand("false"); err == nil {
+		t.Error("expect error of failed command")
+	}
+}
+
+func TestGetAccountPasswordFrom(t *testing.T) {
+	_, err := GetAccountPasswordFrom(&Source{Password: "123", Command: "echo 123", Fd: -1})
+	if err == nil {
+		t.Error("expect error of more than one sources")
+	}
+
+	passwd, err := GetAccountPasswordFrom(&Source{Password: "123", Fd: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	Zero(passwd)
+	for _, b := range passwd {
+		if b != 0 {
+			t.Fatal("password not zeroed")
+		}
+	}
+}
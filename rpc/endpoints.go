@@ -0,0 +1,255 @@
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/elastos/Elastos.ELA.Arbiter/config"
+	"github.com/elastos/Elastos.ELA.Arbiter/log"
+)
+
+// EndpointStatus is the health of a side chain rpc endpoint found by the
+// latest health check or call.
+type EndpointStatus struct {
+	IpAddress    string
+	HttpJsonPort int
+	Height       uint32
+	Latency      time.Duration
+	LastCheck    int64
+	Error        string
+	Healthy      bool
+	Active       bool
+}
+
+// endpointGroup is the rpc endpoints of a side chain, the calls are sent to
+// the active one.
+type endpointGroup struct {
+	mux       sync.Mutex
+	name      string
+	endpoints []*config.RpcConfig
+	status    []EndpointStatus
+	active    int
+}
+
+// nonIdempotentMethods are the methods submitting transactions or blocks, they
+// are sent to another endpoint only if the failed endpoint was not connected,
+// as the submit may have been received.
+var nonIdempotentMethods = map[string]struct{}{
+	"sendrawtransaction":             {},
+	"sendrechargetransaction":        {},
+	"sendsmallcrosstransaction":      {},
+	"sendinvalidwithdrawtransaction": {},
+	"submitsideauxblock":             {},
+	"submitsidechainillegaldata":     {},
+}
+
+var (
+	endpointsMux sync.RWMutex
+	// endpointGroups is the endpoint groups by side chain name
+	endpointGroups = make(map[string]*endpointGroup)
+)
+
+// RegisterEndpoints registers the rpc endpoints of the side chain, the calls
+// to the first endpoint are sent to the healthiest one of them. The endpoints
+// registered before for the side chain are replaced.
+func RegisterEndpoints(name string, endpoints []*config.RpcConfig) {
+	if len(endpoints) == 0 {
+		return
+	}
+	group := &endpointGroup{
+		name:      name,
+		endpoints: endpoints,
+		status:    make([]EndpointStatus, len(endpoints)),
+	}
+	for i, endpoint := range endpoints {
+		group.status[i] = EndpointStatus{
+			IpAddress:    endpoint.IpAddress,
+			HttpJsonPort: endpoint.HttpJsonPort,
+			Healthy:      true,
+		}
+	}
+
+	endpointsMux.Lock()
+	endpointGroups[name] = group
+	endpointsMux.Unlock()
+}
+
+// getEndpointGroup returns the group of which the first endpoint has the
+// address of endpoint.
+func getEndpointGroup(endpoint *config.RpcConfig) *endpointGroup {
+	endpointsMux.RLock()
+	defer endpointsMux.RUnlock()
+	for _, group := range endpointGroups {
+		first := group.endpoints[0]
+		if first.IpAddress == endpoint.IpAddress && first.HttpJsonPort == endpoint.HttpJsonPort {
+			return group
+		}
+	}
+	return nil
+}
+
+// GetEndpointsStatus returns the status of the rpc endpoints by side chain
+// name.
+func GetEndpointsStatus() map[string][]EndpointStatus {
+	endpointsMux.RLock()
+	defer endpointsMux.RUnlock()
+
+	result := make(map[string][]EndpointStatus, len(endpointGroups))
+	for _, group := range endpointGroups {
+		group.mux.Lock()
+		status := make([]EndpointStatus, len(group.status))
+		copy(status, group.status)
+		status[group.active].Active = true
+		group.mux.Unlock()
+		result[group.name] = status
+	}
+	return result
+}
+
+// CheckEndpoints checks the height and response time of all the registered
+// endpoints, and switches to the fastest healthy endpoint of a side chain if
+// the active one is unhealthy.
+func CheckEndpoints(timeout time.Duration, maxHeightLag uint32) {
+	endpointsMux.RLock()
+	groups := make([]*endpointGroup, 0, len(endpointGroups))
+	for _, group := range endpointGroups {
+		groups = append(groups, group)
+	}
+	endpointsMux.RUnlock()
+
+	for _, group := range groups {
+		group.check(timeout, maxHeightLag)
+	}
+}
+
+// HealthCheckLoop checks the registered endpoints in the interval of
+// HealthCheck config.
+func HealthCheckLoop() {
+	healthCheck := config.Parameters.HealthCheck
+	if healthCheck == nil {
+		return
+	}
+	for {
+		CheckEndpoints(time.Millisecond*healthCheck.Timeout, healthCheck.MaxHeightLag)
+		time.Sleep(time.Millisecond * healthCheck.Interval)
+	}
+}
+
+func (g *endpointGroup) check(timeout time.Duration, maxHeightLag uint32) {
+	status := make([]EndpointStatus, len(g.endpoints))
+	var maxHeight uint32
+	for i, endpoint := range g.endpoints {
+		status[i] = EndpointStatus{
+			IpAddress:    endpoint.IpAddress,
+			HttpJsonPort: endpoint.HttpJsonPort,
+		}
+		start := time.Now()
+		height, err := getBlockHeight(endpoint, timeout)
+		status[i].Latency = time.Since(start)
+		status[i].LastCheck = start.Unix()
+		if err != nil {
+			status[i].Error = err.Error()
+			continue
+		}
+		status[i].Height = height
+		if height > maxHeight {
+			maxHeight = height
+		}
+	}
+	for i := range status {
+		status[i].Healthy = status[i].Error == "" && status[i].Height+maxHeightLag >= maxHeight
+	}
+
+	g.mux.Lock()
+	defer g.mux.Unlock()
+	g.status = status
+	if status[g.active].Healthy {
+		return
+	}
+	best := -1
+	for i := range status {
+		if status[i].Healthy && (best < 0 || status[i].Latency < status[best].Latency) {
+			best = i
+		}
+	}
+	if best >= 0 {
+		g.switchTo(best)
+	}
+}
+
+// call calls the active endpoint, and the other healthy endpoints in order if
+// it is unreachable, the first endpoint responded becomes the active one. The
+// non idempotent methods are not sent again once they have been sent.
+func (g *endpointGroup) call(method string, params map[string]interface{}) ([]byte, error) {
+	_, nonIdempotent := nonIdempotentMethods[method]
+	g.mux.Lock()
+	active := g.active
+	candidates := []int{active}
+	for i := range g.endpoints {
+		if i != active && g.status[i].Healthy {
+			candidates = append(candidates, i)
+		}
+	}
+	g.mux.Unlock()
+
+	var err error
+	for _, i := range candidates {
+		var body []byte
+		body, err = call(method, params, g.endpoints[i], time.Minute)
+		g.mux.Lock()
+		if err == nil {
+			if g.active == active && i != active {
+				g.switchTo(i)
+			}
+			g.mux.Unlock()
+			return body, nil
+		}
+		g.status[i].Healthy = false
+		g.status[i].Error = err.Error()
+		g.mux.Unlock()
+		if nonIdempotent && !isDialError(err) {
+			break
+		}
+	}
+	return nil, err
+}
+
+// isDialError returns true if the request failed before connected to the
+// endpoint, so it was never received.
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// switchTo changes the active endpoint, the group should be locked.
+func (g *endpointGroup) switchTo(i int) {
+	log.Warn("[Endpoints] side chain", g.name, "switches rpc endpoint from",
+		endpointString(g.endpoints[g.active]), "to", endpointString(g.endpoints[i]))
+	g.active = i
+}
+
+func endpointString(endpoint *config.RpcConfig) string {
+	return endpoint.IpAddress + ":" + strconv.Itoa(endpoint.HttpJsonPort)
+}
+
+func getBlockHeight(endpoint *config.RpcConfig, timeout time.Duration) (uint32, error) {
+	body, err := call("getblockcount", nil, endpoint, timeout)
+	if err != nil {
+		return 0, err
+	}
+	resp := Response{}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return 0, err
+	}
+	if resp.Error != nil {
+		return 0, errors.New(resp.Error.Message)
+	}
+	if count, ok := resp.Result.(float64); ok && count >= 1 {
+		return uint32(count) - 1, nil
+	}
+	return 0, errors.New("invalid block count")
+}
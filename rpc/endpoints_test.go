@@ -0,0 +1,133 @@
+package rpc
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/elastos/Elastos.ELA.Arbiter/config"
+	"github.com/elastos/Elastos.ELA.Arbiter/log"
+)
+
+func TestMain(m *testing.M) {
+	dir, err := ioutil.TempDir("", "arbiter-rpc")
+	if err != nil {
+		panic(err)
+	}
+	log.Init(filepath.Join(dir, "logs"), 1, 0, 0)
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// newTestNode returns a node responding the block count.
+func newTestNode(t *testing.T, count int) (*httptest.Server, *config.RpcConfig) {
+	return newTestServer(t, strconv.Itoa(count))
+}
+
+// newTestServer returns a node responding the json result to all methods.
+func newTestServer(t *testing.T, result string) (*httptest.Server, *config.RpcConfig) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"id":0,"jsonrpc":"2.0","result":%s,"error":null}`, result)
+	}))
+	t.Cleanup(server.Close)
+	host, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	httpJsonPort, _ := strconv.Atoi(port)
+	return server, &config.RpcConfig{IpAddress: host, HttpJsonPort: httpJsonPort}
+}
+
+func TestEndpointFailover(t *testing.T) {
+	_, lagging := newTestNode(t, 100)
+	backup, synced := newTestNode(t, 110)
+	RegisterEndpoints("ESC", []*config.RpcConfig{lagging, synced})
+
+	CheckEndpoints(time.Second, 6)
+	status := GetEndpointsStatus()["ESC"]
+	if len(status) != 2 || status[0].Healthy || !status[1].Healthy || !status[1].Active ||
+		status[1].Height != 109 {
+		t.Fatalf("lagging endpoint should be replaced, %+v", status)
+	}
+	if height, err := GetCurrentHeight(lagging); err != nil || height != 109 {
+		t.Errorf("calls should be sent to the active endpoint, got %d, %v", height, err)
+	}
+
+	// the unhealthy endpoint is not tried until the next check
+	backup.Close()
+	if _, err := GetCurrentHeight(lagging); err == nil {
+		t.Error("unreachable endpoint should fail")
+	}
+	CheckEndpoints(time.Second, 6)
+	if height, err := GetCurrentHeight(lagging); err != nil || height != 99 {
+		t.Errorf("calls should fail over to the reachable endpoint, got %d, %v", height, err)
+	}
+	status = GetEndpointsStatus()["ESC"]
+	if !status[0].Active || status[1].Healthy || status[1].Error == "" {
+		t.Errorf("unreachable endpoint should be unhealthy, %+v", status)
+	}
+}
+
+func TestEndpointFailover_Call(t *testing.T) {
+	primary, primaryRpc := newTestNode(t, 100)
+	_, backupRpc := newTestNode(t, 100)
+	RegisterEndpoints("EID", []*config.RpcConfig{primaryRpc, backupRpc})
+
+	primary.Close()
+	if height, err := GetCurrentHeight(primaryRpc); err != nil || height != 99 {
+		t.Errorf("calls should fail over to the backup endpoint, got %d, %v", height, err)
+	}
+	status := GetEndpointsStatus()["EID"]
+	if status[0].Healthy || status[0].Active || !status[1].Active {
+		t.Errorf("backup endpoint should become active, %+v", status)
+	}
+}
+
+func TestEndpointFailover_Submit(t *testing.T) {
+	// the primary receives the request and drops the connection
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, _, _ := w.(http.Hijacker).Hijack()
+		conn.Close()
+	}))
+	t.Cleanup(primary.Close)
+	host, port, _ := net.SplitHostPort(primary.Listener.Addr().String())
+	httpJsonPort, _ := strconv.Atoi(port)
+	primaryRpc := &config.RpcConfig{IpAddress: host, HttpJsonPort: httpJsonPort}
+
+	var calls int
+	backup := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		fmt.Fprint(w, `{"id":0,"jsonrpc":"2.0","result":"hash","error":null}`)
+	}))
+	t.Cleanup(backup.Close)
+	host, port, _ = net.SplitHostPort(backup.Listener.Addr().String())
+	httpJsonPort, _ = strconv.Atoi(port)
+	backupRpc := &config.RpcConfig{IpAddress: host, HttpJsonPort: httpJsonPort}
+	RegisterEndpoints("ETH", []*config.RpcConfig{primaryRpc, backupRpc})
+
+	// a copy of the config is routed to the group of the side chain
+	rpcCopy := *primaryRpc
+	if _, err := CallAndUnmarshal("sendrawtransaction", Param("data", "00"), &rpcCopy); err == nil || calls != 0 {
+		t.Errorf("submit received by an endpoint should not be sent again, calls %d, %v", calls, err)
+	}
+	RegisterEndpoints("ETH", []*config.RpcConfig{primaryRpc, backupRpc})
+	if _, err := CallAndUnmarshal("getblockcount", nil, &rpcCopy); err != nil || calls != 1 {
+		t.Errorf("query should fail over to the backup endpoint, calls %d, %v", calls, err)
+	}
+
+	// the submit is sent to the backup if the primary is not connected
+	primary.Close()
+	RegisterEndpoints("ETH", []*config.RpcConfig{primaryRpc, backupRpc})
+	if _, err := CallAndUnmarshal("sendrawtransaction", Param("data", "00"), primaryRpc); err != nil || calls != 2 {
+		t.Errorf("submit should fail over if the endpoint is unreachable, calls %d, %v", calls, err)
+	}
+	if len(GetEndpointsStatus()["ETH"]) != 2 {
+		t.Error("endpoints registered again should replace the old ones")
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/elastos/Elastos.ELA/common"
 	"github.com/elastos/Elastos.ELA/dpos/p2p/peer"
 	"github.com/elastos/Elastos.ELA/servers"
+	elaerrors "github.com/elastos/Elastos.ELA/servers/errors"
 )
 
 type Response struct {
@@ -38,6 +39,17 @@ type Error struct {
 	Message string `json:"message"`
 }
 
+// RejectedError is returned if the node received the request and refused it,
+// so sending it again gets the same result.
+type RejectedError struct {
+	Code    int64
+	Message string
+}
+
+func (e *RejectedError) Error() string {
+	return e.Message
+}
+
 type ArbitratorGroupInfo struct {
 	OnDutyArbitratorIndex int
 	Arbitrators           []string
@@ -79,7 +91,7 @@ func GetActiveDposPeers(height uint32) (result []peer.PID, err error) {
 	} else {
 		rpcMethod = "getcrosschainpeersinfo"
 	}
-	resp, err := CallAndUnmarshal(rpcMethod, nil,
+	resp, err := quorumCallAndUnmarshal(rpcMethod, nil,
 		config.Parameters.MainNode.Rpc)
 	if err != nil {
 		return nil, err
@@ -130,7 +142,7 @@ func GetArbitratorGroupInfoByHeight(height uint32) (*ArbitratorGroupInfo, error)
 		return groupInfo, nil
 	}
 
-	resp, err := CallAndUnmarshal("getarbitratorgroupbyheight",
+	resp, err := quorumCallAndUnmarshal("getarbitratorgroupbyheight",
 		Param("height", height), config.Parameters.MainNode.Rpc)
 	if err != nil {
 		return nil, err
@@ -164,6 +176,23 @@ func GetBlockByHeight(height uint32, config *config.RpcConfig) (*base.BlockInfo,
 	return block, nil
 }
 
+// GetNodeMagic returns the magic number reported by getnodestate of the
+// node, reported is false if the node does not report it.
+func GetNodeMagic(config *config.RpcConfig) (magic uint32, reported bool, err error) {
+	result, err := CallAndUnmarshal("getnodestate", nil, config)
+	if err != nil {
+		return 0, false, err
+	}
+	state, ok := result.(map[string]interface{})
+	if !ok {
+		return 0, false, errors.New("[GetNodeMagic] invalid node state")
+	}
+	if m, ok := state["magic"].(float64); ok {
+		return uint32(m), true, nil
+	}
+	return 0, false, nil
+}
+
 func GetBlockByHash(hash *common.Uint256, config *config.RpcConfig) (*base.BlockInfo, error) {
 	hashBytes, err := common.HexStringToBytes(hash.String())
 	if err != nil {
@@ -345,7 +374,7 @@ func GetDepositTransactionInfoByHash(transactionHash string, config *config.RpcC
 func GetExistWithdrawTransactions(txs []string) ([]string, error) {
 	parameter := make(map[string]interface{})
 	parameter["txs"] = txs
-	result, err := CallAndUnmarshal("getexistwithdrawtransactions",
+	result, err := quorumCallAndUnmarshal("getexistwithdrawtransactions",
 		parameter, config.Parameters.MainNode.Rpc)
 	if err != nil {
 		return nil, err
@@ -414,7 +443,7 @@ func GetWithdrawUTXOsByAmount(genesisAddress string, amount common.Fixed64, conf
 	parameter := make(map[string]interface{})
 	parameter["address"] = genesisAddress
 	parameter["amount"] = amount.String()
-	result, err := CallAndUnmarshal("getutxosbyamount", parameter, config)
+	result, err := quorumCallAndUnmarshal("getutxosbyamount", parameter, config)
 	if err != nil {
 		return nil, err
 	}
@@ -482,7 +511,30 @@ func GetUnspentUtxo(addresses []string, config *config.RpcConfig) ([]base.UTXOIn
 	return utxoInfos, nil
 }
 
-func post(url string, contentType string, user string, pass string, body io.Reader) (resp *http.Response, err error) {
+// GetCirculatingSupply returns the ELA circulating on the side chain by the
+// method, the side chain returns the amount as a string or a number.
+func GetCirculatingSupply(method string, config *config.RpcConfig) (common.Fixed64, error) {
+	result, err := CallAndUnmarshal(method, nil, config)
+	if err != nil {
+		return 0, err
+	}
+	var amount string
+	switch r := result.(type) {
+	case string:
+		amount = r
+	case float64:
+		amount = strconv.FormatFloat(r, 'f', -1, 64)
+	default:
+		return 0, errors.New("invalid data type")
+	}
+	supply, err := common.StringToFixed64(amount)
+	if err != nil {
+		return 0, err
+	}
+	return *supply, nil
+}
+
+func post(url string, contentType string, user string, pass string, body io.Reader, timeout time.Duration) (resp *http.Response, err error) {
 	req, err := http.NewRequest("POST", url, body)
 	if err != nil {
 		return nil, err
@@ -493,11 +545,23 @@ func post(url string, contentType string, user string, pass string, body io.Read
 	req.Header.Set("Content-Type", contentType)
 
 	client := *http.DefaultClient
-	client.Timeout = time.Minute
+	client.Timeout = timeout
 	return client.Do(req)
 }
 
+// Call calls the method of the rpc endpoint, if the endpoint is the first one
+// of a side chain registered by RegisterEndpoints, the active endpoint of the
+// side chain is called instead and the other healthy endpoints are tried if it
+// is unreachable.
 func Call(method string, params map[string]interface{}, config *config.RpcConfig) ([]byte, error) {
+	group := getEndpointGroup(config)
+	if group == nil {
+		return call(method, params, config, time.Minute)
+	}
+	return group.call(method, params)
+}
+
+func call(method string, params map[string]interface{}, config *config.RpcConfig, timeout time.Duration) ([]byte, error) {
 	url := "http://" + config.IpAddress + ":" + strconv.Itoa(config.HttpJsonPort)
 	data, err := json.Marshal(map[string]interface{}{
 		"method": method,
@@ -507,7 +571,7 @@ func Call(method string, params map[string]interface{}, config *config.RpcConfig
 		return nil, err
 	}
 
-	resp, err := post(url, "application/json", config.User, config.Pass, strings.NewReader(string(data)))
+	resp, err := post(url, "application/json", config.User, config.Pass, strings.NewReader(string(data)), timeout)
 	if err != nil {
 		log.Debug("POST requset err:", err)
 		return nil, err
@@ -594,3 +658,32 @@ func GetTransaction(tx string, config *config.RpcConfig) (it.Transaction, error)
 
 	return txn, nil
 }
+
+// ErrUnknownTransaction is returned if the node finds the transaction neither
+// in the blockchain nor in the mempool.
+var ErrUnknownTransaction = errors.New("unknown transaction")
+
+// GetTransactionConfirmations returns the confirmations of the transaction,
+// zero if it is still in the mempool.
+func GetTransactionConfirmations(tx string, config *config.RpcConfig) (uint32, error) {
+	param := make(map[string]interface{})
+	param["txid"] = tx
+	param["verbose"] = true
+	resp, err := CallAndUnmarshalResponse("getrawtransaction", param, config)
+	if err != nil {
+		return 0, err
+	}
+	if resp.Error != nil {
+		if resp.Code == int64(elaerrors.UnknownTransaction) {
+			return 0, ErrUnknownTransaction
+		}
+		return 0, errors.New(resp.Message)
+	}
+	var info struct {
+		Confirmations uint32 `json:"confirmations"`
+	}
+	if err := Unmarshal(resp.Result, &info); err != nil {
+		return 0, err
+	}
+	return info.Confirmations, nil
+}
@@ -0,0 +1,103 @@
+package rpc
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/elastos/Elastos.ELA.Arbiter/config"
+	"github.com/elastos/Elastos.ELA.Arbiter/events"
+	"github.com/elastos/Elastos.ELA.Arbiter/log"
+)
+
+// quorumCallAndUnmarshal calls the method of the rpc endpoint, if the endpoint
+// is the main node Rpc and MainNode.Quorum is greater than 1, all the main
+// node endpoints are called and the result is used only if it is returned by
+// Quorum endpoints.
+func quorumCallAndUnmarshal(method string, params map[string]interface{}, rpcConfig *config.RpcConfig) (interface{}, error) {
+	mainNode := config.Parameters.MainNode
+	if mainNode == nil || rpcConfig != mainNode.Rpc || mainNode.Quorum <= 1 {
+		return CallAndUnmarshal(method, params, rpcConfig)
+	}
+	return quorumCall(method, params, mainNode.Endpoints(), mainNode.Quorum)
+}
+
+func quorumCall(method string, params map[string]interface{}, endpoints []*config.RpcConfig, quorum int) (interface{}, error) {
+	type answer struct {
+		result interface{}
+		err    error
+	}
+	answers := make([]answer, len(endpoints))
+	var wg sync.WaitGroup
+	for i, endpoint := range endpoints {
+		wg.Add(1)
+		go func(i int, endpoint *config.RpcConfig) {
+			defer wg.Done()
+			answers[i].result, answers[i].err = CallAndUnmarshal(method, params, endpoint)
+		}(i, endpoint)
+	}
+	wg.Wait()
+
+	// results are compared by their json encoding, the endpoints are grouped
+	// by the encoding of the result they returned, the digest is only shown
+	// in logs
+	votes := make(map[string][]string)
+	results := make(map[string]interface{})
+	digests := make(map[string]string)
+	var failures []string
+	for i, a := range answers {
+		endpoint := endpointString(endpoints[i])
+		if a.err != nil {
+			failures = append(failures, endpoint+" "+a.err.Error())
+			continue
+		}
+		data, err := json.Marshal(a.result)
+		if err != nil {
+			failures = append(failures, endpoint+" "+err.Error())
+			continue
+		}
+		key := string(data)
+		if _, ok := results[key]; !ok {
+			digest := sha256.Sum256(data)
+			digests[key] = hex.EncodeToString(digest[:4])
+			results[key] = a.result
+		}
+		votes[key] = append(votes[key], endpoint)
+	}
+
+	if len(votes) > 1 {
+		groups := make([]string, 0, len(votes))
+		for key, voters := range votes {
+			groups = append(groups, strings.Join(voters, ",")+" returned "+digests[key])
+		}
+		sort.Strings(groups)
+		message := fmt.Sprintf("main node endpoints disagree on %s: %s",
+			method, strings.Join(groups, "; "))
+		log.Warn("[Quorum]", message)
+		events.Notify(events.ETMainNodeDisagreement, &events.Alert{
+			Subject: method,
+			Message: message,
+		})
+	}
+	for key, voters := range votes {
+		if len(voters) >= quorum {
+			return results[key], nil
+		}
+	}
+
+	max := 0
+	for _, voters := range votes {
+		if len(voters) > max {
+			max = len(voters)
+		}
+	}
+	if len(failures) > 0 {
+		log.Warn("[Quorum] main node endpoints failed on", method+":", strings.Join(failures, "; "))
+	}
+	return nil, fmt.Errorf("quorum %d of main node endpoints not reached on %s, "+
+		"at most %d agreed and %d failed", quorum, method, max, len(failures))
+}
@@ -0,0 +1,50 @@
+package rpc
+
+import (
+	"testing"
+
+	"github.com/elastos/Elastos.ELA.Arbiter/config"
+	"github.com/elastos/Elastos.ELA.Arbiter/events"
+)
+
+func TestQuorumCall(t *testing.T) {
+	var alerts []*events.Alert
+	events.Subscribe(func(e *events.Event) {
+		if e.Type == events.ETMainNodeDisagreement {
+			alerts = append(alerts, e.Data.(*events.Alert))
+		}
+	})
+
+	_, rpc1 := newTestServer(t, `["tx1","tx2"]`)
+	_, rpc2 := newTestServer(t, `["tx1","tx2"]`)
+	_, forked := newTestServer(t, `["tx1"]`)
+	down, rpc4 := newTestServer(t, `["tx1","tx2"]`)
+	down.Close()
+
+	params := map[string]interface{}{"txs": []string{"tx1", "tx2", "tx3"}}
+	result, err := quorumCall("getexistwithdrawtransactions", params,
+		[]*config.RpcConfig{rpc1, forked, rpc2}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var txs []string
+	if err := Unmarshal(&result, &txs); err != nil || len(txs) != 2 {
+		t.Errorf("result agreed by quorum should be used, got %v", result)
+	}
+	if len(alerts) != 1 || alerts[0].Subject != "getexistwithdrawtransactions" || alerts[0].Message == "" {
+		t.Errorf("disagreement should be alerted, got %v", alerts)
+	}
+
+	if _, err := quorumCall("getexistwithdrawtransactions", params,
+		[]*config.RpcConfig{rpc1, forked, rpc4}, 2); err == nil {
+		t.Error("result not agreed by quorum should not be used")
+	}
+	alerts = nil
+	if _, err := quorumCall("getexistwithdrawtransactions", params,
+		[]*config.RpcConfig{rpc1, rpc2, rpc4}, 2); err != nil {
+		t.Errorf("quorum should be reached without failed endpoints, %v", err)
+	}
+	if len(alerts) != 0 {
+		t.Error("failed endpoints should not be alerted as disagreement")
+	}
+}
@@ -10,80 +10,36 @@ import (
 	"github.com/elastos/Elastos.ELA.Arbiter/log"
 	"github.com/elastos/Elastos.ELA.Arbiter/rpc"
 
+	"github.com/elastos/Elastos.ELA/core/contract"
 	"github.com/elastos/Elastos.ELA/core/types/payload"
 	elacommon "github.com/elastos/Elastos.ELA/core/types/common"
 	"github.com/elastos/Elastos.ELA/common"
 	"github.com/elastos/Elastos.ELA/crypto"
 )
 
-type SideChainPowAccount struct {
-	Address          string
-	availableBalance common.Fixed64
-}
-
-func checkSideChainPowAccounts(addresses []string, minThreshold int) ([]*SideChainPowAccount, error) {
-	var warnAddresses []*SideChainPowAccount
-	currentHeight := arbitrator.ArbitratorGroupSingleton.GetCurrentHeight()
-	for _, addr := range addresses {
-		available := common.Fixed64(0)
-		locked := common.Fixed64(0)
-		programHash, _ := common.Uint168FromAddress(addr)
-		UTXOs, err := GetAddressUTXOs(programHash)
-		if err != nil {
-			return nil, errors.New("get " + addr + " UTXOs failed")
-		}
-		for _, utxo := range UTXOs {
-			if utxo.LockTime < currentHeight {
-				available += *utxo.Amount
-			} else {
-				locked += *utxo.Amount
-			}
-		}
-
-		if available < common.Fixed64(minThreshold) {
-			warnAddresses = append(warnAddresses, &SideChainPowAccount{
-				Address:          addr,
-				availableBalance: available,
-			})
-		}
+func divideTransfer(outputs []*Transfer, fee common.Fixed64) (common.Uint256, error) {
+	// create transaction
+	mainContract, err := contract.CreateStandardContract(arbiterSigner.PublicKey())
+	if err != nil {
+		return common.Uint256{}, errors.New("create main account contract failed: " + err.Error())
 	}
-
-	if len(warnAddresses) > 0 {
-		var warningStr string
-		for _, sideChainPowAccount := range warnAddresses {
-			warningStr += sideChainPowAccount.Address
-			warningStr += ": "
-			warningStr += sideChainPowAccount.availableBalance.String()
-			warningStr += " "
-		}
-
-		log.Info("Warning side chain mining account: ", warningStr)
-
-		return warnAddresses, nil
+	from, err := mainContract.ToProgramHash().ToAddress()
+	if err != nil {
+		return common.Uint256{}, errors.New("get main account address failed: " + err.Error())
 	}
-
-	return nil, nil
-}
-
-func divideTransfer(name string, outputs []*Transfer) error {
-	// create transaction
-	fee := common.Fixed64(100000)
-	mainAccount:= client.GetMainAccount()
-
-	from := mainAccount.Address
-	script := mainAccount.RedeemScript
+	script := mainContract.Code
 
 	txType := elacommon.TransferAsset
 	txPayload := &payload.TransferAsset{}
 	txn, err := createTransaction(txType, txPayload, from, &fee, script,
 		uint32(0), arbitrator.ArbitratorGroupSingleton.GetCurrentHeight(), outputs...)
 	if err != nil {
-		return errors.New("create divide transaction failed: " + err.Error())
+		return common.Uint256{}, errors.New("create divide transaction failed: " + err.Error())
 	}
 
-	txnSigned, err := client.Sign(txn)
+	txnSigned, err := arbiterSigner.SignTransaction(txn)
 	if err != nil {
-		return err
+		return common.Uint256{}, err
 	}
 	program := txnSigned.Programs()[0]
 	haveSign, needSign, _ := crypto.GetSignStatus(program.Code, program.Parameter)
@@ -96,39 +52,21 @@ func divideTransfer(name string, outputs []*Transfer) error {
 	// send transaction
 	result, err := rpc.CallAndUnmarshal("sendrawtransaction", rpc.Param("data", content), config.Parameters.MainNode.Rpc)
 	if err != nil {
-		return err
+		return common.Uint256{}, err
 	}
 	log.Debug("Send divide transaction: ", result)
 
-	return nil
+	return txn.Hash(), nil
 }
 
+// SidechainAccountDivide checks the side chain mining accounts every minute
+// and refills the ones running low.
 func SidechainAccountDivide() {
+	loadTopUps()
 	for {
 		select {
 		case <-time.After(time.Second * 60):
-			miningAddresses := make([]string, 0)
-			for _, sideNode := range config.Parameters.SideNodeList {
-				if !sideNode.PowChain {
-					continue
-				}
-				miningAddresses = append(miningAddresses, sideNode.MiningAddr)
-			}
-			warningAccounts, err := checkSideChainPowAccounts(miningAddresses, config.Parameters.MinThreshold)
-			if err != nil {
-				log.Error("Check side chain pow err", err)
-			}
-			if len(warningAccounts) > 0 {
-				var outputs []*Transfer
-				amount := common.Fixed64(config.Parameters.DepositAmount)
-				for _, warningAccount := range warningAccounts {
-					outputs = append(outputs, &Transfer{
-						Address: warningAccount.Address,
-						Amount:  &amount,
-					})
-				}
-				divideTransfer(config.Parameters.WalletPath, outputs)
-			}
+			refillMiningAccounts(time.Now())
 		}
 	}
 }
@@ -0,0 +1,152 @@
+package sideauxpow
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/elastos/Elastos.ELA.Arbiter/config"
+	"github.com/elastos/Elastos.ELA.Arbiter/store"
+
+	"github.com/elastos/Elastos.ELA/common"
+)
+
+const (
+	// FeeStrategyFixed always pays SideAuxPowFee.
+	FeeStrategyFixed = "fixed"
+
+	// FeeStrategyBumpOnMiss raises the fee of the last SideChainPow
+	// transaction by BumpRatio if it missed its side block.
+	FeeStrategyBumpOnMiss = "bumponmiss"
+
+	// FeeStrategyAdaptive raises the fee towards MaxFee with the miss rate of
+	// the recent SideChainPow transactions.
+	FeeStrategyAdaptive = "adaptive"
+)
+
+// feeHistoryPeriod is the period of SideChainPow transactions the fee
+// strategies decide on.
+const feeHistoryPeriod = 24 * time.Hour
+
+// FeeStrategy decides the fee of the next SideChainPow transaction of a side
+// chain from its SideChainPow transactions sent before, ordered from the
+// oldest to the latest, and returns the reason of the decision.
+type FeeStrategy interface {
+	Name() string
+	Fee(history []*store.SideMiningTx) (common.Fixed64, string)
+}
+
+var feeStrategies = map[string]func(base common.Fixed64,
+	cfg *config.SideMiningFeeConfig) FeeStrategy{
+	FeeStrategyFixed: func(base common.Fixed64, cfg *config.SideMiningFeeConfig) FeeStrategy {
+		return &fixedFee{base: base}
+	},
+	FeeStrategyBumpOnMiss: func(base common.Fixed64, cfg *config.SideMiningFeeConfig) FeeStrategy {
+		return &bumpOnMissFee{base: base, max: cfg.MaxFee, ratio: cfg.BumpRatio}
+	},
+	FeeStrategyAdaptive: func(base common.Fixed64, cfg *config.SideMiningFeeConfig) FeeStrategy {
+		return &adaptiveFee{base: base, max: cfg.MaxFee, window: cfg.Window}
+	},
+}
+
+// NewFeeStrategy returns the fee strategy configured, SideAuxPowFee is the
+// base fee of all strategies.
+func NewFeeStrategy(base common.Fixed64, cfg *config.SideMiningFeeConfig) (FeeStrategy, error) {
+	if cfg == nil || cfg.Strategy == "" {
+		return &fixedFee{base: base}, nil
+	}
+	newStrategy, ok := feeStrategies[cfg.Strategy]
+	if !ok {
+		return nil, fmt.Errorf("unknown side mining fee strategy %q", cfg.Strategy)
+	}
+	return newStrategy(base, cfg), nil
+}
+
+type fixedFee struct {
+	base common.Fixed64
+}
+
+func (f *fixedFee) Name() string {
+	return FeeStrategyFixed
+}
+
+func (f *fixedFee) Fee(history []*store.SideMiningTx) (common.Fixed64, string) {
+	return f.base, "fixed fee"
+}
+
+type bumpOnMissFee struct {
+	base  common.Fixed64
+	max   common.Fixed64
+	ratio float64
+}
+
+func (f *bumpOnMissFee) Name() string {
+	return FeeStrategyBumpOnMiss
+}
+
+func (f *bumpOnMissFee) Fee(history []*store.SideMiningTx) (common.Fixed64, string) {
+	// The transactions whose auxpow is not submitted yet have not landed or
+	// missed, decide on the latest one with a result.
+	for i := len(history) - 1; i >= 0; i-- {
+		tx := history[i]
+		switch tx.Result {
+		case store.SideMiningSent:
+			continue
+		case store.SideMiningSubmitted:
+			return f.base, "last transaction " + tx.TransactionHash + " landed"
+		}
+		fee := common.Fixed64(float64(tx.Fee) * f.ratio)
+		if fee < f.base {
+			fee = f.base
+		}
+		if fee > f.max {
+			return f.max, "last transaction " + tx.TransactionHash + " " + tx.Result + ", capped at max fee"
+		}
+		return fee, "last transaction " + tx.TransactionHash + " " + tx.Result + ", fee bumped"
+	}
+	return f.base, "no transaction landed or missed"
+}
+
+type adaptiveFee struct {
+	base   common.Fixed64
+	max    common.Fixed64
+	window int
+}
+
+func (f *adaptiveFee) Name() string {
+	return FeeStrategyAdaptive
+}
+
+func (f *adaptiveFee) Fee(history []*store.SideMiningTx) (common.Fixed64, string) {
+	if len(history) > f.window {
+		history = history[len(history)-f.window:]
+	}
+	summary := SummarizeSideMining(history)
+	rate := summary.MissRate()
+	fee := f.base + common.Fixed64(float64(f.max-f.base)*rate)
+	if fee > f.max {
+		fee = f.max
+	}
+	return fee, fmt.Sprintf("missed %d of %d side blocks", summary.Missed,
+		summary.Submitted+summary.Missed)
+}
+
+// decideSideMiningFee returns the fee of the next SideChainPow transaction of
+// the side chain, the fee strategy and the reason of its decision.
+func decideSideMiningFee(sideNode *config.SideNodeConfig) (common.Fixed64, string, string, error) {
+	strategy, err := NewFeeStrategy(common.Fixed64(config.Parameters.SideAuxPowFee),
+		config.Parameters.SideMiningFee)
+	if err != nil {
+		return 0, "", "", err
+	}
+	var history []*store.SideMiningTx
+	if _, ok := strategy.(*fixedFee); !ok {
+		now := time.Now()
+		history, err = store.SideMiningDbCache.GetSideMiningTxs(sideNode.GenesisBlockAddress,
+			now.Add(-feeHistoryPeriod).Unix(), now.Unix()+1)
+		if err != nil {
+			return 0, "", "", err
+		}
+	}
+	fee, reason := strategy.Fee(history)
+	return fee, strategy.Name(), reason, nil
+}
@@ -0,0 +1,69 @@
+package sideauxpow
+
+import (
+	"testing"
+
+	"github.com/elastos/Elastos.ELA.Arbiter/config"
+	"github.com/elastos/Elastos.ELA.Arbiter/store"
+)
+
+func TestNewFeeStrategy(t *testing.T) {
+	if s, err := NewFeeStrategy(10000, nil); err != nil || s.Name() != FeeStrategyFixed {
+		t.Errorf("expect fixed fee strategy without config, got %v", err)
+	}
+	if _, err := NewFeeStrategy(10000, &config.SideMiningFeeConfig{Strategy: "auction"}); err == nil {
+		t.Error("unknown fee strategy should be refused")
+	}
+	s, _ := NewFeeStrategy(10000, &config.SideMiningFeeConfig{Strategy: FeeStrategyFixed})
+	history := []*store.SideMiningTx{{Fee: 10000, Result: store.SideMiningFailed}}
+	if fee, _ := s.Fee(history); fee != 10000 {
+		t.Errorf("fixed fee strategy should pay the base fee, got %d", fee)
+	}
+}
+
+func TestBumpOnMissFee(t *testing.T) {
+	s, _ := NewFeeStrategy(10000, &config.SideMiningFeeConfig{
+		Strategy:  FeeStrategyBumpOnMiss,
+		MaxFee:    30000,
+		BumpRatio: 2,
+	})
+	cases := []struct {
+		history []*store.SideMiningTx
+		fee     int64
+	}{
+		{nil, 10000},
+		{[]*store.SideMiningTx{{Fee: 20000, Result: store.SideMiningSubmitted}}, 10000},
+		{[]*store.SideMiningTx{{Fee: 10000, Result: store.SideMiningSkipped}}, 20000},
+		{[]*store.SideMiningTx{
+			{Fee: 10000, Result: store.SideMiningSubmitted},
+			{Fee: 20000, Result: store.SideMiningFailed},
+			{Fee: 20000, Result: store.SideMiningSent},
+		}, 30000},
+	}
+	for i, c := range cases {
+		if fee, reason := s.Fee(c.history); int64(fee) != c.fee {
+			t.Errorf("case %d: expect fee %d, got %d, %s", i, c.fee, fee, reason)
+		}
+	}
+}
+
+func TestAdaptiveFee(t *testing.T) {
+	s, _ := NewFeeStrategy(10000, &config.SideMiningFeeConfig{
+		Strategy: FeeStrategyAdaptive,
+		MaxFee:   30000,
+		Window:   4,
+	})
+	history := []*store.SideMiningTx{
+		{SideBlockHash: "a", Result: store.SideMiningFailed},
+		{SideBlockHash: "b", Result: store.SideMiningSubmitted},
+		{SideBlockHash: "c", Result: store.SideMiningFailed},
+		{SideBlockHash: "d", Result: store.SideMiningSubmitted},
+		{SideBlockHash: "e", Result: store.SideMiningSkipped},
+	}
+	if fee, reason := s.Fee(history); fee != 20000 {
+		t.Errorf("expect fee 20000 with half of the window missed, got %d, %s", fee, reason)
+	}
+	if fee, _ := s.Fee(nil); fee != 10000 {
+		t.Errorf("expect base fee without history, got %d", fee)
+	}
+}
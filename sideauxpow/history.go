@@ -0,0 +1,55 @@
+package sideauxpow
+
+import (
+	"github.com/elastos/Elastos.ELA.Arbiter/store"
+
+	"github.com/elastos/Elastos.ELA/common"
+)
+
+// SideMiningSummary counts the side blocks mined by the SideChainPow
+// transactions sent, a side block is missed if none of its auxpows is
+// submitted and none is waiting to be submitted.
+type SideMiningSummary struct {
+	Blocks    int
+	Submitted int
+	Missed    int
+	Pending   int
+	Fees      common.Fixed64
+}
+
+// MissRate returns the ratio of missed side blocks to the side blocks whose
+// auxpow is submitted or missed.
+func (s *SideMiningSummary) MissRate() float64 {
+	if s.Submitted+s.Missed == 0 {
+		return 0
+	}
+	return float64(s.Missed) / float64(s.Submitted+s.Missed)
+}
+
+// SummarizeSideMining summarizes the SideChainPow transactions of a side
+// chain.
+func SummarizeSideMining(txs []*store.SideMiningTx) *SideMiningSummary {
+	summary := &SideMiningSummary{}
+	results := make(map[string]string)
+	for _, tx := range txs {
+		summary.Fees += tx.Fee
+		result, ok := results[tx.SideBlockHash]
+		switch {
+		case !ok, tx.Result == store.SideMiningSubmitted,
+			tx.Result == store.SideMiningSent && result != store.SideMiningSubmitted:
+			results[tx.SideBlockHash] = tx.Result
+		}
+	}
+	summary.Blocks = len(results)
+	for _, result := range results {
+		switch result {
+		case store.SideMiningSubmitted:
+			summary.Submitted++
+		case store.SideMiningSent:
+			summary.Pending++
+		default:
+			summary.Missed++
+		}
+	}
+	return summary
+}
@@ -0,0 +1,32 @@
+package sideauxpow
+
+import (
+	"testing"
+
+	"github.com/elastos/Elastos.ELA.Arbiter/store"
+)
+
+func TestSummarizeSideMining(t *testing.T) {
+	txs := []*store.SideMiningTx{
+		{SideBlockHash: "a", Fee: 10000, Result: store.SideMiningSkipped},
+		{SideBlockHash: "a", Fee: 10000, Result: store.SideMiningSubmitted},
+		{SideBlockHash: "b", Fee: 10000, Result: store.SideMiningFailed},
+		{SideBlockHash: "c", Fee: 10000, Result: store.SideMiningSkipped},
+		{SideBlockHash: "d", Fee: 10000, Result: store.SideMiningFailed},
+		{SideBlockHash: "d", Fee: 10000, Result: store.SideMiningSent},
+		{SideBlockHash: "e", Fee: 10000, Result: store.SideMiningSubmitted},
+	}
+	summary := SummarizeSideMining(txs)
+	if summary.Blocks != 5 || summary.Submitted != 2 || summary.Missed != 2 || summary.Pending != 1 {
+		t.Errorf("side blocks counted wrongly, %+v", summary)
+	}
+	if summary.Fees != 70000 {
+		t.Errorf("expect fees 70000, got %d", summary.Fees)
+	}
+	if rate := summary.MissRate(); rate != 0.5 {
+		t.Errorf("expect miss rate 0.5, got %f", rate)
+	}
+	if rate := SummarizeSideMining(nil).MissRate(); rate != 0 {
+		t.Errorf("expect miss rate 0 without side blocks, got %f", rate)
+	}
+}
@@ -5,13 +5,15 @@ import (
 	"encoding/json"
 	"errors"
 	"sync"
+	"time"
 
 	"github.com/elastos/Elastos.ELA.Arbiter/arbitration/arbitrator"
 	"github.com/elastos/Elastos.ELA.Arbiter/config"
 	"github.com/elastos/Elastos.ELA.Arbiter/log"
 	"github.com/elastos/Elastos.ELA.Arbiter/rpc"
+	"github.com/elastos/Elastos.ELA.Arbiter/signer"
+	"github.com/elastos/Elastos.ELA.Arbiter/store"
 
-	"github.com/elastos/Elastos.ELA/account"
 	"github.com/elastos/Elastos.ELA/common"
 	elacommon "github.com/elastos/Elastos.ELA/core/types/common"
 	"github.com/elastos/Elastos.ELA/core/types/payload"
@@ -20,7 +22,7 @@ import (
 
 var (
 	lock                          sync.RWMutex
-	client                        *account.Client
+	arbiterSigner                 signer.Signer
 	lastSendSideMiningHeightMap   map[common.Uint256]uint32
 	lastNotifySideMiningHeightMap map[common.Uint256]uint32
 	lastSubmitAuxpowHeightMap     map[common.Uint256]uint32
@@ -130,7 +132,11 @@ func sideChainPowTransfer(sideNode *config.SideNodeConfig) error {
 	if config.Parameters.SideAuxPowFee <= 0 {
 		return errors.New("[sideChainPowTransfer] invalid side aux pow fee")
 	}
-	fee := common.Fixed64(config.Parameters.SideAuxPowFee)
+	fee, feeStrategy, feeReason, err := decideSideMiningFee(sideNode)
+	if err != nil {
+		return errors.New("[sideChainPowTransfer] decide fee failed: " + err.Error())
+	}
+	log.Info("[sideChainPowTransfer] fee:", fee, "strategy:", feeStrategy, "reason:", feeReason)
 
 	if sideNode.MiningAddr == "" {
 		return errors.New("[sideChainPowTransfer] get side chain mining address failed:" + sideNode.MiningAddr)
@@ -141,13 +147,12 @@ func sideChainPowTransfer(sideNode *config.SideNodeConfig) error {
 		return errors.New("[sideChainPowTransfer] invalid miningAddr")
 	}
 	codeHash := programHash.ToCodeHash()
-	miningAccount := client.GetAccountByCodeHash(codeHash)
-	if miningAccount == nil {
-		return errors.New("[sideChainPowTransfer] not found miningAddr in keystore")
+	script, err := arbiterSigner.RedeemScript(codeHash)
+	if err != nil {
+		return errors.New("[sideChainPowTransfer] not found miningAddr in keystore: " + err.Error())
 	}
 
 	from := sideNode.MiningAddr
-	script := miningAccount.RedeemScript
 
 	txn, err := createAuxpowTransaction(txType, txPayload, from, &fee, script,
 		arbitrator.ArbitratorGroupSingleton.GetCurrentHeight())
@@ -155,7 +160,7 @@ func sideChainPowTransfer(sideNode *config.SideNodeConfig) error {
 		return errors.New("[sideChainPowTransfer] create transaction failed: " + err.Error())
 	}
 
-	txnSigned, err := client.Sign(txn)
+	txnSigned, err := arbiterSigner.SignTransaction(txn)
 	if err != nil {
 		return err
 	}
@@ -174,6 +179,22 @@ func sideChainPowTransfer(sideNode *config.SideNodeConfig) error {
 		return errors.New("[SendSideChainMining] sendrawtransaction failed: " + err.Error())
 	}
 	log.Info("[SendSideChainMining] End send Sidemining transaction:  genesis address [", sideNode.GenesisBlockAddress, "], result: ", result)
+	now := time.Now()
+	recordPowSpend(sideNode.MiningAddr, fee, now)
+	err = store.SideMiningDbCache.AddSideMiningTx(&store.SideMiningTx{
+		TransactionHash:     txn.Hash().String(),
+		GenesisBlockAddress: sideNode.GenesisBlockAddress,
+		SideBlockHash:       sideBlockHash.String(),
+		SideBlockHeight:     sideAuxBlock.Height,
+		Fee:                 fee,
+		SendHeight:          arbitrator.ArbitratorGroupSingleton.GetCurrentHeight(),
+		SendTime:            now.Unix(),
+		FeeStrategy:         feeStrategy,
+		FeeReason:           feeReason,
+	})
+	if err != nil {
+		log.Warn("[SendSideChainMining] record side mining transaction failed:", err)
+	}
 
 	lock.Lock()
 	defer lock.Unlock()
@@ -191,9 +212,10 @@ func StartSideChainMining(sideNode *config.SideNodeConfig) {
 	}
 }
 
-func Init(c *account.Client) {
-	client = c
+func Init(s signer.Signer) {
+	arbiterSigner = s
 	lastSendSideMiningHeightMap = make(map[common.Uint256]uint32)
 	lastNotifySideMiningHeightMap = make(map[common.Uint256]uint32)
 	lastSubmitAuxpowHeightMap = make(map[common.Uint256]uint32)
+	initTreasury()
 }
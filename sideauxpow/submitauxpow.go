@@ -6,13 +6,15 @@ import (
 	"github.com/elastos/Elastos.ELA.Arbiter/config"
 	"github.com/elastos/Elastos.ELA.Arbiter/log"
 	"github.com/elastos/Elastos.ELA.Arbiter/rpc"
+
+	"github.com/elastos/Elastos.ELA/common"
 )
 
 func SubmitAuxpow(genesishash string, blockhash string, submitauxpow string) error {
 	log.Info("submitsideauxblock")
 
 	var sideNode *config.SideNodeConfig
-	for _, node := range config.Parameters.SideNodeList {
+	for _, node := range config.SideNodes() {
 		if node.GetGenesisBlock() == genesishash {
 			sideNode = node
 		}
@@ -26,14 +28,41 @@ func SubmitAuxpow(genesishash string, blockhash string, submitauxpow string) err
 	params["sideauxpow"] = submitauxpow
 
 	log.Info("[SubmitAuxpow] Submit auxblock sideNode.Rpc：", sideNode.Rpc.IpAddress, ":", sideNode.Rpc.HttpJsonPort)
-	resp, err := rpc.CallAndUnmarshal("submitsideauxblock", params, sideNode.Rpc)
+	resp, err := rpc.CallAndUnmarshalResponse("submitsideauxblock", params, sideNode.Rpc)
 	if err != nil {
 		return err
 	}
-	if resp != nil {
-		log.Info("[SubmitAuxpow] Submit auxblock resp: ", resp)
+	if resp.Error != nil {
+		return &rpc.RejectedError{Code: resp.Code, Message: resp.Message}
+	}
+	if resp.Result != nil {
+		log.Info("[SubmitAuxpow] Submit auxblock resp: ", resp.Result)
 	} else {
 		log.Warn("submitauxblock but resp is nil, sideNode.Rpc:", sideNode.Rpc.IpAddress, ":", sideNode.Rpc.HttpJsonPort)
 	}
 	return nil
 }
+
+// GetAuxBlockHash returns the hash of the side block the side chain is
+// mining, which is the only block it accepts the auxpow of.
+func GetAuxBlockHash(sideNode *config.SideNodeConfig) (common.Uint256, error) {
+	resp, err := rpc.CallAndUnmarshal("createauxblock", rpc.Param("paytoaddress", sideNode.PayToAddr), sideNode.Rpc)
+	if err != nil {
+		return common.Uint256{}, err
+	}
+	auxBlock := struct {
+		Hash string `json:"hash"`
+	}{}
+	if err := unmarshal(resp, &auxBlock); err != nil {
+		return common.Uint256{}, err
+	}
+	data, err := common.HexStringToBytes(auxBlock.Hash)
+	if err != nil {
+		return common.Uint256{}, errors.New("invalid aux block hash " + auxBlock.Hash)
+	}
+	hash, err := common.Uint256FromBytes(data)
+	if err != nil {
+		return common.Uint256{}, errors.New("invalid aux block hash " + auxBlock.Hash)
+	}
+	return *hash, nil
+}
@@ -0,0 +1,408 @@
+package sideauxpow
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/elastos/Elastos.ELA.Arbiter/arbitration/arbitrator"
+	"github.com/elastos/Elastos.ELA.Arbiter/config"
+	"github.com/elastos/Elastos.ELA.Arbiter/events"
+	"github.com/elastos/Elastos.ELA.Arbiter/log"
+	"github.com/elastos/Elastos.ELA.Arbiter/rpc"
+	"github.com/elastos/Elastos.ELA.Arbiter/store"
+
+	"github.com/elastos/Elastos.ELA/common"
+)
+
+// spendWindow is the period in which the spends of mining accounts and the
+// refills of treasury are counted.
+const spendWindow = 24 * time.Hour
+
+// TopUp is a refill sent to a mining account and not confirmed yet.
+type TopUp struct {
+	Address string
+	TxHash  common.Uint256
+	Amount  common.Fixed64
+	Time    time.Time
+}
+
+// MiningAccount is the balance of a side chain mining account and how long
+// it keeps running at the observed spend rate of SideChainPow transactions.
+type MiningAccount struct {
+	SideChain string
+	Address   string
+	Available common.Fixed64
+	Locked    common.Fixed64
+	Pending   common.Fixed64
+	// SpendRate is the amount spent in a day.
+	SpendRate common.Fixed64
+	// Runway is zero if no spend is observed.
+	Runway time.Duration
+	TopUps []*TopUp
+}
+
+type spend struct {
+	amount common.Fixed64
+	time   time.Time
+}
+
+var (
+	treasuryLock  sync.Mutex
+	treasuryStart time.Time
+	powSpends     map[string][]spend
+	refills       []spend
+	pendingTopUps map[string][]*TopUp
+)
+
+func initTreasury() {
+	treasuryStart = time.Now()
+	powSpends = make(map[string][]spend)
+	pendingTopUps = make(map[string][]*TopUp)
+}
+
+// loadTopUps loads the top-ups recorded as pending before the restart, so
+// that the mining accounts are not refilled twice.
+func loadTopUps() {
+	topUps, err := store.PendingTxsDbCache.GetPendingTopUps()
+	if err != nil {
+		log.Error("[Treasury] load pending top-ups failed:", err)
+		return
+	}
+
+	treasuryLock.Lock()
+	defer treasuryLock.Unlock()
+	for _, t := range topUps {
+		txHash, err := common.Uint256FromReversedHexString(t.TransactionHash)
+		if err != nil {
+			log.Warn("[Treasury] invalid pending top-up tx hash:", t.TransactionHash)
+			continue
+		}
+		pendingTopUps[t.Address] = append(pendingTopUps[t.Address], &TopUp{
+			Address: t.Address,
+			TxHash:  *txHash,
+			Amount:  common.Fixed64(t.Amount),
+			Time:    time.Unix(t.RecordTime, 0),
+		})
+	}
+}
+
+func addTopUp(topUp *TopUp) {
+	pendingTopUps[topUp.Address] = append(pendingTopUps[topUp.Address], topUp)
+	if store.PendingTxsDbCache == nil {
+		return
+	}
+	err := store.PendingTxsDbCache.AddPendingTopUp(&store.PendingTopUp{
+		Address:         topUp.Address,
+		TransactionHash: common.ToReversedString(topUp.TxHash),
+		Amount:          int64(topUp.Amount),
+		RecordTime:      topUp.Time.Unix(),
+	})
+	if err != nil {
+		log.Error("[Treasury] record pending top-up failed:", err)
+	}
+}
+
+func removeTopUp(topUp *TopUp) {
+	if store.PendingTxsDbCache == nil {
+		return
+	}
+	if err := store.PendingTxsDbCache.RemovePendingTopUp(topUp.Address, common.ToReversedString(topUp.TxHash)); err != nil {
+		log.Error("[Treasury] remove pending top-up failed:", err)
+	}
+}
+
+// getTopUpConfirmations returns the confirmations of the top-up transaction
+// on the main chain.
+func getTopUpConfirmations(txHash common.Uint256) (uint32, error) {
+	return rpc.GetTransactionConfirmations(common.ToReversedString(txHash), config.Parameters.MainNode.Rpc)
+}
+
+// recordPowSpend records the fee of a SideChainPow transaction sent from the
+// mining account.
+func recordPowSpend(address string, fee common.Fixed64, now time.Time) {
+	treasuryLock.Lock()
+	defer treasuryLock.Unlock()
+	powSpends[address] = append(pruneSpends(powSpends[address], now), spend{fee, now})
+}
+
+func pruneSpends(spends []spend, now time.Time) []spend {
+	for len(spends) > 0 && now.Sub(spends[0].time) > spendWindow {
+		spends = spends[1:]
+	}
+	return spends
+}
+
+func sumSpends(spends []spend, now time.Time) common.Fixed64 {
+	var total common.Fixed64
+	for _, s := range spends {
+		if now.Sub(s.time) <= spendWindow {
+			total += s.amount
+		}
+	}
+	return total
+}
+
+// spendRate returns the amount spent in a day, counted from the spends since
+// start in the window. Periods shorter than an hour are counted as an hour
+// to avoid overestimating the rate from the first spends.
+func spendRate(spends []spend, start, now time.Time) common.Fixed64 {
+	elapsed := now.Sub(start)
+	if elapsed > spendWindow {
+		elapsed = spendWindow
+	}
+	if elapsed < time.Hour {
+		elapsed = time.Hour
+	}
+	return common.Fixed64(float64(sumSpends(spends, now)) * float64(spendWindow) / float64(elapsed))
+}
+
+// runway returns how long the balance lasts at the rate spent in a day.
+func runway(balance, rate common.Fixed64) time.Duration {
+	if rate <= 0 || balance <= 0 {
+		return 0
+	}
+	return time.Duration(float64(balance) / float64(rate) * float64(spendWindow))
+}
+
+// refillAmount returns the amount keeping the account running for
+// RunwayTarget at the spend rate, and at least DepositAmount.
+func refillAmount(cfg *config.TreasuryConfig, depositAmount, available, rate common.Fixed64) common.Fixed64 {
+	target := common.Fixed64(float64(rate) * float64(cfg.RunwayTarget*time.Millisecond) / float64(spendWindow))
+	amount := target - available
+	if amount < depositAmount {
+		amount = depositAmount
+	}
+	return amount
+}
+
+// splitTransfers splits the amount into count outputs to the address, so that
+// the SideChainPow transactions do not wait for the change of each other.
+func splitTransfers(address string, amount common.Fixed64, count int) []*Transfer {
+	if count < 1 || common.Fixed64(count) > amount {
+		count = 1
+	}
+	transfers := make([]*Transfer, 0, count)
+	each := amount / common.Fixed64(count)
+	for i := 0; i < count; i++ {
+		value := each
+		if i == count-1 {
+			value = amount - each*common.Fixed64(count-1)
+		}
+		transfers = append(transfers, &Transfer{Address: address, Amount: &value})
+	}
+	return transfers
+}
+
+// reconcileTopUps removes the pending top-ups of the address whose
+// transaction is found in its utxos. Top-ups not found in the timeout are
+// checked on the main chain, and returned as expired only if the main node
+// does not know the transaction any more.
+func reconcileTopUps(address string, utxos []*UTXO, timeout time.Duration, now time.Time,
+	confirmations func(common.Uint256) (uint32, error)) []*TopUp {
+	treasuryLock.Lock()
+	topUps := pendingTopUps[address]
+	treasuryLock.Unlock()
+
+	var pending, expired []*TopUp
+	for _, topUp := range topUps {
+		confirmed := false
+		for _, utxo := range utxos {
+			if utxo.Op.TxID.IsEqual(topUp.TxHash) {
+				confirmed = true
+				break
+			}
+		}
+		if !confirmed && now.Sub(topUp.Time) > timeout {
+			count, err := confirmations(topUp.TxHash)
+			switch {
+			case err == rpc.ErrUnknownTransaction:
+				expired = append(expired, topUp)
+				removeTopUp(topUp)
+				continue
+			case err != nil:
+				log.Warn("[Treasury] check top-up of", address, "failed:", err)
+			case count > 0:
+				confirmed = true
+			default:
+				log.Warn("[Treasury] top-up of", address, "still in mempool, tx hash:", common.ToReversedString(topUp.TxHash))
+			}
+		}
+		if confirmed {
+			log.Info("[Treasury] top-up of", address, "confirmed, tx hash:", common.ToReversedString(topUp.TxHash))
+			removeTopUp(topUp)
+			continue
+		}
+		pending = append(pending, topUp)
+	}
+
+	treasuryLock.Lock()
+	pendingTopUps[address] = pending
+	treasuryLock.Unlock()
+	return expired
+}
+
+func getMiningAccountUTXOs(address string) ([]*UTXO, error) {
+	programHash, err := common.Uint168FromAddress(address)
+	if err != nil {
+		return nil, errors.New("invalid mining address " + address)
+	}
+	utxos, err := GetAddressUTXOs(programHash)
+	if err != nil {
+		return nil, errors.New("get " + address + " UTXOs failed")
+	}
+	return utxos, nil
+}
+
+func newMiningAccount(sideNode *config.SideNodeConfig, utxos []*UTXO, currentHeight uint32, now time.Time) *MiningAccount {
+	account := &MiningAccount{SideChain: sideNode.Name, Address: sideNode.MiningAddr}
+	for _, utxo := range utxos {
+		if utxo.LockTime < currentHeight {
+			account.Available += *utxo.Amount
+		} else {
+			account.Locked += *utxo.Amount
+		}
+	}
+
+	treasuryLock.Lock()
+	defer treasuryLock.Unlock()
+	for _, topUp := range pendingTopUps[sideNode.MiningAddr] {
+		t := *topUp
+		account.TopUps = append(account.TopUps, &t)
+		account.Pending += topUp.Amount
+	}
+	account.SpendRate = spendRate(powSpends[sideNode.MiningAddr], treasuryStart, now)
+	account.Runway = runway(account.Available+account.Locked+account.Pending, account.SpendRate)
+	return account
+}
+
+// GetMiningAccounts returns the mining accounts of the pow side chains.
+func GetMiningAccounts() ([]*MiningAccount, error) {
+	currentHeight := arbitrator.ArbitratorGroupSingleton.GetCurrentHeight()
+	now := time.Now()
+	var accounts []*MiningAccount
+	for _, sideNode := range config.SideNodes() {
+		if !sideNode.PowChain {
+			continue
+		}
+		utxos, err := getMiningAccountUTXOs(sideNode.MiningAddr)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, newMiningAccount(sideNode, utxos, currentHeight, now))
+	}
+	return accounts, nil
+}
+
+// GetRefilledAmount returns the amount refilled to mining accounts in the
+// last 24 hours.
+func GetRefilledAmount() common.Fixed64 {
+	treasuryLock.Lock()
+	defer treasuryLock.Unlock()
+	return sumSpends(refills, time.Now())
+}
+
+// refillMiningAccounts refills the mining accounts whose available balance
+// is under MinThreshold and which have no pending top-up, in a transaction
+// from the main account. Without Treasury config the accounts are refilled
+// with DepositAmount on every check.
+func refillMiningAccounts(now time.Time) {
+	cfg := config.Parameters.Treasury
+	minThreshold := common.Fixed64(config.Parameters.MinThreshold)
+	currentHeight := arbitrator.ArbitratorGroupSingleton.GetCurrentHeight()
+
+	var budget common.Fixed64
+	if cfg != nil && cfg.DailyBudget > 0 {
+		treasuryLock.Lock()
+		budget = cfg.DailyBudget - sumSpends(refills, now)
+		treasuryLock.Unlock()
+	}
+
+	var outputs []*Transfer
+	var topUps []*TopUp
+	for _, sideNode := range config.SideNodes() {
+		if !sideNode.PowChain {
+			continue
+		}
+		utxos, err := getMiningAccountUTXOs(sideNode.MiningAddr)
+		if err != nil {
+			log.Error("[Treasury] check side chain mining account failed:", err)
+			continue
+		}
+		if cfg != nil {
+			for _, topUp := range reconcileTopUps(sideNode.MiningAddr, utxos, cfg.PendingTimeout*time.Millisecond,
+				now, getTopUpConfirmations) {
+				log.Warn("[Treasury] top-up of", topUp.Address, "dropped by main chain, tx hash:", common.ToReversedString(topUp.TxHash))
+				events.Notify(events.ETSubmitFailed, &events.Alert{
+					Subject: "top-up " + topUp.Address,
+					Message: "top-up transaction " + common.ToReversedString(topUp.TxHash) + " of " + topUp.Amount.String() +
+						" not confirmed in PendingTimeout and unknown to main chain",
+				})
+			}
+		}
+		account := newMiningAccount(sideNode, utxos, currentHeight, now)
+		if account.Available >= minThreshold {
+			continue
+		}
+
+		log.Info("[Treasury] side chain mining account", account.Address, "available balance:",
+			account.Available.String(), "runway:", account.Runway)
+		events.Notify(events.ETSideChainPowAccountLow, &events.Alert{
+			Subject: account.Address,
+			Message: "available balance " + account.Available.String() + " is under MinThreshold " +
+				minThreshold.String() + ", locked balance " + account.Locked.String(),
+		})
+		if cfg == nil {
+			amount := common.Fixed64(config.Parameters.DepositAmount)
+			outputs = append(outputs, &Transfer{Address: account.Address, Amount: &amount})
+			continue
+		}
+		if account.Pending > 0 {
+			log.Info("[Treasury] side chain mining account", account.Address, "waiting for top-up of",
+				account.Pending.String())
+			continue
+		}
+
+		amount := refillAmount(cfg, common.Fixed64(config.Parameters.DepositAmount),
+			account.Available, account.SpendRate)
+		if cfg.DailyBudget > 0 {
+			if budget <= 0 {
+				log.Warn("[Treasury] daily budget exhausted, side chain mining account", account.Address, "not refilled")
+				events.Notify(events.ETSideChainPowAccountLow, &events.Alert{
+					Subject: account.Address,
+					Message: "not refilled, daily budget " + cfg.DailyBudget.String() + " exhausted",
+				})
+				continue
+			}
+			if amount > budget {
+				amount = budget
+			}
+			budget -= amount
+		}
+		outputs = append(outputs, splitTransfers(account.Address, amount, cfg.RefillOutputs)...)
+		topUps = append(topUps, &TopUp{Address: account.Address, Amount: amount, Time: now})
+	}
+	if len(outputs) == 0 {
+		return
+	}
+
+	fee := common.Fixed64(100000)
+	if cfg != nil {
+		fee = cfg.RefillFee
+	}
+	txHash, err := divideTransfer(outputs, fee)
+	if err != nil {
+		log.Error("[Treasury] refill side chain mining accounts failed:", err)
+		return
+	}
+	treasuryLock.Lock()
+	defer treasuryLock.Unlock()
+	refills = pruneSpends(refills, now)
+	for _, topUp := range topUps {
+		topUp.TxHash = txHash
+		addTopUp(topUp)
+		refills = append(refills, spend{topUp.Amount, now})
+		log.Info("[Treasury] refill side chain mining account", topUp.Address, "with", topUp.Amount.String(),
+			"tx hash:", common.ToReversedString(txHash))
+	}
+}
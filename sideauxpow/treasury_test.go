@@ -0,0 +1,172 @@
+package sideauxpow
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/elastos/Elastos.ELA.Arbiter/config"
+	"github.com/elastos/Elastos.ELA.Arbiter/log"
+	"github.com/elastos/Elastos.ELA.Arbiter/rpc"
+	"github.com/elastos/Elastos.ELA.Arbiter/store"
+
+	"github.com/elastos/Elastos.ELA/common"
+	elacommon "github.com/elastos/Elastos.ELA/core/types/common"
+)
+
+func TestMain(m *testing.M) {
+	dir, err := ioutil.TempDir("", "arbiter-sideauxpow")
+	if err != nil {
+		panic(err)
+	}
+	log.Init(filepath.Join(dir, "logs"), 1, 0, 0)
+	initTreasury()
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func TestSpendRate(t *testing.T) {
+	now := time.Now()
+	spends := []spend{
+		{50000, now.Add(-25 * time.Hour)},
+		{50000, now.Add(-2 * time.Hour)},
+		{50000, now.Add(-time.Hour)},
+	}
+	if rate := spendRate(spends, now.Add(-48*time.Hour), now); rate != 100000 {
+		t.Errorf("expect spends in 24 hours, got %d", rate)
+	}
+	if rate := spendRate(spends, now.Add(-12*time.Hour), now); rate != 200000 {
+		t.Errorf("expect spends since start scaled to a day, got %d", rate)
+	}
+	if rate := spendRate(spends, now.Add(-time.Minute), now); rate != 2400000 {
+		t.Errorf("expect period shorter than an hour counted as an hour, got %d", rate)
+	}
+	if r := runway(100000, 200000); r != 12*time.Hour {
+		t.Errorf("expect runway of 12 hours, got %s", r)
+	}
+	if r := runway(100000, 0); r != 0 {
+		t.Errorf("expect no runway without spends, got %s", r)
+	}
+}
+
+func TestRefillAmount(t *testing.T) {
+	cfg := &config.TreasuryConfig{RunwayTarget: 7 * 86400000}
+	if amount := refillAmount(cfg, 1000000, 400000, 1000000); amount != 6600000 {
+		t.Errorf("expect refill lasting the runway target, got %d", amount)
+	}
+	if amount := refillAmount(cfg, 1000000, 400000, 0); amount != 1000000 {
+		t.Errorf("expect DepositAmount without spends, got %d", amount)
+	}
+}
+
+func TestSplitTransfers(t *testing.T) {
+	transfers := splitTransfers("EWYdXxK6L8unXcz2Hu2nmLBQLr67Qx5c2b", 1000003, 4)
+	if len(transfers) != 4 {
+		t.Fatalf("expect 4 outputs, got %d", len(transfers))
+	}
+	var total common.Fixed64
+	for _, transfer := range transfers {
+		total += *transfer.Amount
+	}
+	if total != 1000003 || *transfers[0].Amount != 250000 || *transfers[3].Amount != 250003 {
+		t.Errorf("outputs should add up to the amount, got %d", total)
+	}
+	if transfers := splitTransfers("EWYdXxK6L8unXcz2Hu2nmLBQLr67Qx5c2b", 3, 4); len(transfers) != 1 {
+		t.Errorf("expect a single output for tiny amounts, got %d", len(transfers))
+	}
+}
+
+func TestReconcileTopUps(t *testing.T) {
+	address := "EWYdXxK6L8unXcz2Hu2nmLBQLr67Qx5c2b"
+	now := time.Now()
+	confirmed := &TopUp{Address: address, TxHash: common.Uint256{1}, Amount: 100, Time: now.Add(-time.Minute)}
+	pending := &TopUp{Address: address, TxHash: common.Uint256{2}, Amount: 100, Time: now.Add(-time.Minute)}
+	dropped := &TopUp{Address: address, TxHash: common.Uint256{3}, Amount: 100, Time: now.Add(-2 * time.Hour)}
+	mempool := &TopUp{Address: address, TxHash: common.Uint256{4}, Amount: 100, Time: now.Add(-2 * time.Hour)}
+	spent := &TopUp{Address: address, TxHash: common.Uint256{5}, Amount: 100, Time: now.Add(-2 * time.Hour)}
+	unreachable := &TopUp{Address: address, TxHash: common.Uint256{6}, Amount: 100, Time: now.Add(-2 * time.Hour)}
+	pendingTopUps[address] = []*TopUp{confirmed, pending, dropped, mempool, spent, unreachable}
+
+	confirmations := func(txHash common.Uint256) (uint32, error) {
+		switch txHash {
+		case dropped.TxHash:
+			return 0, rpc.ErrUnknownTransaction
+		case spent.TxHash:
+			return 3, nil
+		case unreachable.TxHash:
+			return 0, errors.New("connection refused")
+		}
+		return 0, nil
+	}
+	amount := common.Fixed64(100)
+	utxos := []*UTXO{{Op: &elacommon.OutPoint{TxID: common.Uint256{1}}, Amount: &amount}}
+	result := reconcileTopUps(address, utxos, time.Hour, now, confirmations)
+	if len(result) != 1 || result[0] != dropped {
+		t.Errorf("expect only the top-up unknown to main chain expired, got %v", result)
+	}
+	left := pendingTopUps[address]
+	if len(left) != 3 || left[0] != pending || left[1] != mempool || left[2] != unreachable {
+		t.Errorf("expect unconfirmed top-ups known to main chain pending, got %v", left)
+	}
+}
+
+// topUpStoreMock keeps the pending top-ups in memory.
+type topUpStoreMock struct {
+	store.PendingTransactionsDataStore
+	topUps []*store.PendingTopUp
+}
+
+func (m *topUpStoreMock) AddPendingTopUp(topUp *store.PendingTopUp) error {
+	m.topUps = append(m.topUps, topUp)
+	return nil
+}
+
+func (m *topUpStoreMock) GetPendingTopUps() ([]*store.PendingTopUp, error) {
+	return m.topUps, nil
+}
+
+func (m *topUpStoreMock) RemovePendingTopUp(address, transactionHash string) error {
+	for i, t := range m.topUps {
+		if t.Address == address && t.TransactionHash == transactionHash {
+			m.topUps = append(m.topUps[:i], m.topUps[i+1:]...)
+			return nil
+		}
+	}
+	return errors.New("pending top-up not found")
+}
+
+func TestLoadTopUps(t *testing.T) {
+	mock := &topUpStoreMock{}
+	pendingTxsDbCache := store.PendingTxsDbCache
+	store.PendingTxsDbCache = mock
+	defer func() {
+		store.PendingTxsDbCache = pendingTxsDbCache
+		initTreasury()
+	}()
+
+	address := "EWYdXxK6L8unXcz2Hu2nmLBQLr67Qx5c2b"
+	topUp := &TopUp{Address: address, TxHash: common.Uint256{1, 2, 3}, Amount: 100, Time: time.Unix(10, 0)}
+	initTreasury()
+	addTopUp(topUp)
+	if len(mock.topUps) != 1 || mock.topUps[0].TransactionHash != common.ToReversedString(topUp.TxHash) {
+		t.Fatalf("top-up should be stored by the reversed tx hash, got %v", mock.topUps)
+	}
+
+	initTreasury()
+	loadTopUps()
+	loaded := pendingTopUps[address]
+	if len(loaded) != 1 || loaded[0].TxHash != topUp.TxHash || loaded[0].Amount != topUp.Amount ||
+		!loaded[0].Time.Equal(topUp.Time) {
+		t.Fatalf("top-up should be loaded as stored, got %v", loaded)
+	}
+
+	removeTopUp(loaded[0])
+	if len(mock.topUps) != 0 {
+		t.Error("loaded top-up should be removed from store")
+	}
+}
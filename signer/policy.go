@@ -0,0 +1,242 @@
+package signer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/elastos/Elastos.ELA.Arbiter/config"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/contract"
+	elatx "github.com/elastos/Elastos.ELA/core/transaction"
+	elacommon "github.com/elastos/Elastos.ELA/core/types/common"
+	it "github.com/elastos/Elastos.ELA/core/types/interfaces"
+	"github.com/elastos/Elastos.ELA/core/types/payload"
+)
+
+// DefaultAllowedTxTypes are the transaction types signed by the remote
+// signer if AllowedTxTypes is not configured.
+var DefaultAllowedTxTypes = []elacommon.TxType{
+	elacommon.WithdrawFromSideChain,
+	elacommon.ReturnSideChainDepositCoin,
+	elacommon.NFTDestroyFromSideChain,
+	elacommon.SideChainPow,
+}
+
+// Policy decides which transactions the remote signer signs.
+type Policy struct {
+	AllowedTxTypes map[elacommon.TxType]struct{}
+
+	// MaxWithdrawAmount is the max total amount of a withdraw transaction
+	// leaving the cross chain address, no limit if it is 0.
+	MaxWithdrawAmount common.Fixed64
+
+	// MaxWithdrawOutputAmount is the max amount of a single output of a
+	// withdraw transaction, no limit if it is 0.
+	MaxWithdrawOutputAmount common.Fixed64
+}
+
+// NewPolicy returns the policy of signer config, the default policy is
+// returned if cfg is nil.
+func NewPolicy(cfg *config.SignerConfig) (*Policy, error) {
+	p := &Policy{AllowedTxTypes: make(map[elacommon.TxType]struct{})}
+	if cfg == nil || len(cfg.AllowedTxTypes) == 0 {
+		for _, txType := range DefaultAllowedTxTypes {
+			p.AllowedTxTypes[txType] = struct{}{}
+		}
+	} else {
+		for _, name := range cfg.AllowedTxTypes {
+			txType, ok := txTypeByName(name)
+			if !ok {
+				return nil, fmt.Errorf("unknown transaction type %s", name)
+			}
+			p.AllowedTxTypes[txType] = struct{}{}
+		}
+	}
+	if cfg != nil {
+		if cfg.MaxWithdrawAmount < 0 || cfg.MaxWithdrawOutputAmount < 0 {
+			return nil, fmt.Errorf("withdraw limits should not be negative")
+		}
+		p.MaxWithdrawAmount = cfg.MaxWithdrawAmount
+		p.MaxWithdrawOutputAmount = cfg.MaxWithdrawOutputAmount
+	}
+	return p, nil
+}
+
+func txTypeByName(name string) (elacommon.TxType, bool) {
+	for i := 0; i < 256; i++ {
+		if txType := elacommon.TxType(i); txType.Name() == name && name != "Unknown" {
+			return txType, true
+		}
+	}
+	return 0, false
+}
+
+// CheckTransaction checks if the transaction is allowed to be signed.
+func (p *Policy) CheckTransaction(txn it.Transaction) error {
+	if _, ok := p.AllowedTxTypes[txn.TxType()]; !ok {
+		return fmt.Errorf("transaction type %s is not allowed", txn.TxType().Name())
+	}
+
+	switch txn.TxType() {
+	case elacommon.WithdrawFromSideChain, elacommon.ReturnSideChainDepositCoin:
+	default:
+		return nil
+	}
+	var total common.Fixed64
+	for i, output := range txn.Outputs() {
+		// the change goes back to the cross chain address
+		if contract.PrefixType(output.ProgramHash[0]) == contract.PrefixCrossChain {
+			continue
+		}
+		if p.MaxWithdrawOutputAmount > 0 && output.Value > p.MaxWithdrawOutputAmount {
+			return fmt.Errorf("output %d amount %s exceeds limit %s", i,
+				output.Value, p.MaxWithdrawOutputAmount)
+		}
+		total += output.Value
+	}
+	if p.MaxWithdrawAmount > 0 && total > p.MaxWithdrawAmount {
+		return fmt.Errorf("withdraw amount %s exceeds limit %s", total,
+			p.MaxWithdrawAmount)
+	}
+	return nil
+}
+
+// CheckContent checks the content to be signed by ECDSA. An unsigned
+// transaction or the feedback of a schnorr proposal is checked by
+// CheckTransaction, other content should be one of the messages signed by
+// arbiter which can not move any coin. Unknown content is refused.
+func (p *Policy) CheckContent(content []byte) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("invalid content, %v", r)
+		}
+	}()
+	if txn, ok := parseUnsignedTransaction(content); ok {
+		return p.CheckTransaction(txn)
+	}
+	if txn, ok := parseSchnorrSFeedback(content); ok {
+		return p.CheckTransaction(txn)
+	}
+	if isArbiterMessage(content) {
+		return nil
+	}
+	return errors.New("content is neither a transaction nor a known message")
+}
+
+// isArbiterMessage returns if the content is the nonce of a p2p handshake,
+// the hash of an invalid withdraw transaction, a side chain pow payload, a
+// cross chain transfer of main chain, an illegal evidence of side chain or the
+// feedback of a schnorr R proposal.
+func isArbiterMessage(content []byte) bool {
+	switch len(content) {
+	case handshakeNonceSize, common.UINT256SIZE, sideChainPowSize:
+		return true
+	}
+	if txn, ok := parseTransaction(content); ok {
+		return txn.TxType() == elacommon.TransferCrossChainAsset
+	}
+	return isIllegalEvidence(content) || isSchnorrRFeedback(content)
+}
+
+const (
+	// handshakeNonceSize is the size of nonce signed in p2p handshakes.
+	handshakeNonceSize = 16
+
+	// sideChainPowSize is the size of side block hash, side genesis hash and
+	// block height signed in the side chain pow payload.
+	sideChainPowSize = common.UINT256SIZE*2 + 4
+
+	// maxSchnorrValueSize is the max size of a point coordinate or a
+	// scalar of schnorr proposals.
+	maxSchnorrValueSize = 65
+)
+
+func parseUnsignedTransaction(content []byte) (it.Transaction, bool) {
+	r := bytes.NewReader(content)
+	txn, err := elatx.GetTransactionByBytes(r)
+	if err != nil {
+		return nil, false
+	}
+	if err := txn.DeserializeUnsigned(r); err != nil || r.Len() != 0 {
+		return nil, false
+	}
+	return txn, true
+}
+
+func parseTransaction(content []byte) (it.Transaction, bool) {
+	r := bytes.NewReader(content)
+	txn, err := elatx.GetTransactionByBytes(r)
+	if err != nil {
+		return nil, false
+	}
+	if err := txn.Deserialize(r); err != nil || r.Len() != 0 {
+		return nil, false
+	}
+	return txn, true
+}
+
+func isIllegalEvidence(content []byte) bool {
+	r := bytes.NewReader(content)
+	var evidence payload.SidechainIllegalData
+	err := evidence.DeserializeUnsigned(r, payload.SidechainIllegalDataVersion)
+	return err == nil && r.Len() == 0
+}
+
+// isSchnorrRFeedback returns if the content is the nonce of a schnorr R
+// proposal with k0, R and P of the signer, k0 is empty if the signer returns
+// partial signatures.
+func isSchnorrRFeedback(content []byte) bool {
+	r := bytes.NewReader(content)
+	nonce, err := common.ReadVarBytes(r, common.UINT256SIZE, "nonce")
+	if err != nil || len(nonce) != common.UINT256SIZE {
+		return false
+	}
+	for i := 0; i < 5; i++ {
+		if _, err := common.ReadVarBytes(r, maxSchnorrValueSize, "krp"); err != nil {
+			return false
+		}
+	}
+	return r.Len() == 0
+}
+
+// parseSchnorrSFeedback returns the transaction of a schnorr S proposal
+// feedback, which is the nonce hash, the unsigned transaction, the public
+// keys of signers, e, the aggregated R if the signers return partial
+// signatures, and s.
+func parseSchnorrSFeedback(content []byte) (it.Transaction, bool) {
+	r := bytes.NewReader(content)
+	var nonceHash common.Uint256
+	if err := nonceHash.Deserialize(r); err != nil {
+		return nil, false
+	}
+	txn, err := elatx.GetTransactionByBytes(r)
+	if err != nil {
+		return nil, false
+	}
+	if err := txn.DeserializeUnsigned(r); err != nil {
+		return nil, false
+	}
+	count, err := common.ReadVarUint(r, 0)
+	if err != nil || count > math.MaxUint8 {
+		return nil, false
+	}
+	for i := uint64(0); i < count; i++ {
+		if _, err := common.ReadVarBytes(r, 33, "pk"); err != nil {
+			return nil, false
+		}
+	}
+	var values int
+	for r.Len() > 0 {
+		if _, err := common.ReadVarBytes(r, maxSchnorrValueSize, "value"); err != nil {
+			return nil, false
+		}
+		values++
+	}
+	if values != 2 && values != 4 {
+		return nil, false
+	}
+	return txn, true
+}
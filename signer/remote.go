@@ -0,0 +1,257 @@
+package signer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/elastos/Elastos.ELA/common"
+	elatx "github.com/elastos/Elastos.ELA/core/transaction"
+	it "github.com/elastos/Elastos.ELA/core/types/interfaces"
+	"github.com/elastos/Elastos.ELA/crypto"
+)
+
+const (
+	methodPublicKey       = "publickey"
+	methodSign            = "sign"
+	methodSignTransaction = "signtransaction"
+	methodRedeemScript    = "redeemscript"
+	methodSchnorrR        = "schnorrr"
+	methodSchnorrS        = "schnorrs"
+
+	// CallTimeout is the max time to wait for a response of remote signer.
+	CallTimeout = 30 * time.Second
+
+	// MaxMessageSize is the max size of a request or response line.
+	MaxMessageSize = 8 * 1024 * 1024
+)
+
+// request is a line of json sent to the signer daemon, params and results are
+// hex strings.
+type request struct {
+	Method string   `json:"method"`
+	Params []string `json:"params,omitempty"`
+}
+
+type response struct {
+	Result []string `json:"result,omitempty"`
+	Error  string   `json:"error,omitempty"`
+}
+
+// Remote is the Signer talking to the signer daemon over a unix socket, so the
+// arbiter key does not live in the arbiter process.
+type Remote struct {
+	path      string
+	publicKey *crypto.PublicKey
+
+	mtx    sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// NewRemote connects to the signer daemon listening on the unix socket path.
+func NewRemote(path string) (*Remote, error) {
+	r := &Remote{path: path}
+	result, err := r.call(methodPublicKey)
+	if err != nil {
+		return nil, err
+	}
+	pk, err := hex.DecodeString(result[0])
+	if err != nil {
+		return nil, err
+	}
+	r.publicKey, err = crypto.DecodePoint(pk)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *Remote) PublicKey() *crypto.PublicKey {
+	return r.publicKey
+}
+
+func (r *Remote) Sign(content []byte) ([]byte, error) {
+	result, err := r.call(methodSign, hex.EncodeToString(content))
+	if err != nil {
+		return nil, err
+	}
+	return hex.DecodeString(result[0])
+}
+
+func (r *Remote) SignTransaction(txn it.Transaction) (it.Transaction, error) {
+	buf := new(bytes.Buffer)
+	if err := txn.Serialize(buf); err != nil {
+		return nil, err
+	}
+	result, err := r.call(methodSignTransaction, hex.EncodeToString(buf.Bytes()))
+	if err != nil {
+		return nil, err
+	}
+	data, err := hex.DecodeString(result[0])
+	if err != nil {
+		return nil, err
+	}
+	signed, err := decodeTransaction(data)
+	if err != nil {
+		return nil, err
+	}
+	if signed.Hash() != txn.Hash() {
+		return nil, errors.New("signed transaction mismatch")
+	}
+	txn.SetPrograms(signed.Programs())
+	return txn, nil
+}
+
+func (r *Remote) RedeemScript(codeHash common.Uint160) ([]byte, error) {
+	result, err := r.call(methodRedeemScript, hex.EncodeToString(codeHash.Bytes()))
+	if err != nil {
+		return nil, err
+	}
+	return hex.DecodeString(result[0])
+}
+
+func (r *Remote) SchnorrR(session common.Uint256) (rx *big.Int, ry *big.Int, px *big.Int, py *big.Int, err error) {
+	result, err := r.call(methodSchnorrR, hex.EncodeToString(session.Bytes()))
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	values, err := decodeInts(result, 4)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	return values[0], values[1], values[2], values[3], nil
+}
+
+// SchnorrLegacyR is refused, the legacy schnorr proposals need k0 out of
+// the signer daemon.
+func (r *Remote) SchnorrLegacyR() (k0 *big.Int, rx *big.Int, ry *big.Int, px *big.Int, py *big.Int, err error) {
+	return nil, nil, nil, nil, nil, errors.New("legacy schnorr signature is refused by the signer daemon")
+}
+
+// SchnorrLegacyS is refused, e is multiplied by the private key without
+// checking it.
+func (r *Remote) SchnorrLegacyS(e *big.Int, txn it.Transaction) (*big.Int, error) {
+	return nil, errors.New("legacy schnorr signature is refused by the signer daemon")
+}
+
+func (r *Remote) SchnorrS(session common.Uint256, txn it.Transaction, rx, ry, px, py *big.Int) (*big.Int, error) {
+	buf := new(bytes.Buffer)
+	if err := txn.Serialize(buf); err != nil {
+		return nil, err
+	}
+	result, err := r.call(methodSchnorrS, hex.EncodeToString(session.Bytes()),
+		hex.EncodeToString(buf.Bytes()), hex.EncodeToString(rx.Bytes()), hex.EncodeToString(ry.Bytes()),
+		hex.EncodeToString(px.Bytes()), hex.EncodeToString(py.Bytes()))
+	if err != nil {
+		return nil, err
+	}
+	values, err := decodeInts(result, 1)
+	if err != nil {
+		return nil, err
+	}
+	return values[0], nil
+}
+
+// call sends the request and waits for the response, the connection is
+// reestablished on next call if anything goes wrong.
+func (r *Remote) call(method string, params ...string) ([]string, error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if r.conn == nil {
+		conn, err := net.DialTimeout("unix", r.path, CallTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("connect signer %s error: %v", r.path, err)
+		}
+		r.conn = conn
+		r.reader = bufio.NewReaderSize(conn, 4096)
+	}
+
+	resp, err := r.roundTrip(&request{Method: method, Params: params})
+	if err != nil {
+		r.conn.Close()
+		r.conn, r.reader = nil, nil
+		return nil, fmt.Errorf("signer %s %s error: %v", r.path, method, err)
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("signer refused %s: %s", method, resp.Error)
+	}
+	if len(resp.Result) == 0 {
+		return nil, fmt.Errorf("signer %s returned empty result", method)
+	}
+	return resp.Result, nil
+}
+
+func (r *Remote) roundTrip(req *request) (*response, error) {
+	r.conn.SetDeadline(time.Now().Add(CallTimeout))
+	if err := writeMessage(r.conn, req); err != nil {
+		return nil, err
+	}
+	var resp response
+	if err := readMessage(r.reader, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func writeMessage(conn net.Conn, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = conn.Write(append(data, '\n'))
+	return err
+}
+
+func readMessage(reader *bufio.Reader, v interface{}) error {
+	var line []byte
+	for {
+		part, isPrefix, err := reader.ReadLine()
+		if err != nil {
+			return err
+		}
+		line = append(line, part...)
+		if len(line) > MaxMessageSize {
+			return errors.New("message too large")
+		}
+		if !isPrefix {
+			break
+		}
+	}
+	return json.Unmarshal(line, v)
+}
+
+func decodeTransaction(data []byte) (it.Transaction, error) {
+	r := bytes.NewReader(data)
+	txn, err := elatx.GetTransactionByBytes(r)
+	if err != nil {
+		return nil, err
+	}
+	if err := txn.Deserialize(r); err != nil {
+		return nil, err
+	}
+	return txn, nil
+}
+
+func decodeInts(values []string, count int) ([]*big.Int, error) {
+	if len(values) != count {
+		return nil, fmt.Errorf("expect %d values, got %d", count, len(values))
+	}
+	result := make([]*big.Int, 0, count)
+	for _, v := range values {
+		b, err := hex.DecodeString(v)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, new(big.Int).SetBytes(b))
+	}
+	return result, nil
+}
@@ -0,0 +1,210 @@
+package signer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"os"
+
+	"github.com/elastos/Elastos.ELA.Arbiter/log"
+
+	"github.com/elastos/Elastos.ELA/common"
+)
+
+// Server is the signer daemon serving a Signer to the arbiter over a unix
+// socket, every transaction is checked by the policy before signing.
+type Server struct {
+	signer Signer
+	policy *Policy
+}
+
+// NewServer returns the signer daemon of signer.
+func NewServer(signer Signer, policy *Policy) *Server {
+	return &Server{signer: signer, policy: policy}
+}
+
+// ListenAndServe listens on the unix socket path which is only accessible by
+// the owner, and serves the requests.
+func (s *Server) ListenAndServe(path string) error {
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return fmt.Errorf("%s exists and is not a socket", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+	}
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return err
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		listener.Close()
+		return err
+	}
+	log.Info("[Signer] listen on ", path)
+	return s.Serve(listener)
+}
+
+// Serve accepts connections on listener and serves the requests.
+func (s *Server) Serve(listener net.Listener) error {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+		go s.serveConn(conn)
+	}
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReaderSize(conn, 4096)
+	for {
+		var req request
+		if err := readMessage(reader, &req); err != nil {
+			if err != io.EOF {
+				log.Warn("[Signer] read request error: ", err)
+			}
+			return
+		}
+
+		var resp response
+		result, err := s.handle(&req)
+		if err != nil {
+			log.Warnf("[Signer] refused %s: %v", req.Method, err)
+			resp.Error = err.Error()
+		} else {
+			resp.Result = result
+		}
+		if err := writeMessage(conn, &resp); err != nil {
+			log.Warn("[Signer] write response error: ", err)
+			return
+		}
+	}
+}
+
+func (s *Server) handle(req *request) ([]string, error) {
+	params := make([][]byte, 0, len(req.Params))
+	for _, p := range req.Params {
+		b, err := hex.DecodeString(p)
+		if err != nil {
+			return nil, errors.New("invalid params")
+		}
+		params = append(params, b)
+	}
+
+	switch req.Method {
+	case methodPublicKey:
+		pk, err := s.signer.PublicKey().EncodePoint(true)
+		if err != nil {
+			return nil, err
+		}
+		return []string{hex.EncodeToString(pk)}, nil
+
+	case methodSign:
+		if len(params) != 1 {
+			return nil, errors.New("invalid params")
+		}
+		if err := s.policy.CheckContent(params[0]); err != nil {
+			return nil, err
+		}
+		signature, err := s.signer.Sign(params[0])
+		if err != nil {
+			return nil, err
+		}
+		return []string{hex.EncodeToString(signature)}, nil
+
+	case methodSignTransaction:
+		if len(params) != 1 {
+			return nil, errors.New("invalid params")
+		}
+		txn, err := decodeTransaction(params[0])
+		if err != nil {
+			return nil, err
+		}
+		if err := s.policy.CheckTransaction(txn); err != nil {
+			return nil, err
+		}
+		signed, err := s.signer.SignTransaction(txn)
+		if err != nil {
+			return nil, err
+		}
+		buf := new(bytes.Buffer)
+		if err := signed.Serialize(buf); err != nil {
+			return nil, err
+		}
+		log.Info("[Signer] signed transaction ", signed.Hash())
+		return []string{hex.EncodeToString(buf.Bytes())}, nil
+
+	case methodRedeemScript:
+		if len(params) != 1 {
+			return nil, errors.New("invalid params")
+		}
+		codeHash, err := common.Uint160FromBytes(params[0])
+		if err != nil {
+			return nil, err
+		}
+		script, err := s.signer.RedeemScript(codeHash)
+		if err != nil {
+			return nil, err
+		}
+		return []string{hex.EncodeToString(script)}, nil
+
+	case methodSchnorrR:
+		if len(params) != 1 {
+			return nil, errors.New("invalid params")
+		}
+		session, err := common.Uint256FromBytes(params[0])
+		if err != nil {
+			return nil, err
+		}
+		rx, ry, px, py, err := s.signer.SchnorrR(*session)
+		if err != nil {
+			return nil, err
+		}
+		return encodeInts(rx, ry, px, py), nil
+
+	case methodSchnorrS:
+		if len(params) != 6 {
+			return nil, errors.New("invalid params")
+		}
+		session, err := common.Uint256FromBytes(params[0])
+		if err != nil {
+			return nil, err
+		}
+		txn, err := decodeTransaction(params[1])
+		if err != nil {
+			return nil, err
+		}
+		if err := s.policy.CheckTransaction(txn); err != nil {
+			return nil, err
+		}
+		points := make([]*big.Int, 0, 4)
+		for _, p := range params[2:] {
+			points = append(points, new(big.Int).SetBytes(p))
+		}
+		sValue, err := s.signer.SchnorrS(*session, txn, points[0], points[1], points[2], points[3])
+		if err != nil {
+			return nil, err
+		}
+		log.Info("[Signer] schnorr signed transaction ", txn.Hash())
+		return encodeInts(sValue), nil
+
+	default:
+		return nil, fmt.Errorf("unknown method %s", req.Method)
+	}
+}
+
+func encodeInts(values ...*big.Int) []string {
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		result = append(result, hex.EncodeToString(v.Bytes()))
+	}
+	return result
+}
@@ -0,0 +1,192 @@
+package signer
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+	"time"
+
+	crypto2 "github.com/elastos/Elastos.ELA.Arbiter/arbitration/crypto"
+
+	"github.com/elastos/Elastos.ELA/account"
+	"github.com/elastos/Elastos.ELA/common"
+	it "github.com/elastos/Elastos.ELA/core/types/interfaces"
+	"github.com/elastos/Elastos.ELA/crypto"
+)
+
+// Signer signs with the arbiter key, so the callers do not need to know
+// where the key lives.
+type Signer interface {
+	// PublicKey returns the public key of arbiter.
+	PublicKey() *crypto.PublicKey
+
+	// Sign returns the ECDSA signature of content by arbiter key.
+	Sign(content []byte) ([]byte, error)
+
+	// SignTransaction signs the transaction by the accounts of keystore.
+	SignTransaction(txn it.Transaction) (it.Transaction, error)
+
+	// RedeemScript returns the redeem script of the keystore account.
+	RedeemScript(codeHash common.Uint160) ([]byte, error)
+
+	// SchnorrR returns the nonce point R = k0*G of the schnorr session and
+	// the arbiter public key P, k0 is generated once for the session and
+	// never leaves the signer.
+	SchnorrR(session common.Uint256) (rx *big.Int, ry *big.Int, px *big.Int, py *big.Int, err error)
+
+	// SchnorrS returns the partial signature s = k + e*x mod N of the
+	// schnorr withdraw transaction, e is computed from the aggregated nonce
+	// point R, the aggregated public key P and the transaction hash. A
+	// session signs only one e, k0 would be leaked by two.
+	SchnorrS(session common.Uint256, txn it.Transaction, rx, ry, px, py *big.Int) (*big.Int, error)
+
+	// SchnorrLegacyR generates a random k0 and returns it with R = k0*G and
+	// the arbiter public key P, for the schnorr proposals made before
+	// SchnorrPartialSignatureHeight.
+	SchnorrLegacyR() (k0 *big.Int, rx *big.Int, ry *big.Int, px *big.Int, py *big.Int, err error)
+
+	// SchnorrLegacyS returns e multiplied by arbiter private key, for the
+	// schnorr proposals made before SchnorrPartialSignatureHeight.
+	SchnorrLegacyS(e *big.Int, txn it.Transaction) (*big.Int, error)
+}
+
+// SchnorrSessionTimeout is the time a schnorr session is kept after its
+// nonce is generated.
+const SchnorrSessionTimeout = time.Hour
+
+// schnorrSession is the nonce of a schnorr session and the partial signature
+// made by it.
+type schnorrSession struct {
+	k0      *big.Int
+	rx, ry  *big.Int
+	created time.Time
+
+	e *big.Int
+	s *big.Int
+}
+
+// schnorrSessions keeps the nonces of schnorr sessions, so they are neither
+// returned nor used to sign two different e.
+type schnorrSessions struct {
+	mtx      sync.Mutex
+	sessions map[common.Uint256]*schnorrSession
+}
+
+// r returns the nonce point of the session, the nonce is generated by getR
+// at the first time.
+func (ss *schnorrSessions) r(session common.Uint256, getR func() (k0, rx, ry *big.Int, err error)) (*big.Int, *big.Int, error) {
+	ss.mtx.Lock()
+	defer ss.mtx.Unlock()
+	if ss.sessions == nil {
+		ss.sessions = make(map[common.Uint256]*schnorrSession)
+	}
+	now := time.Now()
+	for id, s := range ss.sessions {
+		if now.Sub(s.created) > SchnorrSessionTimeout {
+			delete(ss.sessions, id)
+		}
+	}
+	if s, ok := ss.sessions[session]; ok {
+		return s.rx, s.ry, nil
+	}
+	k0, rx, ry, err := getR()
+	if err != nil {
+		return nil, nil, err
+	}
+	ss.sessions[session] = &schnorrSession{k0: k0, rx: rx, ry: ry, created: now}
+	return rx, ry, nil
+}
+
+// s returns the partial signature of the session by sign, the same signature
+// is returned if the session is asked to sign the same e again.
+func (ss *schnorrSessions) s(session common.Uint256, e *big.Int, sign func(k0 *big.Int) *big.Int) (*big.Int, error) {
+	ss.mtx.Lock()
+	defer ss.mtx.Unlock()
+	s, ok := ss.sessions[session]
+	if !ok {
+		return nil, errors.New("schnorr session not found")
+	}
+	if s.e != nil {
+		if s.e.Cmp(e) != 0 {
+			return nil, errors.New("schnorr session already signed another message")
+		}
+		return s.s, nil
+	}
+	s.e, s.s = e, sign(s.k0)
+	return s.s, nil
+}
+
+// Keystore is the Signer with the keystore opened in process.
+type Keystore struct {
+	client   *account.Client
+	sessions schnorrSessions
+}
+
+// NewKeystore returns the Signer of the opened keystore.
+func NewKeystore(client *account.Client) *Keystore {
+	return &Keystore{client: client}
+}
+
+func (k *Keystore) PublicKey() *crypto.PublicKey {
+	return k.client.GetMainAccount().PubKey()
+}
+
+func (k *Keystore) Sign(content []byte) ([]byte, error) {
+	return k.client.GetMainAccount().Sign(content)
+}
+
+func (k *Keystore) SignTransaction(txn it.Transaction) (it.Transaction, error) {
+	return k.client.Sign(txn)
+}
+
+func (k *Keystore) RedeemScript(codeHash common.Uint160) ([]byte, error) {
+	acc := k.client.GetAccountByCodeHash(codeHash)
+	if acc == nil {
+		return nil, errors.New("account not found in keystore")
+	}
+	return acc.RedeemScript, nil
+}
+
+func (k *Keystore) SchnorrR(session common.Uint256) (rx *big.Int, ry *big.Int, px *big.Int, py *big.Int, err error) {
+	return schnorrR(&k.sessions, k.privateKey(), session)
+}
+
+func (k *Keystore) SchnorrS(session common.Uint256, txn it.Transaction, rx, ry, px, py *big.Int) (*big.Int, error) {
+	return schnorrS(&k.sessions, k.privateKey(), session, txn, rx, ry, px, py)
+}
+
+func (k *Keystore) SchnorrLegacyR() (k0 *big.Int, rx *big.Int, ry *big.Int, px *big.Int, py *big.Int, err error) {
+	return crypto2.GetR(k.privateKey())
+}
+
+func (k *Keystore) SchnorrLegacyS(e *big.Int, txn it.Transaction) (*big.Int, error) {
+	return crypto2.GetEMulPrivateKey(k.privateKey(), e), nil
+}
+
+func (k *Keystore) privateKey() *big.Int {
+	return new(big.Int).SetBytes(k.client.GetMainAccount().PrivateKey)
+}
+
+func schnorrR(sessions *schnorrSessions, privateKey *big.Int, session common.Uint256) (rx *big.Int, ry *big.Int, px *big.Int, py *big.Int, err error) {
+	rx, ry, err = sessions.r(session, func() (*big.Int, *big.Int, *big.Int, error) {
+		k0, rx, ry, _, _, err := crypto2.GetR(privateKey)
+		return k0, rx, ry, err
+	})
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	px, py = crypto2.Curve.ScalarBaseMult(crypto2.IntToByte(privateKey))
+	return rx, ry, px, py, nil
+}
+
+func schnorrS(sessions *schnorrSessions, privateKey *big.Int, session common.Uint256, txn it.Transaction,
+	rx, ry, px, py *big.Int) (*big.Int, error) {
+	if !crypto2.Curve.IsOnCurve(rx, ry) || !crypto2.Curve.IsOnCurve(px, py) {
+		return nil, errors.New("invalid aggregated point")
+	}
+	message := txn.Hash()
+	e := crypto2.GetE([]*big.Int{rx}, []*big.Int{ry}, []*big.Int{px}, []*big.Int{py}, message[:])
+	return sessions.s(session, e, func(k0 *big.Int) *big.Int {
+		return crypto2.GetPartialS(privateKey, k0, rx, ry, px, py, message[:])
+	})
+}
@@ -0,0 +1,389 @@
+package signer
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	crypto2 "github.com/elastos/Elastos.ELA.Arbiter/arbitration/crypto"
+	"github.com/elastos/Elastos.ELA.Arbiter/config"
+	"github.com/elastos/Elastos.ELA.Arbiter/log"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/contract"
+	pg "github.com/elastos/Elastos.ELA/core/contract/program"
+	elatx "github.com/elastos/Elastos.ELA/core/transaction"
+	elacommon "github.com/elastos/Elastos.ELA/core/types/common"
+	"github.com/elastos/Elastos.ELA/core/types/functions"
+	it "github.com/elastos/Elastos.ELA/core/types/interfaces"
+	"github.com/elastos/Elastos.ELA/core/types/outputpayload"
+	"github.com/elastos/Elastos.ELA/core/types/payload"
+	"github.com/elastos/Elastos.ELA/crypto"
+)
+
+var testDir string
+
+func TestMain(m *testing.M) {
+	var err error
+	testDir, err = ioutil.TempDir("", "arbiter-signer")
+	if err != nil {
+		panic(err)
+	}
+	log.Init(filepath.Join(testDir, "logs"), 1, 0, 0)
+	functions.GetTransactionByTxType = elatx.GetTransaction
+	functions.GetTransactionByBytes = elatx.GetTransactionByBytes
+	functions.CreateTransaction = elatx.CreateTransaction
+	functions.GetTransactionParameters = elatx.GetTransactionparameters
+
+	code := m.Run()
+	os.RemoveAll(testDir)
+	os.Exit(code)
+}
+
+// testSigner signs by hashing instead of the ECDSA of keystore, which
+// panics with recent Go toolchains, the schnorr signatures are made as the
+// keystore does.
+type testSigner struct {
+	privateKey *big.Int
+	publicKey  *crypto.PublicKey
+	sessions   schnorrSessions
+}
+
+func newTestSigner(t *testing.T) *testSigner {
+	privateKey, pk, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testSigner{privateKey: new(big.Int).SetBytes(privateKey), publicKey: pk}
+}
+
+func (s *testSigner) PublicKey() *crypto.PublicKey {
+	return s.publicKey
+}
+
+func (s *testSigner) Sign(content []byte) ([]byte, error) {
+	hash := sha256.Sum256(content)
+	return hash[:], nil
+}
+
+func (s *testSigner) SignTransaction(txn it.Transaction) (it.Transaction, error) {
+	txn.Programs()[0].Parameter = []byte{1}
+	return txn, nil
+}
+
+func (s *testSigner) RedeemScript(codeHash common.Uint160) ([]byte, error) {
+	script, err := contract.CreateStandardRedeemScript(s.publicKey)
+	if err != nil {
+		return nil, err
+	}
+	if *common.ToCodeHash(script) != codeHash {
+		return nil, errors.New("account not found in keystore")
+	}
+	return script, nil
+}
+
+func (s *testSigner) SchnorrR(session common.Uint256) (rx *big.Int, ry *big.Int, px *big.Int, py *big.Int, err error) {
+	return schnorrR(&s.sessions, s.privateKey, session)
+}
+
+func (s *testSigner) SchnorrS(session common.Uint256, txn it.Transaction, rx, ry, px, py *big.Int) (*big.Int, error) {
+	return schnorrS(&s.sessions, s.privateKey, session, txn, rx, ry, px, py)
+}
+
+func (s *testSigner) SchnorrLegacyR() (k0 *big.Int, rx *big.Int, ry *big.Int, px *big.Int, py *big.Int, err error) {
+	return crypto2.GetR(s.privateKey)
+}
+
+func (s *testSigner) SchnorrLegacyS(e *big.Int, txn it.Transaction) (*big.Int, error) {
+	return crypto2.GetEMulPrivateKey(s.privateKey, e), nil
+}
+
+func newTestRemote(t *testing.T, signer Signer, policy *Policy) *Remote {
+	path := filepath.Join(testDir, t.Name()+".sock")
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go NewServer(signer, policy).Serve(listener)
+
+	remote, err := NewRemote(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return remote
+}
+
+func newWithdrawTx(t *testing.T, signer Signer, amounts ...common.Fixed64) it.Transaction {
+	pk := signer.PublicKey()
+	code, err := contract.CreateStandardRedeemScript(pk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var outputs []*elacommon.Output
+	for _, amount := range amounts {
+		outputs = append(outputs, &elacommon.Output{
+			ProgramHash: common.Uint168{byte(contract.PrefixStandard)},
+			Value:       amount,
+			Type:        elacommon.OTNone,
+			Payload:     &outputpayload.DefaultOutput{},
+		})
+	}
+	outputs = append(outputs, &elacommon.Output{
+		ProgramHash: common.Uint168{byte(contract.PrefixCrossChain)},
+		Value:       1000000,
+		Type:        elacommon.OTNone,
+		Payload:     &outputpayload.DefaultOutput{},
+	})
+	return elatx.CreateTransaction(
+		elacommon.TxVersion09,
+		elacommon.WithdrawFromSideChain,
+		payload.WithdrawFromSideChainVersion,
+		&payload.WithdrawFromSideChain{},
+		[]*elacommon.Attribute{},
+		[]*elacommon.Input{{Previous: elacommon.OutPoint{TxID: common.Uint256{1}}}},
+		outputs,
+		0,
+		[]*pg.Program{{Code: code}},
+	)
+}
+
+func unsignedBytes(t *testing.T, txn it.Transaction) []byte {
+	buf := new(bytes.Buffer)
+	if err := txn.SerializeUnsigned(buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// schnorrRFeedback returns the content of a schnorr R feedback signed by
+// arbiter, the nonce with k0, R and P.
+func schnorrRFeedback(t *testing.T) []byte {
+	buf := new(bytes.Buffer)
+	values := [][]byte{make([]byte, common.UINT256SIZE), {1}, {2}, {3}, {4}, {5}}
+	for _, v := range values {
+		if err := common.WriteVarBytes(buf, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return buf.Bytes()
+}
+
+// schnorrSFeedback returns the content of a schnorr S feedback signed by
+// arbiter, the aggregated R is carried if partial.
+func schnorrSFeedback(t *testing.T, txn it.Transaction, partial bool) []byte {
+	buf := new(bytes.Buffer)
+	nonceHash := common.Uint256{1}
+	if err := nonceHash.Serialize(buf); err != nil {
+		t.Fatal(err)
+	}
+	if err := txn.SerializeUnsigned(buf); err != nil {
+		t.Fatal(err)
+	}
+	if err := common.WriteVarUint(buf, 1); err != nil {
+		t.Fatal(err)
+	}
+	values := [][]byte{bytes.Repeat([]byte{2}, 33), {1}}
+	if partial {
+		values = append(values, []byte{2}, []byte{3})
+	}
+	values = append(values, []byte{4})
+	for _, v := range values {
+		if err := common.WriteVarBytes(buf, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return buf.Bytes()
+}
+
+func TestPolicy(t *testing.T) {
+	signer := newTestSigner(t)
+	policy, err := NewPolicy(&config.SignerConfig{
+		MaxWithdrawAmount:       300,
+		MaxWithdrawOutputAmount: 200,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := policy.CheckTransaction(newWithdrawTx(t, signer, 100, 200)); err != nil {
+		t.Errorf("withdraw in limits should be allowed, %v", err)
+	}
+	if err := policy.CheckTransaction(newWithdrawTx(t, signer, 201)); err == nil {
+		t.Error("output over limit should be refused")
+	}
+	if err := policy.CheckTransaction(newWithdrawTx(t, signer, 200, 200)); err == nil {
+		t.Error("withdraw over limit should be refused")
+	}
+
+	if err := policy.CheckContent(unsignedBytes(t, newWithdrawTx(t, signer, 201))); err == nil {
+		t.Error("unsigned transaction content over limit should be refused")
+	}
+	if err := policy.CheckContent(make([]byte, handshakeNonceSize)); err != nil {
+		t.Errorf("handshake nonce should be allowed, %v", err)
+	}
+	if err := policy.CheckContent([]byte("unknown message")); err == nil {
+		t.Error("unknown content should be refused")
+	}
+	if err := policy.CheckContent(schnorrRFeedback(t)); err != nil {
+		t.Errorf("schnorr R feedback should be allowed, %v", err)
+	}
+	for _, partial := range []bool{false, true} {
+		if err := policy.CheckContent(schnorrSFeedback(t, newWithdrawTx(t, signer, 100), partial)); err != nil {
+			t.Errorf("schnorr S feedback in limits should be allowed, %v", err)
+		}
+		if err := policy.CheckContent(schnorrSFeedback(t, newWithdrawTx(t, signer, 201), partial)); err == nil {
+			t.Error("schnorr S feedback over limit should be refused")
+		}
+	}
+
+	transfer := newWithdrawTx(t, signer, 100)
+	transfer.SetTxType(elacommon.TransferAsset)
+	if err := policy.CheckTransaction(transfer); err == nil {
+		t.Error("transfer asset should be refused by default")
+	}
+	policy, err = NewPolicy(&config.SignerConfig{AllowedTxTypes: []string{"TransferAsset"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := policy.CheckTransaction(transfer); err != nil {
+		t.Errorf("allowed transfer asset should be signed, %v", err)
+	}
+
+	if _, err := NewPolicy(&config.SignerConfig{AllowedTxTypes: []string{"NotExist"}}); err == nil {
+		t.Error("unknown transaction type should be invalid")
+	}
+}
+
+func TestRemote(t *testing.T) {
+	signer := newTestSigner(t)
+	policy, err := NewPolicy(&config.SignerConfig{MaxWithdrawAmount: 300})
+	if err != nil {
+		t.Fatal(err)
+	}
+	remote := newTestRemote(t, signer, policy)
+
+	pk, _ := remote.PublicKey().EncodePoint(true)
+	expected, _ := signer.PublicKey().EncodePoint(true)
+	if !bytes.Equal(pk, expected) {
+		t.Fatal("public key mismatch")
+	}
+
+	content := make([]byte, handshakeNonceSize)
+	signature, err := remote.Sign(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected, _ := signer.Sign(content); !bytes.Equal(signature, expected) {
+		t.Error("signature mismatch")
+	}
+	if _, err := remote.Sign(unsignedBytes(t, newWithdrawTx(t, signer, 301))); err == nil {
+		t.Error("unsigned transaction content over limit should be refused")
+	}
+
+	txn := newWithdrawTx(t, signer, 100)
+	signed, err := remote.SignTransaction(txn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(signed.Programs()[0].Parameter, []byte{1}) {
+		t.Error("transaction not signed")
+	}
+	if _, err := remote.SignTransaction(newWithdrawTx(t, signer, 301)); err == nil {
+		t.Error("withdraw over limit should be refused")
+	}
+
+	codeHash := common.ToCodeHash(signed.Programs()[0].Code)
+	script, err := remote.RedeemScript(*codeHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(script, signed.Programs()[0].Code) {
+		t.Error("redeem script mismatch")
+	}
+	if _, err := remote.RedeemScript(common.Uint160{}); err == nil {
+		t.Error("unknown account should be refused")
+	}
+
+	session := common.Uint256{1}
+	rx, ry, px, py, err := remote.SchnorrR(session)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if px.Cmp(signer.PublicKey().X) != 0 || py.Cmp(signer.PublicKey().Y) != 0 {
+		t.Error("schnorr P mismatch")
+	}
+	s, err := remote.SchnorrS(session, txn, rx, ry, px, py)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var publicKey [33]byte
+	copy(publicKey[:], crypto2.Marshal(px, py))
+	if ok, err := crypto.SchnorrVerify(publicKey, txn.Hash(), crypto2.GetS(rx, s)); !ok {
+		t.Error("schnorr signature of single signer should be valid", err)
+	}
+	if _, err := remote.SchnorrS(common.Uint256{2}, newWithdrawTx(t, signer, 301), rx, ry, px, py); err == nil {
+		t.Error("schnorr withdraw over limit should be refused")
+	}
+	if _, _, _, _, _, err := remote.SchnorrLegacyR(); err == nil {
+		t.Error("legacy schnorr R should be refused")
+	}
+	if _, err := remote.SchnorrLegacyS(big.NewInt(1), txn); err == nil {
+		t.Error("legacy schnorr S should be refused")
+	}
+}
+
+func TestSchnorrNonce(t *testing.T) {
+	signer := newTestSigner(t)
+	x := signer.privateKey
+	txn := newWithdrawTx(t, signer, 100)
+	message := txn.Hash()
+
+	// e is computed from the points, the attacker sends G as R and P to
+	// learn s = k + e*x of a known e.
+	gx, gy := crypto2.Curve.Params().Gx, crypto2.Curve.Params().Gy
+	session := common.Uint256{1}
+	if _, err := signer.SchnorrS(session, txn, gx, gy, gx, gy); err == nil {
+		t.Error("schnorr session without nonce should be refused")
+	}
+	if _, _, _, _, err := signer.SchnorrR(session); err != nil {
+		t.Fatal(err)
+	}
+	s1, err := signer.SchnorrS(session, txn, gx, gy, gx, gy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e1 := crypto2.GetE([]*big.Int{gx}, []*big.Int{gy}, []*big.Int{gx}, []*big.Int{gy}, message[:])
+	ex := new(big.Int).Mul(e1, x)
+	if s1.Cmp(x) == 0 || s1.Cmp(ex.Mod(ex, crypto2.N)) == 0 {
+		t.Error("partial signature should be blinded by the nonce")
+	}
+	if s, _ := signer.SchnorrS(session, txn, gx, gy, gx, gy); s.Cmp(s1) != 0 {
+		t.Error("same message of the session should get the same signature")
+	}
+
+	// a second e of the same nonce solves x = (s1 - s2) / (e1 - e2)
+	px, py := signer.PublicKey().X, signer.PublicKey().Y
+	if _, err := signer.SchnorrS(session, txn, gx, gy, px, py); err == nil {
+		t.Error("second message of the same nonce should be refused")
+	}
+	if _, err := signer.SchnorrS(session, newWithdrawTx(t, signer, 200), gx, gy, gx, gy); err == nil {
+		t.Error("second transaction of the same nonce should be refused")
+	}
+	if _, err := signer.SchnorrS(session, txn, big.NewInt(1), big.NewInt(2), gx, gy); err == nil {
+		t.Error("point not on curve should be refused")
+	}
+
+	// a new session gets a new nonce
+	rx, _, _, _, _ := signer.SchnorrR(session)
+	rx2, _, _, _, _ := signer.SchnorrR(common.Uint256{2})
+	if rx.Cmp(rx2) == 0 {
+		t.Error("sessions should not share the nonce")
+	}
+}
@@ -48,7 +48,8 @@ const (
 				Id INTEGER NOT NULL PRIMARY KEY,
 				TransactionHash VARCHAR UNIQUE,
 				TransactionData BLOB,
-				BlockHeight INTEGER
+				BlockHeight INTEGER,
+				RejectReason TEXT DEFAULT ''
 			);`
 	CreateNFTDestroyTxsTable = `CREATE TABLE IF NOT EXISTS NFTDestroyTxs (
 				Id INTEGER NOT NULL PRIMARY KEY,
@@ -110,6 +111,9 @@ type DataStoreMainChain interface {
 	GetAllMainChainTxHashes() ([]string, []string, error)
 	GetAllMainChainTxs() ([]*base.MainChainTransaction, error)
 	GetMainChainTxsFromHashes(transactionHashes []string, genesisBlockAddresses string) ([]*base.SpvTransaction, error)
+	GetMainChainTxData(transactionHash, genesisBlockAddress string) ([]byte, []byte, error)
+	AddMainChainTxData(transactionHash, genesisBlockAddress string, transactionData, merkleProof []byte) error
+	UpdateMainChainTxProof(tx *base.MainChainTransaction) error
 }
 
 type DataStoreSideChain interface {
@@ -126,9 +130,12 @@ type DataStoreSideChain interface {
 	GetAllSideChainTxHashes() ([]string, error)
 	GetAllSideChainTxHashesAndHeights() ([]string, []uint32, error)
 	GetSideChainTxsFromHashes(transactionHashes []string) ([]*base.WithdrawTx, error)
+	GetSideChainTxData(transactionHash string) ([]byte, uint32, error)
+	SetSideChainTxRejectReason(transactionHash, reason string) error
 
 	AddReturnDepositTx(txid string, genesisBlockAddress string, transactionByte []byte) error
 	GetReturnDepositTx(txid string) ([]byte, error)
+	GetReturnDepositTxRecordTime(txid string) (int64, error)
 	GetAllReturnDepositTx(genesisBlockAddress string) ([][]byte, []string, error)
 	GetAllReturnDepositTxs() ([]string, error)
 	RemoveReturnDepositTxs(transactionHashes []string) error
@@ -352,6 +359,10 @@ func CreateSideChainDBByConfig(sideChain *config.SideNodeConfig) (*DataStoreSide
 	if err != nil {
 		return nil, err
 	}
+	// Add the reject reason column missing in the table created by older versions
+	if err := addColumnIfNotExists(db, "SideChainTxs", "RejectReason TEXT DEFAULT ''"); err != nil {
+		return nil, err
+	}
 	// Create return deposit transactions table
 	_, err = db.Exec(CreateReturnDepositTransactionsTable)
 	if err != nil {
@@ -406,6 +417,10 @@ func initSideChainDB() ([]*sql.DB, error) {
 		if err != nil {
 			return nil, err
 		}
+		// Add the reject reason column missing in the table created by older versions
+		if err := addColumnIfNotExists(db, "SideChainTxs", "RejectReason TEXT DEFAULT ''"); err != nil {
+			return nil, err
+		}
 		// Create return deposit transactions table
 		_, err = db.Exec(CreateReturnDepositTransactionsTable)
 		if err != nil {
@@ -435,7 +450,7 @@ func initSideChainDBByName(sideChainName string) (*sql.DB, error) {
 		return nil, err
 	}
 
-	for _, sideChain := range config.Parameters.SideNodeList {
+	for _, sideChain := range config.SideNodes() {
 		if sideChain.Name != sideChainName {
 			continue
 		}
@@ -456,6 +471,10 @@ func initSideChainDBByName(sideChainName string) (*sql.DB, error) {
 		if err != nil {
 			return nil, err
 		}
+		// Add the reject reason column missing in the table created by older versions
+		if err := addColumnIfNotExists(db, "SideChainTxs", "RejectReason TEXT DEFAULT ''"); err != nil {
+			return nil, err
+		}
 		// Create return deposit transactions table
 		_, err = db.Exec(CreateReturnDepositTransactionsTable)
 		if err != nil {
@@ -568,7 +587,7 @@ func (store *DataStoreSideChainImpl) AddSideChainTxs(txs []*base.SideChainTransa
 	defer tx.Commit()
 
 	// Prepare sql statement
-	stmt, err := tx.Prepare("INSERT INTO SideChainTxs(TransactionHash, TransactionData, BlockHeight) values(?,?,?)")
+	stmt, err := tx.Prepare("INSERT INTO SideChainTxs(TransactionHash, TransactionData, BlockHeight, RejectReason) values(?,?,?,?)")
 	if err != nil {
 		return err
 	}
@@ -576,7 +595,7 @@ func (store *DataStoreSideChainImpl) AddSideChainTxs(txs []*base.SideChainTransa
 
 	// Do insert
 	for _, tx := range txs {
-		_, err = stmt.Exec(tx.TransactionHash, tx.Transaction, tx.BlockHeight)
+		_, err = stmt.Exec(tx.TransactionHash, tx.Transaction, tx.BlockHeight, tx.RejectReason)
 		if err != nil {
 			log.Error("[AddSideChainTxs] err")
 			continue
@@ -591,14 +610,14 @@ func (store *DataStoreSideChainImpl) AddSideChainTx(tx *base.SideChainTransactio
 	defer store.mux.Unlock()
 
 	// Prepare sql statement
-	stmt, err := store.Prepare("INSERT INTO SideChainTxs(TransactionHash, TransactionData, BlockHeight) values(?,?,?)")
+	stmt, err := store.Prepare("INSERT INTO SideChainTxs(TransactionHash, TransactionData, BlockHeight, RejectReason) values(?,?,?,?)")
 	if err != nil {
 		return err
 	}
 	defer stmt.Close()
 
 	// Do insert
-	_, err = stmt.Exec(tx.TransactionHash, tx.Transaction, tx.BlockHeight)
+	_, err = stmt.Exec(tx.TransactionHash, tx.Transaction, tx.BlockHeight, tx.RejectReason)
 	if err != nil {
 		return err
 	}
@@ -688,13 +707,37 @@ func (store *DataStoreSideChainImpl) GetAllSideChainTxHashesAndHeights() ([]stri
 	return txHashes, blockHeights, nil
 }
 
+// GetSideChainTxData returns the serialized transaction and block height of
+// a withdraw transaction.
+func (store *DataStoreSideChainImpl) GetSideChainTxData(transactionHash string) ([]byte, uint32, error) {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	rows, err := store.Query(`SELECT TransactionData, BlockHeight FROM SideChainTxs WHERE TransactionHash=?`, transactionHash)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, 0, errors.New("side chain transaction not found")
+	}
+	var transactionBytes []byte
+	var blockHeight uint32
+	err = rows.Scan(&transactionBytes, &blockHeight)
+	if err != nil {
+		return nil, 0, err
+	}
+	return transactionBytes, blockHeight, nil
+}
+
 func (store *DataStoreSideChainImpl) GetSideChainTxsFromHashes(transactionHashes []string) ([]*base.WithdrawTx, error) {
 	store.mux.Lock()
 	defer store.mux.Unlock()
 
 	var txs []*base.WithdrawTx
 	var buf bytes.Buffer
-	buf.WriteString("SELECT SideChainTxs.TransactionData FROM SideChainTxs WHERE TransactionHash IN (")
+	buf.WriteString("SELECT SideChainTxs.TransactionData, SideChainTxs.RejectReason FROM SideChainTxs WHERE TransactionHash IN (")
 	hashesLen := len(transactionHashes)
 	for index, hash := range transactionHashes {
 		buf.WriteString("'")
@@ -716,7 +759,8 @@ func (store *DataStoreSideChainImpl) GetSideChainTxsFromHashes(transactionHashes
 
 	for rows.Next() {
 		var transactionBytes []byte
-		err = rows.Scan(&transactionBytes)
+		var rejectReason string
+		err = rows.Scan(&transactionBytes, &rejectReason)
 		if err != nil {
 			return nil, err
 		}
@@ -724,12 +768,24 @@ func (store *DataStoreSideChainImpl) GetSideChainTxsFromHashes(transactionHashes
 		tx := new(base.WithdrawTx)
 		reader := bytes.NewReader(transactionBytes)
 		tx.Deserialize(reader)
+		tx.RejectReason = rejectReason
 		txs = append(txs, tx)
 
 	}
 	return txs, nil
 }
 
+// SetSideChainTxRejectReason records the reason the withdraw transaction is
+// rejected by the withdraw rules.
+func (store *DataStoreSideChainImpl) SetSideChainTxRejectReason(transactionHash, reason string) error {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	_, err := store.Exec("UPDATE SideChainTxs SET RejectReason=? WHERE TransactionHash=?",
+		reason, transactionHash)
+	return err
+}
+
 func (store *DataStoreSideChainImpl) AddNFTDestroyTx(tx *base.NFTDestroyTransaction) error {
 	store.mux.Lock()
 	defer store.mux.Unlock()
@@ -987,6 +1043,28 @@ func (store *DataStoreSideChainImpl) GetReturnDepositTx(txid string) ([]byte, er
 	return transactionBytes, nil
 }
 
+// GetReturnDepositTxRecordTime returns the unix time the return deposit of
+// the deposit transaction is recorded, 0 if it is not recorded.
+func (store *DataStoreSideChainImpl) GetReturnDepositTxRecordTime(txid string) (int64, error) {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	var recordTime string
+	err := store.QueryRow(`SELECT RecordTime FROM ReturnDepositTransactions WHERE TransactionHash=?
+				ORDER BY Id LIMIT 1`, txid).Scan(&recordTime)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	t, err := time.ParseInLocation("2006-01-02_15.04.05", recordTime, time.Local)
+	if err != nil {
+		return 0, err
+	}
+	return t.Unix(), nil
+}
+
 func (store *DataStoreMainChainImpl) ResetDataStore(dbName string) error {
 	store.DB.Close()
 	os.Remove(dbName)
@@ -1246,6 +1324,66 @@ func (store *DataStoreMainChainImpl) GetAllMainChainTxs() ([]*base.MainChainTran
 	return txs, nil
 }
 
+// GetMainChainTxData returns the serialized transaction and merkle proof of
+// a deposit transaction.
+func (store *DataStoreMainChainImpl) GetMainChainTxData(transactionHash, genesisBlockAddress string) ([]byte, []byte, error) {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	rows, err := store.Query(`SELECT TransactionData, MerkleProof FROM MainChainTxs WHERE TransactionHash=? AND GenesisBlockAddress=?`,
+		transactionHash, genesisBlockAddress)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, nil, errors.New("main chain transaction not found")
+	}
+	var transactionBytes []byte
+	var merkleProofBytes []byte
+	err = rows.Scan(&transactionBytes, &merkleProofBytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	return transactionBytes, merkleProofBytes, nil
+}
+
+// AddMainChainTxData adds a deposit transaction by its serialized data.
+func (store *DataStoreMainChainImpl) AddMainChainTxData(transactionHash, genesisBlockAddress string, transactionData, merkleProof []byte) error {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	stmt, err := store.Prepare("INSERT INTO MainChainTxs(TransactionHash, GenesisBlockAddress, TransactionData, MerkleProof) values(?,?,?,?)")
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(transactionHash, genesisBlockAddress, transactionData, merkleProof)
+	return err
+}
+
+// UpdateMainChainTxProof replaces the merkle proof of the recorded deposit
+// transaction, the block of the old proof may have been reorganized.
+func (store *DataStoreMainChainImpl) UpdateMainChainTxProof(tx *base.MainChainTransaction) error {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	buf := new(bytes.Buffer)
+	if err := tx.Proof.Serialize(buf); err != nil {
+		return err
+	}
+	stmt, err := store.Prepare("UPDATE MainChainTxs SET MerkleProof=? WHERE TransactionHash=? AND GenesisBlockAddress=?")
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(buf.Bytes(), tx.TransactionHash, tx.GenesisBlockAddress)
+	return err
+}
+
 func (store *DataStoreMainChainImpl) GetMainChainTxsFromHashes(transactionHashes []string,
 	genesisBlockAddresses string) ([]*base.SpvTransaction, error) {
 	store.mux.Lock()
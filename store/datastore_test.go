@@ -5,12 +5,14 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/elastos/Elastos.ELA.Arbiter/arbitration/base"
 	"github.com/elastos/Elastos.ELA.Arbiter/config"
 
 	"github.com/elastos/Elastos.ELA/core/contract/program"
 	"github.com/elastos/Elastos.ELA.SPV/bloom"
+	"github.com/elastos/Elastos.ELA/common"
 	elacommon "github.com/elastos/Elastos.ELA/core/types/common"
 	elatx "github.com/elastos/Elastos.ELA/core/transaction"
 	"github.com/elastos/Elastos.ELA/core/types/payload"
@@ -56,7 +58,7 @@ func TestDataStoreImpl_AddSideChainTx(t *testing.T) {
 
 	buf := new(bytes.Buffer)
 	tx.Serialize(buf)
-	if err := datastore[0].AddSideChainTx(&base.SideChainTransaction{txHash, buf.Bytes(), 10}); err != nil {
+	if err := datastore[0].AddSideChainTx(&base.SideChainTransaction{txHash, buf.Bytes(), 10, ""}); err != nil {
 		t.Error("Add side chain transaction error.")
 	}
 
@@ -119,9 +121,9 @@ func TestDataStoreImpl_AddSideChainTxs(t *testing.T) {
 	tx.Serialize(buf)
 	err = datastore[0].AddSideChainTxs(
 		[]*base.SideChainTransaction{
-			&base.SideChainTransaction{txHash1, buf.Bytes(), 10},
-			&base.SideChainTransaction{txHash2, buf.Bytes(), 10},
-			&base.SideChainTransaction{txHash3, buf.Bytes(), 10},
+			&base.SideChainTransaction{txHash1, buf.Bytes(), 10, ""},
+			&base.SideChainTransaction{txHash2, buf.Bytes(), 10, ""},
+			&base.SideChainTransaction{txHash3, buf.Bytes(), 10, ""},
 		})
 	if err != nil {
 		t.Error("Add side chain transaction error.")
@@ -190,8 +192,8 @@ func TestDataStoreImpl_RemoveSideChainTxs(t *testing.T) {
 	buf2 := new(bytes.Buffer)
 	tx2.Serialize(buf2)
 
-	datastore[0].AddSideChainTx(&base.SideChainTransaction{txHash, buf.Bytes(), 10})
-	datastore[0].AddSideChainTx(&base.SideChainTransaction{txHash2, buf2.Bytes(), 10})
+	datastore[0].AddSideChainTx(&base.SideChainTransaction{txHash, buf.Bytes(), 10, ""})
+	datastore[0].AddSideChainTx(&base.SideChainTransaction{txHash2, buf2.Bytes(), 10, ""})
 
 	if ok, err := datastore[0].HasSideChainTx(txHash); !ok || err != nil {
 		t.Error("Should have specified transaction.")
@@ -244,10 +246,10 @@ func TestDataStoreImpl_GetAllSideChainTxHashes(t *testing.T) {
 	)
 	buf := new(bytes.Buffer)
 	tx.Serialize(buf)
-	datastore[0].AddSideChainTx(&base.SideChainTransaction{txHash, buf.Bytes(), 10})
-	datastore[0].AddSideChainTx(&base.SideChainTransaction{txHash2, buf.Bytes(), 10})
-	datastore[0].AddSideChainTx(&base.SideChainTransaction{txHash3, buf.Bytes(), 11})
-	datastore[0].AddSideChainTx(&base.SideChainTransaction{txHash3, buf.Bytes(), 11})
+	datastore[0].AddSideChainTx(&base.SideChainTransaction{txHash, buf.Bytes(), 10, ""})
+	datastore[0].AddSideChainTx(&base.SideChainTransaction{txHash2, buf.Bytes(), 10, ""})
+	datastore[0].AddSideChainTx(&base.SideChainTransaction{txHash3, buf.Bytes(), 11, ""})
+	datastore[0].AddSideChainTx(&base.SideChainTransaction{txHash3, buf.Bytes(), 11, ""})
 
 	txHashes, err := datastore[0].GetAllSideChainTxHashes()
 	if err != nil {
@@ -317,9 +319,9 @@ func TestDataStoreImpl_GetSideChainTxsFromHashes(t *testing.T) {
 	tx2.SetLockTime( 2)
 	tx3.SetLockTime( 3)
 
-	datastore[0].AddSideChainTx(&base.SideChainTransaction{txHash, buf1.Bytes(), 10})
-	datastore[0].AddSideChainTx(&base.SideChainTransaction{txHash2, buf2.Bytes(), 10})
-	datastore[0].AddSideChainTx(&base.SideChainTransaction{txHash3, buf3.Bytes(), 10})
+	datastore[0].AddSideChainTx(&base.SideChainTransaction{txHash, buf1.Bytes(), 10, ""})
+	datastore[0].AddSideChainTx(&base.SideChainTransaction{txHash2, buf2.Bytes(), 10, ""})
+	datastore[0].AddSideChainTx(&base.SideChainTransaction{txHash3, buf3.Bytes(), 10, ""})
 
 	var txHashes []string
 	txHashes = append(txHashes, txHash)
@@ -339,6 +341,82 @@ func TestDataStoreImpl_GetSideChainTxsFromHashes(t *testing.T) {
 	datastore[0].ResetDataStore(DBNameSideChain)
 }
 
+func TestDataStoreImpl_SideChainTxRejectReason(t *testing.T) {
+	datastore, err := OpenSideChainDataStore()
+	if err != nil {
+		t.Fatal("Open database error.")
+	}
+
+	var txs []*base.SideChainTransaction
+	for i, reason := range []string{"address EXXX is frozen", ""} {
+		amount := common.Fixed64(10000)
+		withdrawTx := &base.WithdrawTx{
+			Txid: &common.Uint256{byte(i + 1)},
+			WithdrawInfo: &base.WithdrawInfo{WithdrawAssets: []*base.WithdrawAsset{
+				{TargetAddress: "EXXX", Amount: &amount, CrossChainAmount: &amount},
+			}},
+		}
+		buf := new(bytes.Buffer)
+		withdrawTx.Serialize(buf)
+		txs = append(txs, &base.SideChainTransaction{
+			TransactionHash: withdrawTx.Txid.String(),
+			Transaction:     buf.Bytes(),
+			BlockHeight:     10,
+			RejectReason:    reason,
+		})
+	}
+	if err := datastore[0].AddSideChainTxs(txs); err != nil {
+		t.Error("Add side chain transactions error.", err)
+	}
+	if err := datastore[0].SetSideChainTxRejectReason(txs[1].TransactionHash, "fee too low"); err != nil {
+		t.Error("Set reject reason error.", err)
+	}
+
+	withdrawTxs, err := datastore[0].GetSideChainTxsFromHashes(
+		[]string{txs[0].TransactionHash, txs[1].TransactionHash})
+	if err != nil || len(withdrawTxs) != 2 {
+		t.Fatal("Get side chain transactions error.", err)
+	}
+	reasons := make(map[string]string)
+	for _, tx := range withdrawTxs {
+		reasons[tx.Txid.String()] = tx.RejectReason
+	}
+	if reasons[txs[0].TransactionHash] != "address EXXX is frozen" ||
+		reasons[txs[1].TransactionHash] != "fee too low" {
+		t.Errorf("Reject reasons recorded wrongly, %v", reasons)
+	}
+
+	DBNameSideChain := filepath.Join(DBDocumentNAME,
+		config.Parameters.SideNodeList[0].Name+"_sideChainCache.db")
+	datastore[0].ResetDataStore(DBNameSideChain)
+}
+
+func TestDataStoreImpl_GetReturnDepositTxRecordTime(t *testing.T) {
+	datastore, err := OpenSideChainDataStore()
+	if err != nil {
+		t.Fatal("Open database error.")
+	}
+
+	recordTime, err := datastore[0].GetReturnDepositTxRecordTime("testHash")
+	if err != nil || recordTime != 0 {
+		t.Error("Return deposit transaction should not be recorded.", err)
+	}
+
+	start := time.Now().Unix()
+	if err := datastore[0].AddReturnDepositTx("testHash", "XKUh4GLhFJiqAMTF6HyWQrV9pK9HcGUdfJ",
+		[]byte{1}); err != nil {
+		t.Error("Add return deposit transaction error.", err)
+	}
+	recordTime, err = datastore[0].GetReturnDepositTxRecordTime("testHash")
+	if err != nil || recordTime < start || recordTime > time.Now().Unix() {
+		t.Errorf("Record time %d recorded wrongly, %v", recordTime, err)
+	}
+
+	DBNameSideChain := filepath.Join(DBDocumentNAME,
+		config.Parameters.SideNodeList[0].Name+"_sideChainCache.db")
+	datastore[0].ResetDataStore(DBNameSideChain)
+}
+
 func TestDataStoreImpl_AddMainChainTx(t *testing.T) {
 	datastore, err := OpenMainChainDataStore()
 	if err != nil {
@@ -380,6 +458,16 @@ func TestDataStoreImpl_AddMainChainTx(t *testing.T) {
 		t.Error("Should have specified transaction.")
 	}
 
+	if err := datastore.UpdateMainChainTxProof(&base.MainChainTransaction{TransactionHash: txHash,
+		GenesisBlockAddress: genesisAddress, Proof: &bloom.MerkleProof{Height: 16}}); err != nil {
+		t.Error("Update merkle proof error.", err)
+	}
+	_, proof, err := datastore.GetMainChainTxData(txHash, genesisAddress)
+	updated := new(bloom.MerkleProof)
+	if err != nil || updated.Deserialize(bytes.NewReader(proof)) != nil || updated.Height != 16 {
+		t.Error("Merkle proof should be updated.", err)
+	}
+
 	datastore.ResetDataStore(DBNameMainChain)
 }
 
@@ -0,0 +1,172 @@
+package store
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/elastos/Elastos.ELA.Arbiter/log"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+var DecommissionsDBName = filepath.Join(DBDocumentNAME, "decommissions.db")
+
+const (
+	// DecommissionDraining is the stage of side chains refusing new deposits
+	// and processing the queued withdraws.
+	DecommissionDraining = "draining"
+
+	// DecommissionRetired is the stage of side chains no longer synced or
+	// listened.
+	DecommissionRetired = "retired"
+)
+
+const (
+	//ReturnedDeposits: the count of deposits returned since decommissioned
+	CreateDecommissionsTable = `CREATE TABLE IF NOT EXISTS Decommissions (
+				Id INTEGER NOT NULL PRIMARY KEY,
+				GenesisBlockAddress VARCHAR(34) UNIQUE,
+				Stage VARCHAR(10),
+				Reason TEXT,
+				ReturnedDeposits INTEGER DEFAULT 0,
+				StartTime INTEGER,
+				RetireTime INTEGER DEFAULT 0
+			);`
+)
+
+var (
+	DecommissionDbCache DecommissionDataStore
+)
+
+// Decommission is the progress of retiring a side chain.
+type Decommission struct {
+	GenesisBlockAddress string
+	Stage               string
+	Reason              string
+	ReturnedDeposits    int
+	StartTime           int64
+	RetireTime          int64
+}
+
+type DecommissionDataStore interface {
+	AddDecommission(d *Decommission) error
+	AddReturnedDeposits(genesisBlockAddress string, count int) error
+	SetDecommissionRetired(genesisBlockAddress string) error
+	GetDecommissions() ([]*Decommission, error)
+
+	ResetDataStore(dbName string) error
+}
+
+type DecommissionDataStoreImpl struct {
+	mux *sync.Mutex
+
+	*sql.DB
+}
+
+func OpenDecommissionDataStore() (DecommissionDataStore, error) {
+	db, err := initDecommissionDB()
+	if err != nil {
+		return nil, err
+	}
+	dataStore := &DecommissionDataStoreImpl{DB: db, mux: new(sync.Mutex)}
+
+	// Handle system interrupt signals
+	dataStore.catchSystemSignals()
+
+	return dataStore, nil
+}
+
+func initDecommissionDB() (*sql.DB, error) {
+	err := CheckAndCreateDocument(DBDocumentNAME)
+	if err != nil {
+		log.Error("Create DBCache doucument error:", err)
+		return nil, err
+	}
+	db, err := sql.Open(DriverName, DecommissionsDBName)
+	if err != nil {
+		log.Error("Open data db error:", err)
+		return nil, err
+	}
+	// Create decommissions table
+	_, err = db.Exec(CreateDecommissionsTable)
+	if err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+func (store *DecommissionDataStoreImpl) catchSystemSignals() {
+	HandleSignal(func() {
+		store.mux.Lock()
+		store.Close()
+		os.Exit(-1)
+	})
+}
+
+func (store *DecommissionDataStoreImpl) ResetDataStore(dbName string) error {
+
+	store.DB.Close()
+	os.Remove(dbName)
+
+	var err error
+	store.DB, err = initDecommissionDB()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (store *DecommissionDataStoreImpl) AddDecommission(d *Decommission) error {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	_, err := store.Exec(`INSERT INTO Decommissions(GenesisBlockAddress, Stage, Reason, StartTime) values(?,?,?,?)`,
+		d.GenesisBlockAddress, d.Stage, d.Reason, d.StartTime)
+	return err
+}
+
+func (store *DecommissionDataStoreImpl) AddReturnedDeposits(genesisBlockAddress string, count int) error {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	_, err := store.Exec(`UPDATE Decommissions SET ReturnedDeposits=ReturnedDeposits+? WHERE GenesisBlockAddress=?`,
+		count, genesisBlockAddress)
+	return err
+}
+
+func (store *DecommissionDataStoreImpl) SetDecommissionRetired(genesisBlockAddress string) error {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	_, err := store.Exec(`UPDATE Decommissions SET Stage=?, RetireTime=? WHERE GenesisBlockAddress=?`,
+		DecommissionRetired, time.Now().Unix(), genesisBlockAddress)
+	return err
+}
+
+func (store *DecommissionDataStoreImpl) GetDecommissions() ([]*Decommission, error) {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	rows, err := store.Query(`SELECT GenesisBlockAddress, Stage, Reason, ReturnedDeposits, StartTime, RetireTime
+				FROM Decommissions ORDER BY Id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var decommissions []*Decommission
+	for rows.Next() {
+		d := &Decommission{}
+		err = rows.Scan(&d.GenesisBlockAddress, &d.Stage, &d.Reason, &d.ReturnedDeposits, &d.StartTime,
+			&d.RetireTime)
+		if err != nil {
+			return nil, err
+		}
+		decommissions = append(decommissions, d)
+	}
+	return decommissions, nil
+}
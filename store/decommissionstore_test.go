@@ -0,0 +1,40 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDecommissionDataStoreImpl(t *testing.T) {
+	datastore, err := OpenDecommissionDataStore()
+	if err != nil {
+		t.Fatal("Open database error.")
+	}
+
+	now := time.Now().Unix()
+	if err := datastore.AddDecommission(&Decommission{GenesisBlockAddress: "testAddress",
+		Stage: DecommissionDraining, Reason: "sunset", StartTime: now}); err != nil {
+		t.Error("Add decommission error.", err)
+	}
+	if err := datastore.AddDecommission(&Decommission{GenesisBlockAddress: "testAddress",
+		Stage: DecommissionDraining, StartTime: now}); err == nil {
+		t.Error("Side chain should not be decommissioned twice.")
+	}
+	datastore.AddReturnedDeposits("testAddress", 2)
+	datastore.AddReturnedDeposits("testAddress", 1)
+	if err := datastore.SetDecommissionRetired("testAddress"); err != nil {
+		t.Error("Set decommission retired error.", err)
+	}
+
+	decommissions, err := datastore.GetDecommissions()
+	if err != nil || len(decommissions) != 1 {
+		t.Fatal("Get decommissions error.", err)
+	}
+	d := decommissions[0]
+	if d.Stage != DecommissionRetired || d.Reason != "sunset" || d.ReturnedDeposits != 3 ||
+		d.StartTime != now || d.RetireTime == 0 {
+		t.Errorf("Decommission recorded wrongly, %+v", d)
+	}
+
+	datastore.ResetDataStore(DecommissionsDBName)
+}
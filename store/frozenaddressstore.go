@@ -0,0 +1,241 @@
+package store
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/elastos/Elastos.ELA.Arbiter/log"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+var FrozenAddressesDBName = filepath.Join(DBDocumentNAME, "frozenAddresses.db")
+
+const (
+	// WithdrawBlocked is the status of withdraw transactions held since they
+	// pay to a frozen address.
+	WithdrawBlocked = "blocked"
+
+	// WithdrawReleased is the status of blocked withdraw transactions
+	// released to be proposed as usual.
+	WithdrawReleased = "released"
+
+	// WithdrawReturned is the status of blocked withdraw transactions
+	// returned to the side chain as invalid withdraws.
+	WithdrawReturned = "returned"
+)
+
+const (
+	//Expiry: the unix time the address is no longer frozen, 0 if never
+	CreateFrozenAddressesTable = `CREATE TABLE IF NOT EXISTS FrozenAddresses (
+				Id INTEGER NOT NULL PRIMARY KEY,
+				Address VARCHAR(34) UNIQUE,
+				Reason TEXT,
+				Expiry INTEGER DEFAULT 0,
+				RecordTime INTEGER
+			);`
+	//Address: the frozen address paid by the withdraw transaction
+	//Status: blocked, released or returned
+	CreateBlockedWithdrawsTable = `CREATE TABLE IF NOT EXISTS BlockedWithdraws (
+				Id INTEGER NOT NULL PRIMARY KEY,
+				TransactionHash VARCHAR,
+				GenesisBlockAddress VARCHAR(34),
+				Address VARCHAR(34),
+				Reason TEXT,
+				Status VARCHAR(10),
+				BlockTime INTEGER,
+				UpdateTime INTEGER,
+				UNIQUE (TransactionHash, GenesisBlockAddress)
+			);`
+)
+
+var (
+	FrozenAddressDbCache FrozenAddressDataStore
+)
+
+// FrozenAddress is an address the withdraw transactions should not pay to
+// until Expiry.
+type FrozenAddress struct {
+	Address    string
+	Reason     string
+	Expiry     int64
+	RecordTime int64
+}
+
+// BlockedWithdraw is a withdraw transaction held since it pays to a frozen
+// address.
+type BlockedWithdraw struct {
+	TransactionHash     string
+	GenesisBlockAddress string
+	Address             string
+	Reason              string
+	Status              string
+	BlockTime           int64
+	UpdateTime          int64
+}
+
+type FrozenAddressDataStore interface {
+	AddFrozenAddress(addr *FrozenAddress) error
+	RemoveFrozenAddress(address string) error
+	GetFrozenAddresses() ([]*FrozenAddress, error)
+
+	AddBlockedWithdraw(withdraw *BlockedWithdraw) error
+	UpdateBlockedWithdrawStatus(transactionHash, genesisBlockAddress, status string) error
+	GetBlockedWithdraws() ([]*BlockedWithdraw, error)
+
+	ResetDataStore(dbName string) error
+}
+
+type FrozenAddressDataStoreImpl struct {
+	mux *sync.Mutex
+
+	*sql.DB
+}
+
+func OpenFrozenAddressDataStore() (FrozenAddressDataStore, error) {
+	db, err := initFrozenAddressDB()
+	if err != nil {
+		return nil, err
+	}
+	dataStore := &FrozenAddressDataStoreImpl{DB: db, mux: new(sync.Mutex)}
+
+	// Handle system interrupt signals
+	dataStore.catchSystemSignals()
+
+	return dataStore, nil
+}
+
+func initFrozenAddressDB() (*sql.DB, error) {
+	err := CheckAndCreateDocument(DBDocumentNAME)
+	if err != nil {
+		log.Error("Create DBCache doucument error:", err)
+		return nil, err
+	}
+	db, err := sql.Open(DriverName, FrozenAddressesDBName)
+	if err != nil {
+		log.Error("Open data db error:", err)
+		return nil, err
+	}
+	// Create frozen addresses table
+	_, err = db.Exec(CreateFrozenAddressesTable)
+	if err != nil {
+		return nil, err
+	}
+	// Create blocked withdraws table
+	_, err = db.Exec(CreateBlockedWithdrawsTable)
+	if err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+func (store *FrozenAddressDataStoreImpl) catchSystemSignals() {
+	HandleSignal(func() {
+		store.mux.Lock()
+		store.Close()
+		os.Exit(-1)
+	})
+}
+
+func (store *FrozenAddressDataStoreImpl) ResetDataStore(dbName string) error {
+
+	store.DB.Close()
+	os.Remove(dbName)
+
+	var err error
+	store.DB, err = initFrozenAddressDB()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// AddFrozenAddress freezes the address, the reason and expiry of an address
+// already frozen are replaced.
+func (store *FrozenAddressDataStoreImpl) AddFrozenAddress(addr *FrozenAddress) error {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	_, err := store.Exec(`INSERT OR REPLACE INTO FrozenAddresses(Address, Reason, Expiry, RecordTime) values(?,?,?,?)`,
+		addr.Address, addr.Reason, addr.Expiry, addr.RecordTime)
+	return err
+}
+
+func (store *FrozenAddressDataStoreImpl) RemoveFrozenAddress(address string) error {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	_, err := store.Exec("DELETE FROM FrozenAddresses WHERE Address=?", address)
+	return err
+}
+
+func (store *FrozenAddressDataStoreImpl) GetFrozenAddresses() ([]*FrozenAddress, error) {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	rows, err := store.Query(`SELECT Address, Reason, Expiry, RecordTime FROM FrozenAddresses ORDER BY Id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var addrs []*FrozenAddress
+	for rows.Next() {
+		addr := &FrozenAddress{}
+		if err := rows.Scan(&addr.Address, &addr.Reason, &addr.Expiry, &addr.RecordTime); err != nil {
+			return nil, err
+		}
+		addrs = append(addrs, addr)
+	}
+	return addrs, nil
+}
+
+// AddBlockedWithdraw records the withdraw transaction blocked, the withdraw
+// already recorded is kept unchanged.
+func (store *FrozenAddressDataStoreImpl) AddBlockedWithdraw(withdraw *BlockedWithdraw) error {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	_, err := store.Exec(`INSERT OR IGNORE INTO BlockedWithdraws(TransactionHash, GenesisBlockAddress, Address, Reason,
+				Status, BlockTime, UpdateTime) values(?,?,?,?,?,?,?)`,
+		withdraw.TransactionHash, withdraw.GenesisBlockAddress, withdraw.Address, withdraw.Reason,
+		WithdrawBlocked, withdraw.BlockTime, withdraw.BlockTime)
+	return err
+}
+
+func (store *FrozenAddressDataStoreImpl) UpdateBlockedWithdrawStatus(transactionHash, genesisBlockAddress, status string) error {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	_, err := store.Exec(`UPDATE BlockedWithdraws SET Status=?, UpdateTime=? WHERE TransactionHash=? AND GenesisBlockAddress=?`,
+		status, time.Now().Unix(), transactionHash, genesisBlockAddress)
+	return err
+}
+
+func (store *FrozenAddressDataStoreImpl) GetBlockedWithdraws() ([]*BlockedWithdraw, error) {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	rows, err := store.Query(`SELECT TransactionHash, GenesisBlockAddress, Address, Reason, Status, BlockTime, UpdateTime
+				FROM BlockedWithdraws ORDER BY Id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var withdraws []*BlockedWithdraw
+	for rows.Next() {
+		w := &BlockedWithdraw{}
+		err = rows.Scan(&w.TransactionHash, &w.GenesisBlockAddress, &w.Address, &w.Reason, &w.Status,
+			&w.BlockTime, &w.UpdateTime)
+		if err != nil {
+			return nil, err
+		}
+		withdraws = append(withdraws, w)
+	}
+	return withdraws, nil
+}
@@ -0,0 +1,57 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFrozenAddressDataStoreImpl(t *testing.T) {
+	datastore, err := OpenFrozenAddressDataStore()
+	if err != nil {
+		t.Fatal("Open database error.")
+	}
+
+	now := time.Now().Unix()
+	datastore.AddFrozenAddress(&FrozenAddress{Address: "testAddress1", Reason: "stolen", RecordTime: now})
+	datastore.AddFrozenAddress(&FrozenAddress{Address: "testAddress2", Reason: "sanctioned", RecordTime: now})
+	if err := datastore.AddFrozenAddress(&FrozenAddress{Address: "testAddress1", Reason: "phishing",
+		Expiry: now + 3600, RecordTime: now}); err != nil {
+		t.Error("Freeze address again error.", err)
+	}
+	if err := datastore.RemoveFrozenAddress("testAddress2"); err != nil {
+		t.Error("Remove frozen address error.", err)
+	}
+	addrs, err := datastore.GetFrozenAddresses()
+	if err != nil || len(addrs) != 1 {
+		t.Fatal("Get frozen addresses error.", err)
+	}
+	if addrs[0].Address != "testAddress1" || addrs[0].Reason != "phishing" || addrs[0].Expiry != now+3600 {
+		t.Errorf("Frozen address recorded wrongly, %+v", addrs[0])
+	}
+
+	w := &BlockedWithdraw{
+		TransactionHash:     "testHash",
+		GenesisBlockAddress: "testGenesis",
+		Address:             "testAddress1",
+		Reason:              "phishing",
+		BlockTime:           now,
+	}
+	if err := datastore.AddBlockedWithdraw(w); err != nil {
+		t.Error("Add blocked withdraw error.", err)
+	}
+	if err := datastore.UpdateBlockedWithdrawStatus("testHash", "testGenesis", WithdrawReleased); err != nil {
+		t.Error("Update blocked withdraw status error.", err)
+	}
+	w.Reason = "again"
+	datastore.AddBlockedWithdraw(w)
+	withdraws, err := datastore.GetBlockedWithdraws()
+	if err != nil || len(withdraws) != 1 {
+		t.Fatal("Get blocked withdraws error.", err)
+	}
+	if withdraws[0].Status != WithdrawReleased || withdraws[0].Reason != "phishing" ||
+		withdraws[0].Address != "testAddress1" {
+		t.Errorf("Blocked withdraw recorded wrongly, %+v", withdraws[0])
+	}
+
+	datastore.ResetDataStore(FrozenAddressesDBName)
+}
@@ -0,0 +1,220 @@
+package store
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/elastos/Elastos.ELA.Arbiter/log"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+var PausesDBName = filepath.Join(DBDocumentNAME, "pauses.db")
+
+const (
+	// PausedByOperator is the source of pauses set by the operator.
+	PausedByOperator = "operator"
+
+	// BreakerWithdrawVolume is the circuit breaker tripped when the withdraws
+	// found in the window exceed the limit of the side chain.
+	BreakerWithdrawVolume = "withdrawvolume"
+
+	// BreakerIllegalEvidence is the circuit breaker tripped when illegal
+	// evidence is found on the side chain.
+	BreakerIllegalEvidence = "illegalevidence"
+)
+
+const (
+	//Kind: deposit or withdraw
+	//PausedBy: operator or the circuit breaker tripped
+	CreateSideChainPausesTable = `CREATE TABLE IF NOT EXISTS SideChainPauses (
+				Id INTEGER NOT NULL PRIMARY KEY,
+				GenesisBlockAddress VARCHAR(34),
+				Kind VARCHAR(10),
+				PausedBy VARCHAR(20),
+				Reason TEXT,
+				PauseTime INTEGER,
+				UNIQUE (GenesisBlockAddress, Kind)
+			);`
+	CreateBreakerTripsTable = `CREATE TABLE IF NOT EXISTS BreakerTrips (
+				Id INTEGER NOT NULL PRIMARY KEY,
+				GenesisBlockAddress VARCHAR(34),
+				Breaker VARCHAR(20),
+				Detail TEXT,
+				TripTime INTEGER
+			);`
+)
+
+var (
+	PauseDbCache PauseDataStore
+)
+
+// SideChainPause is the deposits or withdraws of a side chain paused.
+type SideChainPause struct {
+	GenesisBlockAddress string
+	Kind                string
+	PausedBy            string
+	Reason              string
+	PauseTime           int64
+}
+
+// BreakerTrip is a trip of the circuit breaker of a side chain.
+type BreakerTrip struct {
+	GenesisBlockAddress string
+	Breaker             string
+	Detail              string
+	TripTime            int64
+}
+
+type PauseDataStore interface {
+	AddPause(pause *SideChainPause) error
+	RemovePause(genesisBlockAddress, kind string) error
+	GetPauses() ([]*SideChainPause, error)
+
+	AddBreakerTrip(trip *BreakerTrip) error
+	GetBreakerTrips(genesisBlockAddress string) ([]*BreakerTrip, error)
+
+	ResetDataStore(dbName string) error
+}
+
+type PauseDataStoreImpl struct {
+	mux *sync.Mutex
+
+	*sql.DB
+}
+
+func OpenPauseDataStore() (PauseDataStore, error) {
+	db, err := initPauseDB()
+	if err != nil {
+		return nil, err
+	}
+	dataStore := &PauseDataStoreImpl{DB: db, mux: new(sync.Mutex)}
+
+	// Handle system interrupt signals
+	dataStore.catchSystemSignals()
+
+	return dataStore, nil
+}
+
+func initPauseDB() (*sql.DB, error) {
+	err := CheckAndCreateDocument(DBDocumentNAME)
+	if err != nil {
+		log.Error("Create DBCache doucument error:", err)
+		return nil, err
+	}
+	db, err := sql.Open(DriverName, PausesDBName)
+	if err != nil {
+		log.Error("Open data db error:", err)
+		return nil, err
+	}
+	// Create side chain pauses table
+	_, err = db.Exec(CreateSideChainPausesTable)
+	if err != nil {
+		return nil, err
+	}
+	// Create breaker trips table
+	_, err = db.Exec(CreateBreakerTripsTable)
+	if err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+func (store *PauseDataStoreImpl) catchSystemSignals() {
+	HandleSignal(func() {
+		store.mux.Lock()
+		store.Close()
+		os.Exit(-1)
+	})
+}
+
+func (store *PauseDataStoreImpl) ResetDataStore(dbName string) error {
+
+	store.DB.Close()
+	os.Remove(dbName)
+
+	var err error
+	store.DB, err = initPauseDB()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// AddPause pauses the deposits or withdraws of the side chain, the pause
+// already recorded is kept unchanged.
+func (store *PauseDataStoreImpl) AddPause(pause *SideChainPause) error {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	_, err := store.Exec(`INSERT OR IGNORE INTO SideChainPauses(GenesisBlockAddress, Kind, PausedBy, Reason, PauseTime)
+				values(?,?,?,?,?)`,
+		pause.GenesisBlockAddress, pause.Kind, pause.PausedBy, pause.Reason, pause.PauseTime)
+	return err
+}
+
+func (store *PauseDataStoreImpl) RemovePause(genesisBlockAddress, kind string) error {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	_, err := store.Exec("DELETE FROM SideChainPauses WHERE GenesisBlockAddress=? AND Kind=?",
+		genesisBlockAddress, kind)
+	return err
+}
+
+func (store *PauseDataStoreImpl) GetPauses() ([]*SideChainPause, error) {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	rows, err := store.Query(`SELECT GenesisBlockAddress, Kind, PausedBy, Reason, PauseTime FROM SideChainPauses ORDER BY Id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pauses []*SideChainPause
+	for rows.Next() {
+		p := &SideChainPause{}
+		if err := rows.Scan(&p.GenesisBlockAddress, &p.Kind, &p.PausedBy, &p.Reason, &p.PauseTime); err != nil {
+			return nil, err
+		}
+		pauses = append(pauses, p)
+	}
+	return pauses, nil
+}
+
+func (store *PauseDataStoreImpl) AddBreakerTrip(trip *BreakerTrip) error {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	_, err := store.Exec(`INSERT INTO BreakerTrips(GenesisBlockAddress, Breaker, Detail, TripTime) values(?,?,?,?)`,
+		trip.GenesisBlockAddress, trip.Breaker, trip.Detail, trip.TripTime)
+	return err
+}
+
+// GetBreakerTrips returns the trips of the side chain, or the trips of all
+// side chains if genesisBlockAddress is empty.
+func (store *PauseDataStoreImpl) GetBreakerTrips(genesisBlockAddress string) ([]*BreakerTrip, error) {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	rows, err := store.Query(`SELECT GenesisBlockAddress, Breaker, Detail, TripTime FROM BreakerTrips
+				WHERE ?='' OR GenesisBlockAddress=? ORDER BY Id`, genesisBlockAddress, genesisBlockAddress)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var trips []*BreakerTrip
+	for rows.Next() {
+		t := &BreakerTrip{}
+		if err := rows.Scan(&t.GenesisBlockAddress, &t.Breaker, &t.Detail, &t.TripTime); err != nil {
+			return nil, err
+		}
+		trips = append(trips, t)
+	}
+	return trips, nil
+}
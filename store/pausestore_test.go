@@ -0,0 +1,50 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPauseDataStoreImpl(t *testing.T) {
+	datastore, err := OpenPauseDataStore()
+	if err != nil {
+		t.Fatal("Open database error.")
+	}
+
+	now := time.Now().Unix()
+	datastore.AddPause(&SideChainPause{GenesisBlockAddress: "testAddress", Kind: PendingWithdraw,
+		PausedBy: BreakerWithdrawVolume, Reason: "volume", PauseTime: now})
+	// the pause recorded is kept
+	datastore.AddPause(&SideChainPause{GenesisBlockAddress: "testAddress", Kind: PendingWithdraw,
+		PausedBy: PausedByOperator, Reason: "manual", PauseTime: now})
+	datastore.AddPause(&SideChainPause{GenesisBlockAddress: "testAddress", Kind: PendingDeposit,
+		PausedBy: PausedByOperator, Reason: "manual", PauseTime: now})
+
+	pauses, err := datastore.GetPauses()
+	if err != nil || len(pauses) != 2 {
+		t.Fatal("Get pauses error.", err)
+	}
+	if pauses[0].Kind != PendingWithdraw || pauses[0].PausedBy != BreakerWithdrawVolume || pauses[0].Reason != "volume" {
+		t.Errorf("Pause recorded wrongly, %+v", pauses[0])
+	}
+
+	datastore.RemovePause("testAddress", PendingWithdraw)
+	pauses, _ = datastore.GetPauses()
+	if len(pauses) != 1 || pauses[0].Kind != PendingDeposit {
+		t.Error("Remove pause error.")
+	}
+
+	datastore.AddBreakerTrip(&BreakerTrip{GenesisBlockAddress: "testAddress", Breaker: BreakerWithdrawVolume,
+		Detail: "volume", TripTime: now})
+	datastore.AddBreakerTrip(&BreakerTrip{GenesisBlockAddress: "otherAddress", Breaker: BreakerIllegalEvidence,
+		Detail: "evidence", TripTime: now})
+	if trips, err := datastore.GetBreakerTrips(""); err != nil || len(trips) != 2 {
+		t.Error("Get all breaker trips error.", err)
+	}
+	trips, err := datastore.GetBreakerTrips("otherAddress")
+	if err != nil || len(trips) != 1 || trips[0].Breaker != BreakerIllegalEvidence || trips[0].TripTime != now {
+		t.Error("Get breaker trips of side chain error.", err)
+	}
+
+	datastore.ResetDataStore(PausesDBName)
+}
@@ -0,0 +1,629 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/elastos/Elastos.ELA.Arbiter/log"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+var PendingTxsDBName = filepath.Join(DBDocumentNAME, "pendingTxs.db")
+
+const (
+	// PendingDeposit is the kind of deposit transactions in MainChainTxs.
+	PendingDeposit = "deposit"
+
+	// PendingWithdraw is the kind of withdraw transactions in SideChainTxs.
+	PendingWithdraw = "withdraw"
+)
+
+const (
+	// DepositVerified is the status of deposits whose proof is verified and
+	// confirmed by enough blocks.
+	DepositVerified = "verified"
+
+	// DepositUnconfirmed is the status of deposits whose proof is verified
+	// but confirmed by less blocks than the confirmation depth, or whose
+	// block is not in the best chain any more.
+	DepositUnconfirmed = "unconfirmed"
+
+	// DepositInvalid is the status of deposits whose proof does not match the
+	// transaction, they are quarantined.
+	DepositInvalid = "invalid"
+)
+
+const (
+	//RetryCount: the times of sending again after the first attempt
+	//NextRetry: the unix time before which the transaction is not sent again
+	//LastError: the error of the latest failed attempt
+	CreatePendingTxsInfoTable = `CREATE TABLE IF NOT EXISTS PendingTxsInfo (
+				Id INTEGER NOT NULL PRIMARY KEY,
+				Kind VARCHAR(10),
+				TransactionHash VARCHAR,
+				GenesisBlockAddress VARCHAR(34),
+				FirstSeen INTEGER,
+				RetryCount INTEGER,
+				LastRetry INTEGER,
+				NextRetry INTEGER DEFAULT 0,
+				LastError TEXT DEFAULT '',
+				UNIQUE (Kind, TransactionHash, GenesisBlockAddress)
+			);`
+	//MerkleProof: only for deposit transactions
+	//BlockHeight: only for withdraw transactions
+	CreateQuarantinedTxsTable = `CREATE TABLE IF NOT EXISTS QuarantinedTxs (
+				Id INTEGER NOT NULL PRIMARY KEY,
+				Kind VARCHAR(10),
+				TransactionHash VARCHAR,
+				GenesisBlockAddress VARCHAR(34),
+				TransactionData BLOB,
+				MerkleProof BLOB,
+				BlockHeight INTEGER,
+				Reason TEXT,
+				RecordTime INTEGER,
+				UNIQUE (Kind, TransactionHash, GenesisBlockAddress)
+			);`
+	//Status: verified, unconfirmed or invalid
+	CreateDepositVerificationsTable = `CREATE TABLE IF NOT EXISTS DepositVerifications (
+				Id INTEGER NOT NULL PRIMARY KEY,
+				TransactionHash VARCHAR,
+				GenesisBlockAddress VARCHAR(34),
+				BlockHeight INTEGER,
+				Confirmations INTEGER,
+				Status VARCHAR(12),
+				Error TEXT,
+				VerifyTime INTEGER,
+				UNIQUE (TransactionHash, GenesisBlockAddress)
+			);`
+	CreateAuditTrailTable = `CREATE TABLE IF NOT EXISTS AuditTrail (
+				Id INTEGER NOT NULL PRIMARY KEY,
+				Action VARCHAR(20),
+				Kind VARCHAR(10),
+				TransactionHash VARCHAR,
+				GenesisBlockAddress VARCHAR(34),
+				Detail TEXT,
+				RecordTime INTEGER
+			);`
+	CreatePendingTopUpsTable = `CREATE TABLE IF NOT EXISTS PendingTopUps (
+				Id INTEGER NOT NULL PRIMARY KEY,
+				Address VARCHAR(34),
+				TransactionHash VARCHAR,
+				Amount INTEGER,
+				RecordTime INTEGER,
+				UNIQUE (Address, TransactionHash)
+			);`
+)
+
+var (
+	PendingTxsDbCache PendingTransactionsDataStore
+)
+
+type PendingTxInfo struct {
+	Kind                string
+	TransactionHash     string
+	GenesisBlockAddress string
+	FirstSeen           int64
+	RetryCount          int
+	LastRetry           int64
+	NextRetry           int64
+	LastError           string
+}
+
+type QuarantinedTx struct {
+	Kind                string
+	TransactionHash     string
+	GenesisBlockAddress string
+	TransactionData     []byte
+	MerkleProof         []byte
+	BlockHeight         uint32
+	Reason              string
+	RecordTime          int64
+}
+
+// DepositVerification is the result of verifying the merkle proof of a
+// deposit transaction against the spv headers before recharging it.
+type DepositVerification struct {
+	TransactionHash     string
+	GenesisBlockAddress string
+	BlockHeight         uint32
+	Confirmations       uint32
+	Status              string
+	Error               string
+	VerifyTime          int64
+}
+
+type AuditRecord struct {
+	Action              string
+	Kind                string
+	TransactionHash     string
+	GenesisBlockAddress string
+	Detail              string
+	RecordTime          int64
+}
+
+// PendingTopUp is a refill sent to a side chain mining account and not
+// confirmed yet.
+type PendingTopUp struct {
+	Address         string
+	TransactionHash string
+	Amount          int64
+	RecordTime      int64
+}
+
+type PendingTransactionsDataStore interface {
+	AddPendingTxsAttempt(kind string, transactionHashes []string, genesisBlockAddress string) error
+	GetPendingTxsInfo(kind string) ([]*PendingTxInfo, error)
+	UpdatePendingTxRetry(kind, transactionHash, genesisBlockAddress string, nextRetry int64, lastError string) error
+	RemovePendingTxsInfo(kind string, transactionHashes, genesisBlockAddresses []string) error
+
+	AddQuarantinedTx(tx *QuarantinedTx) error
+	HasQuarantinedTx(kind, transactionHash, genesisBlockAddress string) (bool, error)
+	GetQuarantinedTx(kind, transactionHash, genesisBlockAddress string) (*QuarantinedTx, error)
+	GetQuarantinedTxs() ([]*QuarantinedTx, error)
+	RemoveQuarantinedTx(kind, transactionHash, genesisBlockAddress string) error
+
+	SetDepositVerification(verification *DepositVerification) error
+	GetDepositVerifications() ([]*DepositVerification, error)
+	RemoveDepositVerifications(transactionHashes, genesisBlockAddresses []string) error
+
+	AddAuditRecord(record *AuditRecord) error
+	GetAuditRecords(limit int) ([]*AuditRecord, error)
+
+	AddPendingTopUp(topUp *PendingTopUp) error
+	GetPendingTopUps() ([]*PendingTopUp, error)
+	RemovePendingTopUp(address, transactionHash string) error
+
+	ResetDataStore(dbName string) error
+}
+
+type PendingTxsDataStoreImpl struct {
+	mux *sync.Mutex
+
+	*sql.DB
+}
+
+func OpenPendingTxsDataStore() (PendingTransactionsDataStore, error) {
+	db, err := initPendingTxsDB()
+	if err != nil {
+		return nil, err
+	}
+	dataStore := &PendingTxsDataStoreImpl{DB: db, mux: new(sync.Mutex)}
+
+	// Handle system interrupt signals
+	dataStore.catchSystemSignals()
+
+	return dataStore, nil
+}
+
+func initPendingTxsDB() (*sql.DB, error) {
+	err := CheckAndCreateDocument(DBDocumentNAME)
+	if err != nil {
+		log.Error("Create DBCache doucument error:", err)
+		return nil, err
+	}
+	db, err := sql.Open(DriverName, PendingTxsDBName)
+	if err != nil {
+		log.Error("Open data db error:", err)
+		return nil, err
+	}
+	// Create pending transactions info table
+	_, err = db.Exec(CreatePendingTxsInfoTable)
+	if err != nil {
+		return nil, err
+	}
+	// Create quarantined transactions table
+	_, err = db.Exec(CreateQuarantinedTxsTable)
+	if err != nil {
+		return nil, err
+	}
+	// Create deposit verifications table
+	_, err = db.Exec(CreateDepositVerificationsTable)
+	if err != nil {
+		return nil, err
+	}
+	// Create audit trail table
+	_, err = db.Exec(CreateAuditTrailTable)
+	if err != nil {
+		return nil, err
+	}
+	// Create pending top-ups table
+	_, err = db.Exec(CreatePendingTopUpsTable)
+	if err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// addColumnIfNotExists adds the column to the table if the table has no
+// column of the name, column is the name followed by its definition.
+func addColumnIfNotExists(db *sql.DB, table, column string) error {
+	rows, err := db.Query("PRAGMA table_info(" + table + ")")
+	if err != nil {
+		return err
+	}
+	name := strings.Fields(column)[0]
+	for rows.Next() {
+		var cid, notNull, pk int
+		var columnName, columnType string
+		var defaultValue interface{}
+		if err := rows.Scan(&cid, &columnName, &columnType, &notNull, &defaultValue, &pk); err != nil {
+			rows.Close()
+			return err
+		}
+		if strings.EqualFold(columnName, name) {
+			rows.Close()
+			return nil
+		}
+	}
+	rows.Close()
+
+	_, err = db.Exec("ALTER TABLE " + table + " ADD COLUMN " + column)
+	return err
+}
+
+func (store *PendingTxsDataStoreImpl) catchSystemSignals() {
+	HandleSignal(func() {
+		store.mux.Lock()
+		store.Close()
+		os.Exit(-1)
+	})
+}
+
+func (store *PendingTxsDataStoreImpl) AddPendingTopUp(topUp *PendingTopUp) error {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	stmt, err := store.Prepare(`INSERT OR REPLACE INTO PendingTopUps(Address, TransactionHash, Amount, RecordTime) values(?,?,?,?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(topUp.Address, topUp.TransactionHash, topUp.Amount, topUp.RecordTime)
+	return err
+}
+
+func (store *PendingTxsDataStoreImpl) GetPendingTopUps() ([]*PendingTopUp, error) {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	rows, err := store.Query(`SELECT Address, TransactionHash, Amount, RecordTime FROM PendingTopUps ORDER BY Id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var topUps []*PendingTopUp
+	for rows.Next() {
+		topUp := &PendingTopUp{}
+		err = rows.Scan(&topUp.Address, &topUp.TransactionHash, &topUp.Amount, &topUp.RecordTime)
+		if err != nil {
+			return nil, err
+		}
+		topUps = append(topUps, topUp)
+	}
+	return topUps, nil
+}
+
+func (store *PendingTxsDataStoreImpl) RemovePendingTopUp(address, transactionHash string) error {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	_, err := store.Exec(`DELETE FROM PendingTopUps WHERE Address=? AND TransactionHash=?`, address, transactionHash)
+	return err
+}
+
+func (store *PendingTxsDataStoreImpl) ResetDataStore(dbName string) error {
+
+	store.DB.Close()
+	os.Remove(dbName)
+
+	var err error
+	store.DB, err = initPendingTxsDB()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// AddPendingTxsAttempt records an attempt of sending the transactions, the
+// first attempt records FirstSeen and the others increase RetryCount.
+func (store *PendingTxsDataStoreImpl) AddPendingTxsAttempt(kind string, transactionHashes []string, genesisBlockAddress string) error {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	tx, err := store.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Commit()
+
+	stmt, err := tx.Prepare(`INSERT INTO PendingTxsInfo(Kind, TransactionHash, GenesisBlockAddress, FirstSeen, RetryCount, LastRetry) values(?,?,?,?,0,0)
+				ON CONFLICT(Kind, TransactionHash, GenesisBlockAddress) DO UPDATE SET RetryCount=RetryCount+1, LastRetry=?`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now().Unix()
+	for _, txHash := range transactionHashes {
+		if _, err := stmt.Exec(kind, txHash, genesisBlockAddress, now, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (store *PendingTxsDataStoreImpl) GetPendingTxsInfo(kind string) ([]*PendingTxInfo, error) {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	rows, err := store.Query(`SELECT TransactionHash, GenesisBlockAddress, FirstSeen, RetryCount, LastRetry, NextRetry, LastError FROM PendingTxsInfo
+				WHERE Kind=?`, kind)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var infos []*PendingTxInfo
+	for rows.Next() {
+		info := &PendingTxInfo{Kind: kind}
+		err = rows.Scan(&info.TransactionHash, &info.GenesisBlockAddress, &info.FirstSeen, &info.RetryCount, &info.LastRetry,
+			&info.NextRetry, &info.LastError)
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// UpdatePendingTxRetry records the result of a failed attempt of sending the
+// transaction and the unix time of the next attempt.
+func (store *PendingTxsDataStoreImpl) UpdatePendingTxRetry(kind, transactionHash, genesisBlockAddress string, nextRetry int64, lastError string) error {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	stmt, err := store.Prepare(`UPDATE PendingTxsInfo SET NextRetry=?, LastError=?
+				WHERE Kind=? AND TransactionHash=? AND GenesisBlockAddress=?`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(nextRetry, lastError, kind, transactionHash, genesisBlockAddress)
+	return err
+}
+
+func (store *PendingTxsDataStoreImpl) RemovePendingTxsInfo(kind string, transactionHashes, genesisBlockAddresses []string) error {
+	if len(transactionHashes) != len(genesisBlockAddresses) {
+		return errors.New("invalid parameter, transactionHashes and genesisBlockAddresses should be in pair")
+	}
+
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	tx, err := store.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Commit()
+
+	stmt, err := tx.Prepare("DELETE FROM PendingTxsInfo WHERE Kind=? AND TransactionHash=? AND GenesisBlockAddress=?")
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for i := 0; i < len(transactionHashes); i++ {
+		stmt.Exec(kind, transactionHashes[i], genesisBlockAddresses[i])
+	}
+	return nil
+}
+
+func (store *PendingTxsDataStoreImpl) AddQuarantinedTx(tx *QuarantinedTx) error {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	stmt, err := store.Prepare(`INSERT INTO QuarantinedTxs(Kind, TransactionHash, GenesisBlockAddress, TransactionData, MerkleProof, BlockHeight, Reason, RecordTime)
+				values(?,?,?,?,?,?,?,?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(tx.Kind, tx.TransactionHash, tx.GenesisBlockAddress, tx.TransactionData,
+		tx.MerkleProof, tx.BlockHeight, tx.Reason, time.Now().Unix())
+	return err
+}
+
+func (store *PendingTxsDataStoreImpl) HasQuarantinedTx(kind, transactionHash, genesisBlockAddress string) (bool, error) {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	rows, err := store.Query(`SELECT Id FROM QuarantinedTxs WHERE Kind=? AND TransactionHash=? AND GenesisBlockAddress=?`,
+		kind, transactionHash, genesisBlockAddress)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	return rows.Next(), nil
+}
+
+func (store *PendingTxsDataStoreImpl) GetQuarantinedTx(kind, transactionHash, genesisBlockAddress string) (*QuarantinedTx, error) {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	rows, err := store.Query(`SELECT TransactionData, MerkleProof, BlockHeight, Reason, RecordTime FROM QuarantinedTxs
+				WHERE Kind=? AND TransactionHash=? AND GenesisBlockAddress=?`, kind, transactionHash, genesisBlockAddress)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, errors.New("quarantined transaction not found")
+	}
+	tx := &QuarantinedTx{
+		Kind:                kind,
+		TransactionHash:     transactionHash,
+		GenesisBlockAddress: genesisBlockAddress,
+	}
+	err = rows.Scan(&tx.TransactionData, &tx.MerkleProof, &tx.BlockHeight, &tx.Reason, &tx.RecordTime)
+	if err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// GetQuarantinedTxs returns all quarantined transactions without their data.
+func (store *PendingTxsDataStoreImpl) GetQuarantinedTxs() ([]*QuarantinedTx, error) {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	rows, err := store.Query(`SELECT Kind, TransactionHash, GenesisBlockAddress, BlockHeight, Reason, RecordTime FROM QuarantinedTxs`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var txs []*QuarantinedTx
+	for rows.Next() {
+		tx := &QuarantinedTx{}
+		err = rows.Scan(&tx.Kind, &tx.TransactionHash, &tx.GenesisBlockAddress, &tx.BlockHeight, &tx.Reason, &tx.RecordTime)
+		if err != nil {
+			return nil, err
+		}
+		txs = append(txs, tx)
+	}
+	return txs, nil
+}
+
+func (store *PendingTxsDataStoreImpl) RemoveQuarantinedTx(kind, transactionHash, genesisBlockAddress string) error {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	stmt, err := store.Prepare("DELETE FROM QuarantinedTxs WHERE Kind=? AND TransactionHash=? AND GenesisBlockAddress=?")
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(kind, transactionHash, genesisBlockAddress)
+	return err
+}
+
+// SetDepositVerification records the latest verification result of the
+// deposit transaction.
+func (store *PendingTxsDataStoreImpl) SetDepositVerification(verification *DepositVerification) error {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	stmt, err := store.Prepare(`INSERT OR REPLACE INTO DepositVerifications(TransactionHash, GenesisBlockAddress, BlockHeight, Confirmations, Status, Error, VerifyTime)
+				values(?,?,?,?,?,?,?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(verification.TransactionHash, verification.GenesisBlockAddress, verification.BlockHeight,
+		verification.Confirmations, verification.Status, verification.Error, time.Now().Unix())
+	return err
+}
+
+func (store *PendingTxsDataStoreImpl) GetDepositVerifications() ([]*DepositVerification, error) {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	rows, err := store.Query(`SELECT TransactionHash, GenesisBlockAddress, BlockHeight, Confirmations, Status, Error, VerifyTime FROM DepositVerifications`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var verifications []*DepositVerification
+	for rows.Next() {
+		v := &DepositVerification{}
+		err = rows.Scan(&v.TransactionHash, &v.GenesisBlockAddress, &v.BlockHeight, &v.Confirmations,
+			&v.Status, &v.Error, &v.VerifyTime)
+		if err != nil {
+			return nil, err
+		}
+		verifications = append(verifications, v)
+	}
+	return verifications, nil
+}
+
+func (store *PendingTxsDataStoreImpl) RemoveDepositVerifications(transactionHashes, genesisBlockAddresses []string) error {
+	if len(transactionHashes) != len(genesisBlockAddresses) {
+		return errors.New("invalid parameter, transactionHashes and genesisBlockAddresses should be in pair")
+	}
+
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	tx, err := store.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Commit()
+
+	stmt, err := tx.Prepare("DELETE FROM DepositVerifications WHERE TransactionHash=? AND GenesisBlockAddress=?")
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for i := 0; i < len(transactionHashes); i++ {
+		stmt.Exec(transactionHashes[i], genesisBlockAddresses[i])
+	}
+	return nil
+}
+
+func (store *PendingTxsDataStoreImpl) AddAuditRecord(record *AuditRecord) error {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	stmt, err := store.Prepare(`INSERT INTO AuditTrail(Action, Kind, TransactionHash, GenesisBlockAddress, Detail, RecordTime) values(?,?,?,?,?,?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(record.Action, record.Kind, record.TransactionHash, record.GenesisBlockAddress,
+		record.Detail, time.Now().Unix())
+	return err
+}
+
+// GetAuditRecords returns the latest audit records, newest first.
+func (store *PendingTxsDataStoreImpl) GetAuditRecords(limit int) ([]*AuditRecord, error) {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	rows, err := store.Query(`SELECT Action, Kind, TransactionHash, GenesisBlockAddress, Detail, RecordTime FROM AuditTrail
+				ORDER BY Id DESC LIMIT ?`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []*AuditRecord
+	for rows.Next() {
+		record := &AuditRecord{}
+		err = rows.Scan(&record.Action, &record.Kind, &record.TransactionHash, &record.GenesisBlockAddress,
+			&record.Detail, &record.RecordTime)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
@@ -0,0 +1,204 @@
+package store
+
+import (
+	"testing"
+)
+
+func TestPendingTxsDataStoreImpl_AddPendingTxsAttempt(t *testing.T) {
+	datastore, err := OpenPendingTxsDataStore()
+	if err != nil {
+		t.Fatal("Open database error.")
+	}
+
+	genesisBlockAddress := "testAddress"
+	err = datastore.AddPendingTxsAttempt(PendingDeposit, []string{"testHash1", "testHash2"}, genesisBlockAddress)
+	if err != nil {
+		t.Error("Add pending transactions attempt error.", err)
+	}
+	err = datastore.AddPendingTxsAttempt(PendingDeposit, []string{"testHash1"}, genesisBlockAddress)
+	if err != nil {
+		t.Error("Add pending transactions attempt error.", err)
+	}
+
+	infos, err := datastore.GetPendingTxsInfo(PendingDeposit)
+	if err != nil || len(infos) != 2 {
+		t.Fatal("Get pending transactions info error.")
+	}
+	for _, info := range infos {
+		if info.FirstSeen == 0 {
+			t.Error("First seen time should be recorded.")
+		}
+		if info.TransactionHash == "testHash1" && (info.RetryCount != 1 || info.LastRetry == 0) {
+			t.Error("Retry should be counted.")
+		}
+		if info.TransactionHash == "testHash2" && info.RetryCount != 0 {
+			t.Error("First attempt should not be counted as retry.")
+		}
+	}
+
+	infos, err = datastore.GetPendingTxsInfo(PendingWithdraw)
+	if err != nil || len(infos) != 0 {
+		t.Error("Pending transactions info should be separated by kind.")
+	}
+
+	err = datastore.UpdatePendingTxRetry(PendingDeposit, "testHash2", genesisBlockAddress, 100, "timeout")
+	if err != nil {
+		t.Error("Update pending transaction retry error.", err)
+	}
+	infos, _ = datastore.GetPendingTxsInfo(PendingDeposit)
+	for _, info := range infos {
+		if info.TransactionHash == "testHash2" && (info.NextRetry != 100 || info.LastError != "timeout") {
+			t.Error("Retry state should be recorded.")
+		}
+		if info.TransactionHash == "testHash1" && (info.NextRetry != 0 || info.LastError != "") {
+			t.Error("Retry state of other transactions should not be changed.")
+		}
+	}
+
+	err = datastore.RemovePendingTxsInfo(PendingDeposit, []string{"testHash1"}, []string{genesisBlockAddress})
+	if err != nil {
+		t.Error("Remove pending transactions info error.")
+	}
+	infos, _ = datastore.GetPendingTxsInfo(PendingDeposit)
+	if len(infos) != 1 || infos[0].TransactionHash != "testHash2" {
+		t.Error("Remove pending transactions info error.")
+	}
+
+	datastore.ResetDataStore(PendingTxsDBName)
+}
+
+func TestPendingTxsDataStoreImpl_QuarantinedTx(t *testing.T) {
+	datastore, err := OpenPendingTxsDataStore()
+	if err != nil {
+		t.Fatal("Open database error.")
+	}
+
+	tx := &QuarantinedTx{
+		Kind:                PendingWithdraw,
+		TransactionHash:     "testHash",
+		GenesisBlockAddress: "testAddress",
+		TransactionData:     []byte{1, 2, 3},
+		BlockHeight:         10,
+		Reason:              "test",
+	}
+	if err := datastore.AddQuarantinedTx(tx); err != nil {
+		t.Error("Add quarantined transaction error.")
+	}
+	if err := datastore.AddQuarantinedTx(tx); err == nil {
+		t.Error("Quarantined transaction should be unique.")
+	}
+
+	ok, err := datastore.HasQuarantinedTx(PendingWithdraw, "testHash", "testAddress")
+	if err != nil || !ok {
+		t.Error("Check quarantined transaction error.")
+	}
+	got, err := datastore.GetQuarantinedTx(PendingWithdraw, "testHash", "testAddress")
+	if err != nil || len(got.TransactionData) != 3 || got.BlockHeight != 10 || got.Reason != "test" {
+		t.Error("Get quarantined transaction error.")
+	}
+
+	if err := datastore.RemoveQuarantinedTx(PendingWithdraw, "testHash", "testAddress"); err != nil {
+		t.Error("Remove quarantined transaction error.")
+	}
+	txs, err := datastore.GetQuarantinedTxs()
+	if err != nil || len(txs) != 0 {
+		t.Error("Remove quarantined transaction error.")
+	}
+
+	datastore.AddAuditRecord(&AuditRecord{Action: "quarantine", Kind: PendingWithdraw, TransactionHash: "testHash"})
+	datastore.AddAuditRecord(&AuditRecord{Action: "restore", Kind: PendingWithdraw, TransactionHash: "testHash"})
+	records, err := datastore.GetAuditRecords(1)
+	if err != nil || len(records) != 1 || records[0].Action != "restore" || records[0].RecordTime == 0 {
+		t.Error("Get audit records error.")
+	}
+
+	datastore.ResetDataStore(PendingTxsDBName)
+}
+
+func TestAddColumnIfNotExists(t *testing.T) {
+	datastore, err := OpenPendingTxsDataStore()
+	if err != nil {
+		t.Fatal("Open database error.")
+	}
+	db := datastore.(*PendingTxsDataStoreImpl).DB
+
+	if _, err = db.Exec(`CREATE TABLE OldTxs (Id INTEGER NOT NULL PRIMARY KEY, TransactionHash VARCHAR)`); err != nil {
+		t.Fatal(err)
+	}
+	db.Exec("INSERT INTO OldTxs(TransactionHash) values('testHash')")
+	for i := 0; i < 2; i++ {
+		if err := addColumnIfNotExists(db, "OldTxs", "LastError TEXT DEFAULT ''"); err != nil {
+			t.Error("Add column error.", err)
+		}
+	}
+	var lastError string
+	if err := db.QueryRow("SELECT LastError FROM OldTxs").Scan(&lastError); err != nil || lastError != "" {
+		t.Error("Column should be added to the old table.", err)
+	}
+
+	datastore.ResetDataStore(PendingTxsDBName)
+}
+
+func TestPendingTxsDataStoreImpl_DepositVerification(t *testing.T) {
+	datastore, err := OpenPendingTxsDataStore()
+	if err != nil {
+		t.Fatal("Open database error.")
+	}
+
+	v := &DepositVerification{
+		TransactionHash:     "testHash",
+		GenesisBlockAddress: "testAddress",
+		BlockHeight:         10,
+		Confirmations:       2,
+		Status:              DepositUnconfirmed,
+	}
+	if err := datastore.SetDepositVerification(v); err != nil {
+		t.Error("Set deposit verification error.", err)
+	}
+	v.Confirmations, v.Status = 6, DepositVerified
+	if err := datastore.SetDepositVerification(v); err != nil {
+		t.Error("Set deposit verification again error.", err)
+	}
+	verifications, err := datastore.GetDepositVerifications()
+	if err != nil || len(verifications) != 1 || verifications[0].Status != DepositVerified ||
+		verifications[0].Confirmations != 6 || verifications[0].VerifyTime == 0 {
+		t.Error("Latest deposit verification should be kept.")
+	}
+
+	err = datastore.RemoveDepositVerifications([]string{"testHash"}, []string{"testAddress"})
+	if err != nil {
+		t.Error("Remove deposit verifications error.")
+	}
+	verifications, _ = datastore.GetDepositVerifications()
+	if len(verifications) != 0 {
+		t.Error("Deposit verification should be removed.")
+	}
+
+	datastore.ResetDataStore(PendingTxsDBName)
+}
+
+func TestPendingTxsDataStoreImpl_PendingTopUp(t *testing.T) {
+	datastore, err := OpenPendingTxsDataStore()
+	if err != nil {
+		t.Fatal("Open database error.")
+	}
+
+	topUp := &PendingTopUp{Address: "testAddress", TransactionHash: "testHash", Amount: 100, RecordTime: 10}
+	if err := datastore.AddPendingTopUp(topUp); err != nil {
+		t.Error("Add pending top-up error.", err)
+	}
+	topUps, err := datastore.GetPendingTopUps()
+	if err != nil || len(topUps) != 1 || *topUps[0] != *topUp {
+		t.Error("Pending top-up should be recorded.")
+	}
+
+	if err := datastore.RemovePendingTopUp("testAddress", "testHash"); err != nil {
+		t.Error("Remove pending top-up error.")
+	}
+	topUps, _ = datastore.GetPendingTopUps()
+	if len(topUps) != 0 {
+		t.Error("Pending top-up should be removed.")
+	}
+
+	datastore.ResetDataStore(PendingTxsDBName)
+}
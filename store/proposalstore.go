@@ -0,0 +1,307 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/elastos/Elastos.ELA.Arbiter/log"
+
+	"github.com/elastos/Elastos.ELA/common"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+var ProposalsDBName = filepath.Join(DBDocumentNAME, "proposals.db")
+
+const (
+	// ProposalRejected is the status of proposals refused by the limits.
+	ProposalRejected = "rejected"
+
+	// ProposalNeedApproval is the status of proposals waiting for the
+	// operator to approve.
+	ProposalNeedApproval = "needapproval"
+)
+
+const (
+	//Reference: the side chain transaction or deposit transaction paid by the output
+	CreateWithdrawUsagesTable = `CREATE TABLE IF NOT EXISTS WithdrawUsages (
+				Id INTEGER NOT NULL PRIMARY KEY,
+				GenesisBlockAddress VARCHAR(34),
+				Reference VARCHAR,
+				Address VARCHAR(34),
+				Amount INTEGER,
+				RecordTime INTEGER,
+				UNIQUE (GenesisBlockAddress, Reference, Address)
+			);`
+	//Approved: the proposal is approved by the operator
+	CreateRejectedProposalsTable = `CREATE TABLE IF NOT EXISTS RejectedProposals (
+				Id INTEGER NOT NULL PRIMARY KEY,
+				TransactionHash VARCHAR UNIQUE,
+				GenesisBlockAddress VARCHAR(34),
+				Amount INTEGER,
+				Status VARCHAR(20),
+				Reason TEXT,
+				Approved INTEGER DEFAULT 0,
+				RecordTime INTEGER
+			);`
+)
+
+var (
+	ProposalDbCache ProposalDataStore
+)
+
+// WithdrawUsage is an amount signed to be paid to Address, it is counted in
+// the daily withdraw limits.
+type WithdrawUsage struct {
+	GenesisBlockAddress string
+	Reference           string
+	Address             string
+	Amount              common.Fixed64
+	RecordTime          int64
+}
+
+type RejectedProposal struct {
+	TransactionHash     string
+	GenesisBlockAddress string
+	Amount              common.Fixed64
+	Status              string
+	Reason              string
+	Approved            bool
+	RecordTime          int64
+}
+
+type ProposalDataStore interface {
+	AddWithdrawUsages(usages []*WithdrawUsage) error
+	GetWithdrawUsages(since int64) ([]*WithdrawUsage, error)
+	RemoveWithdrawUsages(before int64) error
+
+	AddRejectedProposal(proposal *RejectedProposal) error
+	GetRejectedProposals(limit int) ([]*RejectedProposal, error)
+	ApproveProposal(transactionHash string) (*RejectedProposal, error)
+	IsProposalApproved(transactionHash string) (bool, error)
+
+	ResetDataStore(dbName string) error
+}
+
+type ProposalDataStoreImpl struct {
+	mux *sync.Mutex
+
+	*sql.DB
+}
+
+func OpenProposalDataStore() (ProposalDataStore, error) {
+	db, err := initProposalDB()
+	if err != nil {
+		return nil, err
+	}
+	dataStore := &ProposalDataStoreImpl{DB: db, mux: new(sync.Mutex)}
+
+	// Handle system interrupt signals
+	dataStore.catchSystemSignals()
+
+	return dataStore, nil
+}
+
+func initProposalDB() (*sql.DB, error) {
+	err := CheckAndCreateDocument(DBDocumentNAME)
+	if err != nil {
+		log.Error("Create DBCache doucument error:", err)
+		return nil, err
+	}
+	db, err := sql.Open(DriverName, ProposalsDBName)
+	if err != nil {
+		log.Error("Open data db error:", err)
+		return nil, err
+	}
+	// Create withdraw usages table
+	_, err = db.Exec(CreateWithdrawUsagesTable)
+	if err != nil {
+		return nil, err
+	}
+	// Create rejected proposals table
+	_, err = db.Exec(CreateRejectedProposalsTable)
+	if err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+func (store *ProposalDataStoreImpl) catchSystemSignals() {
+	HandleSignal(func() {
+		store.mux.Lock()
+		store.Close()
+		os.Exit(-1)
+	})
+}
+
+func (store *ProposalDataStoreImpl) ResetDataStore(dbName string) error {
+
+	store.DB.Close()
+	os.Remove(dbName)
+
+	var err error
+	store.DB, err = initProposalDB()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// AddWithdrawUsages records the signed withdraws, the withdraws already
+// recorded are ignored so signing a proposal again is not counted twice.
+func (store *ProposalDataStoreImpl) AddWithdrawUsages(usages []*WithdrawUsage) error {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	tx, err := store.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Commit()
+
+	stmt, err := tx.Prepare(`INSERT OR IGNORE INTO WithdrawUsages(GenesisBlockAddress, Reference, Address, Amount, RecordTime) values(?,?,?,?,?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now().Unix()
+	for _, u := range usages {
+		if _, err := stmt.Exec(u.GenesisBlockAddress, u.Reference, u.Address, int64(u.Amount), now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetWithdrawUsages returns the withdraws signed since the unix time.
+func (store *ProposalDataStoreImpl) GetWithdrawUsages(since int64) ([]*WithdrawUsage, error) {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	rows, err := store.Query(`SELECT GenesisBlockAddress, Reference, Address, Amount, RecordTime FROM WithdrawUsages WHERE RecordTime>=?`, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var usages []*WithdrawUsage
+	for rows.Next() {
+		u := &WithdrawUsage{}
+		var amount int64
+		err = rows.Scan(&u.GenesisBlockAddress, &u.Reference, &u.Address, &amount, &u.RecordTime)
+		if err != nil {
+			return nil, err
+		}
+		u.Amount = common.Fixed64(amount)
+		usages = append(usages, u)
+	}
+	return usages, nil
+}
+
+// RemoveWithdrawUsages removes the withdraws signed before the unix time.
+func (store *ProposalDataStoreImpl) RemoveWithdrawUsages(before int64) error {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	_, err := store.Exec("DELETE FROM WithdrawUsages WHERE RecordTime<?", before)
+	return err
+}
+
+// AddRejectedProposal records the rejected proposal, the status and reason of
+// a proposal rejected again are updated while the approval is kept.
+func (store *ProposalDataStoreImpl) AddRejectedProposal(proposal *RejectedProposal) error {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	stmt, err := store.Prepare(`INSERT INTO RejectedProposals(TransactionHash, GenesisBlockAddress, Amount, Status, Reason, RecordTime) values(?,?,?,?,?,?)
+				ON CONFLICT(TransactionHash) DO UPDATE SET Status=excluded.Status, Reason=excluded.Reason, RecordTime=excluded.RecordTime`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(proposal.TransactionHash, proposal.GenesisBlockAddress, int64(proposal.Amount),
+		proposal.Status, proposal.Reason, time.Now().Unix())
+	return err
+}
+
+// GetRejectedProposals returns the latest rejected proposals, newest first.
+func (store *ProposalDataStoreImpl) GetRejectedProposals(limit int) ([]*RejectedProposal, error) {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	rows, err := store.Query(`SELECT TransactionHash, GenesisBlockAddress, Amount, Status, Reason, Approved, RecordTime FROM RejectedProposals
+				ORDER BY RecordTime DESC, Id DESC LIMIT ?`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var proposals []*RejectedProposal
+	for rows.Next() {
+		p := &RejectedProposal{}
+		var amount int64
+		err = rows.Scan(&p.TransactionHash, &p.GenesisBlockAddress, &amount, &p.Status, &p.Reason,
+			&p.Approved, &p.RecordTime)
+		if err != nil {
+			return nil, err
+		}
+		p.Amount = common.Fixed64(amount)
+		proposals = append(proposals, p)
+	}
+	return proposals, nil
+}
+
+// ApproveProposal approves the proposal waiting for approval, it will be
+// signed when it is proposed again.
+func (store *ProposalDataStoreImpl) ApproveProposal(transactionHash string) (*RejectedProposal, error) {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	rows, err := store.Query(`SELECT GenesisBlockAddress, Amount, Status, Reason, Approved, RecordTime FROM RejectedProposals
+				WHERE TransactionHash=?`, transactionHash)
+	if err != nil {
+		return nil, err
+	}
+	p := &RejectedProposal{TransactionHash: transactionHash}
+	var amount int64
+	found := rows.Next()
+	if found {
+		err = rows.Scan(&p.GenesisBlockAddress, &amount, &p.Status, &p.Reason, &p.Approved, &p.RecordTime)
+	}
+	rows.Close()
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, errors.New("rejected proposal not found")
+	}
+	if p.Status != ProposalNeedApproval {
+		return nil, errors.New("proposal is not waiting for approval")
+	}
+	p.Amount = common.Fixed64(amount)
+
+	if _, err := store.Exec("UPDATE RejectedProposals SET Approved=1 WHERE TransactionHash=?", transactionHash); err != nil {
+		return nil, err
+	}
+	p.Approved = true
+	return p, nil
+}
+
+func (store *ProposalDataStoreImpl) IsProposalApproved(transactionHash string) (bool, error) {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	rows, err := store.Query(`SELECT Id FROM RejectedProposals WHERE TransactionHash=? AND Approved=1`, transactionHash)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	return rows.Next(), nil
+}
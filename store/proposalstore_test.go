@@ -0,0 +1,93 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProposalDataStoreImpl_WithdrawUsages(t *testing.T) {
+	datastore, err := OpenProposalDataStore()
+	if err != nil {
+		t.Fatal("Open database error.")
+	}
+
+	usages := []*WithdrawUsage{
+		{GenesisBlockAddress: "testAddress", Reference: "testHash1", Address: "address1", Amount: 100},
+		{GenesisBlockAddress: "testAddress", Reference: "testHash2", Address: "address2", Amount: 200},
+	}
+	if err := datastore.AddWithdrawUsages(usages); err != nil {
+		t.Error("Add withdraw usages error.", err)
+	}
+	if err := datastore.AddWithdrawUsages(usages[:1]); err != nil {
+		t.Error("Add withdraw usages again error.", err)
+	}
+
+	got, err := datastore.GetWithdrawUsages(time.Now().Add(-time.Hour).Unix())
+	if err != nil || len(got) != 2 {
+		t.Fatal("Withdraw usages should be recorded only once.")
+	}
+	for _, u := range got {
+		if u.RecordTime == 0 || u.Reference == "testHash2" && u.Amount != 200 {
+			t.Error("Get withdraw usages error.")
+		}
+	}
+
+	if err := datastore.RemoveWithdrawUsages(time.Now().Add(time.Hour).Unix()); err != nil {
+		t.Error("Remove withdraw usages error.")
+	}
+	got, _ = datastore.GetWithdrawUsages(0)
+	if len(got) != 0 {
+		t.Error("Old withdraw usages should be removed.")
+	}
+
+	datastore.ResetDataStore(ProposalsDBName)
+}
+
+func TestProposalDataStoreImpl_RejectedProposal(t *testing.T) {
+	datastore, err := OpenProposalDataStore()
+	if err != nil {
+		t.Fatal("Open database error.")
+	}
+
+	rejected := &RejectedProposal{
+		TransactionHash:     "testHash1",
+		GenesisBlockAddress: "testAddress",
+		Amount:              100,
+		Status:              ProposalRejected,
+		Reason:              "test",
+	}
+	if err := datastore.AddRejectedProposal(rejected); err != nil {
+		t.Error("Add rejected proposal error.", err)
+	}
+	if _, err := datastore.ApproveProposal("testHash1"); err == nil {
+		t.Error("Rejected proposal should not be approved.")
+	}
+	if _, err := datastore.ApproveProposal("testHash2"); err == nil {
+		t.Error("Unknown proposal should not be approved.")
+	}
+
+	rejected.Status = ProposalNeedApproval
+	rejected.Reason = "outlier"
+	if err := datastore.AddRejectedProposal(rejected); err != nil {
+		t.Error("Add rejected proposal again error.", err)
+	}
+	p, err := datastore.ApproveProposal("testHash1")
+	if err != nil || !p.Approved || p.Amount != 100 {
+		t.Error("Approve proposal error.", err)
+	}
+	if ok, err := datastore.IsProposalApproved("testHash1"); err != nil || !ok {
+		t.Error("Proposal should be approved.")
+	}
+
+	rejected.Status = ProposalRejected
+	datastore.AddRejectedProposal(rejected)
+	proposals, err := datastore.GetRejectedProposals(10)
+	if err != nil || len(proposals) != 1 {
+		t.Fatal("Get rejected proposals error.")
+	}
+	if proposals[0].Status != ProposalRejected || !proposals[0].Approved || proposals[0].RecordTime == 0 {
+		t.Error("Approval should be kept when rejected again.")
+	}
+
+	datastore.ResetDataStore(ProposalsDBName)
+}
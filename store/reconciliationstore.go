@@ -0,0 +1,175 @@
+package store
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/elastos/Elastos.ELA.Arbiter/log"
+
+	"github.com/elastos/Elastos.ELA/common"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+var ReconciliationDBName = filepath.Join(DBDocumentNAME, "reconciliation.db")
+
+const (
+	//Locked: the balance of the genesis address on the main chain
+	//Discrepancy: Locked minus Supply, PendingWithdraws and PendingDeposits
+	//Error: the reason the side chain is not reconciled, the amounts are
+	//unknown if it is set
+	CreateReconciliationReportsTable = `CREATE TABLE IF NOT EXISTS ReconciliationReports (
+				Id INTEGER NOT NULL PRIMARY KEY,
+				GenesisBlockAddress VARCHAR(34),
+				Locked INTEGER,
+				Supply INTEGER,
+				PendingWithdraws INTEGER,
+				PendingDeposits INTEGER,
+				Discrepancy INTEGER,
+				Flagged BOOLEAN,
+				Error TEXT,
+				ReportTime INTEGER
+			);`
+)
+
+var (
+	ReconciliationDbCache ReconciliationDataStore
+)
+
+// ReconciliationReport is the reconciliation of a side chain at a time.
+type ReconciliationReport struct {
+	GenesisBlockAddress string
+	Locked              common.Fixed64
+	Supply              common.Fixed64
+	PendingWithdraws    common.Fixed64
+	PendingDeposits     common.Fixed64
+	Discrepancy         common.Fixed64
+	Flagged             bool
+	Error               string
+	ReportTime          int64
+}
+
+type ReconciliationDataStore interface {
+	AddReconciliationReport(report *ReconciliationReport) error
+	GetReconciliationReports(genesisBlockAddress string, from, to int64) ([]*ReconciliationReport, error)
+	RemoveReconciliationReportsBefore(reportTime int64) error
+
+	ResetDataStore(dbName string) error
+}
+
+type ReconciliationDataStoreImpl struct {
+	mux *sync.Mutex
+
+	*sql.DB
+}
+
+func OpenReconciliationDataStore() (ReconciliationDataStore, error) {
+	db, err := initReconciliationDB()
+	if err != nil {
+		return nil, err
+	}
+	dataStore := &ReconciliationDataStoreImpl{DB: db, mux: new(sync.Mutex)}
+
+	// Handle system interrupt signals
+	dataStore.catchSystemSignals()
+
+	return dataStore, nil
+}
+
+func initReconciliationDB() (*sql.DB, error) {
+	err := CheckAndCreateDocument(DBDocumentNAME)
+	if err != nil {
+		log.Error("Create DBCache doucument error:", err)
+		return nil, err
+	}
+	db, err := sql.Open(DriverName, ReconciliationDBName)
+	if err != nil {
+		log.Error("Open data db error:", err)
+		return nil, err
+	}
+	// Create reconciliation reports table
+	_, err = db.Exec(CreateReconciliationReportsTable)
+	if err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+func (store *ReconciliationDataStoreImpl) catchSystemSignals() {
+	HandleSignal(func() {
+		store.mux.Lock()
+		store.Close()
+		os.Exit(-1)
+	})
+}
+
+func (store *ReconciliationDataStoreImpl) ResetDataStore(dbName string) error {
+
+	store.DB.Close()
+	os.Remove(dbName)
+
+	var err error
+	store.DB, err = initReconciliationDB()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (store *ReconciliationDataStoreImpl) AddReconciliationReport(report *ReconciliationReport) error {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	_, err := store.Exec(`INSERT INTO ReconciliationReports(GenesisBlockAddress, Locked, Supply, PendingWithdraws,
+				PendingDeposits, Discrepancy, Flagged, Error, ReportTime) values(?,?,?,?,?,?,?,?,?)`,
+		report.GenesisBlockAddress, int64(report.Locked), int64(report.Supply), int64(report.PendingWithdraws),
+		int64(report.PendingDeposits), int64(report.Discrepancy), report.Flagged, report.Error, report.ReportTime)
+	return err
+}
+
+// GetReconciliationReports returns the reports of the side chain made in
+// [from, to), or the reports of all side chains if genesisBlockAddress is
+// empty.
+func (store *ReconciliationDataStoreImpl) GetReconciliationReports(genesisBlockAddress string, from, to int64) ([]*ReconciliationReport, error) {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	rows, err := store.Query(`SELECT GenesisBlockAddress, Locked, Supply, PendingWithdraws, PendingDeposits,
+				Discrepancy, Flagged, Error, ReportTime FROM ReconciliationReports
+				WHERE (?='' OR GenesisBlockAddress=?) AND ReportTime>=? AND ReportTime<? ORDER BY Id`,
+		genesisBlockAddress, genesisBlockAddress, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reports []*ReconciliationReport
+	for rows.Next() {
+		r := &ReconciliationReport{}
+		var locked, supply, pendingWithdraws, pendingDeposits, discrepancy int64
+		err = rows.Scan(&r.GenesisBlockAddress, &locked, &supply, &pendingWithdraws, &pendingDeposits,
+			&discrepancy, &r.Flagged, &r.Error, &r.ReportTime)
+		if err != nil {
+			return nil, err
+		}
+		r.Locked = common.Fixed64(locked)
+		r.Supply = common.Fixed64(supply)
+		r.PendingWithdraws = common.Fixed64(pendingWithdraws)
+		r.PendingDeposits = common.Fixed64(pendingDeposits)
+		r.Discrepancy = common.Fixed64(discrepancy)
+		reports = append(reports, r)
+	}
+	return reports, nil
+}
+
+// RemoveReconciliationReportsBefore removes the reports made before the unix
+// time.
+func (store *ReconciliationDataStoreImpl) RemoveReconciliationReportsBefore(reportTime int64) error {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	_, err := store.Exec("DELETE FROM ReconciliationReports WHERE ReportTime<?", reportTime)
+	return err
+}
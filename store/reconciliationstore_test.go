@@ -0,0 +1,56 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReconciliationDataStoreImpl(t *testing.T) {
+	datastore, err := OpenReconciliationDataStore()
+	if err != nil {
+		t.Fatal("Open database error.")
+	}
+
+	now := time.Now().Unix()
+	report := &ReconciliationReport{
+		GenesisBlockAddress: "testAddress",
+		Locked:              1000,
+		Supply:              900,
+		PendingWithdraws:    50,
+		PendingDeposits:     20,
+		Discrepancy:         30,
+		Flagged:             true,
+		ReportTime:          now,
+	}
+	if err := datastore.AddReconciliationReport(report); err != nil {
+		t.Error("Add reconciliation report error.", err)
+	}
+	datastore.AddReconciliationReport(&ReconciliationReport{GenesisBlockAddress: "testAddress2",
+		Error: "side chain unreachable", ReportTime: now - 3600})
+
+	reports, err := datastore.GetReconciliationReports("testAddress", now, now+1)
+	if err != nil || len(reports) != 1 {
+		t.Fatal("Get reconciliation reports by side chain error.", err)
+	}
+	if *reports[0] != *report {
+		t.Errorf("Reconciliation report recorded wrongly, %+v", reports[0])
+	}
+	reports, _ = datastore.GetReconciliationReports("", now-7200, now+1)
+	if len(reports) != 2 || reports[1].Error != "side chain unreachable" {
+		t.Error("Get reconciliation reports in range error.")
+	}
+	reports, _ = datastore.GetReconciliationReports("", now-7200, now)
+	if len(reports) != 1 || reports[0].GenesisBlockAddress != "testAddress2" {
+		t.Error("Reconciliation reports out of range should not be returned.")
+	}
+
+	if err := datastore.RemoveReconciliationReportsBefore(now); err != nil {
+		t.Error("Remove reconciliation reports error.", err)
+	}
+	reports, _ = datastore.GetReconciliationReports("", now-7200, now+1)
+	if len(reports) != 1 || reports[0].GenesisBlockAddress != "testAddress" {
+		t.Error("Reconciliation reports before the time should be removed.")
+	}
+
+	datastore.ResetDataStore(ReconciliationDBName)
+}
@@ -0,0 +1,207 @@
+package store
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/elastos/Elastos.ELA.Arbiter/log"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+var RegistrationsDBName = filepath.Join(DBDocumentNAME, "registrations.db")
+
+const (
+	// RegistrationPending is the status of registered side chains waiting for
+	// the operator to review.
+	RegistrationPending = "pending"
+
+	// RegistrationApproved is the status of registered side chains approved
+	// and activated.
+	RegistrationApproved = "approved"
+
+	// RegistrationRejected is the status of registered side chains rejected
+	// by the operator or the probe of side node.
+	RegistrationRejected = "rejected"
+)
+
+const (
+	//RpcInfo: the json of rpc information set by setregistersidechainrpcinfo
+	//SideNodeConfig: the json of side node config activated if approved
+	CreateRegistrationReviewsTable = `CREATE TABLE IF NOT EXISTS RegistrationReviews (
+				Id INTEGER NOT NULL PRIMARY KEY,
+				TransactionHash VARCHAR,
+				GenesisBlockAddress VARCHAR(34),
+				RpcInfo TEXT DEFAULT '',
+				Status VARCHAR(10),
+				Reason TEXT DEFAULT '',
+				SideNodeConfig TEXT DEFAULT '',
+				RecordTime INTEGER,
+				UpdateTime INTEGER,
+				UNIQUE (TransactionHash, GenesisBlockAddress)
+			);`
+)
+
+var (
+	RegistrationDbCache RegistrationDataStore
+)
+
+// RegistrationReview is the review of a side chain registered on the main
+// chain.
+type RegistrationReview struct {
+	TransactionHash     string
+	GenesisBlockAddress string
+	RpcInfo             string
+	Status              string
+	Reason              string
+	SideNodeConfig      string
+	RecordTime          int64
+	UpdateTime          int64
+}
+
+type RegistrationDataStore interface {
+	SetRegistrationRpcInfo(transactionHash, genesisBlockAddress, rpcInfo string) error
+	SetRegistrationReview(review *RegistrationReview) error
+	GetRegistrationReview(transactionHash, genesisBlockAddress string) (*RegistrationReview, error)
+	GetRegistrationReviews() ([]*RegistrationReview, error)
+
+	ResetDataStore(dbName string) error
+}
+
+type RegistrationDataStoreImpl struct {
+	mux *sync.Mutex
+
+	*sql.DB
+}
+
+func OpenRegistrationDataStore() (RegistrationDataStore, error) {
+	db, err := initRegistrationDB()
+	if err != nil {
+		return nil, err
+	}
+	dataStore := &RegistrationDataStoreImpl{DB: db, mux: new(sync.Mutex)}
+
+	// Handle system interrupt signals
+	dataStore.catchSystemSignals()
+
+	return dataStore, nil
+}
+
+func initRegistrationDB() (*sql.DB, error) {
+	err := CheckAndCreateDocument(DBDocumentNAME)
+	if err != nil {
+		log.Error("Create DBCache doucument error:", err)
+		return nil, err
+	}
+	db, err := sql.Open(DriverName, RegistrationsDBName)
+	if err != nil {
+		log.Error("Open data db error:", err)
+		return nil, err
+	}
+	// Create registration reviews table
+	_, err = db.Exec(CreateRegistrationReviewsTable)
+	if err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+func (store *RegistrationDataStoreImpl) catchSystemSignals() {
+	HandleSignal(func() {
+		store.mux.Lock()
+		store.Close()
+		os.Exit(-1)
+	})
+}
+
+func (store *RegistrationDataStoreImpl) ResetDataStore(dbName string) error {
+
+	store.DB.Close()
+	os.Remove(dbName)
+
+	var err error
+	store.DB, err = initRegistrationDB()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SetRegistrationRpcInfo records the rpc information of the side chain
+// waiting for review, the status of a review already recorded is kept.
+func (store *RegistrationDataStoreImpl) SetRegistrationRpcInfo(transactionHash, genesisBlockAddress, rpcInfo string) error {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	now := time.Now().Unix()
+	_, err := store.Exec(`INSERT INTO RegistrationReviews(TransactionHash, GenesisBlockAddress, RpcInfo, Status,
+				RecordTime, UpdateTime) values(?,?,?,?,?,?)
+				ON CONFLICT(TransactionHash, GenesisBlockAddress) DO UPDATE SET RpcInfo=excluded.RpcInfo,
+				UpdateTime=excluded.UpdateTime`,
+		transactionHash, genesisBlockAddress, rpcInfo, RegistrationPending, now, now)
+	return err
+}
+
+// SetRegistrationReview records the status, reason and side node config of
+// the review, the rpc information recorded is kept.
+func (store *RegistrationDataStoreImpl) SetRegistrationReview(review *RegistrationReview) error {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	now := time.Now().Unix()
+	_, err := store.Exec(`INSERT INTO RegistrationReviews(TransactionHash, GenesisBlockAddress, Status, Reason,
+				SideNodeConfig, RecordTime, UpdateTime) values(?,?,?,?,?,?,?)
+				ON CONFLICT(TransactionHash, GenesisBlockAddress) DO UPDATE SET Status=excluded.Status,
+				Reason=excluded.Reason, SideNodeConfig=excluded.SideNodeConfig, UpdateTime=excluded.UpdateTime`,
+		review.TransactionHash, review.GenesisBlockAddress, review.Status, review.Reason,
+		review.SideNodeConfig, now, now)
+	return err
+}
+
+// GetRegistrationReview returns the review of the registration, nil if it
+// is not recorded.
+func (store *RegistrationDataStoreImpl) GetRegistrationReview(transactionHash, genesisBlockAddress string) (*RegistrationReview, error) {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	r := &RegistrationReview{}
+	err := store.QueryRow(`SELECT TransactionHash, GenesisBlockAddress, RpcInfo, Status, Reason, SideNodeConfig,
+				RecordTime, UpdateTime FROM RegistrationReviews WHERE TransactionHash=? AND GenesisBlockAddress=?`,
+		transactionHash, genesisBlockAddress).Scan(&r.TransactionHash, &r.GenesisBlockAddress, &r.RpcInfo,
+		&r.Status, &r.Reason, &r.SideNodeConfig, &r.RecordTime, &r.UpdateTime)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (store *RegistrationDataStoreImpl) GetRegistrationReviews() ([]*RegistrationReview, error) {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	rows, err := store.Query(`SELECT TransactionHash, GenesisBlockAddress, RpcInfo, Status, Reason, SideNodeConfig,
+				RecordTime, UpdateTime FROM RegistrationReviews ORDER BY Id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reviews []*RegistrationReview
+	for rows.Next() {
+		r := &RegistrationReview{}
+		err = rows.Scan(&r.TransactionHash, &r.GenesisBlockAddress, &r.RpcInfo, &r.Status, &r.Reason,
+			&r.SideNodeConfig, &r.RecordTime, &r.UpdateTime)
+		if err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, r)
+	}
+	return reviews, nil
+}
@@ -0,0 +1,41 @@
+package store
+
+import (
+	"testing"
+)
+
+func TestRegistrationDataStoreImpl(t *testing.T) {
+	datastore, err := OpenRegistrationDataStore()
+	if err != nil {
+		t.Fatal("Open database error.")
+	}
+
+	if err := datastore.SetRegistrationRpcInfo("testHash1", "testAddress", `{"ipaddr":"127.0.0.1"}`); err != nil {
+		t.Error("Set registration rpc info error.", err)
+	}
+	if err := datastore.SetRegistrationReview(&RegistrationReview{TransactionHash: "testHash1",
+		GenesisBlockAddress: "testAddress", Status: RegistrationRejected, Reason: "genesis block mismatch"}); err != nil {
+		t.Error("Set registration review error.", err)
+	}
+	datastore.SetRegistrationRpcInfo("testHash1", "testAddress", `{"ipaddr":"127.0.0.2"}`)
+	datastore.SetRegistrationReview(&RegistrationReview{TransactionHash: "testHash2",
+		GenesisBlockAddress: "testAddress2", Status: RegistrationApproved, SideNodeConfig: `{"Name":"ESC"}`})
+
+	r, err := datastore.GetRegistrationReview("testHash1", "testAddress")
+	if err != nil || r == nil {
+		t.Fatal("Get registration review error.", err)
+	}
+	if r.Status != RegistrationRejected || r.Reason != "genesis block mismatch" ||
+		r.RpcInfo != `{"ipaddr":"127.0.0.2"}` {
+		t.Errorf("Registration review recorded wrongly, %+v", r)
+	}
+	if r, _ := datastore.GetRegistrationReview("testHash3", "testAddress"); r != nil {
+		t.Error("Registration review not recorded should be nil.")
+	}
+	reviews, err := datastore.GetRegistrationReviews()
+	if err != nil || len(reviews) != 2 || reviews[1].SideNodeConfig != `{"Name":"ESC"}` {
+		t.Error("Get registration reviews error.", err)
+	}
+
+	datastore.ResetDataStore(RegistrationsDBName)
+}
@@ -0,0 +1,301 @@
+package store
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/elastos/Elastos.ELA.Arbiter/log"
+
+	"github.com/elastos/Elastos.ELA/common"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+var SideMiningDBName = filepath.Join(DBDocumentNAME, "sideMining.db")
+
+const (
+	// SideMiningSent is the result of SideChainPow transactions sent to the
+	// main chain and not submitted to the side chain yet.
+	SideMiningSent = "sent"
+
+	// SideMiningSubmitted is the result of SideChainPow transactions whose
+	// auxpow is accepted by the side chain.
+	SideMiningSubmitted = "submitted"
+
+	// SideMiningFailed is the result of SideChainPow transactions whose
+	// auxpow failed to be submitted to the side chain.
+	SideMiningFailed = "failed"
+
+	// SideMiningSkipped is the result of SideChainPow transactions whose
+	// auxpow is not submitted since the side chain has moved on.
+	SideMiningSkipped = "skipped"
+)
+
+const (
+	//SendHeight: the main chain height when the transaction is sent
+	//InclusionHeight: the main chain height of the block including the transaction, 0 if not found yet
+	//FeeStrategy, FeeReason: the fee strategy deciding the fee and why it decided so
+	CreateSideMiningTxsTable = `CREATE TABLE IF NOT EXISTS SideMiningTxs (
+				Id INTEGER NOT NULL PRIMARY KEY,
+				TransactionHash VARCHAR UNIQUE,
+				GenesisBlockAddress VARCHAR(34),
+				SideBlockHash VARCHAR,
+				SideBlockHeight INTEGER,
+				Fee INTEGER,
+				SendHeight INTEGER,
+				SendTime INTEGER,
+				InclusionHeight INTEGER DEFAULT 0,
+				Result VARCHAR(10),
+				Error TEXT DEFAULT '',
+				SubmitTime INTEGER DEFAULT 0,
+				FeeStrategy VARCHAR DEFAULT '',
+				FeeReason TEXT DEFAULT ''
+			);`
+	//NextRetry: the unix time of submitting the auxpow again
+	CreateAuxpowRetriesTable = `CREATE TABLE IF NOT EXISTS AuxpowRetries (
+				Id INTEGER NOT NULL PRIMARY KEY,
+				TransactionHash VARCHAR UNIQUE,
+				GenesisHash VARCHAR,
+				SideBlockHash VARCHAR,
+				SideBlockHeight INTEGER,
+				Auxpow TEXT,
+				Attempts INTEGER,
+				NextRetry INTEGER
+			);`
+)
+
+var (
+	SideMiningDbCache SideMiningDataStore
+)
+
+// SideMiningTx is a SideChainPow transaction sent by the arbiter and the
+// result of submitting its auxpow to the side chain.
+type SideMiningTx struct {
+	TransactionHash     string
+	GenesisBlockAddress string
+	SideBlockHash       string
+	SideBlockHeight     uint32
+	Fee                 common.Fixed64
+	SendHeight          uint32
+	SendTime            int64
+	InclusionHeight     uint32
+	Result              string
+	Error               string
+	SubmitTime          int64
+	FeeStrategy         string
+	FeeReason           string
+}
+
+// AuxpowRetry is the auxpow of a SideChainPow transaction refused by the
+// side chain and to be submitted again.
+type AuxpowRetry struct {
+	TransactionHash string
+	GenesisHash     string
+	SideBlockHash   string
+	SideBlockHeight uint32
+	Auxpow          string
+	Attempts        int
+	NextRetry       int64
+}
+
+type SideMiningDataStore interface {
+	AddSideMiningTx(tx *SideMiningTx) error
+	UpdateSideMiningInclusion(transactionHash string, height uint32) error
+	UpdateSideMiningResult(transactionHash, result, errMsg string) error
+	GetSideMiningTxs(genesisBlockAddress string, from, to int64) ([]*SideMiningTx, error)
+
+	SetAuxpowRetry(retry *AuxpowRetry) error
+	GetAuxpowRetries(genesisHash string) ([]*AuxpowRetry, error)
+	RemoveAuxpowRetry(transactionHash string) error
+
+	ResetDataStore(dbName string) error
+}
+
+type SideMiningDataStoreImpl struct {
+	mux *sync.Mutex
+
+	*sql.DB
+}
+
+func OpenSideMiningDataStore() (SideMiningDataStore, error) {
+	db, err := initSideMiningDB()
+	if err != nil {
+		return nil, err
+	}
+	dataStore := &SideMiningDataStoreImpl{DB: db, mux: new(sync.Mutex)}
+
+	// Handle system interrupt signals
+	dataStore.catchSystemSignals()
+
+	return dataStore, nil
+}
+
+func initSideMiningDB() (*sql.DB, error) {
+	err := CheckAndCreateDocument(DBDocumentNAME)
+	if err != nil {
+		log.Error("Create DBCache doucument error:", err)
+		return nil, err
+	}
+	db, err := sql.Open(DriverName, SideMiningDBName)
+	if err != nil {
+		log.Error("Open data db error:", err)
+		return nil, err
+	}
+	// Create side mining transactions table
+	_, err = db.Exec(CreateSideMiningTxsTable)
+	if err != nil {
+		return nil, err
+	}
+	// Create auxpow retries table
+	_, err = db.Exec(CreateAuxpowRetriesTable)
+	if err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+func (store *SideMiningDataStoreImpl) catchSystemSignals() {
+	HandleSignal(func() {
+		store.mux.Lock()
+		store.Close()
+		os.Exit(-1)
+	})
+}
+
+func (store *SideMiningDataStoreImpl) ResetDataStore(dbName string) error {
+
+	store.DB.Close()
+	os.Remove(dbName)
+
+	var err error
+	store.DB, err = initSideMiningDB()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// AddSideMiningTx records the SideChainPow transaction sent, the transaction
+// already recorded is kept unchanged.
+func (store *SideMiningDataStoreImpl) AddSideMiningTx(tx *SideMiningTx) error {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	stmt, err := store.Prepare(`INSERT OR IGNORE INTO SideMiningTxs(TransactionHash, GenesisBlockAddress, SideBlockHash, SideBlockHeight, Fee, SendHeight, SendTime, Result,
+				FeeStrategy, FeeReason) values(?,?,?,?,?,?,?,?,?,?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(tx.TransactionHash, tx.GenesisBlockAddress, tx.SideBlockHash, tx.SideBlockHeight,
+		int64(tx.Fee), tx.SendHeight, tx.SendTime, SideMiningSent, tx.FeeStrategy, tx.FeeReason)
+	return err
+}
+
+// UpdateSideMiningInclusion records the main chain height of the block
+// including the SideChainPow transaction.
+func (store *SideMiningDataStoreImpl) UpdateSideMiningInclusion(transactionHash string, height uint32) error {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	_, err := store.Exec("UPDATE SideMiningTxs SET InclusionHeight=? WHERE TransactionHash=?",
+		height, transactionHash)
+	return err
+}
+
+// UpdateSideMiningResult records the result of submitting the auxpow of the
+// SideChainPow transaction to the side chain.
+func (store *SideMiningDataStoreImpl) UpdateSideMiningResult(transactionHash, result, errMsg string) error {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	_, err := store.Exec("UPDATE SideMiningTxs SET Result=?, Error=?, SubmitTime=? WHERE TransactionHash=?",
+		result, errMsg, time.Now().Unix(), transactionHash)
+	return err
+}
+
+// GetSideMiningTxs returns the SideChainPow transactions sent in the range
+// of unix time [from, to), empty genesisBlockAddress matches all side chains.
+func (store *SideMiningDataStoreImpl) GetSideMiningTxs(genesisBlockAddress string, from, to int64) ([]*SideMiningTx, error) {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	rows, err := store.Query(`SELECT TransactionHash, GenesisBlockAddress, SideBlockHash, SideBlockHeight, Fee, SendHeight, SendTime,
+				InclusionHeight, Result, Error, SubmitTime, FeeStrategy, FeeReason FROM SideMiningTxs
+				WHERE (?='' OR GenesisBlockAddress=?) AND SendTime>=? AND SendTime<? ORDER BY Id`,
+		genesisBlockAddress, genesisBlockAddress, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var txs []*SideMiningTx
+	for rows.Next() {
+		tx := &SideMiningTx{}
+		var fee int64
+		err = rows.Scan(&tx.TransactionHash, &tx.GenesisBlockAddress, &tx.SideBlockHash, &tx.SideBlockHeight,
+			&fee, &tx.SendHeight, &tx.SendTime, &tx.InclusionHeight, &tx.Result, &tx.Error, &tx.SubmitTime,
+			&tx.FeeStrategy, &tx.FeeReason)
+		if err != nil {
+			return nil, err
+		}
+		tx.Fee = common.Fixed64(fee)
+		txs = append(txs, tx)
+	}
+	return txs, nil
+}
+
+// SetAuxpowRetry records the auxpow to be submitted again, replacing the one
+// of the same SideChainPow transaction.
+func (store *SideMiningDataStoreImpl) SetAuxpowRetry(retry *AuxpowRetry) error {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	stmt, err := store.Prepare(`INSERT OR REPLACE INTO AuxpowRetries(TransactionHash, GenesisHash, SideBlockHash, SideBlockHeight, Auxpow,
+				Attempts, NextRetry) values(?,?,?,?,?,?,?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(retry.TransactionHash, retry.GenesisHash, retry.SideBlockHash, retry.SideBlockHeight,
+		retry.Auxpow, retry.Attempts, retry.NextRetry)
+	return err
+}
+
+// GetAuxpowRetries returns the auxpows of the side chain to be submitted again.
+func (store *SideMiningDataStoreImpl) GetAuxpowRetries(genesisHash string) ([]*AuxpowRetry, error) {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	rows, err := store.Query(`SELECT TransactionHash, GenesisHash, SideBlockHash, SideBlockHeight, Auxpow, Attempts, NextRetry
+				FROM AuxpowRetries WHERE GenesisHash=? ORDER BY Id`, genesisHash)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var retries []*AuxpowRetry
+	for rows.Next() {
+		retry := &AuxpowRetry{}
+		err = rows.Scan(&retry.TransactionHash, &retry.GenesisHash, &retry.SideBlockHash, &retry.SideBlockHeight,
+			&retry.Auxpow, &retry.Attempts, &retry.NextRetry)
+		if err != nil {
+			return nil, err
+		}
+		retries = append(retries, retry)
+	}
+	return retries, nil
+}
+
+func (store *SideMiningDataStoreImpl) RemoveAuxpowRetry(transactionHash string) error {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	_, err := store.Exec("DELETE FROM AuxpowRetries WHERE TransactionHash=?", transactionHash)
+	return err
+}
@@ -0,0 +1,92 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSideMiningDataStoreImpl(t *testing.T) {
+	datastore, err := OpenSideMiningDataStore()
+	if err != nil {
+		t.Fatal("Open database error.")
+	}
+
+	now := time.Now().Unix()
+	tx := &SideMiningTx{
+		TransactionHash:     "testHash1",
+		GenesisBlockAddress: "testAddress",
+		SideBlockHash:       "testBlock1",
+		SideBlockHeight:     100,
+		Fee:                 10000,
+		SendHeight:          1000,
+		SendTime:            now,
+		FeeStrategy:         "bumponmiss",
+		FeeReason:           "last transaction missed",
+	}
+	if err := datastore.AddSideMiningTx(tx); err != nil {
+		t.Error("Add side mining transaction error.", err)
+	}
+	datastore.AddSideMiningTx(&SideMiningTx{TransactionHash: "testHash2", GenesisBlockAddress: "testAddress2",
+		SendTime: now - 3600})
+
+	if err := datastore.UpdateSideMiningInclusion("testHash1", 1001); err != nil {
+		t.Error("Update side mining inclusion error.", err)
+	}
+	if err := datastore.UpdateSideMiningResult("testHash1", SideMiningFailed, "block not found"); err != nil {
+		t.Error("Update side mining result error.", err)
+	}
+
+	txs, err := datastore.GetSideMiningTxs("testAddress", now, now+1)
+	if err != nil || len(txs) != 1 {
+		t.Fatal("Get side mining transactions by side chain error.", err)
+	}
+	got := txs[0]
+	if got.Fee != 10000 || got.SideBlockHeight != 100 || got.InclusionHeight != 1001 ||
+		got.Result != SideMiningFailed || got.Error != "block not found" || got.SubmitTime == 0 ||
+		got.FeeStrategy != "bumponmiss" || got.FeeReason != "last transaction missed" {
+		t.Errorf("Side mining transaction recorded wrongly, %+v", got)
+	}
+	txs, _ = datastore.GetSideMiningTxs("", now-7200, now+1)
+	if len(txs) != 2 || txs[1].Result != SideMiningSent {
+		t.Error("Get side mining transactions in range error.")
+	}
+	txs, _ = datastore.GetSideMiningTxs("", now-7200, now)
+	if len(txs) != 1 || txs[0].TransactionHash != "testHash2" {
+		t.Error("Side mining transactions out of range should not be returned.")
+	}
+
+	datastore.ResetDataStore(SideMiningDBName)
+}
+
+func TestSideMiningDataStoreImpl_AuxpowRetry(t *testing.T) {
+	datastore, err := OpenSideMiningDataStore()
+	if err != nil {
+		t.Fatal("Open database error.")
+	}
+
+	retry := &AuxpowRetry{TransactionHash: "testHash", GenesisHash: "testGenesis", SideBlockHash: "testBlock",
+		SideBlockHeight: 100, Auxpow: "testAuxpow", Attempts: 1, NextRetry: 10}
+	if err := datastore.SetAuxpowRetry(retry); err != nil {
+		t.Error("Set auxpow retry error.", err)
+	}
+	retry.Attempts, retry.NextRetry = 2, 20
+	if err := datastore.SetAuxpowRetry(retry); err != nil {
+		t.Error("Set auxpow retry again error.", err)
+	}
+	retries, err := datastore.GetAuxpowRetries("testGenesis")
+	if err != nil || len(retries) != 1 || *retries[0] != *retry {
+		t.Error("Latest auxpow retry should be kept.")
+	}
+	if retries, _ := datastore.GetAuxpowRetries("otherGenesis"); len(retries) != 0 {
+		t.Error("Auxpow retries of other side chains should not be returned.")
+	}
+
+	if err := datastore.RemoveAuxpowRetry("testHash"); err != nil {
+		t.Error("Remove auxpow retry error.")
+	}
+	if retries, _ := datastore.GetAuxpowRetries("testGenesis"); len(retries) != 0 {
+		t.Error("Auxpow retry should be removed.")
+	}
+
+	datastore.ResetDataStore(SideMiningDBName)
+}
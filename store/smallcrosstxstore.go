@@ -0,0 +1,267 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/elastos/Elastos.ELA.Arbiter/log"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+var SmallCrossTxsDBName = filepath.Join(DBDocumentNAME, "smallCrossTxs.db")
+
+const (
+	// SmallCrossTxPending is the state of small cross transfers signed but
+	// not sent to the side chain yet.
+	SmallCrossTxPending = "pending"
+
+	// SmallCrossTxSent is the state of small cross transfers accepted by the
+	// side chain.
+	SmallCrossTxSent = "sent"
+
+	// SmallCrossTxFailed is the state of small cross transfers failed to be
+	// sent or refused by the side chain, they are sent again.
+	SmallCrossTxFailed = "failed"
+
+	// SmallCrossTxFinished is the state of small cross transfers appeared in
+	// the side chain, they are kept so they are never signed again while the
+	// main chain still lists them.
+	SmallCrossTxFinished = "finished"
+)
+
+const (
+	//Response: the latest response or error of the side chain
+	CreateSmallCrossTxsTable = `CREATE TABLE IF NOT EXISTS SmallCrossTxs (
+				Id INTEGER NOT NULL PRIMARY KEY,
+				TransactionHash VARCHAR,
+				GenesisBlockAddress VARCHAR(34),
+				TransactionData BLOB,
+				Signature BLOB,
+				State VARCHAR(10),
+				Response TEXT,
+				Attempts INTEGER,
+				FirstSeen INTEGER,
+				LastAttempt INTEGER,
+				UNIQUE (TransactionHash, GenesisBlockAddress)
+			);`
+)
+
+var (
+	SmallCrossTxsDbCache SmallCrossTransfersDataStore
+)
+
+type SmallCrossTx struct {
+	TransactionHash     string
+	GenesisBlockAddress string
+	TransactionData     []byte
+	Signature           []byte
+	State               string
+	Response            string
+	Attempts            int
+	FirstSeen           int64
+	LastAttempt         int64
+}
+
+type SmallCrossTransfersDataStore interface {
+	AddSmallCrossTx(tx *SmallCrossTx) error
+	GetSmallCrossTx(transactionHash, genesisBlockAddress string) (*SmallCrossTx, error)
+	GetSmallCrossTxs(genesisBlockAddress, state string) ([]*SmallCrossTx, error)
+	UpdateSmallCrossTxState(transactionHash, genesisBlockAddress, state, response string) error
+	FinishSmallCrossTxs(transactionHashes, genesisBlockAddresses []string) error
+	RemoveSmallCrossTxsBefore(firstSeen int64) error
+
+	ResetDataStore(dbName string) error
+}
+
+type SmallCrossTxsDataStoreImpl struct {
+	mux *sync.Mutex
+
+	*sql.DB
+}
+
+func OpenSmallCrossTxsDataStore() (SmallCrossTransfersDataStore, error) {
+	db, err := initSmallCrossTxsDB()
+	if err != nil {
+		return nil, err
+	}
+	dataStore := &SmallCrossTxsDataStoreImpl{DB: db, mux: new(sync.Mutex)}
+
+	// Handle system interrupt signals
+	dataStore.catchSystemSignals()
+
+	return dataStore, nil
+}
+
+func initSmallCrossTxsDB() (*sql.DB, error) {
+	err := CheckAndCreateDocument(DBDocumentNAME)
+	if err != nil {
+		log.Error("Create DBCache doucument error:", err)
+		return nil, err
+	}
+	db, err := sql.Open(DriverName, SmallCrossTxsDBName)
+	if err != nil {
+		log.Error("Open data db error:", err)
+		return nil, err
+	}
+	// Create small cross transfers table
+	_, err = db.Exec(CreateSmallCrossTxsTable)
+	if err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+func (store *SmallCrossTxsDataStoreImpl) catchSystemSignals() {
+	HandleSignal(func() {
+		store.mux.Lock()
+		store.Close()
+		os.Exit(-1)
+	})
+}
+
+func (store *SmallCrossTxsDataStoreImpl) ResetDataStore(dbName string) error {
+
+	store.DB.Close()
+	os.Remove(dbName)
+
+	var err error
+	store.DB, err = initSmallCrossTxsDB()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// AddSmallCrossTx records the signed small cross transfer in pending state,
+// the transfer already recorded is kept unchanged.
+func (store *SmallCrossTxsDataStoreImpl) AddSmallCrossTx(tx *SmallCrossTx) error {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	stmt, err := store.Prepare(`INSERT OR IGNORE INTO SmallCrossTxs(TransactionHash, GenesisBlockAddress, TransactionData, Signature, State, Response, Attempts, FirstSeen, LastAttempt)
+				values(?,?,?,?,?,'',0,?,0)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(tx.TransactionHash, tx.GenesisBlockAddress, tx.TransactionData, tx.Signature,
+		SmallCrossTxPending, time.Now().Unix())
+	return err
+}
+
+func (store *SmallCrossTxsDataStoreImpl) GetSmallCrossTx(transactionHash, genesisBlockAddress string) (*SmallCrossTx, error) {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	rows, err := store.Query(`SELECT TransactionData, Signature, State, Response, Attempts, FirstSeen, LastAttempt FROM SmallCrossTxs
+				WHERE TransactionHash=? AND GenesisBlockAddress=?`, transactionHash, genesisBlockAddress)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, errors.New("small cross transfer not found")
+	}
+	tx := &SmallCrossTx{
+		TransactionHash:     transactionHash,
+		GenesisBlockAddress: genesisBlockAddress,
+	}
+	err = rows.Scan(&tx.TransactionData, &tx.Signature, &tx.State, &tx.Response, &tx.Attempts,
+		&tx.FirstSeen, &tx.LastAttempt)
+	if err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// GetSmallCrossTxs returns the small cross transfers without their data,
+// empty genesisBlockAddress or state matches all.
+func (store *SmallCrossTxsDataStoreImpl) GetSmallCrossTxs(genesisBlockAddress, state string) ([]*SmallCrossTx, error) {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	rows, err := store.Query(`SELECT TransactionHash, GenesisBlockAddress, State, Response, Attempts, FirstSeen, LastAttempt FROM SmallCrossTxs
+				WHERE (?='' OR GenesisBlockAddress=?) AND (?='' OR State=?) ORDER BY Id`,
+		genesisBlockAddress, genesisBlockAddress, state, state)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var txs []*SmallCrossTx
+	for rows.Next() {
+		tx := &SmallCrossTx{}
+		err = rows.Scan(&tx.TransactionHash, &tx.GenesisBlockAddress, &tx.State, &tx.Response, &tx.Attempts,
+			&tx.FirstSeen, &tx.LastAttempt)
+		if err != nil {
+			return nil, err
+		}
+		txs = append(txs, tx)
+	}
+	return txs, nil
+}
+
+// UpdateSmallCrossTxState records the result of an attempt of sending the
+// small cross transfer to the side chain.
+func (store *SmallCrossTxsDataStoreImpl) UpdateSmallCrossTxState(transactionHash, genesisBlockAddress, state, response string) error {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	stmt, err := store.Prepare(`UPDATE SmallCrossTxs SET State=?, Response=?, Attempts=Attempts+1, LastAttempt=?
+				WHERE TransactionHash=? AND GenesisBlockAddress=?`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(state, response, time.Now().Unix(), transactionHash, genesisBlockAddress)
+	return err
+}
+
+// FinishSmallCrossTxs records the small cross transfers appeared in the side
+// chain, the ones not recorded yet are added.
+func (store *SmallCrossTxsDataStoreImpl) FinishSmallCrossTxs(transactionHashes, genesisBlockAddresses []string) error {
+	if len(transactionHashes) != len(genesisBlockAddresses) {
+		return errors.New("invalid parameter, transactionHashes and genesisBlockAddresses should be in pair")
+	}
+
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	tx, err := store.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Commit()
+
+	stmt, err := tx.Prepare(`INSERT INTO SmallCrossTxs(TransactionHash, GenesisBlockAddress, State, Response, Attempts, FirstSeen, LastAttempt)
+				values(?,?,?,'',0,?,0) ON CONFLICT(TransactionHash, GenesisBlockAddress) DO UPDATE SET State=excluded.State`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now().Unix()
+	for i := 0; i < len(transactionHashes); i++ {
+		stmt.Exec(transactionHashes[i], genesisBlockAddresses[i], SmallCrossTxFinished, now)
+	}
+	return nil
+}
+
+// RemoveSmallCrossTxsBefore removes the small cross transfers first seen
+// before the unix time.
+func (store *SmallCrossTxsDataStoreImpl) RemoveSmallCrossTxsBefore(firstSeen int64) error {
+	store.mux.Lock()
+	defer store.mux.Unlock()
+
+	_, err := store.Exec("DELETE FROM SmallCrossTxs WHERE FirstSeen<?", firstSeen)
+	return err
+}
@@ -0,0 +1,64 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSmallCrossTxsDataStoreImpl(t *testing.T) {
+	datastore, err := OpenSmallCrossTxsDataStore()
+	if err != nil {
+		t.Fatal("Open database error.")
+	}
+
+	tx := &SmallCrossTx{
+		TransactionHash:     "testHash1",
+		GenesisBlockAddress: "testAddress",
+		TransactionData:     []byte{1, 2, 3},
+		Signature:           []byte{4, 5},
+	}
+	if err := datastore.AddSmallCrossTx(tx); err != nil {
+		t.Error("Add small cross transfer error.", err)
+	}
+	tx.Signature = []byte{6}
+	if err := datastore.AddSmallCrossTx(tx); err != nil {
+		t.Error("Add small cross transfer again error.", err)
+	}
+	datastore.AddSmallCrossTx(&SmallCrossTx{TransactionHash: "testHash2", GenesisBlockAddress: "testAddress2"})
+
+	got, err := datastore.GetSmallCrossTx("testHash1", "testAddress")
+	if err != nil || len(got.TransactionData) != 3 || len(got.Signature) != 2 ||
+		got.State != SmallCrossTxPending || got.FirstSeen == 0 {
+		t.Error("Small cross transfer recorded should be kept.")
+	}
+
+	if err := datastore.UpdateSmallCrossTxState("testHash1", "testAddress", SmallCrossTxSent, "true"); err != nil {
+		t.Error("Update small cross transfer error.", err)
+	}
+	txs, err := datastore.GetSmallCrossTxs("", SmallCrossTxSent)
+	if err != nil || len(txs) != 1 || txs[0].Attempts != 1 || txs[0].Response != "true" || txs[0].LastAttempt == 0 {
+		t.Error("Get small cross transfers by state error.")
+	}
+	txs, err = datastore.GetSmallCrossTxs("testAddress2", "")
+	if err != nil || len(txs) != 1 || txs[0].TransactionHash != "testHash2" {
+		t.Error("Get small cross transfers by side chain error.")
+	}
+
+	if err := datastore.FinishSmallCrossTxs([]string{"testHash1", "testHash3"},
+		[]string{"testAddress", "testAddress"}); err != nil {
+		t.Error("Finish small cross transfers error.")
+	}
+	txs, _ = datastore.GetSmallCrossTxs("testAddress", SmallCrossTxFinished)
+	if len(txs) != 2 || txs[0].TransactionHash != "testHash1" || txs[1].TransactionHash != "testHash3" {
+		t.Error("Small cross transfers appeared in side chain should be finished.")
+	}
+	if err := datastore.RemoveSmallCrossTxsBefore(time.Now().Add(time.Hour).Unix()); err != nil {
+		t.Error("Remove old small cross transfers error.")
+	}
+	txs, _ = datastore.GetSmallCrossTxs("", "")
+	if len(txs) != 0 {
+		t.Error("Small cross transfers should be removed.")
+	}
+
+	datastore.ResetDataStore(SmallCrossTxsDBName)
+}